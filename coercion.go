@@ -2,7 +2,9 @@
 Package coercion provides a workflow engine that can execute complex workflows using
 reusable plugins. This is designed for localized workflows and not workflows on shared mediums.
 Aka, there are no policy engines, emergency stop systems or centralization mechanisms that keep
-teams from running over each other.
+teams from running over each other. Within a Workstream, Blocks and Sequences can lock named resources
+with workflow.Locks so that its Plans do not run over each other on them. A Plan can wait for other Plans
+to finish with its workflow.Plan.DependsOn, and Plans can be chained with a workflow.Pipeline.
 
 Use of this package encourages using github.com/gostdlib/base/init.Service() in your main after your flag parsing.
 */
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/element-of-surprise/coercion/internal/execute"
+	"github.com/element-of-surprise/coercion/internal/schedule"
 	"github.com/element-of-surprise/coercion/plugins/registry"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/errors"
@@ -21,6 +24,7 @@ import (
 	"github.com/element-of-surprise/coercion/workflow/utils/walk"
 	"github.com/google/uuid"
 	"github.com/gostdlib/base/context"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // This makes UUID generation much faster.
@@ -41,9 +45,12 @@ type Result[T any] struct {
 type Workstream struct {
 	reg   *registry.Register
 	exec  *execute.Plans
+	sched *schedule.Scheduler
 	store storage.Vault
 
 	execOptions []execute.Option
+	catchUp     workflow.CatchUp
+	noRecovery  bool
 }
 
 // Option is an optional argument for New(). For future use.
@@ -74,6 +81,58 @@ func WithMaxSubmit(d time.Duration) Option {
 func WithNoRecovery() Option {
 	return func(w *Workstream) error {
 		w.execOptions = append(w.execOptions, execute.WithNoRecovery())
+		w.noRecovery = true
+		return nil
+	}
+}
+
+// WithHooks sets Hooks that are called as the objects in a Plan change state. This can be used for notifications,
+// audit logs or metrics. See workflow.Hooks for details on when Hooks are called.
+func WithHooks(h workflow.Hooks) Option {
+	return func(w *Workstream) error {
+		w.execOptions = append(w.execOptions, execute.WithHooks(h))
+		return nil
+	}
+}
+
+// WithTracerProvider sets the TracerProvider used to trace Plans. Each Plan has a span with a child span for each
+// Checks, Block, Sequence, Action and Attempt. The span of an Attempt is in the Context passed to the plugin, so
+// plugins can add their own spans to the trace. If this is not set, the global TracerProvider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(w *Workstream) error {
+		w.execOptions = append(w.execOptions, execute.WithTracerProvider(tp))
+		return nil
+	}
+}
+
+// WithMaxRunningPlans sets the maximum number of plans that can run at the same time. A plan that is started once
+// this is reached has a Status of workflow.Queued until a running plan finishes. Queued plans run in the order of
+// their workflow.Plan.Priority and then in the order that they were started. The queue is kept in storage, so
+// queued plans keep their order if they are recovered. A queued plan can be stopped, but not paused. If this is
+// not set, there is no limit.
+func WithMaxRunningPlans(n int) Option {
+	return func(w *Workstream) error {
+		w.execOptions = append(w.execOptions, execute.WithMaxRunningPlans(n))
+		return nil
+	}
+}
+
+// WithCatchUp sets the workflow.CatchUp of the plans scheduled with StartAt or Schedule. This is what is done
+// about runs that were due while the Workstream was down. The CatchUp is stored with the schedule, so a
+// schedule keeps the CatchUp it was created with. If this is not set, the default is workflow.CUOnce.
+func WithCatchUp(c workflow.CatchUp) Option {
+	return func(w *Workstream) error {
+		w.catchUp = c
+		return nil
+	}
+}
+
+// WithClock sets the function that returns the current time when running plans. This is the time that the
+// workflow.Windows of plans and blocks are evaluated at and that is recorded in the State of objects. This is
+// useful for testing maintenance windows. If this is not set, time.Now is used.
+func WithClock(now func() time.Time) Option {
+	return func(w *Workstream) error {
+		w.execOptions = append(w.execOptions, execute.WithClock(now))
 		return nil
 	}
 }
@@ -107,6 +166,13 @@ func New(ctx context.Context, reg *registry.Register, store storage.Vault, optio
 	}
 	ws.exec = exec
 
+	ws.sched = schedule.New(ctx, store, ws.Submit, exec.Start)
+	if !ws.noRecovery {
+		if err := ws.sched.Recover(ctx); err != nil {
+			return nil, errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+	}
+
 	return ws, nil
 }
 
@@ -119,15 +185,100 @@ type defaulter interface {
 // Start() to begin execution. Using the Plan object after submitting it results in undefined behavior.
 // To get the status of the plan, use the Status method.
 func (w *Workstream) Submit(ctx context.Context, plan *workflow.Plan) (uuid.UUID, error) {
-	if err := w.populateRegistry(ctx, plan); err != nil {
+	if err := w.validate(ctx, plan); err != nil {
 		return uuid.Nil, err
 	}
-	w.requestDefaults(ctx, plan)
-
-	if err := workflow.Validate(plan); err != nil {
+	if err := w.create(ctx, plan); err != nil {
 		return uuid.Nil, err
 	}
+	return plan.ID, nil
+}
+
+// SubmitPipeline submits the Plans of a workflow.Pipeline to the Workstream. Each workflow.Edge is added to
+// the DependsOn of the Plan it goes to as a workflow.PlanDep on the Plan it comes from. It returns the UUIDs of
+// the Plans in the order of Pipeline.Plans. If the Pipeline or any of its Plans is invalid, an error is returned
+// and nothing is submitted. If a Plan cannot be written to storage, the Plans that were written are deleted
+// before the error is returned. As with Submit, the Plans are not executed. Each must be started with Start, after
+// which it has a Status of workflow.Waiting until the Plans it depends on have finished.
+func (w *Workstream) SubmitPipeline(ctx context.Context, p *workflow.Pipeline) ([]uuid.UUID, error) {
+	if err := workflow.ValidatePipeline(p); err != nil {
+		return nil, err
+	}
+	for _, plan := range p.Plans {
+		if err := w.validate(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	byName := make(map[string]*workflow.Plan, len(p.Plans))
+	for _, plan := range p.Plans {
+		byName[plan.Name] = plan
+	}
+	into := map[string][]workflow.Edge{}
+	for _, e := range p.Edges {
+		into[e.To] = append(into[e.To], e)
+	}
+
+	// A Plan gets its ID when it is created, so the Plans that it depends on are created first.
+	created := map[string]bool{}
+	var create func(plan *workflow.Plan) error
+	create = func(plan *workflow.Plan) error {
+		if created[plan.Name] {
+			return nil
+		}
+		for _, e := range into[plan.Name] {
+			from := byName[e.From]
+			if err := create(from); err != nil {
+				return err
+			}
+			plan.DependsOn = append(plan.DependsOn, &workflow.PlanDep{ID: from.ID, On: e.On})
+		}
+		if err := w.create(ctx, plan); err != nil {
+			return err
+		}
+		created[plan.Name] = true
+		return nil
+	}
+
+	for _, plan := range p.Plans {
+		if err := create(plan); err != nil {
+			w.deletePlans(ctx, p.Plans, created)
+			return nil, err
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(p.Plans))
+	for _, plan := range p.Plans {
+		ids = append(ids, plan.ID)
+	}
+	return ids, nil
+}
+
+// deletePlans deletes the Plans in plans whose Name is in created from storage.
+func (w *Workstream) deletePlans(ctx context.Context, plans []*workflow.Plan, created map[string]bool) {
+	ctx = context.WithoutCancel(ctx)
+	for _, plan := range plans {
+		if !created[plan.Name] {
+			continue
+		}
+		if err := w.store.Delete(ctx, plan.ID); err != nil {
+			context.Log(ctx).Error("could not delete plan of a pipeline that failed to submit", "id", plan.ID, "error", err)
+		}
+	}
+}
+
+// validate readies plan for submission and validates it.
+func (w *Workstream) validate(ctx context.Context, plan *workflow.Plan) error {
+	if err := w.populateRegistry(ctx, plan); err != nil {
+		return err
+	}
+	w.requestDefaults(ctx, plan)
 
+	return workflow.Validate(plan)
+}
+
+// create sets the defaults of plan, which must have been validated, and writes it to storage.
+func (w *Workstream) create(ctx context.Context, plan *workflow.Plan) error {
 	for item := range walk.Plan(plan) {
 		if def, ok := item.Value.(defaulter); ok {
 			def.Defaults()
@@ -135,11 +286,7 @@ func (w *Workstream) Submit(ctx context.Context, plan *workflow.Plan) (uuid.UUID
 	}
 	plan.SubmitTime = w.now()
 
-	if err := w.store.Create(ctx, plan); err != nil {
-		return uuid.Nil, err
-	}
-
-	return plan.ID, nil
+	return w.store.Create(ctx, plan)
 }
 
 type setPlanIDer interface {
@@ -176,10 +323,76 @@ func (w *Workstream) populateRegistry(ctx context.Context, plan *workflow.Plan)
 }
 
 // Start begins execution of a plan with the given id. The plan must have been submitted to the workstream.
+// If the plan has a workflow.Plan.DependsOn, it has a Status of workflow.Waiting until the plans it depends on
+// have finished and is workflow.Skipped if they did not finish as it needs. The plans it depends on by
+// GroupID are the ones in the group when it is started. It is an error to start a plan that depends on
+// itself through other plans. A waiting plan can be stopped with Stop.
+// If the Workstream was created WithMaxRunningPlans and that many plans are running, the plan is queued.
 func (w *Workstream) Start(ctx context.Context, id uuid.UUID) error {
 	return w.exec.Start(ctx, id)
 }
 
+// StartAt starts the plan with the given id at t. The plan must have been submitted to the workstream and not
+// started. Until t, the plan has a Status of workflow.Scheduled and its workflow.Plan.Schedule records when it
+// starts. If t has passed, the plan is started right away. The schedule is kept in storage, so it survives a
+// restart. A start that was missed while the Workstream was down is handled by WithCatchUp. Wait waits for the
+// plan to start and then finish. A scheduled plan that has not started can be cancelled with Stop.
+func (w *Workstream) StartAt(ctx context.Context, id uuid.UUID, t time.Time) error {
+	return w.sched.StartAt(ctx, id, t, w.catchUp)
+}
+
+// Schedule makes the plan with the given id the template of a recurring schedule. Each time the cron expression
+// is due, the template is cloned with clone.Plan and the clone is submitted and started. The template must have
+// been submitted to the workstream and not started. It has a Status of workflow.Scheduled and is never run
+// itself. Its workflow.Plan.Schedule records the next run and the ID of the last run, and the runs have the
+// GroupID of the template. The cron expression has the five fields minute, hour, day of month, month and day
+// of week and is evaluated in UTC. The schedule is kept in storage, so it survives a restart. Runs that were
+// missed while the Workstream was down are handled by WithCatchUp. A schedule is ended with Stop on the template.
+func (w *Workstream) Schedule(ctx context.Context, id uuid.UUID, cron string) error {
+	return w.sched.Schedule(ctx, id, cron, w.catchUp)
+}
+
+// Stop stops execution of a running plan with the given id. Actions that are executing are allowed to finish
+// or time out, but nothing new is started. DeferredActions and DeferredChecks still run as configured. Anything that
+// did not finish is marked Stopped and the Plan's Reason is set to workflow.FRStopped. While stopping, the plan
+// has a Status of workflow.Stopping, which recovery honors by finishing the stop. Stop returns once the final
+// state has been written to storage. If the plan has already finished, this returns nil. If the plan is
+// waiting on its schedule from StartAt or Schedule, the schedule is cancelled and the plan is recorded as Stopped.
+func (w *Workstream) Stop(ctx context.Context, id uuid.UUID) error {
+	if ok, err := w.sched.Stop(ctx, id); ok || err != nil {
+		return err
+	}
+	return w.exec.Stop(ctx, id)
+}
+
+// Pause pauses a running plan with the given id. Blocks and Sequences that are running are allowed to finish,
+// but no new ones are started until Resume is called. While paused, the plan has a Status of workflow.Paused,
+// which is kept if the plan is recovered. A paused plan can still be stopped with Stop.
+func (w *Workstream) Pause(ctx context.Context, id uuid.UUID) error {
+	return w.exec.Pause(ctx, id)
+}
+
+// Resume resumes a plan with the given id that was paused with Pause. If the plan is not paused, this does nothing.
+func (w *Workstream) Resume(ctx context.Context, id uuid.UUID) error {
+	return w.exec.Resume(ctx, id)
+}
+
+// Approve approves the workflow.Approval with key in a running plan with the given id on behalf of approver,
+// which is required. The Block or Sequence waiting at the Approval's gate continues. An Approval can be approved
+// before its gate is reached. The decision and approver are stored with the plan and kept if it is recovered.
+// It is an error to decide an Approval that has already been decided or has expired.
+func (w *Workstream) Approve(ctx context.Context, id uuid.UUID, key, approver string) error {
+	return w.exec.Approve(ctx, id, key, approver)
+}
+
+// Reject rejects the workflow.Approval with key in a running plan with the given id on behalf of approver,
+// which is required. The Block or Sequence with the Approval fails. The decision and approver are stored with
+// the plan and kept if it is recovered. It is an error to decide an Approval that has already been decided or
+// has expired.
+func (w *Workstream) Reject(ctx context.Context, id uuid.UUID, key, approver string) error {
+	return w.exec.Reject(ctx, id, key, approver)
+}
+
 // Plan returns the plan with the given id. If the plan does not exist, an error is returned.
 func (w *Workstream) Plan(ctx context.Context, id uuid.UUID) (*workflow.Plan, error) {
 	return w.store.Read(ctx, id)
@@ -187,8 +400,12 @@ func (w *Workstream) Plan(ctx context.Context, id uuid.UUID) (*workflow.Plan, er
 
 // Wait waits for the plan with the given id to complete and returns the Plan's final state.
 // If the plan does not exist, an error is returned. If the context is canceled, the error
-// will be context.Canceled.
+// will be context.Canceled. If the plan was scheduled with StartAt, this waits for it to start. It is an error
+// to wait on the template of a Schedule.
 func (w *Workstream) Wait(ctx context.Context, id uuid.UUID) (*workflow.Plan, error) {
+	if err := w.sched.Wait(ctx, id); err != nil {
+		return nil, err
+	}
 	if err := w.exec.Wait(ctx, id); err != nil {
 		return nil, err
 	}
@@ -218,7 +435,7 @@ func (w *Workstream) Status(ctx context.Context, id uuid.UUID, interval time.Dur
 				if !yield(Result[*workflow.Plan]{Data: plan, Err: nil}) {
 					return
 				}
-				if plan.State.Get().Status != workflow.Running {
+				if !plan.State.Get().Status.Active() {
 					return
 				}
 			}
@@ -226,6 +443,27 @@ func (w *Workstream) Status(ctx context.Context, id uuid.UUID, interval time.Dur
 	}
 }
 
+// Events returns an iterator of the changes to the plan with the given id as they are written to storage. This
+// is an alternative to Status that does not poll. The iterator first yields a Snapshot Event for every object in
+// the plan as it is in storage, then an Event for each change that follows. Iteration terminates when the plan
+// finishes or the Context is canceled. If the plan is not running in this process, only the Snapshot is yielded.
+// If the caller falls thousands of changes behind, the changes it has not read are dropped and a new Snapshot of
+// the plan is yielded in their place. If the plan cannot be read, a single Result with Err set is yielded.
+func (w *Workstream) Events(ctx context.Context, id uuid.UUID) iter.Seq[Result[workflow.Event]] {
+	return func(yield func(Result[workflow.Event]) bool) {
+		events, err := w.exec.Events(ctx, id)
+		if err != nil {
+			yield(Result[workflow.Event]{Err: err})
+			return
+		}
+		for ev := range events {
+			if !yield(Result[workflow.Event]{Data: ev}) {
+				return
+			}
+		}
+	}
+}
+
 func (w *Workstream) now() time.Time {
 	return time.Now().UTC()
 }
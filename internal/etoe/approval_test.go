@@ -0,0 +1,141 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEApproval tests that a Block waits at its Approval until it is approved and that a Sequence whose
+// Approval was rejected before its gate fails without running its Actions.
+func TestEtoEApproval(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("approval etoe", "tests Approvals etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: builder.New: %v", err)
+	}
+	build.AddBlock(
+		builder.BlockArgs{
+			Name:              "block0",
+			Descr:             "block0",
+			Concurrency:       1,
+			ToleratedFailures: 1,
+			Approval:          &workflow.Approval{Key: "release", Expiry: time.Minute},
+		},
+	)
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:     "seq1",
+			Descr:    "seq1",
+			Approval: &workflow.Approval{Key: "seq1"},
+			Actions:  []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEApproval: Start: %v", err)
+	}
+
+	if err := ws.Reject(ctx, id, "seq1", ""); err == nil {
+		t.Errorf("TestEtoEApproval: Reject with no approver: got err == nil, want err != nil")
+	}
+	if err := ws.Reject(ctx, id, "seq1", "reviewer"); err != nil {
+		t.Fatalf("TestEtoEApproval: Reject(seq1): %v", err)
+	}
+
+	// Wait for block0 to reach its gate before we approve it.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		p, err := ws.Plan(ctx, id)
+		if err != nil {
+			t.Fatalf("TestEtoEApproval: Plan: %v", err)
+		}
+		if p.Blocks[0].Approval.Decision.Get().Status == workflow.APWaiting {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("TestEtoEApproval: block0 never waited at its Approval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := plug.Calls.Load(); got != 0 {
+		t.Errorf("TestEtoEApproval: got %d plugin calls before the Approval, want 0", got)
+	}
+
+	if err := ws.Approve(ctx, id, "release", "operator"); err != nil {
+		t.Fatalf("TestEtoEApproval: Approve(release): %v", err)
+	}
+	if err := ws.Approve(ctx, id, "release", "operator"); err == nil {
+		t.Errorf("TestEtoEApproval: second Approve(release): got err == nil, want err != nil")
+	}
+
+	result, err := ws.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEApproval: Wait: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEApproval: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	block0 := result.Blocks[0]
+	if d := block0.Approval.Decision.Get(); d.Status != workflow.APApproved || d.Approver != "operator" || d.Requested.IsZero() {
+		t.Errorf("TestEtoEApproval: block0 Decision = %+v, want Approved by operator after it was Requested", d)
+	}
+	if got := block0.Sequences[0].State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEApproval: seq0 status = %v, want %v", got, workflow.Completed)
+	}
+	seq1 := block0.Sequences[1]
+	if got := seq1.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestEtoEApproval: seq1 status = %v, want %v", got, workflow.Failed)
+	}
+	if d := seq1.Approval.Decision.Get(); d.Status != workflow.APRejected || d.Approver != "reviewer" {
+		t.Errorf("TestEtoEApproval: seq1 Decision = %+v, want Rejected by reviewer", d)
+	}
+	if got := plug.Calls.Load(); got != 1 {
+		t.Errorf("TestEtoEApproval: got %d plugin calls, want 1", got)
+	}
+	if err := ws.Approve(ctx, id, "release", "operator"); err == nil {
+		t.Errorf("TestEtoEApproval: Approve of a finished plan: got err == nil, want err != nil")
+	}
+}
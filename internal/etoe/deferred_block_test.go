@@ -0,0 +1,119 @@
+package etoe
+
+import (
+	"testing"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEBlockSequenceDeferredActions tests that the DeferredActions of Blocks and Sequences run
+// the batches that match how their Block or Sequence ended and that the results are stored.
+func TestEtoEBlockSequenceDeferredActions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	seq := func(name, arg string) *workflow.Sequence {
+		return &workflow.Sequence{
+			Name:  name,
+			Descr: name,
+			Actions: []*workflow.Action{
+				{Name: name + "-action", Descr: name + "-action", Plugin: testplugin.Name, Req: testplugin.Req{Arg: arg}},
+			},
+		}
+	}
+
+	build, err := builder.New("block deferred actions etoe", "tests Block and Sequence DeferredActions etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(seq("seq0", ""))
+	build.AddDeferredActions()
+	build.AddDeferBatch(newDeferBatch("seq0-onSuccess", workflow.OnSuccess, false, "")).Up()
+	build.AddDeferBatch(newDeferBatch("seq0-onFailure", workflow.OnFailure, false, "")).Up()
+	build.Up().Up()
+	build.AddSequence(seq("seq1", "error"))
+	build.AddDeferredActions()
+	build.AddDeferBatch(newDeferBatch("seq1-onFailure", workflow.OnFailure, false, "")).Up()
+	build.Up().Up()
+	build.AddDeferredActions()
+	build.AddDeferBatch(newDeferBatch("block0-onSuccess", workflow.OnSuccess, false, "")).Up()
+	build.AddDeferBatch(newDeferBatch("block0-onFailure", workflow.OnFailure, false, "")).Up()
+	build.AddDeferBatch(newDeferBatch("block0-always", workflow.Always, false, "")).Up()
+	build.Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Failed {
+		t.Fatalf("TestEtoEBlockSequenceDeferredActions: plan status = %v, want %v", got, workflow.Failed)
+	}
+
+	block0 := result.Blocks[0]
+	tests := []struct {
+		name string
+		da   *workflow.DeferredActions
+		want []workflow.Status
+	}{
+		{name: "seq0", da: block0.Sequences[0].DeferredActions, want: []workflow.Status{workflow.Completed, workflow.NotStarted}},
+		{name: "seq1", da: block0.Sequences[1].DeferredActions, want: []workflow.Status{workflow.Completed}},
+		{name: "block0", da: block0.DeferredActions, want: []workflow.Status{workflow.NotStarted, workflow.Completed, workflow.Completed}},
+	}
+	for _, test := range tests {
+		if test.da == nil {
+			t.Errorf("TestEtoEBlockSequenceDeferredActions(%s): DeferredActions is nil", test.name)
+			continue
+		}
+		if got := test.da.State.Get().Status; got != workflow.Completed {
+			t.Errorf("TestEtoEBlockSequenceDeferredActions(%s): DeferredActions status = %v, want %v", test.name, got, workflow.Completed)
+		}
+		if len(test.da.DeferredBatches) != len(test.want) {
+			t.Errorf("TestEtoEBlockSequenceDeferredActions(%s): batch count = %d, want %d", test.name, len(test.da.DeferredBatches), len(test.want))
+			continue
+		}
+		for i, b := range test.da.DeferredBatches {
+			if got := b.State.Get().Status; got != test.want[i] {
+				t.Errorf("TestEtoEBlockSequenceDeferredActions(%s): batch[%d] %s status = %v, want %v", test.name, i, b.Name, got, test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package etoe
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"github.com/google/uuid"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEDependsOn tests that Blocks start after the Blocks in their DependsOn and that Blocks that
+// are ready run at the same time up to the Plan's Concurrency.
+func TestEtoEDependsOn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	key := workflow.NewV7()
+	seq := func(name string) *workflow.Sequence {
+		return &workflow.Sequence{
+			Name:  name,
+			Descr: name,
+			Actions: []*workflow.Action{
+				{Name: name, Descr: name, Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 200 * time.Millisecond}},
+			},
+		}
+	}
+
+	build, err := builder.New("depends etoe", "tests DependsOn etoe", builder.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: builder.New: %v", err)
+	}
+	// block1 and block2 both wait for block0, then run together.
+	build.AddBlock(builder.BlockArgs{Name: "block1", Descr: "block1", Concurrency: 1, DependsOn: []uuid.UUID{key}})
+	build.AddSequence(seq("seq1")).Up().Up()
+	build.AddBlock(builder.BlockArgs{Name: "block2", Descr: "block2", Concurrency: 1, DependsOn: []uuid.UUID{key}})
+	build.AddSequence(seq("seq2")).Up().Up()
+	build.AddBlock(builder.BlockArgs{Key: key, Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(seq("seq0")).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEDependsOn: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEDependsOn: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEDependsOn: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoEDependsOn: plan status = %v, want %v", got, workflow.Completed)
+	}
+	if result.Concurrency != 2 {
+		t.Errorf("TestEtoEDependsOn: stored plan Concurrency = %d, want 2", result.Concurrency)
+	}
+
+	block1, block2, block0 := result.Blocks[0].State.Get(), result.Blocks[1].State.Get(), result.Blocks[2].State.Get()
+	for i, b := range result.Blocks {
+		if b.State.Get().Status != workflow.Completed {
+			t.Errorf("TestEtoEDependsOn: block(%d) status = %v, want %v", i, b.State.Get().Status, workflow.Completed)
+		}
+	}
+	if block1.Start.Before(block0.End) || block2.Start.Before(block0.End) {
+		t.Errorf("TestEtoEDependsOn: block1 or block2 started before block0 ended")
+	}
+	if !block1.Start.Before(block2.End) || !block2.Start.Before(block1.End) {
+		t.Errorf("TestEtoEDependsOn: block1 and block2 did not run at the same time")
+	}
+	if !slices.Equal(result.Blocks[0].DependsOn, []uuid.UUID{key}) {
+		t.Errorf("TestEtoEDependsOn: stored block1 DependsOn = %v, want %v", result.Blocks[0].DependsOn, []uuid.UUID{key})
+	}
+}
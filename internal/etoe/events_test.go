@@ -0,0 +1,122 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+func TestEtoEEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEEvents: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("events etoe", "tests that Events works etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEEvents: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 1 * time.Second}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEEvents: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEEvents: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEEvents: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEEvents: Start: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var (
+		events       []workflow.Event
+		live         bool
+		planStatus   workflow.Status
+		actionDone   bool
+		attemptCount int
+	)
+	for r := range ws.Events(ctx, id) {
+		if r.Err != nil {
+			t.Fatalf("TestEtoEEvents: Events: %v", r.Err)
+		}
+		ev := r.Data
+		events = append(events, ev)
+
+		if !ev.Snapshot {
+			live = true
+		} else if live {
+			t.Errorf("TestEtoEEvents: got Snapshot Event after a change Event")
+		}
+		if ev.Type == workflow.OTPlan {
+			planStatus = ev.NewStatus
+		}
+		if ev.Type == workflow.OTAction && !ev.Snapshot {
+			if ev.NewStatus == workflow.Completed {
+				actionDone = true
+			}
+			if ev.Attempt != nil {
+				attemptCount++
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("TestEtoEEvents: Events did not end when the plan finished")
+	}
+
+	if len(events) == 0 || !events[0].Snapshot || events[0].Type != workflow.OTPlan || events[0].ID != id {
+		t.Errorf("TestEtoEEvents: first Event should be a Snapshot of the Plan, got %+v", events)
+	}
+	if planStatus != workflow.Completed {
+		t.Errorf("TestEtoEEvents: last Plan Event status = %v, want %v", planStatus, workflow.Completed)
+	}
+	if !actionDone {
+		t.Errorf("TestEtoEEvents: did not get an Event for action0 completing")
+	}
+	if attemptCount != 1 {
+		t.Errorf("TestEtoEEvents: got %d Attempt Events, want 1", attemptCount)
+	}
+
+	// A finished plan only has its Snapshot.
+	for r := range ws.Events(ctx, id) {
+		if r.Err != nil {
+			t.Fatalf("TestEtoEEvents: Events after finish: %v", r.Err)
+		}
+		if !r.Data.Snapshot {
+			t.Errorf("TestEtoEEvents: Events after finish: got change Event %+v", r.Data)
+		}
+	}
+}
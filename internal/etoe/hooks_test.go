@@ -0,0 +1,136 @@
+package etoe
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// countHooks counts the Hooks that are called and panics on OnActionStart to prove that a bad Hook
+// does not hurt the Plan.
+type countHooks struct {
+	workflow.NoOpHooks
+
+	mu     sync.Mutex
+	counts map[string]int
+	ended  chan struct{}
+}
+
+func (c *countHooks) inc(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[name]++
+}
+
+func (c *countHooks) OnPlanStart(ctx context.Context, p *workflow.Plan) { c.inc("OnPlanStart") }
+func (c *countHooks) OnPlanEnd(ctx context.Context, p *workflow.Plan) {
+	c.inc("OnPlanEnd")
+	close(c.ended)
+}
+func (c *countHooks) OnBlockStart(ctx context.Context, b *workflow.Block) { c.inc("OnBlockStart") }
+func (c *countHooks) OnBlockEnd(ctx context.Context, b *workflow.Block)   { c.inc("OnBlockEnd") }
+func (c *countHooks) OnSequenceEnd(ctx context.Context, s *workflow.Sequence) {
+	c.inc("OnSequenceEnd")
+}
+func (c *countHooks) OnActionStart(ctx context.Context, a *workflow.Action) {
+	c.inc("OnActionStart")
+	panic("bad hook")
+}
+func (c *countHooks) OnActionAttempt(ctx context.Context, a *workflow.Action, attempt workflow.Attempt) {
+	c.inc("OnActionAttempt")
+}
+func (c *countHooks) OnActionEnd(ctx context.Context, a *workflow.Action) { c.inc("OnActionEnd") }
+
+func TestEtoEHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("hooks etoe", "tests that Hooks work etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 2})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq1",
+			Descr:   "seq1",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: build.Plan: %v", err)
+	}
+
+	hooks := &countHooks{counts: map[string]int{}, ended: make(chan struct{})}
+	ws, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEHooks: Start: %v", err)
+	}
+	result, err := ws.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEHooks: Wait: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEHooks: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	select {
+	case <-hooks.ended:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("TestEtoEHooks: OnPlanEnd was never called")
+	}
+
+	want := map[string]int{
+		"OnPlanStart":     1,
+		"OnPlanEnd":       1,
+		"OnBlockStart":    1,
+		"OnBlockEnd":      1,
+		"OnSequenceEnd":   2,
+		"OnActionStart":   2,
+		"OnActionAttempt": 2,
+		"OnActionEnd":     2,
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	for name, n := range want {
+		if got := hooks.counts[name]; got != n {
+			t.Errorf("TestEtoEHooks: %s called %d times, want %d", name, got, n)
+		}
+	}
+}
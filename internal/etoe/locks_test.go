@@ -0,0 +1,118 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"github.com/google/uuid"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoELocks tests that the Blocks of two Plans that lock the same resource run one after the other
+// when either lock is Exclusive, and at the same time when both are Shared.
+func TestEtoELocks(t *testing.T) {
+	t.Parallel()
+
+	const resource = "cluster/eastus-1"
+
+	tests := []struct {
+		name        string
+		locks       []*workflow.Locks
+		wantOverlap bool
+	}{
+		{
+			name:  "Exclusive locks run one after the other",
+			locks: []*workflow.Locks{{Exclusive: []string{resource}}, {Exclusive: []string{resource}}},
+		},
+		{
+			name:  "Exclusive waits for Shared",
+			locks: []*workflow.Locks{{Shared: []string{resource}}, {Exclusive: []string{resource}}},
+		},
+		{
+			name:        "Shared locks run together",
+			locks:       []*workflow.Locks{{Shared: []string{resource}}, {Shared: []string{resource}}},
+			wantOverlap: true,
+		},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+
+		reg := registry.New()
+		reg.Register(&testplugin.Plugin{AlwaysRespond: true})
+
+		store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+		if err != nil {
+			t.Fatalf("TestEtoELocks(%s): sqlite.New: %v", test.name, err)
+		}
+		defer store.Close(ctx)
+
+		ws, err := workstream.New(ctx, reg, liveVault{store})
+		if err != nil {
+			t.Fatalf("TestEtoELocks(%s): workstream.New: %v", test.name, err)
+		}
+
+		var ids []uuid.UUID
+		for i, locks := range test.locks {
+			build, err := builder.New("locks etoe", "tests resource locks etoe")
+			if err != nil {
+				t.Fatalf("TestEtoELocks(%s): builder.New: %v", test.name, err)
+			}
+			build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1, Locks: locks})
+			build.AddSequence(
+				&workflow.Sequence{
+					Name:    "seq0",
+					Descr:   "seq0",
+					Actions: []*workflow.Action{{Name: "slow", Descr: "slow", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 300 * time.Millisecond}}},
+				},
+			)
+			build.Up()
+			build.Up()
+
+			plan, err := build.Plan()
+			if err != nil {
+				t.Fatalf("TestEtoELocks(%s): plan %d build.Plan: %v", test.name, i, err)
+			}
+			id, err := ws.Submit(ctx, plan)
+			if err != nil {
+				t.Fatalf("TestEtoELocks(%s): plan %d Submit: %v", test.name, i, err)
+			}
+			ids = append(ids, id)
+		}
+		for _, id := range ids {
+			if err := ws.Start(ctx, id); err != nil {
+				t.Fatalf("TestEtoELocks(%s): Start: %v", test.name, err)
+			}
+		}
+
+		var blocks []*workflow.Block
+		for _, id := range ids {
+			result, err := ws.Wait(ctx, id)
+			if err != nil {
+				t.Fatalf("TestEtoELocks(%s): Wait: %v", test.name, err)
+			}
+			if got := result.State.Get().Status; got != workflow.Completed {
+				t.Errorf("TestEtoELocks(%s): plan status = %v, want %v", test.name, got, workflow.Completed)
+			}
+			blocks = append(blocks, result.Blocks[0])
+		}
+
+		for _, b := range blocks {
+			if b.Locks.Held.Get() {
+				t.Errorf("TestEtoELocks(%s): block Locks are recorded as held after the plan finished", test.name)
+			}
+		}
+		a, b := blocks[0].State.Get(), blocks[1].State.Get()
+		overlap := a.Start.Before(b.End) && b.Start.Before(a.End)
+		if overlap != test.wantOverlap {
+			t.Errorf("TestEtoELocks(%s): blocks ran at the same time = %v, want %v", test.name, overlap, test.wantOverlap)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+func TestEtoEPause(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEPause: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("pause etoe", "tests that a Pause works etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEPause: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 1 * time.Second}}},
+		},
+	).Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq1",
+			Descr:   "seq1",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+	build.AddBlock(builder.BlockArgs{Name: "block1", Descr: "block1", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEPause: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEPause: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEPause: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEPause: Start: %v", err)
+	}
+
+	// Wait for block0/seq0/action0 to be running before we pause.
+	deadline := time.Now().Add(10 * time.Second)
+	for plug.Running.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("TestEtoEPause: action0 never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := ws.Pause(ctx, id); err != nil {
+		t.Fatalf("TestEtoEPause: Pause: %v", err)
+	}
+
+	// Give the in flight Action time to finish. Nothing new should start.
+	time.Sleep(2 * time.Second)
+
+	paused, err := ws.Plan(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEPause: Plan: %v", err)
+	}
+	if got := paused.State.Get().Status; got != workflow.Paused {
+		t.Errorf("TestEtoEPause: paused plan status = %v, want %v", got, workflow.Paused)
+	}
+	if got := paused.Blocks[0].Sequences[0].State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEPause: in flight Sequence status = %v, want %v", got, workflow.Completed)
+	}
+	if got := paused.Blocks[0].Sequences[1].State.Get().Status; got != workflow.NotStarted {
+		t.Errorf("TestEtoEPause: next Sequence status = %v, want %v", got, workflow.NotStarted)
+	}
+	if got := plug.Calls.Load(); got != 1 {
+		t.Errorf("TestEtoEPause: got %d plugin calls while paused, want 1", got)
+	}
+
+	if err := ws.Resume(ctx, id); err != nil {
+		t.Fatalf("TestEtoEPause: Resume: %v", err)
+	}
+
+	result, err := ws.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEPause: Wait: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEPause: plan status = %v, want %v", got, workflow.Completed)
+	}
+	if got := plug.Calls.Load(); got != 3 {
+		t.Errorf("TestEtoEPause: got %d plugin calls, want 3", got)
+	}
+	if err := ws.Pause(ctx, id); err == nil {
+		t.Errorf("TestEtoEPause: Pause of a finished plan: got err == nil, want err != nil")
+	}
+}
@@ -0,0 +1,373 @@
+package etoe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"github.com/google/uuid"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// newDepsWorkstream returns a Workstream on an in memory sqlite store for the dependency tests.
+func newDepsWorkstream(t *testing.T, name string) *workstream.Workstream {
+	ctx := context.Background()
+
+	reg := registry.New()
+	reg.Register(&testplugin.Plugin{AlwaysRespond: true})
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("%s: sqlite.New: %v", name, err)
+	}
+	t.Cleanup(func() { store.Close(ctx) })
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("%s: workstream.New: %v", name, err)
+	}
+	return ws
+}
+
+// newDepsPlan returns a Plan with a single Action that runs for 200ms and fails if fail is set.
+func newDepsPlan(t *testing.T, name string, fail bool, options ...builder.Option) *workflow.Plan {
+	arg := ""
+	if fail {
+		arg = "error"
+	}
+
+	build, err := builder.New(name, "tests plan dependencies etoe", options...)
+	if err != nil {
+		t.Fatalf("%s: builder.New: %v", name, err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action", Descr: "action", Plugin: testplugin.Name, Req: testplugin.Req{Arg: arg, Sleep: 200 * time.Millisecond}}},
+		},
+	)
+	build.Up()
+	build.Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("%s: build.Plan: %v", name, err)
+	}
+	return plan
+}
+
+// TestEtoEPlanDeps tests that a Plan that depends on another Plan is Waiting until it finishes and then runs
+// or is Skipped.
+func TestEtoEPlanDeps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		failDep bool
+		on      workflow.Outcome
+		want    workflow.Status
+	}{
+		{name: "runs after its dependency passes", want: workflow.Completed},
+		{name: "skipped when its dependency fails", failDep: true, want: workflow.Skipped},
+		{name: "runs after its dependency fails", failDep: true, on: workflow.OCFail, want: workflow.Completed},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+		ws := newDepsWorkstream(t, "TestEtoEPlanDeps("+test.name+")")
+
+		depID, err := ws.Submit(ctx, newDepsPlan(t, "dependency", test.failDep))
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Submit dependency: %v", test.name, err)
+		}
+		id, err := ws.Submit(ctx, newDepsPlan(t, "dependent", false, builder.WithDependsOn(&workflow.PlanDep{ID: depID, On: test.on})))
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Submit dependent: %v", test.name, err)
+		}
+
+		// The dependent is started first, so it must wait for its dependency to be started and finish.
+		if err := ws.Start(ctx, id); err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Start dependent: %v", test.name, err)
+		}
+		plan, err := ws.Plan(ctx, id)
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Plan: %v", test.name, err)
+		}
+		if got := plan.State.Get().Status; got != workflow.Waiting {
+			t.Errorf("TestEtoEPlanDeps(%s): dependent status before its dependency started = %v, want %v", test.name, got, workflow.Waiting)
+		}
+		if got := plan.DependsOn[0].Plans; len(got) != 1 || got[0] != depID {
+			t.Errorf("TestEtoEPlanDeps(%s): dependent waits on plans %v, want [%s]", test.name, got, depID)
+		}
+		if err := ws.Start(ctx, depID); err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Start dependency: %v", test.name, err)
+		}
+
+		dep, err := ws.Wait(ctx, depID)
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Wait dependency: %v", test.name, err)
+		}
+		result, err := ws.Wait(ctx, id)
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDeps(%s): Wait dependent: %v", test.name, err)
+		}
+		if got := result.State.Get().Status; got != test.want {
+			t.Errorf("TestEtoEPlanDeps(%s): dependent status = %v, want %v", test.name, got, test.want)
+		}
+		if test.want == workflow.Completed && result.Blocks[0].State.Get().Start.Before(dep.State.Get().End) {
+			t.Errorf("TestEtoEPlanDeps(%s): dependent ran before its dependency finished", test.name)
+		}
+		if test.want == workflow.Skipped && result.Blocks[0].State.Get().Status != workflow.NotStarted {
+			t.Errorf("TestEtoEPlanDeps(%s): skipped dependent ran its blocks", test.name)
+		}
+	}
+}
+
+// TestEtoEPlanDepsStop tests that a Waiting Plan can be stopped.
+func TestEtoEPlanDepsStop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ws := newDepsWorkstream(t, "TestEtoEPlanDepsStop")
+
+	depID, err := ws.Submit(ctx, newDepsPlan(t, "dependency", false))
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsStop: Submit dependency: %v", err)
+	}
+	id, err := ws.Submit(ctx, newDepsPlan(t, "dependent", false, builder.WithDependsOn(&workflow.PlanDep{ID: depID})))
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsStop: Submit dependent: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEPlanDepsStop: Start: %v", err)
+	}
+	if err := ws.Stop(ctx, id); err != nil {
+		t.Fatalf("TestEtoEPlanDepsStop: Stop: %v", err)
+	}
+
+	plan, err := ws.Plan(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsStop: Plan: %v", err)
+	}
+	if got := plan.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEPlanDepsStop: status = %v, want %v", got, workflow.Stopped)
+	}
+	if plan.Reason != workflow.FRStopped {
+		t.Errorf("TestEtoEPlanDepsStop: reason = %v, want %v", plan.Reason, workflow.FRStopped)
+	}
+}
+
+// TestEtoEPlanDepsRecovery tests that a Plan that was Waiting when the Workstream went down waits on the same
+// Plans when it is recovered.
+func TestEtoEPlanDepsRecovery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	reg := registry.New()
+	reg.Register(&testplugin.Plugin{AlwaysRespond: true})
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: workstream.New: %v", err)
+	}
+
+	// Record the Plans as a Workstream would have before it went down. The dependency is Queued, so it
+	// runs once it is recovered.
+	dep := newDepsPlan(t, "dependency", false)
+	if _, err := ws.Submit(ctx, dep); err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: Submit dependency: %v", err)
+	}
+	plan := newDepsPlan(t, "dependent", false, builder.WithDependsOn(&workflow.PlanDep{ID: dep.ID}))
+	if _, err := ws.Submit(ctx, plan); err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: Submit dependent: %v", err)
+	}
+	now := time.Now().UTC()
+	dep.State.Set(workflow.State{Status: workflow.Queued, Start: now})
+	if err := store.UpdatePlan(ctx, dep); err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: UpdatePlan dependency: %v", err)
+	}
+	plan.DependsOn[0].Plans = []uuid.UUID{dep.ID}
+	plan.State.Set(workflow.State{Status: workflow.Waiting, Start: now})
+	if err := store.UpdatePlan(ctx, plan); err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: UpdatePlan dependent: %v", err)
+	}
+
+	recovered, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: workstream.New: %v", err)
+	}
+
+	depResult, err := recovered.Wait(ctx, dep.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: Wait dependency: %v", err)
+	}
+	result, err := recovered.Wait(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsRecovery: Wait dependent: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEPlanDepsRecovery: dependent status = %v, want %v", got, workflow.Completed)
+	}
+	if result.Blocks[0].State.Get().Start.Before(depResult.State.Get().End) {
+		t.Errorf("TestEtoEPlanDepsRecovery: dependent ran before its dependency finished")
+	}
+}
+
+// TestEtoEPlanDepsCycle tests that a Plan that depends on itself through other Plans cannot be started.
+func TestEtoEPlanDepsCycle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ws := newDepsWorkstream(t, "TestEtoEPlanDepsCycle")
+
+	// Each Plan in the group depends on the others in the group.
+	group := workflow.NewV7()
+	var ids []uuid.UUID
+	for _, name := range []string{"plan0", "plan1"} {
+		id, err := ws.Submit(ctx, newDepsPlan(t, name, false, builder.WithGroupID(group), builder.WithDependsOn(&workflow.PlanDep{GroupID: group})))
+		if err != nil {
+			t.Fatalf("TestEtoEPlanDepsCycle: Submit: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := ws.Start(ctx, ids[0]); err == nil {
+		t.Fatalf("TestEtoEPlanDepsCycle: Start got err == nil, want err != nil")
+	}
+	plan, err := ws.Plan(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("TestEtoEPlanDepsCycle: Plan: %v", err)
+	}
+	if got := plan.State.Get().Status; got != workflow.NotStarted {
+		t.Errorf("TestEtoEPlanDepsCycle: status = %v, want %v", got, workflow.NotStarted)
+	}
+}
+
+// TestEtoEPipeline tests a Pipeline that verifies a deploy if it passes and rolls it back if it fails.
+func TestEtoEPipeline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failDeploy   bool
+		wantVerify   workflow.Status
+		wantRollback workflow.Status
+	}{
+		{name: "deploy passes", wantVerify: workflow.Completed, wantRollback: workflow.Skipped},
+		{name: "deploy fails", failDeploy: true, wantVerify: workflow.Skipped, wantRollback: workflow.Completed},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+		ws := newDepsWorkstream(t, "TestEtoEPipeline("+test.name+")")
+
+		pipeline := &workflow.Pipeline{
+			Plans: []*workflow.Plan{
+				newDepsPlan(t, "verify", false),
+				newDepsPlan(t, "deploy", test.failDeploy),
+				newDepsPlan(t, "rollback", false),
+			},
+			Edges: []workflow.Edge{
+				{From: "deploy", To: "verify"},
+				{From: "deploy", To: "rollback", On: workflow.OCFail},
+			},
+		}
+		ids, err := ws.SubmitPipeline(ctx, pipeline)
+		if err != nil {
+			t.Fatalf("TestEtoEPipeline(%s): SubmitPipeline: %v", test.name, err)
+		}
+		for _, id := range ids {
+			if err := ws.Start(ctx, id); err != nil {
+				t.Fatalf("TestEtoEPipeline(%s): Start: %v", test.name, err)
+			}
+		}
+
+		got := map[string]workflow.Status{}
+		for _, id := range ids {
+			result, err := ws.Wait(ctx, id)
+			if err != nil {
+				t.Fatalf("TestEtoEPipeline(%s): Wait: %v", test.name, err)
+			}
+			got[result.Name] = result.State.Get().Status
+		}
+		if got["verify"] != test.wantVerify {
+			t.Errorf("TestEtoEPipeline(%s): verify status = %v, want %v", test.name, got["verify"], test.wantVerify)
+		}
+		if got["rollback"] != test.wantRollback {
+			t.Errorf("TestEtoEPipeline(%s): rollback status = %v, want %v", test.name, got["rollback"], test.wantRollback)
+		}
+	}
+}
+
+// createFailVault is a storage.Vault that fails the Create of a Plan with a name in fail.
+type createFailVault struct {
+	storage.Vault
+	fail string
+}
+
+func (v createFailVault) Create(ctx context.Context, plan *workflow.Plan) error {
+	if plan.Name == v.fail {
+		return fmt.Errorf("create failed")
+	}
+	return v.Vault.Create(ctx, plan)
+}
+
+// TestEtoEPipelineCreateFails tests that the Plans of a Pipeline that were written are deleted if another
+// of its Plans cannot be written.
+func TestEtoEPipelineCreateFails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	reg := registry.New()
+	reg.Register(&testplugin.Plugin{AlwaysRespond: true})
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEPipelineCreateFails: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, createFailVault{Vault: store, fail: "verify"})
+	if err != nil {
+		t.Fatalf("TestEtoEPipelineCreateFails: workstream.New: %v", err)
+	}
+
+	deploy := newDepsPlan(t, "deploy", false)
+	pipeline := &workflow.Pipeline{
+		Plans: []*workflow.Plan{deploy, newDepsPlan(t, "verify", false)},
+		Edges: []workflow.Edge{{From: "deploy", To: "verify"}},
+	}
+	if _, err := ws.SubmitPipeline(ctx, pipeline); err == nil {
+		t.Fatalf("TestEtoEPipelineCreateFails: SubmitPipeline got err == nil, want err != nil")
+	}
+
+	results, err := store.Search(ctx, storage.Filters{ByIDs: []uuid.UUID{deploy.ID}})
+	if err != nil {
+		t.Fatalf("TestEtoEPipelineCreateFails: Search: %v", err)
+	}
+	found := 0
+	for range results {
+		found++
+	}
+	if found != 0 {
+		t.Errorf("TestEtoEPipelineCreateFails: the deploy plan is still in storage")
+	}
+}
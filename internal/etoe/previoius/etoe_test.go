@@ -465,8 +465,8 @@ func TestBypassPlan(t *testing.T) {
 		}
 	}
 
-	if result.Data.State.Get().Status != workflow.Completed {
-		t.Fatalf("TestBypassPlan: expected workflow to complete")
+	if result.Data.State.Get().Status != workflow.Skipped {
+		t.Fatalf("TestBypassPlan: expected workflow to be Skipped, got %s", result.Data.State.Get().Status)
 	}
 	if result.Data.PreChecks.State.Get().Status != workflow.NotStarted {
 		t.Fatalf("TestBypassPlan: expected Prechecks in NotStarted, got %s", result.Data.PreChecks.State.Get().Status)
@@ -477,8 +477,8 @@ func TestBypassPlan(t *testing.T) {
 	if result.Data.ContChecks.State.Get().Status != workflow.NotStarted {
 		t.Fatalf("TestBypassPlan: expected ContChecks in NotStarted")
 	}
-	if result.Data.Blocks[0].State.Get().Status != workflow.NotStarted {
-		t.Fatalf("TestBypassPlan: expected Block0 in NotStarted")
+	if result.Data.Blocks[0].State.Get().Status != workflow.Skipped {
+		t.Fatalf("TestBypassPlan: expected Block0 in Skipped, got %s", result.Data.Blocks[0].State.Get().Status)
 	}
 }
 
@@ -647,12 +647,17 @@ func TestBypassBlock(t *testing.T) {
 	if result.Data.Blocks[0].ContChecks.State.Get().Status != workflow.NotStarted {
 		t.Fatalf("TestBypassPlan: expected block 0 ContChecks in NotStarted")
 	}
-	if result.Data.Blocks[0].State.Get().Status != workflow.Completed {
-		t.Fatalf("TestBypassPlan: expected Block0 in NotStarted")
+	if result.Data.Blocks[0].State.Get().Status != workflow.Skipped {
+		t.Fatalf("TestBypassPlan: expected Block0 in Skipped, got %s", result.Data.Blocks[0].State.Get().Status)
 	}
 	for _, seq := range result.Data.Blocks[0].Sequences {
-		if seq.State.Get().Status != workflow.NotStarted {
-			t.Fatalf("TestBypassPlan: expected block 0 Sequence in Completed")
+		if seq.State.Get().Status != workflow.Skipped {
+			t.Fatalf("TestBypassPlan: expected block 0 Sequence in Skipped, got %s", seq.State.Get().Status)
+		}
+		for _, action := range seq.Actions {
+			if action.State.Get().Status != workflow.Skipped {
+				t.Fatalf("TestBypassPlan: expected block 0 Action in Skipped, got %s", action.State.Get().Status)
+			}
 		}
 	}
 
@@ -0,0 +1,231 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// queuePlan returns a Plan with a single Action that sleeps for sleep.
+func queuePlan(t *testing.T, name string, priority int, sleep time.Duration) *workflow.Plan {
+	t.Helper()
+
+	build, err := builder.New(name, name, builder.WithPriority(priority))
+	if err != nil {
+		t.Fatalf("queuePlan(%s): builder.New: %v", name, err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: sleep}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("queuePlan(%s): build.Plan: %v", name, err)
+	}
+	return plan
+}
+
+// TestEtoEMaxRunningPlans tests that Plans started past WithMaxRunningPlans are Queued and run one at a time
+// in the order of their Priority once the running Plan finishes.
+func TestEtoEMaxRunningPlans(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlans: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithMaxRunningPlans(1))
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlans: workstream.New: %v", err)
+	}
+
+	// first runs right away. low and high are queued, and high runs before low.
+	plans := []*workflow.Plan{
+		queuePlan(t, "first", 0, 200*time.Millisecond),
+		queuePlan(t, "low", 0, 10*time.Millisecond),
+		queuePlan(t, "high", 1, 10*time.Millisecond),
+	}
+	for _, plan := range plans {
+		id, err := ws.Submit(ctx, plan)
+		if err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlans: Submit(%s): %v", plan.Name, err)
+		}
+		if err := ws.Start(ctx, id); err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlans: Start(%s): %v", plan.Name, err)
+		}
+	}
+
+	for _, plan := range plans[1:] {
+		queued, err := ws.Plan(ctx, plan.ID)
+		if err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlans: Plan(%s): %v", plan.Name, err)
+		}
+		if got := queued.State.Get().Status; got != workflow.Queued {
+			t.Errorf("TestEtoEMaxRunningPlans: Plan(%s) status = %v, want %v", plan.Name, got, workflow.Queued)
+		}
+	}
+	if err := ws.Pause(ctx, plans[1].ID); err == nil {
+		t.Errorf("TestEtoEMaxRunningPlans: Pause of a queued plan: got err == nil, want err != nil")
+	}
+
+	results := make([]*workflow.Plan, len(plans))
+	for i, plan := range plans {
+		results[i], err = ws.Wait(ctx, plan.ID)
+		if err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlans: Wait(%s): %v", plan.Name, err)
+		}
+		if got := results[i].State.Get().Status; got != workflow.Completed {
+			t.Errorf("TestEtoEMaxRunningPlans: Plan(%s) status = %v, want %v", plan.Name, got, workflow.Completed)
+		}
+	}
+
+	first, low, high := results[0].State.Get(), results[1].State.Get(), results[2].State.Get()
+	if high.Start.Before(first.End) {
+		t.Errorf("TestEtoEMaxRunningPlans: high started at %v, before first ended at %v", high.Start, first.End)
+	}
+	if low.Start.Before(high.End) {
+		t.Errorf("TestEtoEMaxRunningPlans: low started at %v, before high ended at %v", low.Start, high.End)
+	}
+}
+
+// TestEtoEMaxRunningPlansStop tests that a Queued Plan that is stopped does not run.
+func TestEtoEMaxRunningPlansStop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansStop: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithMaxRunningPlans(1))
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansStop: workstream.New: %v", err)
+	}
+
+	running := queuePlan(t, "running", 0, 200*time.Millisecond)
+	waiting := queuePlan(t, "waiting", 0, 0)
+	for _, plan := range []*workflow.Plan{running, waiting} {
+		id, err := ws.Submit(ctx, plan)
+		if err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlansStop: Submit(%s): %v", plan.Name, err)
+		}
+		if err := ws.Start(ctx, id); err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlansStop: Start(%s): %v", plan.Name, err)
+		}
+	}
+
+	if err := ws.Stop(ctx, waiting.ID); err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansStop: Stop: %v", err)
+	}
+	result, err := ws.Plan(ctx, waiting.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansStop: Plan: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEMaxRunningPlansStop: stopped plan status = %v, want %v", got, workflow.Stopped)
+	}
+	if got := result.Reason; got != workflow.FRStopped {
+		t.Errorf("TestEtoEMaxRunningPlansStop: stopped plan reason = %v, want %v", got, workflow.FRStopped)
+	}
+
+	if _, err := ws.Wait(ctx, running.ID); err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansStop: Wait: %v", err)
+	}
+	if got := plug.Calls.Load(); got != 1 {
+		t.Errorf("TestEtoEMaxRunningPlansStop: got %d plugin calls, want 1", got)
+	}
+}
+
+// TestEtoEMaxRunningPlansRecovery tests that Plans that were Queued when the Workstream went down are run in
+// the order of the queue when it is recovered.
+func TestEtoEMaxRunningPlansRecovery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansRecovery: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansRecovery: workstream.New: %v", err)
+	}
+
+	// Queue the Plans as a Workstream would have before it went down. The queue is later, earlier, high.
+	queuedAt := time.Now().UTC().Add(-time.Hour)
+	plans := []*workflow.Plan{
+		queuePlan(t, "later", 0, 10*time.Millisecond),
+		queuePlan(t, "earlier", 0, 10*time.Millisecond),
+		queuePlan(t, "high", 1, 10*time.Millisecond),
+	}
+	for i, plan := range plans {
+		if _, err := ws.Submit(ctx, plan); err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlansRecovery: Submit(%s): %v", plan.Name, err)
+		}
+		start := queuedAt.Add(time.Duration(len(plans)-i) * time.Second)
+		plan.State.Set(workflow.State{Status: workflow.Queued, Start: start})
+		if err := store.UpdatePlan(ctx, plan); err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlansRecovery: UpdatePlan(%s): %v", plan.Name, err)
+		}
+	}
+
+	recovered, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithMaxRunningPlans(1))
+	if err != nil {
+		t.Fatalf("TestEtoEMaxRunningPlansRecovery: workstream.New: %v", err)
+	}
+
+	results := map[string]workflow.State{}
+	for _, plan := range plans {
+		result, err := recovered.Wait(ctx, plan.ID)
+		if err != nil {
+			t.Fatalf("TestEtoEMaxRunningPlansRecovery: Wait(%s): %v", plan.Name, err)
+		}
+		if got := result.State.Get().Status; got != workflow.Completed {
+			t.Errorf("TestEtoEMaxRunningPlansRecovery: Plan(%s) status = %v, want %v", plan.Name, got, workflow.Completed)
+		}
+		results[plan.Name] = result.State.Get()
+	}
+
+	order := []string{"high", "earlier", "later"}
+	for i := 1; i < len(order); i++ {
+		prev, next := results[order[i-1]], results[order[i]]
+		if next.Start.Before(prev.End) {
+			t.Errorf("TestEtoEMaxRunningPlansRecovery: %s started before %s ended", order[i], order[i-1])
+		}
+	}
+}
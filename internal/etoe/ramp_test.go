@@ -0,0 +1,114 @@
+package etoe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEBlockRamp tests that a Block with a Ramp runs its Sequences in waves, with each wave starting after
+// the one before it finished, its Soak passed and its WaveChecks succeeded.
+func TestEtoEBlockRamp(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plugCheck := &testplugin.Plugin{AlwaysRespond: true, IsCheckPlugin: true, PlugName: "check"}
+	plugAction := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plugCheck)
+	reg.Register(plugAction)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	const soak = 50 * time.Millisecond
+
+	build, err := builder.New("block ramp etoe", "tests Block Ramp etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: builder.New: %v", err)
+	}
+	build.AddBlock(
+		builder.BlockArgs{
+			Name:        "block0",
+			Descr:       "block0",
+			Concurrency: 4,
+			Ramp:        &workflow.Ramp{Canary: 1, Steps: []int{50}, Soak: soak},
+		},
+	)
+	build.AddChecks(builder.WaveChecks, &workflow.Checks{})
+	build.AddAction(&workflow.Action{Name: "wave check", Descr: "wave check", Plugin: "check", Req: testplugin.Req{Arg: "success"}})
+	build.Up()
+	for i := range 4 {
+		name := fmt.Sprintf("seq%d", i)
+		build.AddSequence(
+			&workflow.Sequence{
+				Name:  name,
+				Descr: name,
+				Actions: []*workflow.Action{
+					{Name: name, Descr: name, Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 50 * time.Millisecond}},
+				},
+			},
+		)
+		build.Up()
+	}
+	build.Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockRamp: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockRamp: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockRamp: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoEBlockRamp: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	block0 := result.Blocks[0]
+	if block0.Ramp == nil || block0.Ramp.Canary != 1 {
+		t.Errorf("TestEtoEBlockRamp: Ramp = %+v, want the Ramp from the Plan", block0.Ramp)
+	}
+	if got := block0.WaveChecks.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEBlockRamp: WaveChecks status = %v, want %v", got, workflow.Completed)
+	}
+
+	// The waves are seq0, seq1 and then seq2 and seq3.
+	seqs := block0.Sequences
+	waves := [][2]int{{0, 1}, {1, 2}, {1, 3}}
+	for _, w := range waves {
+		prev, next := seqs[w[0]].State.Get(), seqs[w[1]].State.Get()
+		if got := next.Start.Sub(prev.End); got < soak {
+			t.Errorf("TestEtoEBlockRamp: %s started %v after %s ended, want at least %v", seqs[w[1]].Name, got, seqs[w[0]].Name, soak)
+		}
+	}
+}
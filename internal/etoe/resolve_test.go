@@ -0,0 +1,101 @@
+package etoe
+
+import (
+	"testing"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEResolve tests that an Action can use the response of an Action in an earlier Block.
+func TestEtoEResolve(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	key := workflow.NewV7()
+
+	build, err := builder.New("resolve etoe", "tests that Actions can use earlier responses etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				// This returns a Resp with Arg "actionid".
+				{Key: key, Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Arg: "echo:actionid"}},
+			},
+		},
+	).Up().Up()
+	build.AddBlock(builder.BlockArgs{Name: "block1", Descr: "block1", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq1",
+			Descr: "seq1",
+			Actions: []*workflow.Action{
+				// This resolves to Arg "actionid", so it returns its own ID.
+				{Name: "action1", Descr: "action1", Plugin: testplugin.Name, Req: testplugin.Req{From: key}},
+			},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEResolve: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEResolve: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEResolve: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoEResolve: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	action1 := result.Blocks[1].Sequences[0].Actions[0]
+	resp, ok := action1.FinalAttempt().Resp.(testplugin.Resp)
+	if !ok {
+		t.Fatalf("TestEtoEResolve: action1 Resp is %T, want testplugin.Resp", action1.FinalAttempt().Resp)
+	}
+	if resp.Arg != action1.ID.String() {
+		t.Errorf("TestEtoEResolve: action1 Resp.Arg = %q, want %q", resp.Arg, action1.ID)
+	}
+	// The stored Req must not be resolved, so recovery resolves it again.
+	if req := action1.Req.(testplugin.Req); req.Arg != "" || req.From != key {
+		t.Errorf("TestEtoEResolve: stored action1 Req = %+v, want it unresolved", req)
+	}
+}
@@ -0,0 +1,101 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoESequenceRetries tests that a Sequence that keeps failing is run Retries more times and that
+// each failed run is stored in the Attempts of the Sequence.
+func TestEtoESequenceRetries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("sequence retries etoe", "tests Sequence Retries etoe")
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Retries: 2,
+			RetryPolicy: &workflow.RetryPolicy{
+				InitialInterval: 10 * time.Millisecond,
+				Multiplier:      1,
+				MaxInterval:     10 * time.Millisecond,
+			},
+			Actions: []*workflow.Action{
+				{Name: "action", Descr: "action", Plugin: testplugin.Name, Req: testplugin.Req{Arg: "error"}},
+			},
+		},
+	)
+	build.Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoESequenceRetries: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoESequenceRetries: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoESequenceRetries: store.Read: %v", err)
+	}
+	seq := result.Blocks[0].Sequences[0]
+	if got := seq.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestEtoESequenceRetries: sequence status = %v, want %v", got, workflow.Failed)
+	}
+	attempts := seq.Attempts.Get()
+	if len(attempts) != 2 {
+		t.Fatalf("TestEtoESequenceRetries: len(Attempts) = %d, want 2", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if len(attempt.Actions) != 1 {
+			t.Errorf("TestEtoESequenceRetries: Attempts[%d]: len(Actions) = %d, want 1", i, len(attempt.Actions))
+			continue
+		}
+		got := attempt.Actions[0]
+		if got.ID != seq.Actions[0].ID || got.Status != workflow.Failed || got.Err == nil {
+			t.Errorf("TestEtoESequenceRetries: Attempts[%d]: got %+v, want a Failed result with an error for Action(%s)", i, got, seq.Actions[0].ID)
+		}
+	}
+	if got := seq.Actions[0].State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestEtoESequenceRetries: final action status = %v, want %v", got, workflow.Failed)
+	}
+}
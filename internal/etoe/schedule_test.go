@@ -0,0 +1,246 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"github.com/google/uuid"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// schedulePlan returns a Plan with a single Action in the group groupID.
+func schedulePlan(t *testing.T, name string, groupID uuid.UUID) *workflow.Plan {
+	t.Helper()
+
+	build, err := builder.New(name, name, builder.WithGroupID(groupID))
+	if err != nil {
+		t.Fatalf("schedulePlan(%s): builder.New: %v", name, err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("schedulePlan(%s): build.Plan: %v", name, err)
+	}
+	return plan
+}
+
+// TestEtoEStartAt tests that a Plan scheduled with StartAt is Scheduled until its time and then runs, and that
+// a scheduled Plan that is stopped never runs.
+func TestEtoEStartAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEStartAt: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEStartAt: workstream.New: %v", err)
+	}
+
+	start := schedulePlan(t, "start", workflow.NewV7())
+	stop := schedulePlan(t, "stop", workflow.NewV7())
+	for _, plan := range []*workflow.Plan{start, stop} {
+		if _, err := ws.Submit(ctx, plan); err != nil {
+			t.Fatalf("TestEtoEStartAt: Submit(%s): %v", plan.Name, err)
+		}
+	}
+
+	at := time.Now().Add(200 * time.Millisecond)
+	if err := ws.StartAt(ctx, start.ID, at); err != nil {
+		t.Fatalf("TestEtoEStartAt: StartAt(start): %v", err)
+	}
+	if err := ws.StartAt(ctx, stop.ID, at.Add(time.Hour)); err != nil {
+		t.Fatalf("TestEtoEStartAt: StartAt(stop): %v", err)
+	}
+	if err := ws.StartAt(ctx, start.ID, at); err == nil {
+		t.Errorf("TestEtoEStartAt: second StartAt: got err == nil, want err != nil")
+	}
+	if err := ws.Start(ctx, start.ID); err == nil {
+		t.Errorf("TestEtoEStartAt: Start of a scheduled plan: got err == nil, want err != nil")
+	}
+
+	scheduled, err := ws.Plan(ctx, start.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEStartAt: Plan: %v", err)
+	}
+	if got := scheduled.State.Get().Status; got != workflow.Scheduled {
+		t.Errorf("TestEtoEStartAt: scheduled plan status = %v, want %v", got, workflow.Scheduled)
+	}
+	if got := scheduled.Schedule; got == nil || !got.At.Equal(at.UTC()) {
+		t.Errorf("TestEtoEStartAt: scheduled plan Schedule = %+v, want At %v", got, at.UTC())
+	}
+
+	result, err := ws.Wait(ctx, start.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEStartAt: Wait(start): %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEStartAt: started plan status = %v, want %v", got, workflow.Completed)
+	}
+	if result.State.Get().Start.Before(at) {
+		t.Errorf("TestEtoEStartAt: plan started at %v, before %v", result.State.Get().Start, at)
+	}
+
+	if err := ws.Stop(ctx, stop.ID); err != nil {
+		t.Fatalf("TestEtoEStartAt: Stop: %v", err)
+	}
+	result, err = ws.Wait(ctx, stop.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEStartAt: Wait(stop): %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEStartAt: stopped plan status = %v, want %v", got, workflow.Stopped)
+	}
+	if got := plug.Calls.Load(); got != 1 {
+		t.Errorf("TestEtoEStartAt: got %d plugin calls, want 1", got)
+	}
+}
+
+// TestEtoEScheduleRecovery tests that Schedules are recovered and that runs that were missed while the
+// Workstream was down are started according to their CatchUp.
+func TestEtoEScheduleRecovery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: workstream.New: %v", err)
+	}
+
+	// Schedule the Plans as a Workstream would have before it went down. The cron Schedules missed the last
+	// three hours and the StartAt Schedule missed its start an hour ago.
+	hour := time.Now().UTC().Truncate(time.Hour)
+	all := schedulePlan(t, "all", workflow.NewV7())
+	skip := schedulePlan(t, "skip", workflow.NewV7())
+	missed := schedulePlan(t, "missed", workflow.NewV7())
+	schedules := map[*workflow.Plan]*workflow.Schedule{
+		all:    {Cron: "0 * * * *", CatchUp: workflow.CUAll, Next: hour.Add(-2 * time.Hour)},
+		skip:   {Cron: "0 * * * *", CatchUp: workflow.CUSkip, Next: hour.Add(-2 * time.Hour)},
+		missed: {At: hour.Add(-time.Hour), CatchUp: workflow.CUSkip, Next: hour.Add(-time.Hour)},
+	}
+	for plan, sched := range schedules {
+		if _, err := ws.Submit(ctx, plan); err != nil {
+			t.Fatalf("TestEtoEScheduleRecovery: Submit(%s): %v", plan.Name, err)
+		}
+		plan.Schedule = sched
+		plan.State.Set(workflow.State{Status: workflow.Scheduled})
+		if err := store.UpdatePlan(ctx, plan); err != nil {
+			t.Fatalf("TestEtoEScheduleRecovery: UpdatePlan(%s): %v", plan.Name, err)
+		}
+	}
+
+	recovered, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: workstream.New: %v", err)
+	}
+
+	tests := []struct {
+		plan     *workflow.Plan
+		wantRuns int
+	}{
+		{plan: all, wantRuns: 3},
+		{plan: skip, wantRuns: 0},
+	}
+	for _, test := range tests {
+		template, err := recovered.Plan(ctx, test.plan.ID)
+		if err != nil {
+			t.Fatalf("TestEtoEScheduleRecovery: Plan(%s): %v", test.plan.Name, err)
+		}
+		if got := template.State.Get().Status; got != workflow.Scheduled {
+			t.Errorf("TestEtoEScheduleRecovery: template(%s) status = %v, want %v", test.plan.Name, got, workflow.Scheduled)
+		}
+		if want := hour.Add(time.Hour); !template.Schedule.Next.Equal(want) {
+			t.Errorf("TestEtoEScheduleRecovery: template(%s) Next = %v, want %v", test.plan.Name, template.Schedule.Next, want)
+		}
+		if _, err := recovered.Wait(ctx, test.plan.ID); err == nil {
+			t.Errorf("TestEtoEScheduleRecovery: Wait on template(%s): got err == nil, want err != nil", test.plan.Name)
+		}
+
+		// The runs have the GroupID of their template.
+		results, err := store.List(ctx, 0)
+		if err != nil {
+			t.Fatalf("TestEtoEScheduleRecovery: List: %v", err)
+		}
+		var runs []uuid.UUID
+		for result := range results {
+			if result.Err != nil {
+				t.Fatalf("TestEtoEScheduleRecovery: List: %v", result.Err)
+			}
+			if result.Result.GroupID == test.plan.GroupID && result.Result.ID != test.plan.ID {
+				runs = append(runs, result.Result.ID)
+			}
+		}
+		for _, id := range runs {
+			run, err := recovered.Wait(ctx, id)
+			if err != nil {
+				t.Fatalf("TestEtoEScheduleRecovery: Wait on run of %s: %v", test.plan.Name, err)
+			}
+			if got := run.State.Get().Status; got != workflow.Completed {
+				t.Errorf("TestEtoEScheduleRecovery: run of %s status = %v, want %v", test.plan.Name, got, workflow.Completed)
+			}
+		}
+		if len(runs) != test.wantRuns {
+			t.Errorf("TestEtoEScheduleRecovery: %s got %d runs, want %d", test.plan.Name, len(runs), test.wantRuns)
+		}
+		if test.wantRuns > 0 && template.Schedule.LastRun == uuid.Nil {
+			t.Errorf("TestEtoEScheduleRecovery: %s has no LastRun", test.plan.Name)
+		}
+	}
+
+	result, err := recovered.Plan(ctx, missed.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: Plan(missed): %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEScheduleRecovery: missed plan status = %v, want %v", got, workflow.Stopped)
+	}
+	if got := result.Reason; got != workflow.FRMissed {
+		t.Errorf("TestEtoEScheduleRecovery: missed plan reason = %v, want %v", got, workflow.FRMissed)
+	}
+
+	if err := recovered.Stop(ctx, all.ID); err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: Stop(all): %v", err)
+	}
+	template, err := recovered.Plan(ctx, all.ID)
+	if err != nil {
+		t.Fatalf("TestEtoEScheduleRecovery: Plan(all): %v", err)
+	}
+	if got := template.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEScheduleRecovery: stopped template status = %v, want %v", got, workflow.Stopped)
+	}
+}
@@ -0,0 +1,102 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEBlockSoak tests that a Block's PostChecks with a SoakDuration are run every Delay until
+// the SoakDuration has passed before the Block completes.
+func TestEtoEBlockSoak(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plugCheck := &testplugin.Plugin{AlwaysRespond: true, IsCheckPlugin: true, PlugName: "check"}
+	plugAction := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plugCheck)
+	reg.Register(plugAction)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	const (
+		delay = 20 * time.Millisecond
+		soak  = 100 * time.Millisecond
+	)
+
+	build, err := builder.New("block soak etoe", "tests Block PostChecks SoakDuration etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddChecks(builder.PostChecks, &workflow.Checks{Delay: delay, SoakDuration: soak})
+	build.AddAction(&workflow.Action{Name: "healthy", Descr: "healthy", Plugin: "check", Req: testplugin.Req{Arg: "success"}})
+	build.Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				{Name: "deploy", Descr: "deploy", Plugin: testplugin.Name, Req: testplugin.Req{}},
+			},
+		},
+	)
+	build.Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockSoak: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockSoak: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockSoak: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoEBlockSoak: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	post := result.Blocks[0].PostChecks
+	if post.SoakDuration != soak {
+		t.Errorf("TestEtoEBlockSoak: SoakDuration = %v, want %v", post.SoakDuration, soak)
+	}
+	state := post.State.Get()
+	if state.Status != workflow.Completed {
+		t.Errorf("TestEtoEBlockSoak: PostChecks status = %v, want %v", state.Status, workflow.Completed)
+	}
+	if got := state.End.Sub(state.Start); got < soak-delay {
+		t.Errorf("TestEtoEBlockSoak: PostChecks ran for %v, want at least %v", got, soak-delay)
+	}
+	if got := plugCheck.Calls.Load(); got < 2 {
+		t.Errorf("TestEtoEBlockSoak: check plugin called %d times, want more than 1", got)
+	}
+}
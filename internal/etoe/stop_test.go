@@ -0,0 +1,198 @@
+package etoe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// liveVault is a storage.Vault that rejects any update made with a cancelled Context, like
+// the cosmosdb and azblob vaults do. The statemachine treats a failed write as fatal.
+type liveVault struct {
+	storage.Vault
+}
+
+func (v liveVault) check(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("update with a cancelled Context: %w", ctx.Err())
+	}
+	return nil
+}
+
+func (v liveVault) UpdatePlan(ctx context.Context, p *workflow.Plan) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdatePlan(ctx, p)
+}
+
+func (v liveVault) UpdateBlock(ctx context.Context, b *workflow.Block) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateBlock(ctx, b)
+}
+
+func (v liveVault) UpdateChecks(ctx context.Context, c *workflow.Checks) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateChecks(ctx, c)
+}
+
+func (v liveVault) UpdateSequence(ctx context.Context, s *workflow.Sequence) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateSequence(ctx, s)
+}
+
+func (v liveVault) UpdateAction(ctx context.Context, a *workflow.Action) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateAction(ctx, a)
+}
+
+func (v liveVault) UpdateDeferredActions(ctx context.Context, da *workflow.DeferredActions) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateDeferredActions(ctx, da)
+}
+
+func (v liveVault) UpdateDeferBatch(ctx context.Context, b *workflow.DeferBatch) error {
+	if err := v.check(ctx); err != nil {
+		return err
+	}
+	return v.Vault.UpdateDeferBatch(ctx, b)
+}
+
+func TestEtoEStop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEStop: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("stop etoe", "tests that a Stop works etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEStop: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 2 * time.Second}},
+				{Name: "action1", Descr: "action1", Plugin: testplugin.Name, Req: testplugin.Req{}},
+			},
+		},
+	).Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq1",
+			Descr:   "seq1",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+	build.AddBlock(builder.BlockArgs{Name: "block1", Descr: "block1", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq0",
+			Descr:   "seq0",
+			Actions: []*workflow.Action{{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{}}},
+		},
+	).Up().Up()
+	build.AddDeferredActions()
+	build.AddDeferBatch(newDeferBatch("onSuccess", workflow.OnSuccess, false, "")).Up()
+	build.AddDeferBatch(newDeferBatch("onFailure", workflow.OnFailure, false, "")).Up()
+	build.AddDeferBatch(newDeferBatch("always", workflow.Always, false, "")).Up()
+	build.Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEStop: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEStop: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEStop: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEStop: Start: %v", err)
+	}
+
+	// Wait for block0/seq0/action0 to be running before we stop.
+	deadline := time.Now().Add(10 * time.Second)
+	for plug.Running.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("TestEtoEStop: action0 never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := ws.Stop(ctx, id); err != nil {
+		t.Fatalf("TestEtoEStop: Stop: %v", err)
+	}
+
+	result, err := ws.Plan(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEStop: Plan: %v", err)
+	}
+
+	if got := result.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEStop: plan status = %v, want %v", got, workflow.Stopped)
+	}
+	if result.Reason != workflow.FRStopped {
+		t.Errorf("TestEtoEStop: plan reason = %v, want %v", result.Reason, workflow.FRStopped)
+	}
+
+	block0, block1 := result.Blocks[0], result.Blocks[1]
+	tests := []struct {
+		name string
+		obj  interface{ GetState() workflow.State }
+		want workflow.Status
+	}{
+		{name: "in flight Action finishes", obj: block0.Sequences[0].Actions[0], want: workflow.Completed},
+		{name: "next Action in the Sequence is Stopped", obj: block0.Sequences[0].Actions[1], want: workflow.Stopped},
+		{name: "running Sequence is Stopped", obj: block0.Sequences[0], want: workflow.Stopped},
+		{name: "unstarted Sequence is Stopped", obj: block0.Sequences[1], want: workflow.Stopped},
+		{name: "unstarted Action is Stopped", obj: block0.Sequences[1].Actions[0], want: workflow.Stopped},
+		{name: "running Block is Stopped", obj: block0, want: workflow.Stopped},
+		{name: "unstarted Block is Stopped", obj: block1, want: workflow.Stopped},
+		{name: "Sequence in unstarted Block is Stopped", obj: block1.Sequences[0], want: workflow.Stopped},
+		{name: "DeferredActions run", obj: result.DeferredActions, want: workflow.Completed},
+		{name: "OnSuccess batch does not run", obj: result.DeferredActions.DeferredBatches[0], want: workflow.NotStarted},
+		{name: "OnFailure batch runs", obj: result.DeferredActions.DeferredBatches[1], want: workflow.Completed},
+		{name: "Always batch runs", obj: result.DeferredActions.DeferredBatches[2], want: workflow.Completed},
+	}
+	for _, test := range tests {
+		if got := test.obj.GetState().Status; got != test.want {
+			t.Errorf("TestEtoEStop(%s): got status %v, want %v", test.name, got, test.want)
+		}
+	}
+}
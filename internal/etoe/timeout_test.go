@@ -0,0 +1,103 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEBlockTimeout tests that a Block that runs past its Timeout stops its running Sequence,
+// still runs its DeferredActions and fails the Plan with FRTimeout.
+func TestEtoEBlockTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("block timeout etoe", "tests Block Timeout etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1, Timeout: 100 * time.Millisecond})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				{Name: "slow", Descr: "slow", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 300 * time.Millisecond}},
+				{Name: "never", Descr: "never", Plugin: testplugin.Name, Req: testplugin.Req{}},
+			},
+		},
+	)
+	build.Up()
+	build.AddDeferredActions()
+	build.AddDeferBatch(newDeferBatch("block0-onFailure", workflow.OnFailure, false, "")).Up()
+	build.Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEBlockTimeout: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestEtoEBlockTimeout: plan status = %v, want %v", got, workflow.Failed)
+	}
+	if result.Reason != workflow.FRTimeout {
+		t.Errorf("TestEtoEBlockTimeout: plan reason = %v, want %v", result.Reason, workflow.FRTimeout)
+	}
+
+	block0 := result.Blocks[0]
+	if got := block0.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestEtoEBlockTimeout: block status = %v, want %v", got, workflow.Failed)
+	}
+	seq := block0.Sequences[0]
+	if got := seq.State.Get().Status; got != workflow.Stopped {
+		t.Errorf("TestEtoEBlockTimeout: sequence status = %v, want %v", got, workflow.Stopped)
+	}
+	wantActions := []workflow.Status{workflow.Completed, workflow.Stopped}
+	for i, a := range seq.Actions {
+		if got := a.State.Get().Status; got != wantActions[i] {
+			t.Errorf("TestEtoEBlockTimeout: action %s status = %v, want %v", a.Name, got, wantActions[i])
+		}
+	}
+	batch := block0.DeferredActions.DeferredBatches[0]
+	if got := batch.State.Get().Status; got != workflow.Completed {
+		t.Errorf("TestEtoEBlockTimeout: DeferBatch %s status = %v, want %v", batch.Name, got, workflow.Completed)
+	}
+}
@@ -0,0 +1,155 @@
+package etoe
+
+import (
+	"testing"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/internal/execute/sm/spans"
+	"github.com/element-of-surprise/coercion/plugins"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestEtoETrace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	retryPlug := &testplugin.Plugin{
+		PlugName:  "retry",
+		Responses: []any{&plugins.Error{Code: 3, Message: "retry me"}, testplugin.Resp{}},
+	}
+	reg := registry.New()
+	reg.Register(plug)
+	reg.Register(retryPlug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoETrace: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	build, err := builder.New("trace etoe", "tests that tracing works etoe")
+	if err != nil {
+		t.Fatalf("TestEtoETrace: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				{Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Arg: "spanid"}},
+				{Name: "action1", Descr: "action1", Plugin: "retry", Req: testplugin.Req{}, Retries: 1},
+			},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoETrace: build.Plan: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ws, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithTracerProvider(tp))
+	if err != nil {
+		t.Fatalf("TestEtoETrace: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoETrace: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoETrace: Start: %v", err)
+	}
+	result, err := ws.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoETrace: Wait: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoETrace: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	byName := map[string][]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		byName[s.Name()] = append(byName[s.Name()], s)
+	}
+
+	one := func(name string) sdktrace.ReadOnlySpan {
+		if len(byName[name]) != 1 {
+			t.Fatalf("TestEtoETrace: got %d spans named %q, want 1", len(byName[name]), name)
+		}
+		return byName[name][0]
+	}
+	planSpan := one("plan trace etoe")
+	blockSpan := one("block block0")
+	seqSpan := one("sequence seq0")
+	action0Span := one("action action0")
+	attempt0Span := one("attempt action0")
+
+	parents := []struct {
+		child, parent sdktrace.ReadOnlySpan
+	}{
+		{blockSpan, planSpan},
+		{seqSpan, blockSpan},
+		{action0Span, seqSpan},
+		{attempt0Span, action0Span},
+	}
+	for _, p := range parents {
+		if p.child.Parent().SpanID() != p.parent.SpanContext().SpanID() {
+			t.Errorf("TestEtoETrace: span %q has parent %s, want %q", p.child.Name(), p.child.Parent().SpanID(), p.parent.Name())
+		}
+	}
+
+	if v, _ := spanAttr(planSpan, spans.PlanID); v.AsString() != id.String() {
+		t.Errorf("TestEtoETrace: plan span %s = %q, want %q", spans.PlanID, v.AsString(), id)
+	}
+	if v, _ := spanAttr(attempt0Span, spans.PlanID); v.AsString() != id.String() {
+		t.Errorf("TestEtoETrace: attempt span %s = %q, want %q", spans.PlanID, v.AsString(), id)
+	}
+	if v, _ := spanAttr(action0Span, spans.Plugin); v.AsString() != testplugin.Name {
+		t.Errorf("TestEtoETrace: action span %s = %q, want %q", spans.Plugin, v.AsString(), testplugin.Name)
+	}
+
+	// The plugin gets the span of its Attempt.
+	resp := result.Blocks[0].Sequences[0].Actions[0].FinalAttempt().Resp.(testplugin.Resp)
+	if resp.Arg != attempt0Span.SpanContext().SpanID().String() {
+		t.Errorf("TestEtoETrace: plugin saw span %s, want the attempt span %s", resp.Arg, attempt0Span.SpanContext().SpanID())
+	}
+
+	retries := byName["attempt action1"]
+	if len(retries) != 2 {
+		t.Fatalf("TestEtoETrace: got %d attempt spans for action1, want 2", len(retries))
+	}
+	for i, s := range retries {
+		if v, _ := spanAttr(s, spans.Retry); v.AsInt64() != int64(i) {
+			t.Errorf("TestEtoETrace: attempt %d has %s = %d, want %d", i, spans.Retry, v.AsInt64(), i)
+		}
+	}
+	if v, ok := spanAttr(retries[0], spans.ErrCode); !ok || v.AsInt64() != 3 {
+		t.Errorf("TestEtoETrace: failed attempt has %s = %v, want 3", spans.ErrCode, v.AsInt64())
+	}
+	if _, ok := spanAttr(retries[1], spans.ErrCode); ok {
+		t.Errorf("TestEtoETrace: successful attempt has %s set", spans.ErrCode)
+	}
+}
@@ -0,0 +1,133 @@
+package etoe
+
+import (
+	"fmt"
+	"testing"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEWhen tests that Blocks, Sequences and Actions whose When condition is false are Skipped
+// and that a When condition can use the status and response of earlier Actions.
+func TestEtoEWhen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	plug := &testplugin.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: sqlite.New: %v", err)
+	}
+	defer store.Close(ctx)
+
+	key := workflow.NewV7()
+	action := func(name string, when workflow.WhenCond) *workflow.Action {
+		return &workflow.Action{Name: name, Descr: name, Plugin: testplugin.Name, Req: testplugin.Req{Arg: "echo:" + name}, When: when}
+	}
+
+	build, err := builder.New("when etoe", "tests When conditions etoe")
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: builder.New: %v", err)
+	}
+	build.AddBlock(builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1})
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:  "seq0",
+			Descr: "seq0",
+			Actions: []*workflow.Action{
+				// This returns a Resp with Arg "ok".
+				{Key: key, Name: "action0", Descr: "action0", Plugin: testplugin.Name, Req: testplugin.Req{Arg: "echo:ok"}},
+				action("ran", workflow.WhenCond(fmt.Sprintf(`status["%s"] == "Completed" && resp["%s"].Arg == "ok"`, key, key))),
+				action("skipped", workflow.WhenCond(fmt.Sprintf(`resp["%s"].Arg != "ok"`, key))),
+			},
+		},
+	).Up()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq1",
+			Descr:   "seq1",
+			When:    "meta == null && false",
+			Actions: []*workflow.Action{action("inSkippedSeq", "")},
+		},
+	).Up().Up()
+
+	build.AddBlock(
+		builder.BlockArgs{
+			Name:        "block1",
+			Descr:       "block1",
+			Concurrency: 1,
+			When:        workflow.WhenCond(fmt.Sprintf(`status["%s"] == "Failed"`, key)),
+		},
+	)
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:    "seq2",
+			Descr:   "seq2",
+			Actions: []*workflow.Action{action("inSkippedBlock", "")},
+		},
+	).Up().Up()
+
+	plan, err := build.Plan()
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: build.Plan: %v", err)
+	}
+
+	ws, err := workstream.New(ctx, reg, liveVault{store})
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: workstream.New: %v", err)
+	}
+	id, err := ws.Submit(ctx, plan)
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: Submit: %v", err)
+	}
+	if err := ws.Start(ctx, id); err != nil {
+		t.Fatalf("TestEtoEWhen: Start: %v", err)
+	}
+	if _, err := ws.Wait(ctx, id); err != nil {
+		t.Fatalf("TestEtoEWhen: Wait: %v", err)
+	}
+
+	result, err := store.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("TestEtoEWhen: store.Read: %v", err)
+	}
+	if got := result.State.Get().Status; got != workflow.Completed {
+		t.Fatalf("TestEtoEWhen: plan status = %v, want %v", got, workflow.Completed)
+	}
+
+	block0, block1 := result.Blocks[0], result.Blocks[1]
+	tests := []struct {
+		name string
+		obj  interface{ GetState() workflow.State }
+		want workflow.Status
+	}{
+		{name: "block0", obj: block0, want: workflow.Completed},
+		{name: "seq0", obj: block0.Sequences[0], want: workflow.Completed},
+		{name: "ran", obj: block0.Sequences[0].Actions[1], want: workflow.Completed},
+		{name: "skipped", obj: block0.Sequences[0].Actions[2], want: workflow.Skipped},
+		{name: "seq1", obj: block0.Sequences[1], want: workflow.Skipped},
+		{name: "inSkippedSeq", obj: block0.Sequences[1].Actions[0], want: workflow.Skipped},
+		{name: "block1", obj: block1, want: workflow.Skipped},
+		{name: "seq2", obj: block1.Sequences[0], want: workflow.Skipped},
+		{name: "inSkippedBlock", obj: block1.Sequences[0].Actions[0], want: workflow.Skipped},
+	}
+	for _, test := range tests {
+		if got := test.obj.GetState().Status; got != test.want {
+			t.Errorf("TestEtoEWhen(%s): status = %v, want %v", test.name, got, test.want)
+		}
+	}
+	if got, want := block1.When, workflow.WhenCond(fmt.Sprintf(`status["%s"] == "Failed"`, key)); got != want {
+		t.Errorf("TestEtoEWhen: stored block1 When = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package etoe
+
+import (
+	"testing"
+	"time"
+
+	workstream "github.com/element-of-surprise/coercion"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+
+	testplugin "github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+)
+
+// TestEtoEWindows tests that a Block waits for the maintenance window of its Plan to open and that a Block
+// with StopOnClose fails once its window closes. The Workstream is given a clock that starts just before
+// the window opens or closes.
+func TestEtoEWindows(t *testing.T) {
+	t.Parallel()
+
+	// This is a Wednesday.
+	opens := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	closes := opens.Add(time.Hour)
+	allow := []workflow.Window{{Start: 10 * time.Hour, End: 11 * time.Hour}}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		planWindows *workflow.Windows
+		blockArgs   builder.BlockArgs
+		wantStatus  workflow.Status
+		wantSeqs    []workflow.Status
+	}{
+		{
+			name:        "block waits for the window to open",
+			now:         opens.Add(-300 * time.Millisecond),
+			planWindows: &workflow.Windows{Allow: allow},
+			blockArgs:   builder.BlockArgs{Name: "block0", Descr: "block0", Concurrency: 1},
+			wantStatus:  workflow.Completed,
+			wantSeqs:    []workflow.Status{workflow.Completed, workflow.Completed},
+		},
+		{
+			name: "block stops when the window closes",
+			now:  closes.Add(-300 * time.Millisecond),
+			blockArgs: builder.BlockArgs{
+				Name:        "block0",
+				Descr:       "block0",
+				Concurrency: 1,
+				Windows:     &workflow.Windows{Allow: allow, StopOnClose: true},
+			},
+			wantStatus: workflow.Failed,
+			wantSeqs:   []workflow.Status{workflow.Completed, workflow.NotStarted},
+		},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+
+		plugCheck := &testplugin.Plugin{AlwaysRespond: true, IsCheckPlugin: true, PlugName: "check"}
+		plugAction := &testplugin.Plugin{AlwaysRespond: true}
+		reg := registry.New()
+		reg.Register(plugCheck)
+		reg.Register(plugAction)
+
+		store, err := sqlite.New(ctx, "", reg, sqlite.WithInMemory())
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): sqlite.New: %v", test.name, err)
+		}
+		defer store.Close(ctx)
+
+		var options []builder.Option
+		if test.planWindows != nil {
+			options = append(options, builder.WithWindows(test.planWindows))
+		}
+		build, err := builder.New("windows etoe", "tests maintenance windows etoe", options...)
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): builder.New: %v", test.name, err)
+		}
+		build.AddBlock(test.blockArgs)
+		build.AddChecks(builder.ContChecks, &workflow.Checks{Delay: time.Minute})
+		build.AddAction(&workflow.Action{Name: "healthy", Descr: "healthy", Plugin: "check", Req: testplugin.Req{Arg: "success"}})
+		build.Up()
+		for _, name := range []string{"seq0", "seq1"} {
+			build.AddSequence(
+				&workflow.Sequence{
+					Name:    name,
+					Descr:   name,
+					Actions: []*workflow.Action{{Name: "slow", Descr: "slow", Plugin: testplugin.Name, Req: testplugin.Req{Sleep: 500 * time.Millisecond}}},
+				},
+			)
+			build.Up()
+		}
+		build.Up()
+
+		plan, err := build.Plan()
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): build.Plan: %v", test.name, err)
+		}
+
+		offset := test.now.Sub(time.Now())
+		ws, err := workstream.New(ctx, reg, liveVault{store}, workstream.WithClock(func() time.Time { return time.Now().Add(offset) }))
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): workstream.New: %v", test.name, err)
+		}
+		id, err := ws.Submit(ctx, plan)
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): Submit: %v", test.name, err)
+		}
+		if err := ws.Start(ctx, id); err != nil {
+			t.Fatalf("TestEtoEWindows(%s): Start: %v", test.name, err)
+		}
+		result, err := ws.Wait(ctx, id)
+		if err != nil {
+			t.Fatalf("TestEtoEWindows(%s): Wait: %v", test.name, err)
+		}
+
+		if got := result.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestEtoEWindows(%s): plan status = %v, want %v", test.name, got, test.wantStatus)
+		}
+		block0 := result.Blocks[0]
+		if got := block0.State.Get().Start; got.Before(opens) {
+			t.Errorf("TestEtoEWindows(%s): block started at %v, before the window opened at %v", test.name, got, opens)
+		}
+		for i, seq := range block0.Sequences {
+			if got := seq.State.Get().Status; got != test.wantSeqs[i] {
+				t.Errorf("TestEtoEWindows(%s): sequence %s status = %v, want %v", test.name, seq.Name, got, test.wantSeqs[i])
+			}
+		}
+		if test.wantStatus == workflow.Failed {
+			if got := block0.ContChecks.State.Get().Status; got != workflow.Failed {
+				t.Errorf("TestEtoEWindows(%s): block ContChecks status = %v, want %v", test.name, got, workflow.Failed)
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package execute
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/errors"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+	"github.com/google/uuid"
+)
+
+// depsPoll is how often a Waiting Plan checks its PlanDeps if no Plan finishes in this process. This catches
+// Plans that are finished by something other than this process.
+const depsPoll = 30 * time.Second
+
+// finishes broadcasts that a Plan run in this process has finished, so that Waiting Plans check their
+// PlanDeps again. The zero value is ready to use.
+type finishes struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// next returns a channel that is closed the next time a Plan finishes. This must be called before the
+// PlanDeps are checked, so that a Plan that finishes during the check is not missed.
+func (f *finishes) next() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.ch == nil {
+		f.ch = make(chan struct{})
+	}
+	return f.ch
+}
+
+// finished wakes everything that is waiting on a channel from next.
+func (f *finishes) finished() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.ch != nil {
+		close(f.ch)
+		f.ch = nil
+	}
+}
+
+// depResult is the result of checking a PlanDep.
+type depResult uint8
+
+const (
+	// depWait is a PlanDep whose Plans have not all finished.
+	depWait depResult = 0
+	// depMet is a PlanDep whose Plans all finished the way it wants.
+	depMet depResult = 1
+	// depUnmet is a PlanDep that can no longer be met.
+	depUnmet depResult = 2
+)
+
+// checkDep checks dep against the Statuses of the Plans it waits on. A Plan that is not in statuses, which
+// happens if it was deleted, can no longer finish, so the PlanDep is unmet.
+func checkDep(dep *workflow.PlanDep, statuses map[uuid.UUID]workflow.Status) depResult {
+	result := depMet
+	for _, id := range dep.Plans {
+		status, ok := statuses[id]
+		if !ok {
+			return depUnmet
+		}
+		switch status {
+		case workflow.Completed:
+			if dep.On == workflow.OCFail {
+				return depUnmet
+			}
+		case workflow.Failed, workflow.Stopped:
+			if dep.On == workflow.OCPass {
+				return depUnmet
+			}
+		case workflow.Skipped:
+			return depUnmet
+		default:
+			result = depWait
+		}
+	}
+	return result
+}
+
+// checkDeps checks all the PlanDeps of plan. It returns depMet only if all of them are met and depUnmet if
+// any of them is.
+func (e *Plans) checkDeps(ctx context.Context, plan *workflow.Plan) (depResult, error) {
+	ids := planDeps(plan)
+	if len(ids) == 0 {
+		return depMet, nil
+	}
+	statuses := make(map[uuid.UUID]workflow.Status, len(ids))
+	results, err := e.store.Search(ctx, storage.Filters{ByIDs: ids})
+	if err != nil {
+		return depWait, err
+	}
+	// The results must be drained before anything else is done with the store.
+	for result := range results {
+		if result.Err != nil {
+			err = result.Err
+			continue
+		}
+		statuses[result.Result.ID] = result.Result.State.Status
+	}
+	if err != nil {
+		return depWait, err
+	}
+
+	result := depMet
+	for _, dep := range plan.DependsOn {
+		switch checkDep(dep, statuses) {
+		case depUnmet:
+			return depUnmet, nil
+		case depWait:
+			result = depWait
+		}
+	}
+	return result, nil
+}
+
+// resolveDeps records in the PlanDeps of plan the Plans that they wait on. A PlanDep with an ID waits on
+// that Plan, which must exist. A PlanDep with a GroupID waits on the Plans with the GroupID other than plan,
+// of which there must be at least one. PlanDeps that were already resolved are left alone.
+func (e *Plans) resolveDeps(ctx context.Context, plan *workflow.Plan) error {
+	for _, dep := range plan.DependsOn {
+		if len(dep.Plans) != 0 {
+			continue
+		}
+		ids, err := e.depPlans(ctx, plan.ID, dep)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			if dep.ID != uuid.Nil {
+				return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) depends on plan(%s), which does not exist", plan.ID, dep.ID))
+			}
+			return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) depends on group(%s), which has no other plans", plan.ID, dep.GroupID))
+		}
+		dep.Plans = ids
+	}
+	return nil
+}
+
+// depPlans returns the IDs of the Plans that dep of the Plan with id waits on, as they are in storage now.
+func (e *Plans) depPlans(ctx context.Context, id uuid.UUID, dep *workflow.PlanDep) ([]uuid.UUID, error) {
+	filters := storage.Filters{ByGroupIDs: []uuid.UUID{dep.GroupID}}
+	if dep.ID != uuid.Nil {
+		filters = storage.Filters{ByIDs: []uuid.UUID{dep.ID}}
+	}
+	results, err := e.store.Search(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for result := range results {
+		if result.Err != nil {
+			err = result.Err
+			continue
+		}
+		if result.Result.ID != id {
+			ids = append(ids, result.Result.ID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	return ids, nil
+}
+
+// checkCycle returns an error if plan, whose PlanDeps are resolved, would wait on itself through the PlanDeps
+// of the Plans it waits on. Plans that are not started have their PlanDeps resolved as they would be now.
+// Plans that have finished do not wait on anything.
+func (e *Plans) checkCycle(ctx context.Context, plan *workflow.Plan) error {
+	edges := func(id uuid.UUID) ([]uuid.UUID, error) {
+		if id == plan.ID {
+			return planDeps(plan), nil
+		}
+		p, err := e.store.Read(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch p.State.Get().Status {
+		case workflow.Completed, workflow.Failed, workflow.Stopped, workflow.Skipped:
+			return nil, nil
+		}
+		var ids []uuid.UUID
+		for _, dep := range p.DependsOn {
+			if len(dep.Plans) != 0 {
+				ids = append(ids, dep.Plans...)
+				continue
+			}
+			resolved, err := e.depPlans(ctx, p.ID, dep)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, resolved...)
+		}
+		return ids, nil
+	}
+
+	path, err := findCycle(plan.ID, edges)
+	if err != nil {
+		return err
+	}
+	if path != nil {
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) depends on itself through plans %v", plan.ID, path))
+	}
+	return nil
+}
+
+// planDeps returns the IDs of the Plans that the resolved PlanDeps of plan wait on.
+func planDeps(plan *workflow.Plan) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, dep := range plan.DependsOn {
+		ids = append(ids, dep.Plans...)
+	}
+	return ids
+}
+
+// findCycle returns the path of Plan IDs from start back to start if start waits on itself. edges returns the
+// IDs of the Plans that a Plan waits on. This returns nil if there is no cycle through start.
+func findCycle(start uuid.UUID, edges func(uuid.UUID) ([]uuid.UUID, error)) ([]uuid.UUID, error) {
+	visited := map[uuid.UUID]bool{}
+	var visit func(id uuid.UUID, path []uuid.UUID) ([]uuid.UUID, error)
+	visit = func(id uuid.UUID, path []uuid.UUID) ([]uuid.UUID, error) {
+		path = append(path, id)
+		if id == start && len(path) > 1 {
+			return path, nil
+		}
+		if visited[id] {
+			return nil, nil
+		}
+		visited[id] = true
+
+		next, err := edges(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range next {
+			if cycle, err := visit(n, path); cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit(start, nil)
+}
+
+// waiting is a Plan that was started with PlanDeps that are not met. It holds the claim for the Plan's run
+// until the Plan is admitted, skipped or stopped.
+type waiting struct {
+	plan    *workflow.Plan
+	runCtx  context.Context
+	stop    <-chan struct{}
+	release func()
+}
+
+// wait records plan as Waiting and waits on its PlanDeps in the background. runCtx, stop and release must
+// come from a winning claimRun.
+func (e *Plans) wait(ctx context.Context, runCtx context.Context, stop <-chan struct{}, release func(), plan *workflow.Plan) error {
+	state := plan.State.Get()
+	state.Status = workflow.Waiting
+	state.Start = e.now()
+	plan.State.Set(state)
+	if err := e.store.UpdatePlan(ctx, plan); err != nil {
+		release()
+		return err
+	}
+	// The Context of the caller may be cancelled once we return, so we wait with the run's.
+	item := &waiting{plan: plan, runCtx: runCtx, stop: stop, release: release}
+	context.Pool(runCtx).Submit(runCtx, func() { e.waitDeps(runCtx, item) })
+	return nil
+}
+
+// waitDeps waits for the PlanDeps of the Waiting Plan in item. Once they are met, the Plan is admitted to
+// run. If they can no longer be met, the Plan is recorded as Skipped. If the Plan is stopped while it is
+// Waiting, it is recorded as Stopped without running.
+func (e *Plans) waitDeps(ctx context.Context, item *waiting) {
+	poll := e.depsPoll
+	if poll == 0 {
+		poll = depsPoll
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		finished := e.finishes.next()
+		result, err := e.checkDeps(ctx, item.plan)
+		if err != nil {
+			context.Log(ctx).Error("could not check the plans a plan depends on", "id", item.plan.ID, "error", err)
+		}
+		switch result {
+		case depMet:
+			if err := e.admit(ctx, item.runCtx, item.stop, item.release, item.plan); err != nil {
+				context.Log(ctx).Error("could not start waiting plan", "id", item.plan.ID, "error", err)
+			}
+			return
+		case depUnmet:
+			e.endWaiting(ctx, item, workflow.Skipped, workflow.FRUnknown)
+			return
+		}
+
+		select {
+		case <-finished:
+		case <-ticker.C:
+		case <-item.stop:
+			e.endWaiting(ctx, item, workflow.Stopped, workflow.FRStopped)
+			return
+		}
+	}
+}
+
+// endWaiting records the Waiting Plan in item as finished with status and reason and releases its run.
+func (e *Plans) endWaiting(ctx context.Context, item *waiting, status workflow.Status, reason workflow.FailureReason) {
+	defer item.release()
+
+	state := item.plan.State.Get()
+	state.Status = status
+	state.End = e.now()
+	item.plan.State.Set(state)
+	item.plan.Reason = reason
+	if err := e.store.UpdatePlan(item.runCtx, item.plan); err != nil {
+		context.Log(ctx).Error("could not end waiting plan", "id", item.plan.ID, "status", status, "error", err)
+	}
+}
@@ -0,0 +1,198 @@
+package execute
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/google/uuid"
+)
+
+func TestCheckDep(t *testing.T) {
+	t.Parallel()
+
+	a, b := NewV7(), NewV7()
+
+	tests := []struct {
+		name     string
+		on       workflow.Outcome
+		statuses map[uuid.UUID]workflow.Status
+		want     depResult
+	}{
+		{
+			name:     "Pass: all Completed",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Completed, b: workflow.Completed},
+			want:     depMet,
+		},
+		{
+			name:     "Pass: one still Running",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Completed, b: workflow.Running},
+			want:     depWait,
+		},
+		{
+			name:     "Pass: one Waiting",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Waiting, b: workflow.Completed},
+			want:     depWait,
+		},
+		{
+			name:     "Pass: one Failed while another runs",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Running, b: workflow.Failed},
+			want:     depUnmet,
+		},
+		{
+			name:     "Pass: one Stopped",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Completed, b: workflow.Stopped},
+			want:     depUnmet,
+		},
+		{
+			name:     "Pass: one Skipped",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Completed, b: workflow.Skipped},
+			want:     depUnmet,
+		},
+		{
+			name:     "Pass: one was deleted",
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Completed},
+			want:     depUnmet,
+		},
+		{
+			name:     "Fail: Failed and Stopped",
+			on:       workflow.OCFail,
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Failed, b: workflow.Stopped},
+			want:     depMet,
+		},
+		{
+			name:     "Fail: one Completed",
+			on:       workflow.OCFail,
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Failed, b: workflow.Completed},
+			want:     depUnmet,
+		},
+		{
+			name:     "Fail: one Skipped",
+			on:       workflow.OCFail,
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Failed, b: workflow.Skipped},
+			want:     depUnmet,
+		},
+		{
+			name:     "Fail: one Queued",
+			on:       workflow.OCFail,
+			statuses: map[uuid.UUID]workflow.Status{a: workflow.Failed, b: workflow.Queued},
+			want:     depWait,
+		},
+	}
+
+	for _, test := range tests {
+		dep := &workflow.PlanDep{GroupID: NewV7(), On: test.on, Plans: []uuid.UUID{a, b}}
+		if got := checkDep(dep, test.statuses); got != test.want {
+			t.Errorf("TestCheckDep(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	t.Parallel()
+
+	ids := make([]uuid.UUID, 4)
+	for i := range ids {
+		ids[i] = NewV7()
+	}
+
+	tests := []struct {
+		name string
+		// edges are the indexes of the Plans that each Plan waits on.
+		edges map[int][]int
+		// want is the indexes of the Plans in the cycle through ids[0], nil if there is none.
+		want []int
+		err  bool
+	}{
+		{
+			name:  "no dependencies",
+			edges: map[int][]int{},
+		},
+		{
+			name:  "chain",
+			edges: map[int][]int{0: {1}, 1: {2}, 2: {3}},
+		},
+		{
+			name:  "diamond",
+			edges: map[int][]int{0: {1, 2}, 1: {3}, 2: {3}},
+		},
+		{
+			name:  "cycle that does not go through the Plan",
+			edges: map[int][]int{0: {1}, 1: {2}, 2: {1}},
+		},
+		{
+			name:  "two Plans",
+			edges: map[int][]int{0: {1}, 1: {0}},
+			want:  []int{0, 1, 0},
+		},
+		{
+			name:  "through a diamond",
+			edges: map[int][]int{0: {1, 2}, 1: {3}, 2: {3}, 3: {0}},
+			want:  []int{0, 1, 3, 0},
+		},
+		{
+			name:  "storage error",
+			edges: map[int][]int{0: {1}, 1: {-1}},
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		edges := func(id uuid.UUID) ([]uuid.UUID, error) {
+			from := slices.Index(ids, id)
+			var next []uuid.UUID
+			for _, i := range test.edges[from] {
+				if i < 0 {
+					return nil, fmt.Errorf("error")
+				}
+				next = append(next, ids[i])
+			}
+			return next, nil
+		}
+
+		got, err := findCycle(ids[0], edges)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestFindCycle(%s): got err == nil, want err != nil", test.name)
+			continue
+		case !test.err && err != nil:
+			t.Errorf("TestFindCycle(%s): got err == %s, want err == nil", test.name, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		var want []uuid.UUID
+		for _, i := range test.want {
+			want = append(want, ids[i])
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("TestFindCycle(%s): got %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestFinishes(t *testing.T) {
+	t.Parallel()
+
+	f := &finishes{}
+	// Nothing is waiting, so this does nothing.
+	f.finished()
+
+	a := f.next()
+	if b := f.next(); a != b {
+		t.Errorf("TestFinishes: next before a finish got a new channel, want the same channel")
+	}
+	f.finished()
+	select {
+	case <-a:
+	default:
+		t.Errorf("TestFinishes: a finish did not close the channel from next")
+	}
+	select {
+	case <-f.next():
+		t.Errorf("TestFinishes: next after a finish got a closed channel, want an open channel")
+	default:
+	}
+}
@@ -4,6 +4,9 @@ package execute
 
 import (
 	"fmt"
+	"iter"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/element-of-surprise/coercion/internal/execute/sm"
@@ -16,6 +19,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/gostdlib/base/statemachine"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -41,6 +46,12 @@ type Plans struct {
 
 	// running tracks plan IDs executing in this process and guards against duplicate in-flight runs.
 	running *running
+	// queue limits the number of Plans that run at the same time. This is nil if there is no limit.
+	queue *queue
+	// finishes wakes the Waiting Plans when a Plan finishes.
+	finishes finishes
+	// depsPoll is how often a Waiting Plan checks its PlanDeps. If this is zero, depsPoll is used.
+	depsPoll time.Duration
 
 	// runner is the function that runs the statemachine.
 	// In production this is the statemachine.Run function.
@@ -56,6 +67,12 @@ type Plans struct {
 	maxSubmit time.Duration
 	// recovery is true if recovery is allowed.
 	recovery bool
+	// hooks are called as Plans change state. This can be nil.
+	hooks workflow.Hooks
+	// tracerProvider provides the Tracer for the span of each Plan.
+	tracerProvider trace.TracerProvider
+	// smOptions are the options for the statemachine.
+	smOptions []sm.Option
 }
 
 // Option is an option for configuring a Plans via New.
@@ -89,16 +106,66 @@ func WithNoRecovery() Option {
 	}
 }
 
+// WithHooks sets Hooks that are called as Plans change state.
+func WithHooks(h workflow.Hooks) Option {
+	return func(p *Plans) error {
+		if h == nil {
+			return fmt.Errorf("hooks cannot be nil")
+		}
+		p.hooks = h
+		return nil
+	}
+}
+
+// WithTracerProvider sets the TracerProvider used to create a span for each Plan. The spans for the objects in
+// the Plan are children of the Plan's span. If this is not set, the global TracerProvider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(p *Plans) error {
+		if tp == nil {
+			return fmt.Errorf("tracer provider cannot be nil")
+		}
+		p.tracerProvider = tp
+		return nil
+	}
+}
+
+// WithMaxRunningPlans sets the maximum number of Plans that can run at the same time. A Plan that is started
+// once this is reached is Queued until a running Plan finishes. Queued Plans run in the order of their Priority
+// and then in the order they were started. Queued Plans are kept in that order if they are recovered.
+// If this is not set, there is no limit.
+func WithMaxRunningPlans(n int) Option {
+	return func(p *Plans) error {
+		if n < 1 {
+			return fmt.Errorf("max running plans must be at least 1")
+		}
+		p.queue = newQueue(n)
+		return nil
+	}
+}
+
+// WithClock sets the function that returns the current time for the statemachine. This is the time that
+// maintenance windows are evaluated at and that is recorded in the State of objects.
+func WithClock(now func() time.Time) Option {
+	return func(p *Plans) error {
+		if now == nil {
+			return fmt.Errorf("clock cannot be nil")
+		}
+		p.smOptions = append(p.smOptions, sm.WithClock(now))
+		return nil
+	}
+}
+
 // New creates a new Executor. This should only be created once.
 func New(ctx context.Context, store storage.Vault, reg *registry.Register, options ...Option) (*Plans, error) {
 	e := &Plans{
-		registry:      reg,
-		store:         store,
-		running:       newRunning(),
-		runner:        statemachine.Run[sm.Data],
-		maxLastUpdate: 30 * time.Minute,
-		maxSubmit:     30 * time.Minute,
-		recovery:      true,
+		registry:       reg,
+		store:          store,
+		running:        newRunning(),
+		runner:         statemachine.Run[sm.Data],
+		maxLastUpdate:  30 * time.Minute,
+		maxSubmit:      30 * time.Minute,
+		recovery:       true,
+		tracerProvider: otel.GetTracerProvider(),
 	}
 
 	for _, o := range options {
@@ -112,7 +179,7 @@ func New(ctx context.Context, store storage.Vault, reg *registry.Register, optio
 	}
 
 	var err error
-	e.states, err = sm.New(store, e.registry)
+	e.states, err = sm.New(ctx, store, e.registry, e.tracerProvider, e.smOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,6 +224,9 @@ func (e *Plans) initPlugins(ctx context.Context) error {
 
 // Start starts a previously Submitted Plan by its ID. Cancelling the Context will not Stop execution.
 // Please use Stop to stop execution of a Plan. If the plan has already been started, this will return nil.
+// If the Plan has a DependsOn, the Plans it depends on are resolved and checked for a cycle, and the Plan is
+// Waiting until they finish. If WithMaxRunningPlans was used and that many Plans are running, the Plan is
+// Queued until it can run.
 func (e *Plans) Start(ctx context.Context, id uuid.UUID) error {
 	plan, err := e.store.Read(ctx, id)
 	if err != nil {
@@ -172,21 +242,71 @@ func (e *Plans) Start(ctx context.Context, id uuid.UUID) error {
 		if err := e.validateStartState(plan); err != nil {
 			return err
 		}
-	case planStatus == workflow.Running:
+	case planStatus == workflow.Scheduled:
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is scheduled and is started by its schedule", id))
+	case planStatus.Active():
 		return nil
 	case planStatus > workflow.Running:
 		return nil
 	}
 
+	if len(plan.DependsOn) != 0 {
+		if err := e.resolveDeps(ctx, plan); err != nil {
+			return err
+		}
+		if err := e.checkCycle(ctx, plan); err != nil {
+			return err
+		}
+	}
+
 	// Claim before any storage mutation. The claim is the single source of truth for "is this ID
 	// running here", so taking it first guarantees that a duplicate Start cannot reach UpdatePlan and
 	// overwrite the winner's in-flight progress with a stale plan object. Losing the claim means a run
 	// is already in flight; the plan is (or is about to be) Running, so report success and do nothing.
-	runCtx, release, won := e.claimRun(ctx, plan.ID)
+	runCtx, stop, release, won := e.claimRun(ctx, plan.ID)
 	if !won {
 		return nil
 	}
 
+	if len(plan.DependsOn) != 0 {
+		return e.wait(ctx, runCtx, stop, release, plan)
+	}
+	return e.admit(ctx, runCtx, stop, release, plan)
+}
+
+// admit runs plan if fewer than the maximum Plans are running. Otherwise plan is recorded as Queued and runs
+// once it reaches the front of the queue. runCtx, stop and release must come from a winning claimRun.
+func (e *Plans) admit(ctx context.Context, runCtx context.Context, stop <-chan struct{}, release func(), plan *workflow.Plan) error {
+	if e.queue == nil {
+		return e.startRun(ctx, runCtx, stop, release, plan)
+	}
+
+	item := &queued{plan: plan, runCtx: runCtx, stop: stop, release: release, admitted: make(chan struct{})}
+	// A recovered Plan keeps the time it was queued, so it keeps its place in the queue.
+	if plan.State.Get().Status != workflow.Queued {
+		state := plan.State.Get()
+		state.Status = workflow.Queued
+		state.Start = e.now()
+		plan.State.Set(state)
+	}
+	if e.queue.enter(item) {
+		return e.startRun(ctx, runCtx, stop, e.queue.releaser(release), plan)
+	}
+
+	if err := e.store.UpdatePlan(ctx, plan); err != nil {
+		if !e.queue.remove(item) {
+			e.queue.done()
+		}
+		release()
+		return err
+	}
+	// The Context of the caller may be cancelled once we return, so we wait with the run's.
+	context.Pool(runCtx).Submit(runCtx, func() { e.waitQueued(runCtx, item) })
+	return nil
+}
+
+// startRun records plan as Running and launches it. runCtx, stop and release must come from a winning claimRun.
+func (e *Plans) startRun(ctx context.Context, runCtx context.Context, stop <-chan struct{}, release func(), plan *workflow.Plan) error {
 	// We own the run. Record Running before launching so the plan is durably Running when we return.
 	state := plan.State.Get()
 	state.Status = workflow.Running
@@ -197,11 +317,43 @@ func (e *Plans) Start(ctx context.Context, id uuid.UUID) error {
 		return err
 	}
 
-	e.launch(ctx, runCtx, release, plan, nil)
-
+	e.launch(ctx, runCtx, stop, release, plan, nil)
 	return nil
 }
 
+// waitQueued waits for the Queued Plan in item to be admitted and then runs it. If the Plan is stopped while
+// it is Queued, it is removed from the queue and recorded as Stopped without running.
+func (e *Plans) waitQueued(ctx context.Context, item *queued) {
+	select {
+	case <-item.admitted:
+	case <-item.stop:
+		if e.queue.remove(item) {
+			e.stopQueued(ctx, item)
+			return
+		}
+		// The Plan was admitted as it was stopped. It runs and the statemachine stops it.
+	}
+
+	if err := e.startRun(ctx, item.runCtx, item.stop, e.queue.releaser(item.release), item.plan); err != nil {
+		context.Log(ctx).Error("could not start queued plan", "id", item.plan.ID, "error", err)
+	}
+}
+
+// stopQueued records the Queued Plan in item as Stopped and releases its run.
+func (e *Plans) stopQueued(ctx context.Context, item *queued) {
+	defer item.release()
+
+	now := e.now()
+	state := item.plan.State.Get()
+	state.Status = workflow.Stopped
+	state.End = now
+	item.plan.State.Set(state)
+	item.plan.Reason = workflow.FRStopped
+	if err := e.store.UpdatePlan(item.runCtx, item.plan); err != nil {
+		context.Log(ctx).Error("could not stop queued plan", "id", item.plan.ID, "error", err)
+	}
+}
+
 // recover recovers a Plan that is in a Running state in storage and restarts it from where it left off.
 // This is used when the Executor starts up.
 func (e *Plans) recover(ctx context.Context) error {
@@ -223,11 +375,27 @@ func (e *Plans) recover(ctx context.Context) error {
 		return nil
 	}
 
+	// The Locks that the recovered plans held are taken before any of them runs, so that a lock
+	// held by one is not granted to another.
+	for _, plan := range req.Data.plans {
+		e.states.RetakeLocks(ctx, plan)
+	}
+
 	// recoveryStarted is used to wait for all the recovered plans to start running.
 	// runPlan starts its own goroutine and this is used to signal when the plan has started.
 	recoveryStarted := make([]chan struct{}, 0, len(req.Data.plans))
+	var queued, dependent []*workflow.Plan
 	for _, plan := range req.Data.plans {
 		context.Log(ctx).Info("coercion: recovered plan", "id", plan.ID, "status", plan.State.Get().Status)
+		// Queued Plans have not run, so they are queued again once the running Plans have taken their slots.
+		switch plan.State.Get().Status {
+		case workflow.Queued:
+			queued = append(queued, plan)
+			continue
+		case workflow.Waiting:
+			dependent = append(dependent, plan)
+			continue
+		}
 		w := make(chan struct{})
 		recoveryStarted = append(recoveryStarted, w)
 		e.runPlan(ctx, plan, w)
@@ -237,6 +405,27 @@ func (e *Plans) recover(ctx context.Context) error {
 		<-rs
 	}
 
+	slices.SortStableFunc(queued, queueOrder)
+	for _, plan := range queued {
+		runCtx, stop, release, won := e.claimRun(ctx, plan.ID)
+		if !won {
+			continue
+		}
+		if err := e.admit(ctx, runCtx, stop, release, plan); err != nil {
+			return err
+		}
+	}
+
+	// Waiting Plans have their PlanDeps resolved, so they wait on the same Plans as before.
+	for _, plan := range dependent {
+		runCtx, stop, release, won := e.claimRun(ctx, plan.ID)
+		if !won {
+			continue
+		}
+		item := &waiting{plan: plan, runCtx: runCtx, stop: stop, release: release}
+		context.Pool(runCtx).Submit(runCtx, func() { e.waitDeps(runCtx, item) })
+	}
+
 	return nil
 }
 
@@ -246,7 +435,7 @@ func (e *Plans) recover(ctx context.Context) error {
 // delete the second's entry, which would leave storage Running with no waiter (the source of the
 // "running state, but isn't in the waiters" bug in Wait).
 func (e *Plans) runPlan(ctx context.Context, plan *workflow.Plan, recoveryStarted chan struct{}) {
-	runCtx, release, won := e.claimRun(ctx, plan.ID)
+	runCtx, stop, release, won := e.claimRun(ctx, plan.ID)
 	if !won {
 		// A recovery caller waits for this channel to be closed; the in-flight run owns closing it,
 		// so signal completion here to avoid hanging the recovery loop.
@@ -255,28 +444,55 @@ func (e *Plans) runPlan(ctx context.Context, plan *workflow.Plan, recoveryStarte
 		}
 		return
 	}
+	// A recovered Plan was already running, so it takes a slot even if that puts us over the limit.
+	if e.queue != nil {
+		e.queue.take()
+		release = e.queue.releaser(release)
+	}
 
-	e.launch(ctx, runCtx, release, plan, recoveryStarted)
+	e.launch(ctx, runCtx, stop, release, plan, recoveryStarted)
 }
 
 // claimRun takes the single-run claim for plan id, fencing out duplicate runs. On success it returns
-// the run context, a release closure that MUST be called exactly once when the run finishes (launch
-// defers it), and won==true. On failure a run for id is already in flight: runCtx and release are nil
-// and won==false. The run context is derived from ctx but not cancelled by it; only release (via
-// Stop, later) cancels the run.
-func (e *Plans) claimRun(ctx context.Context, id uuid.UUID) (runCtx context.Context, release func(), won bool) {
-	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
-	release, won = e.running.claim(id, cancel)
+// the run context, a stop channel that is closed when Stop is called, a release closure that MUST be
+// called exactly once when the run finishes (launch defers it), and won==true. On failure a run for id
+// is already in flight: runCtx, stop and release are nil and won==false. The run context is derived
+// from ctx but is never cancelled, as storage writes must still succeed while a Plan is stopping.
+// release also wakes the Waiting Plans, as they may depend on the Plan.
+func (e *Plans) claimRun(ctx context.Context, id uuid.UUID) (runCtx context.Context, stop <-chan struct{}, release func(), won bool) {
+	runCtx = context.WithoutCancel(ctx)
+	stopCtx, cancel := context.WithCancel(runCtx)
+	unclaim, won := e.running.claim(id, cancel)
 	if !won {
 		cancel()
-		return nil, nil, false
+		return nil, nil, nil, false
+	}
+	release = func() {
+		unclaim()
+		e.finishes.finished()
 	}
-	return runCtx, release, true
+	return runCtx, stopCtx.Done(), release, true
 }
 
-// launch submits the statemachine run for plan on a goroutine and returns immediately. runCtx and
+// launch submits the statemachine run for plan on a goroutine and returns immediately. runCtx, stop and
 // release must come from a winning claimRun; the goroutine calls release exactly once on completion.
-func (e *Plans) launch(ctx context.Context, runCtx context.Context, release func(), plan *workflow.Plan, recoveryStarted chan struct{}) {
+func (e *Plans) launch(ctx context.Context, runCtx context.Context, stop <-chan struct{}, release func(), plan *workflow.Plan, recoveryStarted chan struct{}) {
+	f := newFeed()
+	e.running.setFeed(plan.ID, f)
+	events := sm.NewEvents(plan, f.send)
+	pauser := sm.NewPauser(plan, e.store, events)
+	e.running.setPauser(plan.ID, pauser)
+	approvals := sm.NewApprovals(plan, e.store)
+	e.running.setApprovals(plan.ID, approvals)
+
+	if e.hooks != nil {
+		// We subscribe before the run starts so that the Hooks see every change, which is also why their queue
+		// is not bounded. RunHooks ends when release closes the feed.
+		sub := f.subscribe(0)
+		hookCtx := context.SetPlanID(context.WithoutCancel(runCtx), plan.ID)
+		context.Pool(ctx).Submit(hookCtx, func() { sm.RunHooks(hookCtx, e.hooks, plan, sub.next, recoveryStarted == nil) })
+	}
+
 	context.Pool(ctx).Submit(
 		ctx,
 		func() {
@@ -292,6 +508,10 @@ func (e *Plans) launch(ctx context.Context, runCtx context.Context, release func
 				Data: sm.Data{
 					Plan:            plan,
 					RecoveryStarted: recoveryStarted,
+					Stop:            stop,
+					Pause:           pauser,
+					Approvals:       approvals,
+					Events:          events,
 				},
 				Next: next,
 			}
@@ -320,9 +540,9 @@ func (e *Plans) Wait(ctx context.Context, id uuid.UUID) error {
 			return err
 		}
 		switch plan.GetState().Status {
-		case workflow.NotStarted:
+		case workflow.NotStarted, workflow.Scheduled:
 			return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is not started", id))
-		case workflow.Running:
+		case workflow.Waiting, workflow.Queued, workflow.Running, workflow.Paused, workflow.Stopping:
 			return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("bug: plan(%s) has a running state, but isn't in the waiters", id))
 		}
 		return nil
@@ -336,6 +556,201 @@ func (e *Plans) Wait(ctx context.Context, id uuid.UUID) error {
 	}
 }
 
+// Stop stops a running Plan by its ID. The Plan is recorded as Stopping before any work is stopped, so a
+// Plan that is recovered after a crash will finish stopping instead of restarting. Actions that are
+// executing are allowed to finish or time out, but no new Blocks, Sequences or Actions are started. DeferredActions and DeferredChecks are still run as configured.
+// Objects that did not finish are marked Stopped and the Plan ends as Stopped with a Reason of FRStopped.
+// Stop blocks until the final state of the Plan has been written. Cancelling the Context stops waiting,
+// but the Plan will still stop. If the Plan has already finished, this returns nil.
+func (e *Plans) Stop(ctx context.Context, id uuid.UUID) error {
+	if !e.running.stop(id) {
+		plan, err := e.store.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		switch plan.GetState().Status {
+		case workflow.NotStarted, workflow.Scheduled:
+			return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is not started", id))
+		case workflow.Waiting, workflow.Queued, workflow.Running, workflow.Paused, workflow.Stopping:
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("bug: plan(%s) has a running state, but isn't in the stoppers", id))
+		}
+		return nil
+	}
+
+	return e.Wait(ctx, id)
+}
+
+// Pause pauses a running Plan by its ID. Blocks and Sequences that are running are allowed to finish, but no new
+// Blocks or Sequences are started until Resume is called. A paused Plan has a Status of Paused, which is kept
+// if the Plan is recovered. Pausing a Plan that is already paused does nothing.
+func (e *Plans) Pause(ctx context.Context, id uuid.UUID) error {
+	p, err := e.pauser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := p.Pause(ctx); err != nil {
+		if errors.Is(err, sm.ErrNotPausable) {
+			return errors.E(ctx, errors.CatUser, errors.TypeParameter, err)
+		}
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, err)
+	}
+	return nil
+}
+
+// Resume resumes a Plan that was paused with Pause. Resuming a Plan that is not paused does nothing.
+func (e *Plans) Resume(ctx context.Context, id uuid.UUID) error {
+	p, err := e.pauser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := p.Resume(ctx); err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, err)
+	}
+	return nil
+}
+
+// Approve approves the Approval with key in a running Plan by its ID on behalf of approver. The Block or
+// Sequence waiting at the Approval's gate continues. An Approval can be approved before its gate is reached.
+// The decision and approver are written to storage, so they are kept if the Plan is recovered.
+func (e *Plans) Approve(ctx context.Context, id uuid.UUID, key, approver string) error {
+	return e.decide(ctx, id, key, approver, true)
+}
+
+// Reject rejects the Approval with key in a running Plan by its ID on behalf of approver. The Block or
+// Sequence with the Approval fails. The decision and approver are written to storage, so they are kept if
+// the Plan is recovered.
+func (e *Plans) Reject(ctx context.Context, id uuid.UUID, key, approver string) error {
+	return e.decide(ctx, id, key, approver, false)
+}
+
+// decide approves or rejects the Approval with key in the Plan with id.
+func (e *Plans) decide(ctx context.Context, id uuid.UUID, key, approver string, approve bool) error {
+	if strings.TrimSpace(approver) == "" {
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("approver is required"))
+	}
+
+	a, ok := e.running.approvals(id)
+	if !ok {
+		return e.notRunning(ctx, id, "approvals")
+	}
+	decide := a.Reject
+	if approve {
+		decide = a.Approve
+	}
+	if err := decide(ctx, key, approver); err != nil {
+		if errors.Is(err, sm.ErrNotApprovable) {
+			return errors.E(ctx, errors.CatUser, errors.TypeParameter, err)
+		}
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, err)
+	}
+	return nil
+}
+
+// Events returns the Events for a Plan by its ID. A Snapshot Event is returned for every object in the Plan as it
+// is in storage, followed by an Event for each change as it is written to storage. Iteration ends when the Plan
+// finishes or the Context is done. If the Plan is not running in this process, only the Snapshot is returned.
+// Changes that are not read are queued. If more than maxQueued are queued, they are dropped and a new Snapshot
+// of the Plan as it is in storage is returned in their place, so a reader that stops does not hold every Event.
+func (e *Plans) Events(ctx context.Context, id uuid.UUID) (iter.Seq[workflow.Event], error) {
+	// We subscribe before we read the Plan so that no change can be missed between the two.
+	f, ok := e.running.feed(id)
+	var sub *subscriber
+	if ok {
+		sub = f.subscribe(maxQueued)
+	}
+
+	plan, err := e.store.Read(ctx, id)
+	if err != nil {
+		if sub != nil {
+			f.unsubscribe(sub)
+		}
+		return nil, err
+	}
+	if plan == nil {
+		if sub != nil {
+			f.unsubscribe(sub)
+		}
+		return nil, ErrNotFound
+	}
+
+	return func(yield func(workflow.Event) bool) {
+		if sub != nil {
+			defer f.unsubscribe(sub)
+		}
+
+		statuses := map[uuid.UUID]workflow.Status{}
+		snapshot := func(plan *workflow.Plan) bool {
+			for ev := range sm.Snapshot(plan) {
+				statuses[ev.ID] = ev.NewStatus
+				if !yield(ev) {
+					return false
+				}
+			}
+			return true
+		}
+		if !snapshot(plan) || sub == nil {
+			return
+		}
+
+		for {
+			evs, lagged, ok := sub.read(ctx)
+			if lagged {
+				// Events were dropped. Changes are written before they are sent, so a new Snapshot has
+				// everything that was dropped and everything in evs.
+				plan, err := e.store.Read(ctx, id)
+				if err != nil {
+					context.Log(ctx).Error("could not read plan for events", "id", id, "error", err)
+					return
+				}
+				if !snapshot(plan) {
+					return
+				}
+				evs = nil
+			}
+			for _, ev := range evs {
+				// A change that was written before we read the Plan is already in the Snapshot.
+				if ev.Attempt == nil && statuses[ev.ID] == ev.NewStatus {
+					continue
+				}
+				statuses[ev.ID] = ev.NewStatus
+				if !yield(ev) {
+					return
+				}
+			}
+			if !ok {
+				return
+			}
+		}
+	}, nil
+}
+
+// pauser returns the Pauser for a Plan that is running in this process.
+func (e *Plans) pauser(ctx context.Context, id uuid.UUID) (*sm.Pauser, error) {
+	if p, ok := e.running.pauser(id); ok {
+		return p, nil
+	}
+	return nil, e.notRunning(ctx, id, "pausers")
+}
+
+// notRunning returns the error for a Plan that was not found in the running map named by where.
+func (e *Plans) notRunning(ctx context.Context, id uuid.UUID, where string) error {
+	plan, err := e.store.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	switch plan.GetState().Status {
+	case workflow.NotStarted, workflow.Scheduled:
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is not started", id))
+	case workflow.Waiting:
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is waiting on the plans it depends on and has not started running", id))
+	case workflow.Queued:
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is queued and has not started running", id))
+	case workflow.Running, workflow.Paused, workflow.Stopping:
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("bug: plan(%s) has a running state, but isn't in the %s", id, where))
+	}
+	return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) has finished", id))
+}
+
 // getSetStates provides an interface for grabbing the State struct from workflow objects and setting them.
 type getSetStates interface {
 	GetState() workflow.State
@@ -359,7 +774,8 @@ func (p *Plans) validateStartState(plan *workflow.Plan) error {
 		return fmt.Errorf("Plan.SubmitTime is zero")
 	}
 
-	if plan.SubmitTime.Add(p.maxSubmit).Before(time.Now()) {
+	// A scheduled Plan is started long after it was submitted on purpose.
+	if plan.Schedule == nil && plan.SubmitTime.Add(p.maxSubmit).Before(time.Now()) {
 		return fmt.Errorf("plan is stale, submit time is too old")
 	}
 
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -621,6 +622,109 @@ func TestWait(t *testing.T) {
 	}
 }
 
+func TestStop(t *testing.T) {
+	t.Parallel()
+
+	runningID := NewV7()
+	completedID := NewV7()
+	notStartedID := NewV7()
+	bugRunningID := NewV7()
+	bugStoppingID := NewV7()
+	notFoundID := NewV7()
+
+	newPlan := func(id uuid.UUID, status workflow.Status) *workflow.Plan {
+		p := &workflow.Plan{ID: id}
+		p.State.Set(workflow.State{Status: status})
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		id         uuid.UUID
+		claim      bool
+		wantCancel bool
+		wantErr    bool
+	}{
+		{
+			name:       "Success: plan is running, is cancelled and waited on",
+			id:         runningID,
+			claim:      true,
+			wantCancel: true,
+		},
+		{
+			name: "Success: plan is already Completed",
+			id:   completedID,
+		},
+		{
+			name:    "Error: plan is NotStarted",
+			id:      notStartedID,
+			wantErr: true,
+		},
+		{
+			name:    "Error: plan is Running but not in stoppers",
+			id:      bugRunningID,
+			wantErr: true,
+		},
+		{
+			name:    "Error: plan is Stopping but not in stoppers",
+			id:      bugStoppingID,
+			wantErr: true,
+		},
+		{
+			name:    "Error: plan does not exist",
+			id:      notFoundID,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		p := &Plans{
+			store: &fakeStore{
+				m: map[uuid.UUID]*workflow.Plan{
+					runningID:     newPlan(runningID, workflow.Running),
+					completedID:   newPlan(completedID, workflow.Completed),
+					notStartedID:  newPlan(notStartedID, workflow.NotStarted),
+					bugRunningID:  newPlan(bugRunningID, workflow.Running),
+					bugStoppingID: newPlan(bugStoppingID, workflow.Stopping),
+				},
+			},
+			running: newRunning(),
+		}
+
+		cancelled := make(chan struct{})
+		if test.claim {
+			var once sync.Once
+			var release func()
+			release, _ = p.running.claim(
+				test.id,
+				func() {
+					// Simulate the statemachine finishing once it sees the Stop. release calls this again.
+					once.Do(func() {
+						close(cancelled)
+						go release()
+					})
+				},
+			)
+		}
+
+		err := p.Stop(context.Background(), test.id)
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("TestStop(%s): got err == nil, want err != nil", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("TestStop(%s): got err == %v, want err == nil", test.name, err)
+		}
+
+		if test.wantCancel {
+			select {
+			case <-cancelled:
+			default:
+				t.Errorf("TestStop(%s): run was not cancelled", test.name)
+			}
+		}
+	}
+}
+
 func TestValidateStartState(t *testing.T) {
 	t.Parallel()
 
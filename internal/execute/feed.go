@@ -0,0 +1,141 @@
+package execute
+
+import (
+	"sync"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// maxQueued is the most Events that a subscriber to Events can have queued. A subscriber that falls
+// further behind has its queue dropped and is told that it lagged, so it can read the Plan again.
+const maxQueued = 4096
+
+// feed fans out the Events of a Plan running in this process to its subscribers. Each subscriber has
+// its own queue, so a slow subscriber never blocks the statemachine or other subscribers.
+type feed struct {
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+// newFeed returns a feed ready for use.
+func newFeed() *feed {
+	return &feed{subs: map[*subscriber]struct{}{}}
+}
+
+// send queues ev for every subscriber.
+func (f *feed) send(ev workflow.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for s := range f.subs {
+		s.push(ev)
+	}
+}
+
+// subscribe adds a subscriber to the feed that queues at most max Events, see subscriber.push. If max is 0,
+// the queue is not bounded. If the feed has been closed, this returns nil.
+func (f *feed) subscribe(max int) *subscriber {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	s := &subscriber{max: max, notify: make(chan struct{}, 1)}
+	f.subs[s] = struct{}{}
+	return s
+}
+
+// unsubscribe removes s from the feed.
+func (f *feed) unsubscribe(s *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.subs, s)
+}
+
+// close closes the feed and all subscribers. Subscribers still receive anything already queued.
+func (f *feed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for s := range f.subs {
+		s.close()
+	}
+	f.subs = nil
+}
+
+// subscriber holds the Events that a subscriber has not read yet.
+type subscriber struct {
+	mu     sync.Mutex
+	queue  []workflow.Event
+	closed bool
+	// max is the most Events that can be queued. If this is 0, the queue is not bounded.
+	max int
+	// lagged is set when queued Events were dropped because the queue was full.
+	lagged bool
+	// notify has a value when there is something to read.
+	notify chan struct{}
+}
+
+// push queues ev. If the queue is full, the Events in it are dropped and the subscriber is marked as lagged,
+// so memory is bounded for a subscriber that stops reading.
+func (s *subscriber) push(ev workflow.Event) {
+	s.mu.Lock()
+	if s.max > 0 && len(s.queue) >= s.max {
+		s.queue = nil
+		s.lagged = true
+	}
+	s.queue = append(s.queue, ev)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *subscriber) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the queued Events, blocking until there is at least one. ok is false once the subscriber
+// has been closed and everything has been read, or if the Context is done.
+func (s *subscriber) next(ctx context.Context) (evs []workflow.Event, ok bool) {
+	evs, _, ok = s.read(ctx)
+	return evs, ok
+}
+
+// read is next, but also reports if Events were dropped since the last read because the queue was full.
+// The Events that are returned with lagged set are the ones that were queued after the drop.
+func (s *subscriber) read(ctx context.Context) (evs []workflow.Event, lagged, ok bool) {
+	for {
+		s.mu.Lock()
+		evs, s.queue = s.queue, nil
+		lagged, s.lagged = s.lagged, false
+		closed := s.closed
+		s.mu.Unlock()
+
+		if len(evs) > 0 {
+			return evs, lagged, true
+		}
+		if closed {
+			return nil, lagged, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, false
+		case <-s.notify:
+		}
+	}
+}
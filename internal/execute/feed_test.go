@@ -0,0 +1,89 @@
+package execute
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+func TestFeed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	f := newFeed()
+	sub0 := f.subscribe(0)
+	sub1 := f.subscribe(0)
+	f.unsubscribe(sub1)
+
+	want := []workflow.Event{
+		{Type: workflow.OTPlan, OldStatus: workflow.NotStarted, NewStatus: workflow.Running},
+		{Type: workflow.OTPlan, OldStatus: workflow.Running, NewStatus: workflow.Completed},
+	}
+	for _, ev := range want {
+		f.send(ev)
+	}
+	f.close()
+
+	if sub := f.subscribe(0); sub != nil {
+		t.Errorf("TestFeed: subscribe after close: got subscriber, want nil")
+	}
+
+	// A closed subscriber still returns what was queued before close.
+	var got []workflow.Event
+	for {
+		evs, ok := sub0.next(ctx)
+		got = append(got, evs...)
+		if !ok {
+			break
+		}
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("TestFeed: -want/+got:\n%s", diff)
+	}
+
+	if evs, _ := sub1.next(cancelledCtx()); len(evs) != 0 {
+		t.Errorf("TestFeed: unsubscribed subscriber got %d events, want 0", len(evs))
+	}
+}
+
+func TestFeedMaxQueued(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	f := newFeed()
+	sub := f.subscribe(2)
+	evs := []workflow.Event{
+		{Type: workflow.OTPlan, OldStatus: workflow.NotStarted, NewStatus: workflow.Running},
+		{Type: workflow.OTPlan, OldStatus: workflow.Running, NewStatus: workflow.Paused},
+		{Type: workflow.OTPlan, OldStatus: workflow.Paused, NewStatus: workflow.Running},
+	}
+	for _, ev := range evs {
+		f.send(ev)
+	}
+
+	// The third Event did not fit, so the first two are dropped.
+	got, lagged, ok := sub.read(ctx)
+	if !ok || !lagged {
+		t.Errorf("TestFeedMaxQueued: got (lagged %v, ok %v), want (lagged true, ok true)", lagged, ok)
+	}
+	if diff := pretty.Compare(evs[2:], got); diff != "" {
+		t.Errorf("TestFeedMaxQueued: -want/+got:\n%s", diff)
+	}
+
+	// Lagged is only reported once.
+	f.send(evs[0])
+	if _, lagged, _ := sub.read(ctx); lagged {
+		t.Errorf("TestFeedMaxQueued: second read got lagged == true, want false")
+	}
+}
+
+func cancelledCtx() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
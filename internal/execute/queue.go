@@ -0,0 +1,112 @@
+package execute
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// queued is a Plan that was started while the maximum number of Plans were running. It holds the claim
+// for the Plan's run until the Plan is launched or stopped.
+type queued struct {
+	plan    *workflow.Plan
+	runCtx  context.Context
+	stop    <-chan struct{}
+	release func()
+	// admitted is closed when the Plan leaves the queue because it has a slot to run in.
+	admitted chan struct{}
+}
+
+// queue limits the number of Plans that run at the same time. Plans that are started once the limit is
+// reached wait in the queue, ordered by their Priority and then by the time they were queued. When a running
+// Plan finishes, its slot is handed to the first waiting Plan. The zero value is not usable; construct it
+// with newQueue.
+type queue struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	waiting []*queued
+}
+
+// newQueue returns a queue that allows max Plans to run at the same time.
+func newQueue(max int) *queue {
+	return &queue{max: max}
+}
+
+// enter takes a slot for item and returns true if fewer than the maximum Plans are running. Otherwise item
+// is added to the waiting Plans and admitted is closed once it has a slot.
+func (q *queue) enter(item *queued) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running < q.max {
+		q.running++
+		return true
+	}
+	i, _ := slices.BinarySearchFunc(q.waiting, item, func(a, b *queued) int {
+		if cmp := queueOrder(a.plan, b.plan); cmp != 0 {
+			return cmp
+		}
+		// Plans that sort the same stay in the order they entered.
+		return -1
+	})
+	q.waiting = slices.Insert(q.waiting, i, item)
+	return false
+}
+
+// take takes a slot even if the maximum Plans are running. This is used for recovered Plans that were already
+// running, which must not wait behind the Plans in the queue.
+func (q *queue) take() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running++
+}
+
+// done gives back the slot of a Plan that finished. If a Plan is waiting, the slot is handed to it.
+func (q *queue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) == 0 {
+		q.running--
+		return
+	}
+	// The running count stays the same, as the slot moves to the next Plan.
+	next := q.waiting[0]
+	q.waiting = q.waiting[1:]
+	close(next.admitted)
+}
+
+// remove removes item from the waiting Plans. It returns false if item was already admitted, in which case
+// it holds a slot that must be given back with done.
+func (q *queue) remove(item *queued) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := slices.Index(q.waiting, item)
+	if i < 0 {
+		return false
+	}
+	q.waiting = slices.Delete(q.waiting, i, i+1)
+	return true
+}
+
+// releaser returns a release for a run that holds a slot. It calls release and then gives back the slot.
+func (q *queue) releaser(release func()) func() {
+	return func() {
+		release()
+		q.done()
+	}
+}
+
+// queueOrder compares two Plans for their order in the queue. Plans with a higher Priority are first, then
+// Plans that were queued earlier. While a Plan is Queued, its State.Start is the time it was queued.
+func queueOrder(a, b *workflow.Plan) int {
+	if a.Priority != b.Priority {
+		return b.Priority - a.Priority
+	}
+	return a.State.Get().Start.Compare(b.State.Get().Start)
+}
@@ -0,0 +1,75 @@
+package execute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+)
+
+func newQueued(name string, priority int, queuedAt time.Time) *queued {
+	p := &workflow.Plan{Name: name, Priority: priority}
+	p.State.Set(workflow.State{Status: workflow.Queued, Start: queuedAt})
+	return &queued{plan: p, admitted: make(chan struct{})}
+}
+
+func isAdmitted(item *queued) bool {
+	select {
+	case <-item.admitted:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	q := newQueue(1)
+
+	if !q.enter(newQueued("running", 0, now)) {
+		t.Fatalf("TestQueue: first Plan did not get a slot")
+	}
+	low := newQueued("low", 0, now)
+	lowLater := newQueued("lowLater", 0, now.Add(time.Second))
+	high := newQueued("high", 5, now.Add(2*time.Second))
+	stopped := newQueued("stopped", 0, now.Add(3*time.Second))
+	for _, item := range []*queued{lowLater, stopped, low, high} {
+		if q.enter(item) {
+			t.Fatalf("TestQueue: Plan(%s) got a slot, want it queued", item.plan.Name)
+		}
+	}
+	if !q.remove(stopped) {
+		t.Errorf("TestQueue: remove(stopped) got false, want true")
+	}
+
+	// Each finished Plan hands its slot to the next Plan in the queue.
+	for _, want := range []*queued{high, low, lowLater} {
+		q.done()
+		if !isAdmitted(want) {
+			t.Fatalf("TestQueue: Plan(%s) was not admitted next", want.plan.Name)
+		}
+		if q.remove(want) {
+			t.Errorf("TestQueue: remove(%s) of an admitted Plan got true, want false", want.plan.Name)
+		}
+	}
+	if isAdmitted(stopped) {
+		t.Errorf("TestQueue: removed Plan was admitted")
+	}
+	if q.running != 1 {
+		t.Errorf("TestQueue: got %d running, want 1", q.running)
+	}
+
+	q.done()
+	if q.running != 0 {
+		t.Errorf("TestQueue: got %d running after all Plans finished, want 0", q.running)
+	}
+
+	// A recovered running Plan takes a slot even when the queue is full.
+	q.enter(newQueued("running", 0, now))
+	q.take()
+	if q.running != 2 {
+		t.Errorf("TestQueue: got %d running after take(), want 2", q.running)
+	}
+}
@@ -21,11 +21,11 @@ type recover struct {
 	store  storage.Vault
 }
 
-// start starts the recovery process. It searches for running plans in the data store.
+// start starts the recovery process. It searches for running, queued and waiting plans in the data store.
 // The recovery process DOES NOT use concurrency due to the fact that the sqlite store is flawed
 // and cannot handle concurrent reads and writes.
 func (r *recover) start(req statemachine.Request[recoverData]) statemachine.Request[recoverData] {
-	results, err := r.store.Search(req.Ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Running}})
+	results, err := r.store.Search(req.Ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Waiting, workflow.Queued, workflow.Running, workflow.Paused, workflow.Stopping}})
 	if err != nil {
 		req.Err = err
 		return req
@@ -69,7 +69,7 @@ type recoverData struct {
 
 func (r *recover) Start(req statemachine.Request[recoverData]) statemachine.Request[recoverData] {
 	req.Ctx = context.WithoutCancel(req.Ctx)
-	results, err := r.store.Search(req.Ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Running}})
+	results, err := r.store.Search(req.Ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Waiting, workflow.Queued, workflow.Running, workflow.Paused, workflow.Stopping}})
 	if err != nil {
 		req.Err = fmt.Errorf("failed to search for running plans: %w", err)
 		return req
@@ -115,6 +115,11 @@ func (r *recover) filterPlans(req statemachine.Request[recoverData]) statemachin
 	now := time.Now()
 
 	for i, plan := range req.Data.plans {
+		// A Queued or Waiting Plan has not run, so it can wait for any amount of time.
+		switch plan.State.Get().Status {
+		case workflow.Queued, workflow.Waiting:
+			continue
+		}
 		if walk.LastUpdate(req.Ctx, plan).Add(r.maxAge).Before(now) {
 			req.Data.agedOut = append(req.Data.agedOut, plan)
 			req.Data.plans[i] = nil
@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gostdlib/base/concurrency/sync"
 
+	"github.com/element-of-surprise/coercion/internal/execute/sm"
 	"github.com/element-of-surprise/coercion/workflow/context"
 )
 
@@ -14,9 +15,15 @@ type running struct {
 	// waiters maps a plan ID to a channel closed when that ID's run finishes. Presence of an entry
 	// is the authority for whether the ID is running in this process.
 	waiters sync.ShardedMap[uuid.UUID, chan struct{}]
-	// stoppers maps a plan ID to the CancelFunc that stops its run. Bookkeeping reserved for a future
-	// Stop(); release invokes and removes the entry on completion.
+	// stoppers maps a plan ID to the CancelFunc that closes its stop channel. stop invokes the entry to request
+	// a stop; release invokes and removes the entry on completion.
 	stoppers sync.ShardedMap[uuid.UUID, context.CancelFunc]
+	// pausers maps a plan ID to the Pauser for its run. release removes the entry on completion.
+	pausers sync.ShardedMap[uuid.UUID, *sm.Pauser]
+	// gates maps a plan ID to the Approvals for its run. release removes the entry on completion.
+	gates sync.ShardedMap[uuid.UUID, *sm.Approvals]
+	// feeds maps a plan ID to the feed of Events for its run. release removes the entry on completion.
+	feeds sync.ShardedMap[uuid.UUID, *feed]
 }
 
 // newRunning returns a running ready for use.
@@ -24,12 +31,15 @@ func newRunning() *running {
 	return &running{
 		waiters:  sync.ShardedMap[uuid.UUID, chan struct{}]{IsEqual: func(a, b chan struct{}) bool { return a == b }},
 		stoppers: sync.ShardedMap[uuid.UUID, context.CancelFunc]{},
+		pausers:  sync.ShardedMap[uuid.UUID, *sm.Pauser]{},
+		gates:    sync.ShardedMap[uuid.UUID, *sm.Approvals]{},
+		feeds:    sync.ShardedMap[uuid.UUID, *feed]{},
 	}
 }
 
 // claim attempts to register id as running in this process, taking ownership of cancel. It atomically
 // installs a fresh waiter only if none exists. On success it returns won==true and a release closure
-// that MUST be called exactly once when the run finishes: release cancels the run, removes the
+// that MUST be called exactly once when the run finishes: release calls cancel, removes the
 // stopper, closes the waiter, and deletes only this run's own waiter entry. On failure a run for id
 // is already in flight: claim returns (nil, false), touches no state, and the caller stays
 // responsible for its own cancel.
@@ -42,6 +52,12 @@ func (r *running) claim(id uuid.UUID, cancel context.CancelFunc) (release func()
 	release = func() {
 		cancel()
 		r.stoppers.Del(id)
+		r.pausers.Del(id)
+		r.gates.Del(id)
+		if f, ok := r.feeds.Get(id); ok {
+			f.close()
+			r.feeds.Del(id)
+		}
 		close(waiter)
 		// Delete only our own waiter, never one a later run may have installed.
 		r.waiters.CompareAndDelete(id, waiter)
@@ -55,3 +71,44 @@ func (r *running) wait(id uuid.UUID) (<-chan struct{}, bool) {
 	w, ok := r.waiters.Get(id)
 	return w, ok
 }
+
+// setPauser records the Pauser for id's run. This must only be called by the owner of a winning claim.
+func (r *running) setPauser(id uuid.UUID, p *sm.Pauser) {
+	r.pausers.Set(id, p)
+}
+
+// pauser returns the Pauser for id and ok reporting whether id is running in this process.
+func (r *running) pauser(id uuid.UUID) (*sm.Pauser, bool) {
+	return r.pausers.Get(id)
+}
+
+// setApprovals records the Approvals for id's run. This must only be called by the owner of a winning claim.
+func (r *running) setApprovals(id uuid.UUID, a *sm.Approvals) {
+	r.gates.Set(id, a)
+}
+
+// approvals returns the Approvals for id and ok reporting whether id is running in this process.
+func (r *running) approvals(id uuid.UUID) (*sm.Approvals, bool) {
+	return r.gates.Get(id)
+}
+
+// setFeed records the feed of Events for id's run. This must only be called by the owner of a winning claim.
+func (r *running) setFeed(id uuid.UUID, f *feed) {
+	r.feeds.Set(id, f)
+}
+
+// feed returns the feed of Events for id and ok reporting whether id is running in this process.
+func (r *running) feed(id uuid.UUID) (*feed, bool) {
+	return r.feeds.Get(id)
+}
+
+// stop closes the stop channel for id, which asks the statemachine to stop the Plan. It reports whether
+// a run for id was found in this process. stop does not wait for the run to finish; use wait for that.
+func (r *running) stop(id uuid.UUID) bool {
+	cancel, ok := r.stoppers.Get(id)
+	if !ok {
+		return false
+	}
+	cancel()
+	return true
+}
@@ -164,3 +164,48 @@ func TestRunningWait(t *testing.T) {
 		}
 	}
 }
+
+func TestRunningStop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		claimed bool
+		wantOK  bool
+	}{
+		{
+			name:    "Success: cancels the run when the id is claimed",
+			claimed: true,
+			wantOK:  true,
+		},
+		{
+			name:   "Success: miss when the id was never claimed",
+			wantOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		r := newRunning()
+		id := NewV7()
+
+		cancelled := false
+		if test.claimed {
+			if _, won := r.claim(id, func() { cancelled = true }); !won {
+				t.Errorf("TestRunningStop(%s): setup claim did not win", test.name)
+				continue
+			}
+		}
+
+		ok := r.stop(id)
+		if ok != test.wantOK {
+			t.Errorf("TestRunningStop(%s): got ok == %v, want %v", test.name, ok, test.wantOK)
+		}
+		if cancelled != test.wantOK {
+			t.Errorf("TestRunningStop(%s): got cancelled == %v, want %v", test.name, cancelled, test.wantOK)
+		}
+		// stop must not release the run, only release does that.
+		if _, running := r.wait(id); running != test.claimed {
+			t.Errorf("TestRunningStop(%s): got running == %v after stop, want %v", test.name, running, test.claimed)
+		}
+	}
+}
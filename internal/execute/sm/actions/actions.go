@@ -5,15 +5,19 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/element-of-surprise/coercion/internal/execute/sm/metrics"
+	"github.com/element-of-surprise/coercion/internal/execute/sm/spans"
 	"github.com/element-of-surprise/coercion/plugins"
 	"github.com/element-of-surprise/coercion/plugins/registry"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/context"
 	"github.com/element-of-surprise/coercion/workflow/storage"
 
+	"github.com/google/uuid"
 	"github.com/gostdlib/base/retry/exponential"
 	"github.com/gostdlib/base/statemachine"
 	"github.com/gostdlib/base/telemetry/log"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Data is the data passed to the state machine.
@@ -24,9 +28,14 @@ type Data struct {
 	Updater storage.ActionUpdater
 	// Registry is the registry to get the plugin from.
 	Registry *registry.Register
+	// Keys are the Actions in the Plan that have a Key, by Key. This is used to resolve
+	// the Req of the Action if it is a workflow.Resolver.
+	Keys map[uuid.UUID]*workflow.Action
 
 	// plugin is the plugin to run. This is set by the GetPlugin state.
 	plugin plugins.Plugin
+	// pReq is the request to pass to the plugin. This is set by the Resolve state.
+	pReq any
 	// err is the error that occurred during the state machine. As all states must
 	// call the End state, this is the error that will be returned.
 	err error
@@ -37,6 +46,13 @@ type nower func() time.Time
 // Runner is a state machine that runs a workflow.Action.
 type Runner struct {
 	nower nower
+	// metrics records metrics for each Attempt. This can be nil.
+	metrics *metrics.Metrics
+}
+
+// New creates a new Runner that records metrics for each Attempt to m. m can be nil.
+func New(m *metrics.Metrics) Runner {
+	return Runner{metrics: m}
 }
 
 // Start stats the statemachine and marks the action as running.
@@ -97,10 +113,66 @@ func (r Runner) GetPlugin(req statemachine.Request[Data]) statemachine.Request[D
 	}
 
 	req.Data.plugin = p
+	req.Next = r.Resolve
+	return req
+}
+
+// Resolve sets the request to pass to the plugin. If the Action's Req is a workflow.Resolver, this is
+// what it resolves to from the responses of earlier Actions. If it cannot be resolved, the Action
+// is failed with an Attempt that has a permanent error.
+func (r Runner) Resolve(req statemachine.Request[Data]) statemachine.Request[Data] {
+	action := req.Data.Action
+
+	resolver, ok := action.Req.(workflow.Resolver)
+	if !ok {
+		req.Data.pReq = action.Req
+		req.Next = r.Execute
+		return req
+	}
+
+	pReq, err := resolve(resolver, req.Data.Keys)
+	if err != nil {
+		now := r.now()
+		attempt := workflow.Attempt{
+			Err: &plugins.Error{
+				Message:   fmt.Sprintf("could not resolve the Action's Req: %s", err),
+				Permanent: true,
+			},
+			Start: now,
+			End:   now,
+		}
+		action.Attempts.Append(attempt)
+		if err := req.Data.Updater.UpdateAction(req.Ctx, action); err != nil {
+			log.Fatalf("failed to write Action: %v", err)
+		}
+		req.Data.err = errPermanent(attempt.Err)
+		req.Next = r.End
+		return req
+	}
+
+	req.Data.pReq = pReq
 	req.Next = r.Execute
 	return req
 }
 
+// resolve calls resolver.Resolve() with the responses of the Actions in keys that it uses.
+// Each of these Actions must have Completed.
+func resolve(resolver workflow.Resolver, keys map[uuid.UUID]*workflow.Action) (any, error) {
+	inputs := resolver.Inputs()
+	resps := make(map[uuid.UUID]any, len(inputs))
+	for _, in := range inputs {
+		a, ok := keys[in.Key]
+		if !ok {
+			return nil, fmt.Errorf("no Action has Key(%s)", in.Key)
+		}
+		if a.State.Get().Status != workflow.Completed {
+			return nil, fmt.Errorf("Action(%s) with Key(%s) has not Completed", a.Name, in.Key)
+		}
+		resps[in.Key] = a.FinalAttempt().Resp
+	}
+	return resolver.Resolve(resps)
+}
+
 // Execute runs the action using the plugin and writes the result to the store. This
 // function will retry the action based on the plugin's retry policy.
 func (r Runner) Execute(req statemachine.Request[Data]) statemachine.Request[Data] {
@@ -115,7 +187,7 @@ func (r Runner) Execute(req statemachine.Request[Data]) statemachine.Request[Dat
 	req.Data.err = backoff.Retry(
 		req.Ctx,
 		func(ctx context.Context, record exponential.Record) error {
-			return r.exec(ctx, action, plugin, writer)
+			return r.exec(ctx, action, req.Data.pReq, plugin, writer)
 		},
 	)
 	req.Next = r.End
@@ -153,10 +225,12 @@ func unexpectedTypeMsg(plugin plugins.Plugin, got, want any) string {
 	return fmt.Sprintf("plugin(%s) returned a type %T but expected %T", plugin.Name(), got, want)
 }
 
-// exec runs the action once using the plugin and writes the result to the store, unless the action
-// has exceeded the maximum number of retries. In that case, it returns a permanent error.
-func (r Runner) exec(ctx context.Context, action *workflow.Action, plugin plugins.Plugin, updater storage.ActionUpdater) error {
-	if len(action.Attempts.Get()) > action.Retries {
+// exec runs the action once by passing pReq to the plugin and writes the result to the store, unless
+// the action has exceeded the maximum number of retries. In that case, it returns a permanent error.
+// Each attempt has its own span, which is in the Context passed to the plugin.
+func (r Runner) exec(ctx context.Context, action *workflow.Action, pReq any, plugin plugins.Plugin, updater storage.ActionUpdater) error {
+	retry := len(action.Attempts.Get())
+	if retry > action.Retries {
 		return exponential.ErrPermanent
 	}
 
@@ -173,12 +247,26 @@ func (r Runner) exec(ctx context.Context, action *workflow.Action, plugin plugin
 		action.Attempts.Append(attempt)
 	}()
 
+	var timeout bool
+	ctx, span := spans.Start(ctx, "attempt", action.ID, action.Name, spans.Plugin.String(plugin.Name()), spans.Retry.Int(retry))
+	defer func() {
+		r.metrics.Attempt(ctx, plugin.Name(), retry, attempt.End.Sub(attempt.Start), attempt.Err, timeout)
+		if attempt.Err != nil {
+			span.SetAttributes(spans.ErrCode.Int64(int64(attempt.Err.Code)))
+			span.SetStatus(codes.Error, attempt.Err.Error())
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		span.End()
+	}()
+
 	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), action.Timeout)
-	plugResp := run(runCtx, plugin, action.Req)
+	plugResp := run(runCtx, plugin, pReq)
 	cancel()
 	attempt.End = r.now()
 
 	if plugResp.timeout {
+		timeout = true
 		attempt.Err = &plugins.Error{
 			Message:   pluginTimeoutMsg,
 			Permanent: false,
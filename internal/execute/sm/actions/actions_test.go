@@ -16,6 +16,7 @@ import (
 	"github.com/element-of-surprise/coercion/workflow/context"
 	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
 
+	"github.com/google/uuid"
 	"github.com/gostdlib/base/retry/exponential"
 	"github.com/gostdlib/base/statemachine"
 	"github.com/kylelemons/godebug/pretty"
@@ -140,7 +141,7 @@ func TestGetPlugin(t *testing.T) {
 				},
 				plugin: reg.Plugin(testplugin.Name),
 			},
-			wantNext: methodName(sm.Execute),
+			wantNext: methodName(sm.Resolve),
 		},
 	}
 	for _, test := range tests {
@@ -184,6 +185,7 @@ func TestExecute(t *testing.T) {
 				plugin: &testplugin.Plugin{
 					Responses: []any{pluginErr, pluginErr},
 				},
+				pReq: testplugin.Req{},
 			},
 			wantData: Data{
 				Action: func() *workflow.Action {
@@ -192,7 +194,8 @@ func TestExecute(t *testing.T) {
 					a.State.Set(workflow.State{})
 					return a
 				}(),
-				err: exponential.ErrPermanent,
+				pReq: testplugin.Req{},
+				err:  exponential.ErrPermanent,
 			},
 		},
 		{
@@ -206,6 +209,7 @@ func TestExecute(t *testing.T) {
 				plugin: &testplugin.Plugin{
 					Responses: []any{pluginErr, testplugin.Resp{Arg: "ok"}},
 				},
+				pReq: testplugin.Req{},
 			},
 			wantData: Data{
 				Action: func() *workflow.Action {
@@ -214,6 +218,7 @@ func TestExecute(t *testing.T) {
 					a.State.Set(workflow.State{})
 					return a
 				}(),
+				pReq: testplugin.Req{},
 			},
 		},
 	}
@@ -239,6 +244,102 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	nower := func() time.Time {
+		return now
+	}
+
+	completed := workflow.NewV7()
+	running := workflow.NewV7()
+	keyed := func(status workflow.Status, resp any) *workflow.Action {
+		a := &workflow.Action{}
+		a.State.Set(workflow.State{Status: status})
+		a.Attempts.Set([]workflow.Attempt{{Resp: resp}})
+		return a
+	}
+	keys := map[uuid.UUID]*workflow.Action{
+		completed: keyed(workflow.Completed, testplugin.Resp{Arg: "from"}),
+		running:   keyed(workflow.Running, nil),
+	}
+
+	tests := []struct {
+		name         string
+		req          any
+		wantPReq     any
+		wantAttempts int
+		wantNext     string
+	}{
+		{
+			name:     "Success: Req is not a Resolver",
+			req:      "req",
+			wantPReq: "req",
+			wantNext: methodName(Runner{}.Execute),
+		},
+		{
+			name:     "Success: Req has no Inputs",
+			req:      testplugin.Req{Arg: "arg"},
+			wantPReq: testplugin.Req{Arg: "arg"},
+			wantNext: methodName(Runner{}.Execute),
+		},
+		{
+			name:     "Success: Req resolves from a Completed Action",
+			req:      testplugin.Req{Arg: "arg", From: completed},
+			wantPReq: testplugin.Req{Arg: "from", From: completed},
+			wantNext: methodName(Runner{}.Execute),
+		},
+		{
+			name:         "Error: Input Action has not Completed",
+			req:          testplugin.Req{From: running},
+			wantAttempts: 1,
+			wantNext:     methodName(Runner{}.End),
+		},
+		{
+			name:         "Error: no Action has the Key",
+			req:          testplugin.Req{From: workflow.NewV7()},
+			wantAttempts: 1,
+			wantNext:     methodName(Runner{}.End),
+		},
+	}
+
+	sm := Runner{nower: nower}
+	for _, test := range tests {
+		action := &workflow.Action{Req: test.req}
+		updater := newFakeUpdater()
+		req := statemachine.Request[Data]{
+			Ctx:  context.Background(),
+			Data: Data{Action: action, Updater: updater, Keys: keys},
+		}
+		req = sm.Resolve(req)
+
+		if methodName(req.Next) != test.wantNext {
+			t.Errorf("TestResolve(%s): got Request.Next %s, want %s", test.name, methodName(req.Next), test.wantNext)
+		}
+		if diff := pretty.Compare(test.wantPReq, req.Data.pReq); diff != "" {
+			t.Errorf("TestResolve(%s): pReq -want/+got:\n%s", test.name, diff)
+		}
+		attempts := action.Attempts.Get()
+		if len(attempts) != test.wantAttempts {
+			t.Errorf("TestResolve(%s): got %d Attempts, want %d", test.name, len(attempts), test.wantAttempts)
+			continue
+		}
+		if test.wantAttempts == 0 {
+			continue
+		}
+		if attempts[0].Err == nil || !attempts[0].Err.Permanent {
+			t.Errorf("TestResolve(%s): got Attempt.Err %v, want a permanent error", test.name, attempts[0].Err)
+		}
+		if req.Data.err == nil {
+			t.Errorf("TestResolve(%s): got Data.err == nil, want err != nil", test.name)
+		}
+		if len(updater.updates) != 1 {
+			t.Errorf("TestResolve(%s): got %d writes, want 1", test.name, len(updater.updates))
+		}
+	}
+}
+
 func TestEnd(t *testing.T) {
 	t.Parallel()
 
@@ -408,7 +509,7 @@ func TestExec(t *testing.T) {
 		}
 		defer rw.Close(context.Background())
 
-		err = sm.exec(test.ctx, test.action, test.plugin, rw)
+		err = sm.exec(test.ctx, test.action, test.action.Req, test.plugin, rw)
 
 		switch {
 		case err == nil && test.wantErr:
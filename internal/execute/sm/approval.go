@@ -0,0 +1,192 @@
+package sm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// approvalsKey is a key for the *Approvals in context.Value.
+type approvalsKey struct{}
+
+// gate is an Approval in a Plan.
+type gate struct {
+	approval *workflow.Approval
+	// update writes the Block or Sequence that has the Approval to storage.
+	update func(context.Context) error
+	// decided is closed once the Approval has been decided.
+	decided chan struct{}
+}
+
+// Approvals decides the Approvals of a running Plan. A decision is written to storage with the Block or
+// Sequence that has the Approval, so it is kept if the Plan is recovered. Approvals is safe for concurrent use.
+type Approvals struct {
+	mu    sync.Mutex
+	gates map[string]*gate
+	nower nower
+}
+
+// NewApprovals creates an Approvals for plan that writes decisions to store. Approvals in plan that have
+// already been decided, such as in a recovered Plan, are not waited on.
+func NewApprovals(plan *workflow.Plan, store storage.Updater) *Approvals {
+	a := &Approvals{gates: map[string]*gate{}, nower: func() time.Time { return time.Now().UTC() }}
+
+	add := func(ap *workflow.Approval, update func(context.Context) error) {
+		if ap == nil {
+			return
+		}
+		g := &gate{approval: ap, update: update, decided: make(chan struct{})}
+		if ap.Decision.Get().Status.Decided() {
+			close(g.decided)
+		}
+		a.gates[ap.Key] = g
+	}
+	for _, b := range plan.Blocks {
+		add(b.Approval, func(ctx context.Context) error { return store.UpdateBlock(ctx, b) })
+		for _, seq := range b.Sequences {
+			add(seq.Approval, func(ctx context.Context) error { return store.UpdateSequence(ctx, seq) })
+		}
+	}
+	return a
+}
+
+// Approve approves the Approval with key on behalf of approver. It is an error to decide an Approval
+// that does not exist or has already been decided.
+func (a *Approvals) Approve(ctx context.Context, key, approver string) error {
+	return a.decide(ctx, key, workflow.APApproved, approver)
+}
+
+// Reject rejects the Approval with key on behalf of approver, which fails its Block or Sequence. It is an
+// error to decide an Approval that does not exist or has already been decided.
+func (a *Approvals) Reject(ctx context.Context, key, approver string) error {
+	return a.decide(ctx, key, workflow.APRejected, approver)
+}
+
+// decide records the decision on the Approval with key and writes it to storage. If the write fails, the
+// decision is not recorded.
+func (a *Approvals) decide(ctx context.Context, key string, status workflow.ApprovalStatus, approver string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, ok := a.gates[key]
+	if !ok {
+		return fmt.Errorf("plan has no approval(%s): %w", key, ErrNotApprovable)
+	}
+	orig := g.approval.Decision.Get()
+	if orig.Status.Decided() {
+		return fmt.Errorf("approval(%s) is already %s: %w", key, orig.Status, ErrNotApprovable)
+	}
+
+	d := orig
+	d.Status = status
+	d.Approver = approver
+	d.Decided = a.nower()
+	g.approval.Decision.Set(d)
+	if err := g.update(ctx); err != nil {
+		g.approval.Decision.Set(orig)
+		return fmt.Errorf("could not write approval(%s): %w", key, err)
+	}
+	close(g.decided)
+	return nil
+}
+
+// request records that the gate of g was reached and writes it to storage. If g was already reached or
+// decided, this does nothing.
+func (a *Approvals) request(ctx context.Context, g *gate) (workflow.Decision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	d := g.approval.Decision.Get()
+	if d.Status != workflow.APNotRequested {
+		return d, nil
+	}
+	d.Status = workflow.APWaiting
+	d.Requested = a.nower()
+	g.approval.Decision.Set(d)
+	return d, g.update(ctx)
+}
+
+// expire records that the Expiry of g passed and writes it to storage. If g was decided first, this does nothing.
+func (a *Approvals) expire(ctx context.Context, g *gate) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	d := g.approval.Decision.Get()
+	if d.Status.Decided() {
+		return nil
+	}
+	d.Status = workflow.APExpired
+	d.Decided = a.nower()
+	g.approval.Decision.Set(d)
+	close(g.decided)
+	return g.update(ctx)
+}
+
+// withApprovals attaches a to the Context so that the gates of the Plan can wait on it.
+func withApprovals(ctx context.Context, a *Approvals) context.Context {
+	if a == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, approvalsKey{}, a)
+}
+
+// approvalsFrom returns the *Approvals attached to the Context or nil if there is none.
+func approvalsFrom(ctx context.Context) *Approvals {
+	a, _ := ctx.Value(approvalsKey{}).(*Approvals)
+	return a
+}
+
+// waitApproval waits at the gate of ap until it is decided and returns nil if it was approved. If it was rejected
+// or its Expiry passed, an error is returned. This returns ErrStopped or ErrTimeout if the Plan is stopped or the
+// Plan or Block times out while waiting, which leaves ap Waiting. If ap is nil, this returns nil.
+func (s *States) waitApproval(ctx context.Context, ap *workflow.Approval) error {
+	if ap == nil {
+		return nil
+	}
+	a := approvalsFrom(ctx)
+	if a == nil || a.gates[ap.Key] == nil {
+		return fmt.Errorf("bug: approval(%s) is not in the Plan's Approvals", ap.Key)
+	}
+	g := a.gates[ap.Key]
+
+	d, err := a.request(ctx, g)
+	if err != nil {
+		log.Fatalf("failed to write Approval: %v", err)
+	}
+
+	var expiry <-chan time.Time
+	if ap.Expiry > 0 && !d.Status.Decided() {
+		t := time.NewTimer(max(d.Requested.Add(ap.Expiry).Sub(a.nower()), 0))
+		defer t.Stop()
+		expiry = t.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-stopping(ctx):
+		return ErrStopped
+	case <-expired(ctx):
+		return ErrTimeout
+	case <-g.decided:
+	case <-expiry:
+		if err := a.expire(ctx, g); err != nil {
+			log.Fatalf("failed to write Approval: %v", err)
+		}
+	}
+
+	switch d := ap.Decision.Get(); d.Status {
+	case workflow.APApproved:
+		return nil
+	case workflow.APRejected:
+		return fmt.Errorf("approval(%s) was rejected by %s", ap.Key, d.Approver)
+	default:
+		return fmt.Errorf("approval(%s) was not decided within its Expiry(%v)", ap.Key, ap.Expiry)
+	}
+}
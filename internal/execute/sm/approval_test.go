@@ -0,0 +1,224 @@
+package sm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+
+	"github.com/gostdlib/base/statemachine"
+)
+
+// approvalPlan returns a Plan with a Block that has an Approval with key "block". The Block has one Sequence
+// with one Action, and the Sequence has an Approval with key "seq".
+func approvalPlan(expiry time.Duration) *workflow.Plan {
+	action := &workflow.Action{Name: "action"}
+	action.State.Set(workflow.State{})
+	seq := &workflow.Sequence{Name: "seq", Approval: &workflow.Approval{Key: "seq", Expiry: expiry}, Actions: []*workflow.Action{action}}
+	seq.State.Set(workflow.State{})
+	b := &workflow.Block{Name: "block", Approval: &workflow.Approval{Key: "block", Expiry: expiry}, Sequences: []*workflow.Sequence{seq}}
+	b.State.Set(workflow.State{})
+	return &workflow.Plan{Blocks: []*workflow.Block{b}}
+}
+
+func TestApprovalsDecide(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	plan := approvalPlan(0)
+	updater := &fakeUpdater{}
+	a := NewApprovals(plan, updater)
+
+	if err := a.Approve(ctx, "block", "operator"); err != nil {
+		t.Fatalf("TestApprovalsDecide: Approve(block): got err == %s, want err == nil", err)
+	}
+	if err := a.Reject(ctx, "seq", "reviewer"); err != nil {
+		t.Fatalf("TestApprovalsDecide: Reject(seq): got err == %s, want err == nil", err)
+	}
+	if err := a.Reject(ctx, "block", "operator"); !errors.Is(err, ErrNotApprovable) {
+		t.Errorf("TestApprovalsDecide: Reject(block) after Approve: got err == %v, want ErrNotApprovable", err)
+	}
+	if err := a.Approve(ctx, "missing", "operator"); !errors.Is(err, ErrNotApprovable) {
+		t.Errorf("TestApprovalsDecide: Approve(missing): got err == %v, want ErrNotApprovable", err)
+	}
+
+	if d := plan.Blocks[0].Approval.Decision.Get(); d.Status != workflow.APApproved || d.Approver != "operator" || d.Decided.IsZero() {
+		t.Errorf("TestApprovalsDecide: Block Decision = %+v, want Approved by operator", d)
+	}
+	if d := plan.Blocks[0].Sequences[0].Approval.Decision.Get(); d.Status != workflow.APRejected || d.Approver != "reviewer" {
+		t.Errorf("TestApprovalsDecide: Sequence Decision = %+v, want Rejected by reviewer", d)
+	}
+	if len(updater.blocks) != 1 || updater.blocks[0].Approval.Decision.Get().Status != workflow.APApproved {
+		t.Errorf("TestApprovalsDecide: want the approved Block written to storage once, got %d writes", len(updater.blocks))
+	}
+	if len(updater.seqs) != 1 || updater.seqs[0].Approval.Decision.Get().Status != workflow.APRejected {
+		t.Errorf("TestApprovalsDecide: want the rejected Sequence written to storage once, got %d writes", len(updater.seqs))
+	}
+}
+
+func TestWaitApproval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		expiry     time.Duration
+		preDecide  func(ctx context.Context, a *Approvals)
+		decide     func(ctx context.Context, a *Approvals)
+		stop       bool
+		wantStatus workflow.ApprovalStatus
+		wantErr    error
+		err        bool
+	}{
+		{
+			name:       "Success: approved before the gate",
+			preDecide:  func(ctx context.Context, a *Approvals) { a.Approve(ctx, "block", "operator") },
+			wantStatus: workflow.APApproved,
+		},
+		{
+			name:       "Success: approved at the gate",
+			decide:     func(ctx context.Context, a *Approvals) { a.Approve(ctx, "block", "operator") },
+			wantStatus: workflow.APApproved,
+		},
+		{
+			name:       "Error: rejected at the gate",
+			decide:     func(ctx context.Context, a *Approvals) { a.Reject(ctx, "block", "operator") },
+			wantStatus: workflow.APRejected,
+			err:        true,
+		},
+		{
+			name:       "Error: Expiry passed",
+			expiry:     10 * time.Millisecond,
+			wantStatus: workflow.APExpired,
+			err:        true,
+		},
+		{
+			name:       "Error: stopped while waiting",
+			stop:       true,
+			wantStatus: workflow.APWaiting,
+			wantErr:    ErrStopped,
+			err:        true,
+		},
+	}
+
+	for _, test := range tests {
+		plan := approvalPlan(test.expiry)
+		ap := plan.Blocks[0].Approval
+		a := NewApprovals(plan, &fakeUpdater{})
+		ctx := withApprovals(context.Background(), a)
+		if test.stop {
+			ctx = setStopping(ctx)
+		}
+		if test.preDecide != nil {
+			test.preDecide(ctx, a)
+		}
+		if test.decide != nil {
+			go func() {
+				for ap.Decision.Get().Status != workflow.APWaiting {
+					time.Sleep(time.Millisecond)
+				}
+				test.decide(ctx, a)
+			}()
+		}
+
+		err := (&States{}).waitApproval(ctx, ap)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestWaitApproval(%s): got err == nil, want err != nil", test.name)
+			continue
+		case !test.err && err != nil:
+			t.Errorf("TestWaitApproval(%s): got err == %s, want err == nil", test.name, err)
+			continue
+		case test.wantErr != nil && !errors.Is(err, test.wantErr):
+			t.Errorf("TestWaitApproval(%s): got err == %s, want %s", test.name, err, test.wantErr)
+		}
+		if got := ap.Decision.Get().Status; got != test.wantStatus {
+			t.Errorf("TestWaitApproval(%s): got Status %v, want %v", test.name, got, test.wantStatus)
+		}
+	}
+
+	if err := (&States{}).waitApproval(context.Background(), nil); err != nil {
+		t.Errorf("TestWaitApproval(nil Approval): got err == %s, want err == nil", err)
+	}
+}
+
+func TestBlockApproval(t *testing.T) {
+	t.Parallel()
+
+	states := &States{} // Used to get the method name of a state for wantNextState
+
+	tests := []struct {
+		name            string
+		decide          func(ctx context.Context, a *Approvals)
+		wantBlockStatus workflow.Status
+		wantNextState   statemachine.State[Data]
+		wantErr         bool
+	}{
+		{
+			name:            "Approved",
+			decide:          func(ctx context.Context, a *Approvals) { a.Approve(ctx, "block", "operator") },
+			wantBlockStatus: workflow.Running,
+			wantNextState:   states.BlockPreChecks,
+		},
+		{
+			name:            "Rejected",
+			decide:          func(ctx context.Context, a *Approvals) { a.Reject(ctx, "block", "operator") },
+			wantBlockStatus: workflow.Failed,
+			wantNextState:   states.BlockDeferredChecks,
+			wantErr:         true,
+		},
+	}
+
+	for _, test := range tests {
+		plan := approvalPlan(0)
+		b := plan.Blocks[0]
+		b.State.Set(workflow.State{Status: workflow.Running})
+		updater := &fakeUpdater{}
+		a := NewApprovals(plan, updater)
+		ctx := withApprovals(context.Background(), a)
+		test.decide(ctx, a)
+
+		req := statemachine.Request[Data]{
+			Ctx:  ctx,
+			Data: Data{blocks: []block{{block: b, contCheckResult: newContResult()}}},
+		}
+		states := &States{store: updater}
+		req = states.BlockApproval(req)
+
+		if methodName(req.Next) != methodName(test.wantNextState) {
+			t.Errorf("TestBlockApproval(%s): got next state = %v, want %v", test.name, methodName(req.Next), methodName(test.wantNextState))
+		}
+		if got := b.State.Get().Status; got != test.wantBlockStatus {
+			t.Errorf("TestBlockApproval(%s): got Block status %v, want %v", test.name, got, test.wantBlockStatus)
+		}
+		if (req.Data.err != nil) != test.wantErr {
+			t.Errorf("TestBlockApproval(%s): got err == %v, wantErr %v", test.name, req.Data.err, test.wantErr)
+		}
+	}
+}
+
+func TestExecSeqApprovalRejected(t *testing.T) {
+	t.Parallel()
+
+	plan := approvalPlan(0)
+	seq := plan.Blocks[0].Sequences[0]
+
+	updater := &fakeUpdater{}
+	a := NewApprovals(plan, updater)
+	ctx := withApprovals(context.Background(), a)
+	if err := a.Reject(ctx, "seq", "operator"); err != nil {
+		t.Fatalf("TestExecSeqApprovalRejected: Reject: %s", err)
+	}
+
+	states := &States{store: updater, testActionRunner: fakeActionRunner}
+	if err := states.execSeq(ctx, seq); err == nil {
+		t.Errorf("TestExecSeqApprovalRejected: got err == nil, want err != nil")
+	}
+	if got := seq.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestExecSeqApprovalRejected: got Sequence status %v, want %v", got, workflow.Failed)
+	}
+	if got := seq.Actions[0].State.Get().Status; got != workflow.NotStarted {
+		t.Errorf("TestExecSeqApprovalRejected: got Action status %v, want %v", got, workflow.NotStarted)
+	}
+}
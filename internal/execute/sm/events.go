@@ -0,0 +1,193 @@
+package sm
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+	"github.com/element-of-surprise/coercion/workflow/utils/walk"
+)
+
+// eventsKey is a key for the *Events in context.Value.
+type eventsKey struct{}
+
+// eventObject is an object in a Plan that can have an Event.
+type eventObject interface {
+	workflow.Object
+	GetID() uuid.UUID
+	GetState() workflow.State
+}
+
+// seen is what was last seen of an object.
+type seen struct {
+	status   workflow.Status
+	attempts int
+}
+
+// Events tracks the Status of every object in a Plan and sends a workflow.Event for each change that is
+// written to storage. Events is safe for concurrent use.
+type Events struct {
+	mu   sync.Mutex
+	seen map[uuid.UUID]seen
+	send func(workflow.Event)
+}
+
+// NewEvents creates an Events for plan that calls send for each change. The current state of plan is
+// recorded, so only changes made after this is called are sent. send is called in the order the changes
+// are written and must not block.
+func NewEvents(plan *workflow.Plan, send func(workflow.Event)) *Events {
+	e := &Events{seen: map[uuid.UUID]seen{}, send: send}
+	for item := range walk.Plan(plan) {
+		o, ok := item.Value.(eventObject)
+		if !ok {
+			continue
+		}
+		s, _ := seenOf(o)
+		e.seen[o.GetID()] = s
+	}
+	return e
+}
+
+// observe sends an Event if o has changed since it was last observed. This is safe to call on a nil Events.
+func (e *Events) observe(o eventObject) {
+	if e == nil {
+		return
+	}
+	now, last := seenOf(o)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := o.GetID()
+	before := e.seen[id]
+	if now == before {
+		return
+	}
+	e.seen[id] = now
+
+	ev := workflow.Event{Type: o.Type(), ID: id, OldStatus: before.status, NewStatus: now.status}
+	if now.attempts > before.attempts {
+		ev.Attempt = last
+	}
+	e.send(ev)
+}
+
+// seenOf returns what we can see of o. If o is an Action with Attempts, the last Attempt is returned.
+func seenOf(o eventObject) (seen, *workflow.Attempt) {
+	s := seen{status: o.GetState().Status}
+	a, ok := o.(*workflow.Action)
+	if !ok {
+		return s, nil
+	}
+	attempts := a.Attempts.Get()
+	s.attempts = len(attempts)
+	if len(attempts) == 0 {
+		return s, nil
+	}
+	last := attempts[len(attempts)-1]
+	return s, &last
+}
+
+// Snapshot returns a Snapshot Event for every object in plan.
+func Snapshot(plan *workflow.Plan) iter.Seq[workflow.Event] {
+	return func(yield func(workflow.Event) bool) {
+		for item := range walk.Plan(plan) {
+			o, ok := item.Value.(eventObject)
+			if !ok {
+				continue
+			}
+			s, last := seenOf(o)
+			ev := workflow.Event{
+				Type:      o.Type(),
+				ID:        o.GetID(),
+				OldStatus: s.status,
+				NewStatus: s.status,
+				Attempt:   last,
+				Snapshot:  true,
+			}
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+}
+
+// withEvents attaches e to the Context so that writes to storage send Events.
+func withEvents(ctx context.Context, e *Events) context.Context {
+	if e == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, eventsKey{}, e)
+}
+
+// eventsFrom returns the *Events attached to the Context or nil if there is none.
+func eventsFrom(ctx context.Context) *Events {
+	e, _ := ctx.Value(eventsKey{}).(*Events)
+	return e
+}
+
+// eventStore is a storage.Vault that sends Events for the objects it writes. The Events are taken
+// from the Context of the write.
+type eventStore struct {
+	storage.Vault
+}
+
+func (s eventStore) UpdatePlan(ctx context.Context, p *workflow.Plan) error {
+	if err := s.Vault.UpdatePlan(ctx, p); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(p)
+	return nil
+}
+
+func (s eventStore) UpdateBlock(ctx context.Context, b *workflow.Block) error {
+	if err := s.Vault.UpdateBlock(ctx, b); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(b)
+	return nil
+}
+
+func (s eventStore) UpdateChecks(ctx context.Context, c *workflow.Checks) error {
+	if err := s.Vault.UpdateChecks(ctx, c); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(c)
+	return nil
+}
+
+func (s eventStore) UpdateSequence(ctx context.Context, seq *workflow.Sequence) error {
+	if err := s.Vault.UpdateSequence(ctx, seq); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(seq)
+	return nil
+}
+
+func (s eventStore) UpdateAction(ctx context.Context, a *workflow.Action) error {
+	if err := s.Vault.UpdateAction(ctx, a); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(a)
+	return nil
+}
+
+func (s eventStore) UpdateDeferredActions(ctx context.Context, da *workflow.DeferredActions) error {
+	if err := s.Vault.UpdateDeferredActions(ctx, da); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(da)
+	return nil
+}
+
+func (s eventStore) UpdateDeferBatch(ctx context.Context, b *workflow.DeferBatch) error {
+	if err := s.Vault.UpdateDeferBatch(ctx, b); err != nil {
+		return err
+	}
+	eventsFrom(ctx).observe(b)
+	return nil
+}
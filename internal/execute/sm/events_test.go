@@ -0,0 +1,131 @@
+package sm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gostdlib/base/context"
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/element-of-surprise/coercion/workflow"
+)
+
+type failActionUpdater struct {
+	fakeUpdater
+}
+
+func (f *failActionUpdater) UpdateAction(ctx context.Context, action *workflow.Action) error {
+	return fmt.Errorf("error")
+}
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	attempt := workflow.Attempt{}
+
+	tests := []struct {
+		name      string
+		change    func(a *workflow.Action)
+		failStore bool
+		noEvents  bool
+		want      []workflow.Event
+	}{
+		{
+			name:   "Success: no change sends nothing",
+			change: func(a *workflow.Action) {},
+		},
+		{
+			name: "Success: status change",
+			change: func(a *workflow.Action) {
+				a.State.Set(workflow.State{Status: workflow.Running})
+			},
+			want: []workflow.Event{{Type: workflow.OTAction, OldStatus: workflow.NotStarted, NewStatus: workflow.Running}},
+		},
+		{
+			name: "Success: new Attempt",
+			change: func(a *workflow.Action) {
+				a.Attempts.Set([]workflow.Attempt{attempt})
+			},
+			want: []workflow.Event{{Type: workflow.OTAction, OldStatus: workflow.NotStarted, NewStatus: workflow.NotStarted, Attempt: &attempt}},
+		},
+		{
+			name: "Success: no Events in the Context",
+			change: func(a *workflow.Action) {
+				a.State.Set(workflow.State{Status: workflow.Running})
+			},
+			noEvents: true,
+		},
+		{
+			name: "Error: storage write fails sends nothing",
+			change: func(a *workflow.Action) {
+				a.State.Set(workflow.State{Status: workflow.Running})
+			},
+			failStore: true,
+		},
+	}
+
+	for _, test := range tests {
+		action := &workflow.Action{ID: workflow.NewV7()}
+		action.State.Set(workflow.State{Status: workflow.NotStarted})
+		plan := &workflow.Plan{
+			ID: workflow.NewV7(),
+			Blocks: []*workflow.Block{
+				{ID: workflow.NewV7(), Sequences: []*workflow.Sequence{{ID: workflow.NewV7(), Actions: []*workflow.Action{action}}}},
+			},
+		}
+
+		var got []workflow.Event
+		events := NewEvents(plan, func(ev workflow.Event) { got = append(got, ev) })
+
+		ctx := context.Background()
+		if !test.noEvents {
+			ctx = withEvents(ctx, events)
+		}
+		var store eventStore
+		if test.failStore {
+			store = eventStore{Vault: &failActionUpdater{}}
+		} else {
+			store = eventStore{Vault: &fakeUpdater{}}
+		}
+
+		test.change(action)
+		store.UpdateAction(ctx, action)
+
+		for i := range test.want {
+			test.want[i].ID = action.ID
+		}
+		if diff := pretty.Compare(test.want, got); diff != "" {
+			t.Errorf("TestEvents(%s): -want/+got:\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	attempt := workflow.Attempt{}
+	action := &workflow.Action{ID: workflow.NewV7()}
+	action.State.Set(workflow.State{Status: workflow.Completed})
+	action.Attempts.Set([]workflow.Attempt{attempt})
+	seq := &workflow.Sequence{ID: workflow.NewV7(), Actions: []*workflow.Action{action}}
+	seq.State.Set(workflow.State{Status: workflow.Completed})
+	block := &workflow.Block{ID: workflow.NewV7(), Sequences: []*workflow.Sequence{seq}}
+	block.State.Set(workflow.State{Status: workflow.Running})
+	plan := &workflow.Plan{ID: workflow.NewV7(), Blocks: []*workflow.Block{block}}
+	plan.State.Set(workflow.State{Status: workflow.Running})
+
+	want := []workflow.Event{
+		{Type: workflow.OTPlan, ID: plan.ID, OldStatus: workflow.Running, NewStatus: workflow.Running, Snapshot: true},
+		{Type: workflow.OTBlock, ID: block.ID, OldStatus: workflow.Running, NewStatus: workflow.Running, Snapshot: true},
+		{Type: workflow.OTSequence, ID: seq.ID, OldStatus: workflow.Completed, NewStatus: workflow.Completed, Snapshot: true},
+		{Type: workflow.OTAction, ID: action.ID, OldStatus: workflow.Completed, NewStatus: workflow.Completed, Attempt: &attempt, Snapshot: true},
+	}
+
+	var got []workflow.Event
+	for ev := range Snapshot(plan) {
+		got = append(got, ev)
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("TestSnapshot: -want/+got:\n%s", diff)
+	}
+}
@@ -17,7 +17,7 @@ func (f finalStates) start(req statemachine.Request[Data]) statemachine.Request[
 	return req
 }
 
-// bypassChecks looks through all the checks in the in the Plan bypass and Completes the Plan if there are
+// bypassChecks looks through all the checks in the in the Plan bypass and records the Plan as Skipped if there are
 // bypass checks defined and they all pass. If there are no bypasses defined, the Plan is examined
 // further.
 func (f finalStates) bypassChecks(req statemachine.Request[Data]) statemachine.Request[Data] {
@@ -25,7 +25,10 @@ func (f finalStates) bypassChecks(req statemachine.Request[Data]) statemachine.R
 
 	skipped := f.examineBypasses(plan.BypassChecks)
 	if skipped {
-		req.Next = f.end // Records the Plan as Completed
+		state := plan.State.Get()
+		state.Status = workflow.Skipped
+		plan.State.Set(state)
+		req.Next = f.end
 		return req
 	}
 	req.Next = f.planChecks
@@ -39,11 +42,12 @@ func (f finalStates) bypassChecks(req statemachine.Request[Data]) statemachine.R
 //
 // DeferredActions failure takes precedence over a checks failure: DA runs after all
 // checks and FailElement=true is an explicit opt-in, so its failure must not be masked
-// by an earlier checks failure.
+// by an earlier checks failure. A failure also takes precedence over a Stop. If the Plan or a Block
+// ran past its Timeout, the Plan fails with FRTimeout, as that cut the checks short.
 func (f finalStates) planChecks(req statemachine.Request[Data]) statemachine.Request[Data] {
 	plan := req.Data.Plan
 
-	checksReason, checksErr := f.examineChecks([4]*workflow.Checks{plan.PreChecks, plan.ContChecks, plan.PostChecks, plan.DeferredChecks})
+	checksReason, checksErr := f.examineChecks([4]*workflow.Checks{plan.PreChecks, plan.ContChecks, plan.PostChecks, plan.DeferredChecks}, req.Data.stopped || req.Data.timedOut)
 	daReason, daErr := f.examineDeferredActions(plan.DeferredActions)
 
 	if daErr != nil {
@@ -55,6 +59,15 @@ func (f finalStates) planChecks(req statemachine.Request[Data]) statemachine.Req
 		req.Next = f.end
 		return req
 	}
+	if req.Data.timedOut {
+		state := plan.State.Get()
+		state.Status = workflow.Failed
+		plan.State.Set(state)
+		plan.Reason = workflow.FRTimeout
+		req.Err = fmt.Errorf("timeout")
+		req.Next = f.end
+		return req
+	}
 	if checksErr != nil {
 		state := plan.State.Get()
 		state.Status = workflow.Failed
@@ -82,12 +95,13 @@ func (f finalStates) examineDeferredActions(da *workflow.DeferredActions) (workf
 }
 
 // blocks checks the state of the block and fails the Plan if any of the blocks failed. If a block is not in a
-// state we should be in, it generates an ErrInternalFailure.
+// state we should be in, it generates an ErrInternalFailure. If a Stop was requested and no block failed,
+// the Plan is recorded as Stopped with FRStopped.
 func (f finalStates) blocks(req statemachine.Request[Data]) statemachine.Request[Data] {
 	plan := req.Data.Plan
 	for _, block := range req.Data.Plan.Blocks {
 		switch block.State.Get().Status {
-		case workflow.Completed:
+		case workflow.Completed, workflow.Skipped:
 		case workflow.Failed:
 			state := plan.State.Get()
 			state.Status = workflow.Failed
@@ -96,6 +110,10 @@ func (f finalStates) blocks(req statemachine.Request[Data]) statemachine.Request
 			req.Err = fmt.Errorf("block failure")
 			return req
 		default:
+			// Blocks that were stopped or never started are expected when we were stopped.
+			if req.Data.stopped {
+				continue
+			}
 			state := plan.State.Get()
 			state.Status = workflow.Failed
 			plan.State.Set(state)
@@ -104,11 +122,17 @@ func (f finalStates) blocks(req statemachine.Request[Data]) statemachine.Request
 			return req
 		}
 	}
+	if req.Data.stopped {
+		state := plan.State.Get()
+		state.Status = workflow.Stopped
+		plan.State.Set(state)
+		plan.Reason = workflow.FRStopped
+	}
 	req.Next = f.end
 	return req
 }
 
-// end records a Plan as Completed.
+// end records a Plan as Completed if it has not already been given a final Status.
 func (f finalStates) end(req statemachine.Request[Data]) statemachine.Request[Data] {
 	plan := req.Data.Plan
 	state := plan.State.Get()
@@ -132,8 +156,9 @@ func (f finalStates) examineBypasses(gates *workflow.Checks) bool {
 }
 
 // examineChecks Pre/Cont/Post/Deferred checks passed and returns a failure reason and an error if one of them failed.
-// If nothing failed (or checks are nil) this returns workflow.FRUnknown and a nil error.
-func (f finalStates) examineChecks(checks [4]*workflow.Checks) (workflow.FailureReason, error) {
+// If nothing failed (or checks are nil) this returns workflow.FRUnknown and a nil error. If stopped is set,
+// checks that did not finish are not an error, as a Stop may prevent them from running.
+func (f finalStates) examineChecks(checks [4]*workflow.Checks, stopped bool) (workflow.FailureReason, error) {
 	for i, check := range checks {
 		if check == nil {
 			continue
@@ -162,6 +187,9 @@ func (f finalStates) examineChecks(checks [4]*workflow.Checks) (workflow.Failure
 		case workflow.Failed:
 			return r, fmt.Errorf("%s failure", t)
 		default:
+			if stopped {
+				continue
+			}
 			err := fmt.Errorf("plan End state reached with a %s in %s state, which is invalid: %w", t, check.State.Get().Status, ErrInternalFailure)
 			return r, err
 		}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
 	"github.com/gostdlib/base/statemachine"
 )
 
@@ -20,6 +21,56 @@ func newBlockWithState(state *workflow.State) *workflow.Block {
 	return b
 }
 
+func TestFinalsBypassChecks(t *testing.T) {
+	t.Parallel()
+
+	finals := finalStates{}
+
+	tests := []struct {
+		name       string
+		bypass     *workflow.Checks
+		wantNext   statemachine.State[Data]
+		wantStatus workflow.Status
+	}{
+		{
+			name:       "no bypass checks",
+			wantNext:   finals.planChecks,
+			wantStatus: workflow.Running,
+		},
+		{
+			name:       "bypass checks failed",
+			bypass:     newChecksWithState(&workflow.State{Status: workflow.Failed}),
+			wantNext:   finals.planChecks,
+			wantStatus: workflow.Running,
+		},
+		{
+			name:       "bypass checks completed",
+			bypass:     newChecksWithState(&workflow.State{Status: workflow.Completed}),
+			wantNext:   finals.end,
+			wantStatus: workflow.Skipped,
+		},
+	}
+
+	for _, test := range tests {
+		plan := &workflow.Plan{BypassChecks: test.bypass}
+		plan.State.Set(workflow.State{Status: workflow.Running})
+
+		req := finals.bypassChecks(statemachine.Request[Data]{Data: Data{Plan: plan}})
+		if methodName(req.Next) != methodName(test.wantNext) {
+			t.Errorf("TestFinalsBypassChecks(%s): got next == %v, want next == %v", test.name, methodName(req.Next), methodName(test.wantNext))
+		}
+		if got := plan.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestFinalsBypassChecks(%s): got status == %v, want status == %v", test.name, got, test.wantStatus)
+		}
+		if test.wantStatus == workflow.Skipped {
+			finals.end(req)
+			if got := plan.State.Get().Status; got != test.wantStatus {
+				t.Errorf("TestFinalsBypassChecks(%s): end changed status to %v, want %v", test.name, got, test.wantStatus)
+			}
+		}
+	}
+}
+
 func TestPlanChecks(t *testing.T) {
 	t.Parallel()
 
@@ -95,6 +146,11 @@ func TestBlocks(t *testing.T) {
 			block:    newBlockWithState(&workflow.State{Status: workflow.Completed}),
 			wantNext: finals.end,
 		},
+		{
+			name:     "block is skipped",
+			block:    newBlockWithState(&workflow.State{Status: workflow.Skipped}),
+			wantNext: finals.end,
+		},
 		{
 			name:    "block is failed",
 			block:   newBlockWithState(&workflow.State{Status: workflow.Failed}),
@@ -146,6 +202,117 @@ func TestFinalsEnd(t *testing.T) {
 	}
 }
 
+func TestFinalStatesStopped(t *testing.T) {
+	t.Parallel()
+
+	failedDA := &workflow.DeferredActions{}
+	failedDA.State.Set(workflow.State{Status: workflow.Failed})
+
+	tests := []struct {
+		name       string
+		plan       func() *workflow.Plan
+		stopped    bool
+		wantStatus workflow.Status
+		wantReason workflow.FailureReason
+	}{
+		{
+			name: "Success: Stop with blocks that did not finish",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					PreChecks:  newChecksWithState(&workflow.State{Status: workflow.Completed}),
+					PostChecks: newChecksWithState(&workflow.State{Status: workflow.NotStarted}),
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Completed}),
+						newBlockWithState(&workflow.State{Status: workflow.Stopped}),
+						newBlockWithState(&workflow.State{Status: workflow.NotStarted}),
+					},
+				}
+			},
+			stopped:    true,
+			wantStatus: workflow.Stopped,
+			wantReason: workflow.FRStopped,
+		},
+		{
+			name: "Success: Stop does not hide a failed Block",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Failed}),
+						newBlockWithState(&workflow.State{Status: workflow.NotStarted}),
+					},
+				}
+			},
+			stopped:    true,
+			wantStatus: workflow.Failed,
+			wantReason: workflow.FRBlock,
+		},
+		{
+			name: "Success: Stop does not hide failed DeferredChecks",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					DeferredChecks: newChecksWithState(&workflow.State{Status: workflow.Failed}),
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Stopped}),
+					},
+				}
+			},
+			stopped:    true,
+			wantStatus: workflow.Failed,
+			wantReason: workflow.FRDeferredCheck,
+		},
+		{
+			name: "Success: Stop does not hide failed DeferredActions",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					DeferredActions: failedDA,
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Stopped}),
+					},
+				}
+			},
+			stopped:    true,
+			wantStatus: workflow.Failed,
+			wantReason: workflow.FRDeferredAction,
+		},
+		{
+			name: "Success: no Stop with a block that did not finish is an internal failure",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.NotStarted}),
+					},
+				}
+			},
+			wantStatus: workflow.Failed,
+			wantReason: workflow.FRBlock,
+		},
+	}
+
+	for _, test := range tests {
+		plan := test.plan()
+		plan.State.Set(workflow.State{Status: workflow.Stopping})
+
+		f := finalStates{}
+		req := statemachine.Request[Data]{Ctx: context.Background(), Data: Data{Plan: plan, stopped: test.stopped}, Next: f.start}
+		statemachine.Run("finalStates", req)
+
+		if got := plan.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestFinalStatesStopped(%s): got status %v, want %v", test.name, got, test.wantStatus)
+		}
+		if plan.Reason != test.wantReason {
+			t.Errorf("TestFinalStatesStopped(%s): got reason %v, want %v", test.name, plan.Reason, test.wantReason)
+		}
+	}
+
+	// end must not promote a Stopped Plan to Completed.
+	plan := &workflow.Plan{}
+	plan.State.Set(workflow.State{Status: workflow.Stopped})
+	finalStates{}.end(statemachine.Request[Data]{Data: Data{Plan: plan}})
+	if plan.State.Get().Status != workflow.Stopped {
+		t.Errorf("TestFinalStatesStopped: end changed a Stopped Plan to %v", plan.State.Get().Status)
+	}
+}
+
 // TestPlanChecksFailurePreservesStatus tests that when planChecks fails,
 // the plan ends with Failed status after going through end().
 // This tests the fix for a bug where end() unconditionally set the status
@@ -333,6 +500,7 @@ func TestExamineChecks(t *testing.T) {
 	tests := []struct {
 		name        string
 		checks      [4]*workflow.Checks
+		stopped     bool
 		wantReason  workflow.FailureReason
 		wantErr     bool
 		internalErr bool
@@ -400,11 +568,33 @@ func TestExamineChecks(t *testing.T) {
 			wantErr:     true,
 			internalErr: true,
 		},
+		{
+			name: "check that did not run after a Stop",
+			checks: [4]*workflow.Checks{
+				newChecksWithState(&workflow.State{Status: workflow.Completed}),
+				newChecksWithState(&workflow.State{Status: workflow.Completed}),
+				newChecksWithState(&workflow.State{Status: workflow.NotStarted}),
+				newChecksWithState(&workflow.State{Status: workflow.Completed}),
+			},
+			stopped: true,
+		},
+		{
+			name: "check that failed after a Stop",
+			checks: [4]*workflow.Checks{
+				newChecksWithState(&workflow.State{Status: workflow.Completed}),
+				newChecksWithState(&workflow.State{Status: workflow.Completed}),
+				newChecksWithState(&workflow.State{Status: workflow.NotStarted}),
+				newChecksWithState(&workflow.State{Status: workflow.Failed}),
+			},
+			stopped:    true,
+			wantReason: workflow.FRDeferredCheck,
+			wantErr:    true,
+		},
 	}
 
 	for _, test := range tests {
 		f := finalStates{}
-		r, err := f.examineChecks(test.checks)
+		r, err := f.examineChecks(test.checks, test.stopped)
 		switch {
 		case err == nil && test.wantErr:
 			t.Errorf("TestExamineChecks(%s): got nil error, want error", test.name)
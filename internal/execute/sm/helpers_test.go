@@ -1,6 +1,9 @@
 package sm
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -48,10 +51,19 @@ func TestRunPreChecks(t *testing.T) {
 			preChecks:  &workflow.Checks{Actions: []*workflow.Action{actionSuccess}},
 			contChecks: &workflow.Checks{Actions: []*workflow.Action{actionSuccess}},
 		},
+		{
+			name:      "Success: ContChecks fail within their FailureThreshold",
+			preChecks: &workflow.Checks{Actions: []*workflow.Action{actionSuccess}},
+			contChecks: &workflow.Checks{
+				Actions:          []*workflow.Action{actionError},
+				FailureThreshold: &workflow.FailureThreshold{Consecutive: 2},
+			},
+		},
 	}
 
 	for _, test := range tests {
 		states := &States{
+			store:            &fakeUpdater{},
 			testChecksRunner: fakeRunChecksOnce,
 		}
 
@@ -131,6 +143,99 @@ func TestRunChecksOnce(t *testing.T) {
 	}
 }
 
+func TestRunSoakChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		soak       time.Duration
+		failRound  int
+		stop       bool
+		wantErr    error
+		wantStatus workflow.Status
+		wantRounds int // 0 means more than 1 round.
+	}{
+		{
+			name:       "Success: no SoakDuration runs once",
+			wantStatus: workflow.Completed,
+			wantRounds: 1,
+		},
+		{
+			name:       "Success: rounds until SoakDuration passes",
+			soak:       50 * time.Millisecond,
+			wantStatus: workflow.Completed,
+		},
+		{
+			name:       "Error: a round fails",
+			soak:       time.Minute,
+			failRound:  2,
+			wantErr:    fmt.Errorf("error"),
+			wantStatus: workflow.Failed,
+			wantRounds: 2,
+		},
+		{
+			name:       "Error: stopped during the soak",
+			soak:       time.Minute,
+			stop:       true,
+			wantErr:    ErrStopped,
+			wantStatus: workflow.Stopped,
+			wantRounds: 1,
+		},
+	}
+
+	for _, test := range tests {
+		rounds := 0
+		runner := func(ctx context.Context, actions []*workflow.Action) error {
+			rounds++
+			if rounds == test.failRound {
+				return fmt.Errorf("error")
+			}
+			return nil
+		}
+
+		checks := &workflow.Checks{
+			Delay:        10 * time.Millisecond,
+			SoakDuration: test.soak,
+			Actions:      []*workflow.Action{{Name: "healthy"}},
+		}
+		states := &States{
+			store:                     &fakeUpdater{},
+			testActionsParallelRunner: runner,
+		}
+		ctx := context.Background()
+		if test.stop {
+			ctx = setStopping(ctx)
+		}
+
+		start := time.Now()
+		err := states.runSoakChecks(ctx, checks)
+		switch {
+		case test.wantErr == nil && err != nil:
+			t.Errorf("TestRunSoakChecks(%s): got err == %v, want err == nil", test.name, err)
+		case test.wantErr != nil && err == nil:
+			t.Errorf("TestRunSoakChecks(%s): got err == nil, want err != nil", test.name)
+		case errors.Is(test.wantErr, ErrStopped) && !errors.Is(err, ErrStopped):
+			t.Errorf("TestRunSoakChecks(%s): got err == %v, want ErrStopped", test.name, err)
+		}
+		state := checks.State.Get()
+		if state.Status != test.wantStatus {
+			t.Errorf("TestRunSoakChecks(%s): got status == %v, want %v", test.name, state.Status, test.wantStatus)
+		}
+		switch {
+		case test.wantRounds == 0 && rounds < 2:
+			t.Errorf("TestRunSoakChecks(%s): got %d rounds, want more than 1", test.name, rounds)
+		case test.wantRounds != 0 && rounds != test.wantRounds:
+			t.Errorf("TestRunSoakChecks(%s): got %d rounds, want %d", test.name, rounds, test.wantRounds)
+		}
+		if test.wantStatus == workflow.Completed && state.End.Sub(start) < test.soak-checks.Delay {
+			t.Errorf("TestRunSoakChecks(%s): soak ended after %v, want at least %v", test.name, state.End.Sub(start), test.soak-checks.Delay)
+		}
+		if state.Start.Before(start) || state.Start.Sub(start) > checks.Delay {
+			t.Errorf("TestRunSoakChecks(%s): got Start %v, want the start of the first round", test.name, state.Start)
+		}
+	}
+}
+
 func TestParallelActionsRunner(t *testing.T) {
 	t.Parallel()
 
@@ -212,6 +317,7 @@ func TestExecSeq(t *testing.T) {
 		seq       *workflow.Sequence
 		wantSeq   *workflow.Sequence
 		dbUpdates []*workflow.Sequence
+		stop      bool
 		wantErr   bool
 	}{
 		{
@@ -233,6 +339,17 @@ func TestExecSeq(t *testing.T) {
 				newSequenceWithState("seq", []*workflow.Action{{Name: "action1"}, {Name: "action2"}}, &workflow.State{Status: workflow.Completed, Start: start, End: end}),
 			},
 		},
+		{
+			name:    "stop requested, seq stopped",
+			seq:     newSequenceWithState("seq", []*workflow.Action{{Name: "action1"}, {Name: "action2"}}, &workflow.State{}),
+			wantSeq: newSequenceWithState("seq", []*workflow.Action{{Name: "action1"}, {Name: "action2"}}, &workflow.State{Status: workflow.Stopped, Start: start, End: end}),
+			dbUpdates: []*workflow.Sequence{
+				newSequenceWithState("seq", []*workflow.Action{{Name: "action1"}, {Name: "action2"}}, &workflow.State{Status: workflow.Running, Start: start}),
+				newSequenceWithState("seq", []*workflow.Action{{Name: "action1"}, {Name: "action2"}}, &workflow.State{Status: workflow.Stopped, Start: start, End: end}),
+			},
+			stop:    true,
+			wantErr: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -250,7 +367,12 @@ func TestExecSeq(t *testing.T) {
 			},
 		}
 
-		err := states.execSeq(context.Background(), test.seq)
+		ctx := context.Background()
+		if test.stop {
+			ctx = setStopping(ctx)
+		}
+
+		err := states.execSeq(ctx, test.seq)
 
 		if diff := pretty.Compare(test.wantSeq, test.seq); diff != "" {
 			t.Errorf("TestExecSeq(%s): expected Sequence: -want/+got:\n%s", test.name, diff)
@@ -264,6 +386,78 @@ func TestExecSeq(t *testing.T) {
 	}
 }
 
+func TestExecSeqDeferredActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		actions      []*workflow.Action
+		da           *workflow.DeferredActions
+		wantErr      bool
+		wantStatus   workflow.Status
+		wantDAStatus workflow.Status
+		wantBatches  []workflow.Status
+	}{
+		{
+			name:    "Success: seq completed runs OnSuccess batches",
+			actions: []*workflow.Action{{Name: "action"}},
+			da: newDA(
+				newDABatch(workflow.OnSuccess, false, "ok"),
+				newDABatch(workflow.OnFailure, false, "untouched"),
+			),
+			wantStatus:   workflow.Completed,
+			wantDAStatus: workflow.Completed,
+			wantBatches:  []workflow.Status{workflow.Completed, workflow.NotStarted},
+		},
+		{
+			name:    "Error: seq failed runs OnFailure batches",
+			actions: []*workflow.Action{{Name: "error"}},
+			da: newDA(
+				newDABatch(workflow.OnSuccess, false, "untouched"),
+				newDABatch(workflow.OnFailure, false, "ok"),
+			),
+			wantErr:      true,
+			wantStatus:   workflow.Failed,
+			wantDAStatus: workflow.Completed,
+			wantBatches:  []workflow.Status{workflow.NotStarted, workflow.Completed},
+		},
+		{
+			name:         "Error: FailElement batch fails a completed seq",
+			actions:      []*workflow.Action{{Name: "action"}},
+			da:           newDA(newDABatch(workflow.Always, true, "error")),
+			wantErr:      true,
+			wantStatus:   workflow.Failed,
+			wantDAStatus: workflow.Failed,
+			wantBatches:  []workflow.Status{workflow.Failed},
+		},
+	}
+
+	for _, test := range tests {
+		seq := newSequenceWithState("seq", test.actions, &workflow.State{})
+		seq.DeferredActions = test.da
+		states := &States{
+			store:            &fakeUpdater{},
+			testActionRunner: fakeActionRunner,
+		}
+
+		err := states.execSeq(context.Background(), seq)
+		if (err != nil) != test.wantErr {
+			t.Errorf("TestExecSeqDeferredActions(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if got := seq.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestExecSeqDeferredActions(%s): got seq status == %v, want %v", test.name, got, test.wantStatus)
+		}
+		if got := seq.DeferredActions.State.Get().Status; got != test.wantDAStatus {
+			t.Errorf("TestExecSeqDeferredActions(%s): got DeferredActions status == %v, want %v", test.name, got, test.wantDAStatus)
+		}
+		for i, b := range seq.DeferredActions.DeferredBatches {
+			if got := b.State.Get().Status; got != test.wantBatches[i] {
+				t.Errorf("TestExecSeqDeferredActions(%s): got batch[%d] status == %v, want %v", test.name, i, got, test.wantBatches[i])
+			}
+		}
+	}
+}
+
 func TestResetActions(t *testing.T) {
 	t.Parallel()
 
@@ -284,3 +478,184 @@ func TestResetActions(t *testing.T) {
 		t.Errorf("TestResetActions: -want +got):\n%s", diff)
 	}
 }
+
+func TestRunContRound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		threshold    *workflow.FailureThreshold
+		rounds       []bool // true is a failed round.
+		wantErrRound int    // Round, starting at 1, that returns an error. 0 means none do.
+	}{
+		{
+			name:         "Error: no FailureThreshold fails on the first failed round",
+			rounds:       []bool{false, true},
+			wantErrRound: 2,
+		},
+		{
+			name:      "Success: failed rounds that are not consecutive are tolerated",
+			threshold: &workflow.FailureThreshold{Consecutive: 2},
+			rounds:    []bool{true, false, true, false},
+		},
+		{
+			name:         "Error: Consecutive failed rounds",
+			threshold:    &workflow.FailureThreshold{Consecutive: 2},
+			rounds:       []bool{true, false, true, true},
+			wantErrRound: 4,
+		},
+		{
+			name:         "Error: Failures within the Window",
+			threshold:    &workflow.FailureThreshold{Failures: 3, Window: time.Hour},
+			rounds:       []bool{true, false, true, false, true},
+			wantErrRound: 5,
+		},
+	}
+
+	for _, test := range tests {
+		round := 0
+		runner := func(ctx context.Context, actions []*workflow.Action) error {
+			if test.rounds[round-1] {
+				for _, a := range actions {
+					a.State.Set(workflow.State{Status: workflow.Failed})
+				}
+				return fmt.Errorf("error")
+			}
+			for _, a := range actions {
+				a.State.Set(workflow.State{Status: workflow.Completed})
+			}
+			return nil
+		}
+
+		checks := &workflow.Checks{
+			FailureThreshold: test.threshold,
+			Actions:          []*workflow.Action{{Name: "healthy"}},
+		}
+		states := &States{
+			store:                     &fakeUpdater{},
+			testActionsParallelRunner: runner,
+		}
+
+		errRound := 0
+		for range test.rounds {
+			round++
+			if err := states.runContRound(context.Background(), checks); err != nil {
+				errRound = round
+				break
+			}
+			if got := checks.State.Get().Status; got != workflow.Completed {
+				t.Errorf("TestRunContRound(%s): round %d got status == %v, want %v", test.name, round, got, workflow.Completed)
+			}
+		}
+
+		if errRound != test.wantErrRound {
+			t.Errorf("TestRunContRound(%s): got an error on round %d, want round %d", test.name, errRound, test.wantErrRound)
+		}
+		// Every round that ran is recorded, including the ones that passed and the one that failed the checks.
+		attempts := checks.Attempts.Get()
+		if len(attempts) != round {
+			t.Fatalf("TestRunContRound(%s): got %d Attempts, want %d", test.name, len(attempts), round)
+		}
+		for i, attempt := range attempts {
+			want := workflow.Completed
+			if test.rounds[i] {
+				want = workflow.Failed
+			}
+			if got := attempt.Actions[0].Status; got != want {
+				t.Errorf("TestRunContRound(%s): round %d got recorded status == %v, want %v", test.name, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestRunContRoundTrim(t *testing.T) {
+	t.Parallel()
+
+	failed := workflow.SeqAttempt{Actions: []workflow.ActionResult{{Status: workflow.Failed}}}
+	passed := workflow.SeqAttempt{Actions: []workflow.ActionResult{{Status: workflow.Completed}}}
+
+	tests := []struct {
+		name string
+		old  workflow.SeqAttempt
+		want []workflow.Status
+	}{
+		{
+			name: "the oldest round that passed is dropped",
+			old:  passed,
+			want: []workflow.Status{workflow.Failed, workflow.Completed},
+		},
+		{
+			name: "the oldest round is dropped if none passed",
+			old:  failed,
+			want: []workflow.Status{workflow.Failed, workflow.Failed},
+		},
+	}
+
+	for _, test := range tests {
+		checks := &workflow.Checks{Actions: []*workflow.Action{{Name: "healthy"}}}
+		attempts := []workflow.SeqAttempt{failed}
+		for range maxContRounds - 1 {
+			attempts = append(attempts, test.old)
+		}
+		checks.Attempts.Set(attempts)
+		states := &States{
+			store: &fakeUpdater{},
+			testActionsParallelRunner: func(ctx context.Context, actions []*workflow.Action) error {
+				actions[0].State.Set(workflow.State{Status: workflow.Completed})
+				return nil
+			},
+		}
+
+		if err := states.runContRound(context.Background(), checks); err != nil {
+			t.Fatalf("TestRunContRoundTrim(%s): got err == %s, want err == nil", test.name, err)
+		}
+		attempts = checks.Attempts.Get()
+		if len(attempts) != maxContRounds {
+			t.Errorf("TestRunContRoundTrim(%s): got %d Attempts, want %d", test.name, len(attempts), maxContRounds)
+		}
+		got := []workflow.Status{attempts[0].Actions[0].Status, attempts[len(attempts)-1].Actions[0].Status}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("TestRunContRoundTrim(%s): got first and last rounds %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestThresholdReached(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	round := func(failed bool, end time.Time) workflow.SeqAttempt {
+		status := workflow.Completed
+		if failed {
+			status = workflow.Failed
+		}
+		return workflow.SeqAttempt{Actions: []workflow.ActionResult{{Status: status}}, End: end}
+	}
+	// The last round is the one that failed now.
+	attempts := []workflow.SeqAttempt{
+		round(true, now.Add(-2*time.Hour)),
+		round(false, now.Add(-time.Hour)),
+		round(true, now.Add(-30*time.Minute)),
+		round(true, now.Add(-10*time.Minute)),
+		round(true, now),
+	}
+
+	tests := []struct {
+		name      string
+		threshold workflow.FailureThreshold
+		want      bool
+	}{
+		{name: "Consecutive not reached", threshold: workflow.FailureThreshold{Consecutive: 4}},
+		{name: "Consecutive reached", threshold: workflow.FailureThreshold{Consecutive: 3}, want: true},
+		{name: "Failures outside the Window are not counted", threshold: workflow.FailureThreshold{Failures: 3, Window: 20 * time.Minute}},
+		{name: "Failures within the Window", threshold: workflow.FailureThreshold{Failures: 3, Window: time.Hour}, want: true},
+		{name: "Failures within a longer Window", threshold: workflow.FailureThreshold{Failures: 4, Window: 3 * time.Hour}, want: true},
+		{name: "Rounds that passed are not counted", threshold: workflow.FailureThreshold{Failures: 5, Window: 3 * time.Hour}},
+	}
+
+	for _, test := range tests {
+		if got := thresholdReached(&test.threshold, attempts); got != test.want {
+			t.Errorf("TestThresholdReached(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package sm
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/utils/walk"
+)
+
+// RunHooks calls hooks for the Events of plan returned by next until next returns false. next must block until
+// there are Events. If started is set, plan was started instead of recovered and OnPlanStart is called first.
+func RunHooks(ctx context.Context, hooks workflow.Hooks, plan *workflow.Plan, next func(context.Context) ([]workflow.Event, bool), started bool) {
+	objects := map[uuid.UUID]workflow.Object{}
+	for item := range walk.Plan(plan) {
+		if o, ok := item.Value.(eventObject); ok {
+			objects[o.GetID()] = o
+		}
+	}
+
+	if started {
+		callHook(ctx, "OnPlanStart", func() { hooks.OnPlanStart(ctx, plan) })
+	}
+
+	for {
+		evs, ok := next(ctx)
+		for _, ev := range evs {
+			o, found := objects[ev.ID]
+			if !found {
+				continue
+			}
+			dispatchHook(ctx, hooks, o, ev)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// dispatchHook calls the Hooks for ev, which is a change to o.
+func dispatchHook(ctx context.Context, hooks workflow.Hooks, o workflow.Object, ev workflow.Event) {
+	start := ev.OldStatus == workflow.NotStarted && ev.NewStatus.Active()
+	end := ev.OldStatus != ev.NewStatus && ev.NewStatus != workflow.NotStarted && !ev.NewStatus.Active()
+
+	switch v := o.(type) {
+	case *workflow.Plan:
+		if end {
+			callHook(ctx, "OnPlanEnd", func() { hooks.OnPlanEnd(ctx, v) })
+		}
+	case *workflow.Checks:
+		if start {
+			callHook(ctx, "OnChecksStart", func() { hooks.OnChecksStart(ctx, v) })
+		}
+		if end {
+			callHook(ctx, "OnChecksEnd", func() { hooks.OnChecksEnd(ctx, v) })
+		}
+	case *workflow.Block:
+		if start {
+			callHook(ctx, "OnBlockStart", func() { hooks.OnBlockStart(ctx, v) })
+		}
+		if end {
+			callHook(ctx, "OnBlockEnd", func() { hooks.OnBlockEnd(ctx, v) })
+		}
+	case *workflow.Sequence:
+		if start {
+			callHook(ctx, "OnSequenceStart", func() { hooks.OnSequenceStart(ctx, v) })
+		}
+		if end {
+			callHook(ctx, "OnSequenceEnd", func() { hooks.OnSequenceEnd(ctx, v) })
+		}
+	case *workflow.Action:
+		if start {
+			callHook(ctx, "OnActionStart", func() { hooks.OnActionStart(ctx, v) })
+		}
+		if ev.Attempt != nil {
+			callHook(ctx, "OnActionAttempt", func() { hooks.OnActionAttempt(ctx, v, *ev.Attempt) })
+		}
+		if end {
+			callHook(ctx, "OnActionEnd", func() { hooks.OnActionEnd(ctx, v) })
+		}
+	}
+}
+
+// callHook calls f, logging and recovering from any panic.
+func callHook(ctx context.Context, name string, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			context.Log(ctx).Error(fmt.Sprintf("hook %s panicked", name), "id", context.PlanID(ctx), "panic", r)
+		}
+	}()
+	f()
+}
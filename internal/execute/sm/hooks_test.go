@@ -0,0 +1,105 @@
+package sm
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gostdlib/base/context"
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/element-of-surprise/coercion/workflow"
+)
+
+type recordHooks struct {
+	workflow.NoOpHooks
+
+	mu    sync.Mutex
+	calls []string
+	panic bool
+}
+
+func (r *recordHooks) record(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, s)
+	if r.panic {
+		panic("hook panic")
+	}
+}
+
+func (r *recordHooks) OnPlanStart(ctx context.Context, p *workflow.Plan)   { r.record("OnPlanStart") }
+func (r *recordHooks) OnPlanEnd(ctx context.Context, p *workflow.Plan)     { r.record("OnPlanEnd") }
+func (r *recordHooks) OnBlockStart(ctx context.Context, b *workflow.Block) { r.record("OnBlockStart") }
+func (r *recordHooks) OnBlockEnd(ctx context.Context, b *workflow.Block)   { r.record("OnBlockEnd") }
+func (r *recordHooks) OnActionStart(ctx context.Context, a *workflow.Action) {
+	r.record("OnActionStart")
+}
+func (r *recordHooks) OnActionAttempt(ctx context.Context, a *workflow.Action, attempt workflow.Attempt) {
+	r.record("OnActionAttempt")
+}
+func (r *recordHooks) OnActionEnd(ctx context.Context, a *workflow.Action) { r.record("OnActionEnd") }
+
+func TestRunHooks(t *testing.T) {
+	t.Parallel()
+
+	action := &workflow.Action{ID: workflow.NewV7()}
+	block := &workflow.Block{ID: workflow.NewV7(), Sequences: []*workflow.Sequence{{ID: workflow.NewV7(), Actions: []*workflow.Action{action}}}}
+	plan := &workflow.Plan{ID: workflow.NewV7(), Blocks: []*workflow.Block{block}}
+
+	attempt := &workflow.Attempt{}
+	events := []workflow.Event{
+		{Type: workflow.OTBlock, ID: block.ID, OldStatus: workflow.NotStarted, NewStatus: workflow.Running},
+		{Type: workflow.OTAction, ID: action.ID, OldStatus: workflow.NotStarted, NewStatus: workflow.Running},
+		{Type: workflow.OTAction, ID: action.ID, OldStatus: workflow.Running, NewStatus: workflow.Completed, Attempt: attempt},
+		{Type: workflow.OTBlock, ID: block.ID, OldStatus: workflow.Running, NewStatus: workflow.Completed},
+		// A Plan that is paused and resumed does not start or end.
+		{Type: workflow.OTPlan, ID: plan.ID, OldStatus: workflow.Running, NewStatus: workflow.Paused},
+		{Type: workflow.OTPlan, ID: plan.ID, OldStatus: workflow.Paused, NewStatus: workflow.Running},
+		{Type: workflow.OTPlan, ID: plan.ID, OldStatus: workflow.Running, NewStatus: workflow.Failed},
+		// Unknown objects are ignored.
+		{Type: workflow.OTAction, ID: workflow.NewV7(), OldStatus: workflow.NotStarted, NewStatus: workflow.Running},
+	}
+	all := []string{"OnPlanStart", "OnBlockStart", "OnActionStart", "OnActionAttempt", "OnActionEnd", "OnBlockEnd", "OnPlanEnd"}
+
+	tests := []struct {
+		name    string
+		started bool
+		panic   bool
+		want    []string
+	}{
+		{
+			name:    "Success: started Plan",
+			started: true,
+			want:    all,
+		},
+		{
+			name: "Success: recovered Plan does not call OnPlanStart",
+			want: all[1:],
+		},
+		{
+			name:    "Success: panics are recovered and do not stop later Hooks",
+			started: true,
+			panic:   true,
+			want:    all,
+		},
+	}
+
+	for _, test := range tests {
+		hooks := &recordHooks{panic: test.panic}
+
+		sent := false
+		next := func(ctx context.Context) ([]workflow.Event, bool) {
+			if sent {
+				return nil, false
+			}
+			sent = true
+			return events, true
+		}
+
+		RunHooks(context.Background(), hooks, plan, next, test.started)
+
+		if diff := pretty.Compare(test.want, hooks.calls); diff != "" {
+			t.Errorf("TestRunHooks(%s): -want/+got:\n%s", test.name, diff)
+		}
+	}
+}
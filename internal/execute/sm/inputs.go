@@ -0,0 +1,122 @@
+package sm
+
+import (
+	"sync"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/uuid"
+
+	"github.com/element-of-surprise/coercion/internal/when"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// keysKey is a key for the *planKeys in context.Value.
+type keysKey struct{}
+
+// planKeys has the objects in a Plan that have a Key. These are used to resolve the Req of an Action
+// that is a workflow.Resolver and to evaluate When conditions.
+type planKeys struct {
+	// meta is the Plan's Meta decoded as JSON. This is nil if Meta is not JSON.
+	meta any
+	// objects are the Blocks, Checks, Sequences and Actions in Sequences by Key.
+	objects map[uuid.UUID]stater
+	// actions are the Actions in Sequences by Key.
+	actions map[uuid.UUID]*workflow.Action
+
+	// resps caches the response of each Completed Action decoded as JSON by *workflow.Action.
+	// A Completed Action's response never changes.
+	resps sync.Map
+}
+
+// withKeys attaches the objects in plan that have a Key to the Context.
+func withKeys(ctx context.Context, plan *workflow.Plan) context.Context {
+	k := &planKeys{
+		objects: map[uuid.UUID]stater{},
+		actions: map[uuid.UUID]*workflow.Action{},
+	}
+	if len(plan.Meta) > 0 {
+		if err := json.Unmarshal(plan.Meta, &k.meta); err != nil {
+			k.meta = nil
+		}
+	}
+
+	addChecks := func(checks ...*workflow.Checks) {
+		for _, c := range checks {
+			if c != nil && c.Key != uuid.Nil {
+				k.objects[c.Key] = c
+			}
+		}
+	}
+	addChecks(plan.BypassChecks, plan.PreChecks, plan.ContChecks, plan.PostChecks, plan.DeferredChecks)
+	for _, b := range plan.Blocks {
+		if b.Key != uuid.Nil {
+			k.objects[b.Key] = b
+		}
+		addChecks(b.BypassChecks, b.PreChecks, b.ContChecks, b.PostChecks, b.WaveChecks, b.DeferredChecks)
+		for _, seq := range b.Sequences {
+			if seq.Key != uuid.Nil {
+				k.objects[seq.Key] = seq
+			}
+			for _, a := range seq.Actions {
+				if a.Key != uuid.Nil {
+					k.objects[a.Key] = a
+					k.actions[a.Key] = a
+				}
+			}
+		}
+	}
+	return context.WithValue(ctx, keysKey{}, k)
+}
+
+// keysFrom returns the *planKeys attached to the Context by withKeys or nil if there is none.
+func keysFrom(ctx context.Context) *planKeys {
+	k, _ := ctx.Value(keysKey{}).(*planKeys)
+	return k
+}
+
+// actionKeys returns the Actions in Sequences by Key. This is safe to call on a nil *planKeys.
+func (k *planKeys) actionKeys() map[uuid.UUID]*workflow.Action {
+	if k == nil {
+		return nil
+	}
+	return k.actions
+}
+
+// vars returns the variables for evaluating a When condition. This is safe to call on a nil *planKeys.
+func (k *planKeys) vars() map[string]any {
+	status := map[string]any{}
+	resps := map[string]any{}
+	vars := map[string]any{when.Meta: nil, when.Status: status, when.Resp: resps}
+	if k == nil {
+		return vars
+	}
+
+	vars[when.Meta] = k.meta
+	for key, o := range k.objects {
+		status[key.String()] = o.GetState().Status.String()
+	}
+	for key, a := range k.actions {
+		if a.State.Get().Status != workflow.Completed {
+			continue
+		}
+		resps[key.String()] = k.resp(a)
+	}
+	return vars
+}
+
+// resp returns the response of the Completed Action a decoded as JSON.
+func (k *planKeys) resp(a *workflow.Action) any {
+	if v, ok := k.resps.Load(a); ok {
+		return v
+	}
+	var v any
+	b, err := json.Marshal(a.FinalAttempt().Resp)
+	if err == nil {
+		if err := json.Unmarshal(b, &v); err != nil {
+			v = nil
+		}
+	}
+	k.resps.Store(a, v)
+	return v
+}
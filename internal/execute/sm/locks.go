@@ -0,0 +1,264 @@
+package sm
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/google/uuid"
+
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// lockReq is a request by a Block or Sequence for its Locks.
+type lockReq struct {
+	owner uuid.UUID
+	locks *workflow.Locks
+	// granted is closed once the Locks are held.
+	granted chan struct{}
+}
+
+// resource is a named resource that is locked.
+type resource struct {
+	// holders are the IDs of the Blocks and Sequences that hold a lock on the resource.
+	holders map[uuid.UUID]bool
+	// exclusive is set if the holder has an Exclusive lock.
+	exclusive bool
+}
+
+// lockTable is the local lock manager for the Locks of the Blocks and Sequences of all the Plans that a
+// Workstream runs. A request is granted once all of its Locks are free and no request that was made before
+// it is waiting on one of the same resources, so an Exclusive lock does not wait forever behind Shared locks.
+// Nothing is persisted here, a holder records that it holds its Locks in storage. lockTable is safe for
+// concurrent use. The zero value is not usable; construct it with newLockTable.
+type lockTable struct {
+	mu        sync.Mutex
+	resources map[string]*resource
+	waiting   []*lockReq
+}
+
+// newLockTable returns an empty lockTable.
+func newLockTable() *lockTable {
+	return &lockTable{resources: map[string]*resource{}}
+}
+
+// acquire takes locks for owner, waiting until they are granted. This returns ErrStopped if a Stop is
+// requested and ErrTimeout if the Plan runs past its Timeout while waiting. If the locks are granted as
+// the wait ends, they are held and this returns nil.
+func (t *lockTable) acquire(ctx context.Context, owner uuid.UUID, locks *workflow.Locks) error {
+	r := &lockReq{owner: owner, locks: locks, granted: make(chan struct{})}
+
+	t.mu.Lock()
+	if t.free(locks) && !t.blocked(len(t.waiting), locks) {
+		t.take(owner, locks)
+		t.mu.Unlock()
+		return nil
+	}
+	t.waiting = append(t.waiting, r)
+	t.mu.Unlock()
+
+	context.Log(ctx).Info("waiting for locks", "owner", owner, "resources", locks.Names())
+	var err error
+	select {
+	case <-r.granted:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-stopping(ctx):
+		err = ErrStopped
+	case <-expired(ctx):
+		err = ErrTimeout
+	}
+	if !t.cancel(r) {
+		return nil
+	}
+	return err
+}
+
+// take takes locks for owner even if another holds them. This is used for the Locks of recovered Plans,
+// which were held before the Workstream went down.
+func (t *lockTable) take(owner uuid.UUID, locks *workflow.Locks) {
+	add := func(name string, exclusive bool) {
+		res, ok := t.resources[name]
+		if !ok {
+			res = &resource{holders: map[uuid.UUID]bool{}}
+			t.resources[name] = res
+		}
+		res.holders[owner] = true
+		res.exclusive = res.exclusive || exclusive
+	}
+	for _, n := range locks.Shared {
+		add(n, false)
+	}
+	for _, n := range locks.Exclusive {
+		add(n, true)
+	}
+}
+
+// retake takes locks for owner. See take.
+func (t *lockTable) retake(owner uuid.UUID, locks *workflow.Locks) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.take(owner, locks)
+}
+
+// release gives back the locks that owner holds and grants the requests that were waiting on them.
+func (t *lockTable) release(owner uuid.UUID, locks *workflow.Locks) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, n := range locks.Names() {
+		res, ok := t.resources[n]
+		if !ok {
+			continue
+		}
+		delete(res.holders, owner)
+		if len(res.holders) == 0 {
+			delete(t.resources, n)
+		}
+	}
+	t.grant()
+}
+
+// cancel removes r from the waiting requests. It returns false if r was already granted, in which case
+// its locks are held.
+func (t *lockTable) cancel(r *lockReq) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := slices.Index(t.waiting, r)
+	if i < 0 {
+		return false
+	}
+	t.waiting = slices.Delete(t.waiting, i, i+1)
+	// Requests that were waiting behind r may now be granted.
+	t.grant()
+	return true
+}
+
+// grant grants the waiting requests that can be, in the order they were made. t.mu must be held.
+func (t *lockTable) grant() {
+	for i := 0; i < len(t.waiting); {
+		r := t.waiting[i]
+		if !t.free(r.locks) || t.blocked(i, r.locks) {
+			i++
+			continue
+		}
+		t.take(r.owner, r.locks)
+		t.waiting = slices.Delete(t.waiting, i, i+1)
+		close(r.granted)
+	}
+}
+
+// free reports if locks can be taken without waiting on a holder. t.mu must be held.
+func (t *lockTable) free(locks *workflow.Locks) bool {
+	for _, n := range locks.Shared {
+		if res, ok := t.resources[n]; ok && res.exclusive {
+			return false
+		}
+	}
+	for _, n := range locks.Exclusive {
+		if _, ok := t.resources[n]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// blocked reports if any of the first n waiting requests wants one of the same resources as locks and
+// either of them wants it Exclusive. t.mu must be held.
+func (t *lockTable) blocked(n int, locks *workflow.Locks) bool {
+	for _, r := range t.waiting[:n] {
+		if conflicts(r.locks, locks) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts reports if a and b cannot be held at the same time.
+func conflicts(a, b *workflow.Locks) bool {
+	for _, n := range a.Exclusive {
+		if slices.Contains(b.Shared, n) || slices.Contains(b.Exclusive, n) {
+			return true
+		}
+	}
+	for _, n := range a.Shared {
+		if slices.Contains(b.Exclusive, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// lock takes the Locks of the Block or Sequence with id and records that they are held in storage with
+// update. If locks is nil or is already held, as it is for a recovered Plan, this does nothing. This
+// returns ErrStopped if a Stop is requested and ErrTimeout if the Plan runs past its Timeout while waiting.
+func (s *States) lock(ctx context.Context, id uuid.UUID, locks *workflow.Locks, update func(context.Context) error) error {
+	if locks == nil || locks.Held.Get() {
+		return nil
+	}
+	if err := s.locks.acquire(ctx, id, locks); err != nil {
+		return err
+	}
+	locks.Held.Set(true)
+	if err := update(context.WithoutCancel(ctx)); err != nil {
+		log.Fatalf("failed to write Locks: %v", err)
+	}
+	return nil
+}
+
+// unlock records in storage with update that the Locks of the Block or Sequence with id are not held
+// and then releases them. The final State of the Block or Sequence must be written before this is called,
+// otherwise a recovered Plan could run it again without its Locks. If locks is nil or is not held,
+// this does nothing.
+func (s *States) unlock(ctx context.Context, id uuid.UUID, locks *workflow.Locks, update func(context.Context) error) {
+	if locks == nil || !locks.Held.Get() {
+		return
+	}
+	locks.Held.Set(false)
+	if err := update(context.WithoutCancel(ctx)); err != nil {
+		log.Fatalf("failed to write Locks: %v", err)
+	}
+	s.locks.release(id, locks)
+}
+
+// unlockPlan releases the Locks that are still held by the Blocks and Sequences of plan.
+func (s *States) unlockPlan(ctx context.Context, plan *workflow.Plan) {
+	for _, b := range plan.Blocks {
+		for _, seq := range b.Sequences {
+			s.unlock(ctx, seq.ID, seq.Locks, func(ctx context.Context) error { return s.store.UpdateSequence(ctx, seq) })
+		}
+		s.unlock(ctx, b.ID, b.Locks, func(ctx context.Context) error { return s.store.UpdateBlock(ctx, b) })
+	}
+}
+
+// RetakeLocks takes the Locks that the Blocks and Sequences of the recovered plan held when the Workstream
+// went down. The Locks of a Block or Sequence that had already finished are recorded as released instead.
+// This must be called for all the recovered Plans before any of them run, so that a lock held by one of
+// them is not granted to another.
+func (s *States) RetakeLocks(ctx context.Context, plan *workflow.Plan) {
+	retake := func(id uuid.UUID, status workflow.Status, locks *workflow.Locks, update func(context.Context) error) {
+		if locks == nil || !locks.Held.Get() {
+			return
+		}
+		switch status {
+		case workflow.Completed, workflow.Failed, workflow.Stopped, workflow.Skipped:
+			locks.Held.Set(false)
+			if err := update(ctx); err != nil {
+				log.Fatalf("failed to write Locks: %v", err)
+			}
+			return
+		}
+		s.locks.retake(id, locks)
+	}
+
+	for _, b := range plan.Blocks {
+		retake(b.ID, b.State.Get().Status, b.Locks, func(ctx context.Context) error { return s.store.UpdateBlock(ctx, b) })
+		for _, seq := range b.Sequences {
+			retake(seq.ID, seq.State.Get().Status, seq.Locks, func(ctx context.Context) error { return s.store.UpdateSequence(ctx, seq) })
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package sm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/google/uuid"
+	"github.com/gostdlib/base/statemachine"
+)
+
+// lockStep is a step in TestLockTable. If release is set, the locks of the owner are released. Otherwise
+// the owner asks for its locks.
+type lockStep struct {
+	owner   int
+	locks   *workflow.Locks
+	release bool
+}
+
+func TestLockTable(t *testing.T) {
+	t.Parallel()
+
+	shared := &workflow.Locks{Shared: []string{"cluster"}}
+	exclusive := &workflow.Locks{Exclusive: []string{"cluster"}}
+	other := &workflow.Locks{Exclusive: []string{"other"}}
+
+	tests := []struct {
+		name  string
+		steps []lockStep
+		// wantGranted is the owners that hold their locks after each step, in the order they were granted.
+		wantGranted [][]int
+	}{
+		{
+			name:        "Shared locks are held together",
+			steps:       []lockStep{{owner: 0, locks: shared}, {owner: 1, locks: shared}},
+			wantGranted: [][]int{{0}, {0, 1}},
+		},
+		{
+			name:        "different resources are held together",
+			steps:       []lockStep{{owner: 0, locks: exclusive}, {owner: 1, locks: other}},
+			wantGranted: [][]int{{0}, {0, 1}},
+		},
+		{
+			name: "Exclusive waits for Shared",
+			steps: []lockStep{
+				{owner: 0, locks: shared},
+				{owner: 1, locks: exclusive},
+				{owner: 0, release: true},
+			},
+			wantGranted: [][]int{{0}, {0}, {1}},
+		},
+		{
+			name: "Shared waits behind a waiting Exclusive",
+			steps: []lockStep{
+				{owner: 0, locks: shared},
+				{owner: 1, locks: exclusive},
+				{owner: 2, locks: shared},
+				{owner: 0, release: true},
+				{owner: 1, release: true},
+			},
+			wantGranted: [][]int{{0}, {0}, {0}, {1}, {2}},
+		},
+		{
+			name: "waits behind an earlier request for all of its Locks",
+			steps: []lockStep{
+				{owner: 0, locks: other},
+				{owner: 1, locks: &workflow.Locks{Shared: []string{"cluster"}, Exclusive: []string{"other"}}},
+				{owner: 2, locks: exclusive},
+				{owner: 0, release: true},
+				{owner: 1, release: true},
+			},
+			wantGranted: [][]int{{0}, {0}, {0}, {1}, {2}},
+		},
+	}
+
+	for _, test := range tests {
+		table := newLockTable()
+		owners := make([]uuid.UUID, 3)
+		for i := range owners {
+			owners[i] = workflow.NewV7()
+		}
+		granted := make(chan int, len(test.steps))
+		var held []int
+
+		for i, step := range test.steps {
+			if step.release {
+				table.release(owners[step.owner], test.steps[step.owner].locks)
+				for j, o := range held {
+					if o == step.owner {
+						held = append(held[:j], held[j+1:]...)
+						break
+					}
+				}
+			} else {
+				go func() {
+					if err := table.acquire(context.Background(), owners[step.owner], step.locks); err == nil {
+						granted <- step.owner
+					}
+				}()
+			}
+
+			// Give the goroutines time to be granted or to start waiting.
+			time.Sleep(20 * time.Millisecond)
+		drain:
+			for {
+				select {
+				case o := <-granted:
+					held = append(held, o)
+				default:
+					break drain
+				}
+			}
+
+			if !equalInts(held, test.wantGranted[i]) {
+				t.Errorf("TestLockTable(%s): after step %d got held %v, want %v", test.name, i, held, test.wantGranted[i])
+			}
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLockTableCancel(t *testing.T) {
+	t.Parallel()
+
+	exclusive := &workflow.Locks{Exclusive: []string{"cluster"}}
+	shared := &workflow.Locks{Shared: []string{"cluster"}}
+
+	// This Context is past the deadline of a Plan that started a minute ago with a Timeout of a second.
+	expiredCtx, stop := (&States{}).withTimeout(context.Background(), time.Now().Add(-time.Minute), time.Second)
+	defer stop()
+	<-expired(expiredCtx)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "stopped", ctx: setStopping(context.Background()), wantErr: ErrStopped},
+		{name: "timed out", ctx: expiredCtx, wantErr: ErrTimeout},
+	}
+
+	for _, test := range tests {
+		table := newLockTable()
+		holder, waiter, next := workflow.NewV7(), workflow.NewV7(), workflow.NewV7()
+		table.retake(holder, shared)
+
+		// The waiter is removed when it stops waiting, so it does not hold back the next request.
+		if err := table.acquire(test.ctx, waiter, exclusive); !errors.Is(err, test.wantErr) {
+			t.Errorf("TestLockTableCancel(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if err := table.acquire(context.Background(), next, shared); err != nil {
+			t.Errorf("TestLockTableCancel(%s): next request got err == %s, want err == nil", test.name, err)
+		}
+		if len(table.waiting) != 0 {
+			t.Errorf("TestLockTableCancel(%s): got %d waiting requests, want 0", test.name, len(table.waiting))
+		}
+	}
+}
+
+func TestLockUnlock(t *testing.T) {
+	t.Parallel()
+
+	updater := &fakeUpdater{}
+	s := &States{store: updater, locks: newLockTable()}
+	seq := &workflow.Sequence{
+		ID:      workflow.NewV7(),
+		Locks:   &workflow.Locks{Exclusive: []string{"cluster"}},
+		Actions: []*workflow.Action{{Name: "action"}},
+	}
+	update := func(ctx context.Context) error { return updater.UpdateSequence(ctx, seq) }
+	ctx := context.Background()
+
+	if err := s.lock(ctx, seq.ID, seq.Locks, update); err != nil {
+		t.Fatalf("TestLockUnlock: lock got err == %s, want err == nil", err)
+	}
+	if !seq.Locks.Held.Get() || len(updater.seqs) != 1 || !updater.seqs[0].Locks.Held.Get() {
+		t.Errorf("TestLockUnlock: lock did not record that the Locks are held")
+	}
+	// A recovered sequence that holds its Locks does not take them again.
+	if err := s.lock(ctx, seq.ID, seq.Locks, update); err != nil || len(updater.seqs) != 1 {
+		t.Errorf("TestLockUnlock: second lock got (%v, %d writes), want (nil, 1 write)", err, len(updater.seqs))
+	}
+
+	s.unlock(ctx, seq.ID, seq.Locks, update)
+	if seq.Locks.Held.Get() || len(updater.seqs) != 2 || updater.seqs[1].Locks.Held.Get() {
+		t.Errorf("TestLockUnlock: unlock did not record that the Locks are not held")
+	}
+	if len(s.locks.resources) != 0 {
+		t.Errorf("TestLockUnlock: unlock did not release the Locks: %v", s.locks.resources)
+	}
+}
+
+func TestRetakeLocks(t *testing.T) {
+	t.Parallel()
+
+	newSeq := func(status workflow.Status, held bool) *workflow.Sequence {
+		seq := &workflow.Sequence{
+			ID:      workflow.NewV7(),
+			Locks:   &workflow.Locks{Exclusive: []string{"seq-" + status.String()}},
+			Actions: []*workflow.Action{{Name: "action"}},
+		}
+		seq.Locks.Held.Set(held)
+		seq.State.Set(workflow.State{Status: status})
+		return seq
+	}
+	running := newSeq(workflow.Running, true)
+	completed := newSeq(workflow.Completed, true)
+	notHeld := newSeq(workflow.NotStarted, false)
+
+	block := &workflow.Block{
+		ID:        workflow.NewV7(),
+		Locks:     &workflow.Locks{Shared: []string{"cluster"}},
+		Sequences: []*workflow.Sequence{running, completed, notHeld},
+	}
+	block.Locks.Held.Set(true)
+	block.State.Set(workflow.State{Status: workflow.Running})
+	plan := &workflow.Plan{Blocks: []*workflow.Block{block}}
+
+	updater := &fakeUpdater{}
+	s := &States{store: updater, locks: newLockTable()}
+	s.RetakeLocks(context.Background(), plan)
+
+	for _, name := range []string{"cluster", "seq-Running"} {
+		if _, ok := s.locks.resources[name]; !ok {
+			t.Errorf("TestRetakeLocks: resource(%s) was not retaken", name)
+		}
+	}
+	for _, name := range []string{"seq-Completed", "seq-NotStarted"} {
+		if _, ok := s.locks.resources[name]; ok {
+			t.Errorf("TestRetakeLocks: resource(%s) was retaken", name)
+		}
+	}
+	if completed.Locks.Held.Get() || len(updater.seqs) != 1 || updater.seqs[0].ID != completed.ID {
+		t.Errorf("TestRetakeLocks: the Locks of the completed sequence were not recorded as released")
+	}
+}
+
+// TestExecuteBlockLockWait tests that a Block waiting for its Locks does not hold up the other Blocks and
+// that it gives up and is not started once another Block fails.
+func TestExecuteBlockLockWait(t *testing.T) {
+	t.Parallel()
+
+	exclusive := &workflow.Locks{Exclusive: []string{"cluster"}}
+
+	waiter := &workflow.Block{ID: workflow.NewV7(), Key: workflow.NewV7(), Locks: &workflow.Locks{Exclusive: []string{"cluster"}}}
+	waiter.State.Set(workflow.State{})
+	checks := &workflow.Checks{Actions: []*workflow.Action{{Name: "error"}}}
+	checks.State.Set(workflow.State{})
+	failer := &workflow.Block{ID: workflow.NewV7(), Key: workflow.NewV7(), PreChecks: checks}
+	failer.State.Set(workflow.State{})
+
+	s := &States{store: &fakeUpdater{}, locks: newLockTable(), testChecksRunner: fakeRunChecksOnce}
+	// Another Plan holds the lock that the waiter wants.
+	s.locks.retake(workflow.NewV7(), exclusive)
+
+	req := statemachine.Request[Data]{
+		Ctx: context.Background(),
+		Data: Data{
+			Plan: &workflow.Plan{Blocks: []*workflow.Block{waiter, failer}, Concurrency: 2},
+			blocks: []block{
+				{block: waiter, contCheckResult: newContResult()},
+				{block: failer, contCheckResult: newContResult()},
+			},
+		},
+	}
+
+	done := make(chan statemachine.Request[Data])
+	go func() { done <- s.ExecuteBlock(req) }()
+	select {
+	case req = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("TestExecuteBlockLockWait: ExecuteBlock did not return while a Block waited for its Locks")
+	}
+
+	if req.Data.err == nil {
+		t.Errorf("TestExecuteBlockLockWait: got err == nil, want err != nil")
+	}
+	if got := failer.State.Get().Status; got != workflow.Failed {
+		t.Errorf("TestExecuteBlockLockWait: got failing block status %v, want %v", got, workflow.Failed)
+	}
+	if got := waiter.State.Get().Status; got != workflow.NotStarted {
+		t.Errorf("TestExecuteBlockLockWait: got waiting block status %v, want %v", got, workflow.NotStarted)
+	}
+	if waiter.Locks.Held.Get() || len(s.locks.waiting) != 0 {
+		t.Errorf("TestExecuteBlockLockWait: the waiting block did not give up its lock request")
+	}
+}
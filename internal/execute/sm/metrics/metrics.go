@@ -0,0 +1,162 @@
+// Package metrics has the OpenTelemetry metrics recorded while executing Plans.
+package metrics
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/element-of-surprise/coercion/plugins"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// Attribute keys that are set on our metrics.
+const (
+	// Status is the final Status of a Plan.
+	Status = attribute.Key("coercion.status")
+	// Reason is the FailureReason of a Plan.
+	Reason = attribute.Key("coercion.reason")
+	// Recovered is set if the Plan was recovered.
+	Recovered = attribute.Key("coercion.recovered")
+	// Plugin is the name of the plugin an Action uses.
+	Plugin = attribute.Key("coercion.plugin")
+	// Outcome is the outcome of an Attempt, one of "ok", "error" or "timeout".
+	Outcome = attribute.Key("coercion.outcome")
+)
+
+// Metrics holds the instruments for our metrics. All methods are safe to call on a nil *Metrics,
+// which records nothing.
+type Metrics struct {
+	plansStarted      metric.Int64Counter
+	plansEnded        metric.Int64Counter
+	plansRunning      metric.Int64UpDownCounter
+	seqsRunning       metric.Int64UpDownCounter
+	attemptDuration   metric.Float64Histogram
+	retries           metric.Int64Counter
+	timeouts          metric.Int64Counter
+	contCheckFailures metric.Int64Counter
+}
+
+// New creates the instruments for our metrics with meter.
+func New(meter metric.Meter) (*Metrics, error) {
+	m := &Metrics{}
+
+	var err error
+	m.plansStarted, err = meter.Int64Counter(
+		"coercion.plans.started",
+		metric.WithDescription("The number of Plans that have started or were recovered."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.plansEnded, err = meter.Int64Counter(
+		"coercion.plans.ended",
+		metric.WithDescription("The number of Plans that have ended, by Status and FailureReason."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.plansRunning, err = meter.Int64UpDownCounter(
+		"coercion.plans.running",
+		metric.WithDescription("The number of Plans currently running."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.seqsRunning, err = meter.Int64UpDownCounter(
+		"coercion.sequences.running",
+		metric.WithDescription("The number of Sequences currently running."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.attemptDuration, err = meter.Float64Histogram(
+		"coercion.action.attempt.duration",
+		metric.WithDescription("The time taken by each Attempt of an Action, by plugin."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.retries, err = meter.Int64Counter(
+		"coercion.action.retries",
+		metric.WithDescription("The number of times an Action was retried, by plugin."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.timeouts, err = meter.Int64Counter(
+		"coercion.action.timeouts",
+		metric.WithDescription("The number of Attempts that timed out, by plugin."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.contCheckFailures, err = meter.Int64Counter(
+		"coercion.contchecks.failures",
+		metric.WithDescription("The number of times ContChecks have failed."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PlanStarted records that a Plan has started running in this process.
+func (m *Metrics) PlanStarted(ctx context.Context, recovered bool) {
+	if m == nil {
+		return
+	}
+	m.plansStarted.Add(ctx, 1, metric.WithAttributes(Recovered.Bool(recovered)))
+	m.plansRunning.Add(ctx, 1)
+}
+
+// PlanEnded records that a Plan that was started with PlanStarted has ended.
+func (m *Metrics) PlanEnded(ctx context.Context, plan *workflow.Plan) {
+	if m == nil {
+		return
+	}
+	m.plansEnded.Add(
+		ctx,
+		1,
+		metric.WithAttributes(Status.String(plan.State.Get().Status.String()), Reason.String(plan.Reason.String())),
+	)
+	m.plansRunning.Add(ctx, -1)
+}
+
+// SequenceRunning adds delta to the number of running Sequences.
+func (m *Metrics) SequenceRunning(ctx context.Context, delta int64) {
+	if m == nil {
+		return
+	}
+	m.seqsRunning.Add(ctx, delta)
+}
+
+// Attempt records an Attempt of an Action using plugin. retry is the retry number, starting at 0.
+func (m *Metrics) Attempt(ctx context.Context, plugin string, retry int, d time.Duration, err *plugins.Error, timeout bool) {
+	if m == nil {
+		return
+	}
+	outcome := "ok"
+	switch {
+	case timeout:
+		outcome = "timeout"
+		m.timeouts.Add(ctx, 1, metric.WithAttributes(Plugin.String(plugin)))
+	case err != nil:
+		outcome = "error"
+	}
+	if retry > 0 {
+		m.retries.Add(ctx, 1, metric.WithAttributes(Plugin.String(plugin)))
+	}
+	m.attemptDuration.Record(ctx, d.Seconds(), metric.WithAttributes(Plugin.String(plugin), Outcome.String(outcome)))
+}
+
+// ContCheckFailed records that ContChecks have failed.
+func (m *Metrics) ContCheckFailed(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.contCheckFailures.Add(ctx, 1)
+}
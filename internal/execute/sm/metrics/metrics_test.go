@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/element-of-surprise/coercion/plugins"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// sums returns the value of every Int64 sum by metric name.
+func sums(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+
+	rm := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					got[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					got[m.Name] += int64(dp.Count)
+				}
+			}
+		}
+	}
+	return got
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := New(mp.Meter("test"))
+	if err != nil {
+		t.Fatalf("TestMetrics: New: %v", err)
+	}
+
+	plan := &workflow.Plan{Reason: workflow.FRBlock}
+	plan.State.Set(workflow.State{Status: workflow.Failed})
+
+	m.PlanStarted(ctx, false)
+	m.PlanStarted(ctx, true)
+	m.PlanEnded(ctx, plan)
+	m.SequenceRunning(ctx, 1)
+	m.Attempt(ctx, "plugin", 0, time.Second, &plugins.Error{Message: "error"}, false)
+	m.Attempt(ctx, "plugin", 1, time.Second, &plugins.Error{Message: "timeout"}, true)
+	m.Attempt(ctx, "plugin", 2, time.Second, nil, false)
+	m.ContCheckFailed(ctx)
+
+	want := map[string]int64{
+		"coercion.plans.started":           2,
+		"coercion.plans.ended":             1,
+		"coercion.plans.running":           1,
+		"coercion.sequences.running":       1,
+		"coercion.action.attempt.duration": 3,
+		"coercion.action.retries":          2,
+		"coercion.action.timeouts":         1,
+		"coercion.contchecks.failures":     1,
+	}
+	got := sums(t, reader)
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("TestMetrics: %s = %d, want %d", name, got[name], n)
+		}
+	}
+
+	// A nil Metrics records nothing and does not panic.
+	var nilMetrics *Metrics
+	nilMetrics.PlanStarted(ctx, false)
+	nilMetrics.PlanEnded(ctx, plan)
+	nilMetrics.SequenceRunning(ctx, 1)
+	nilMetrics.Attempt(ctx, "plugin", 0, time.Second, nil, false)
+	nilMetrics.ContCheckFailed(ctx)
+}
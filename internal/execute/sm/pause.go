@@ -0,0 +1,141 @@
+package sm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+)
+
+// closed is a channel that is always closed.
+var closed = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// Pauser pauses and resumes a running Plan. A paused Plan does not start any new Blocks or Sequences,
+// but anything that is already running is allowed to finish. The Paused Status is written to storage,
+// so a Plan recovered after a crash stays paused. A Pauser is safe for concurrent use.
+type Pauser struct {
+	mu sync.Mutex
+	// resumed is closed when the Plan is not paused.
+	resumed chan struct{}
+	// done is set once the Plan has reached End and can no longer be paused.
+	done bool
+
+	plan   *workflow.Plan
+	store  storage.PlanUpdater
+	events *Events
+}
+
+// NewPauser creates a Pauser for plan that writes status changes to store and sends them to events, which
+// may be nil. If the plan is already Paused, such as a recovered Plan, the Pauser starts paused.
+func NewPauser(plan *workflow.Plan, store storage.PlanUpdater, events *Events) *Pauser {
+	p := &Pauser{resumed: closed, plan: plan, store: store, events: events}
+	if plan.State.Get().Status == workflow.Paused {
+		p.resumed = make(chan struct{})
+	}
+	return p
+}
+
+// Pause pauses the Plan. Pausing a Plan that is already Paused does nothing. It is an error to pause a
+// Plan that is not Running.
+func (p *Pauser) Pause(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.plan.State.Get()
+	switch {
+	case state.Status == workflow.Paused:
+		return nil
+	case p.done || state.Status != workflow.Running:
+		return fmt.Errorf("plan(%s) is %s: %w", p.plan.ID, state.Status, ErrNotPausable)
+	}
+
+	if err := p.setStatus(ctx, workflow.Paused); err != nil {
+		return err
+	}
+	p.resumed = make(chan struct{})
+	return nil
+}
+
+// Resume resumes a Paused Plan. Resuming a Plan that is not Paused does nothing.
+func (p *Pauser) Resume(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.plan.State.Get().Status != workflow.Paused {
+		return nil
+	}
+	if err := p.setStatus(ctx, workflow.Running); err != nil {
+		return err
+	}
+	close(p.resumed)
+	p.resumed = closed
+	return nil
+}
+
+// setStatus sets the Plan's Status and writes it to storage. If the write fails, the Status is restored.
+func (p *Pauser) setStatus(ctx context.Context, status workflow.Status) error {
+	orig := p.plan.State.Get()
+	state := orig
+	state.Status = status
+	p.plan.State.Set(state)
+	if err := p.store.UpdatePlan(ctx, p.plan); err != nil {
+		p.plan.State.Set(orig)
+		return fmt.Errorf("could not write plan(%s) status: %w", p.plan.ID, err)
+	}
+	p.events.observe(p.plan)
+	return nil
+}
+
+// wait returns a channel that is closed when the Plan is not paused. This is safe to call on a nil Pauser.
+func (p *Pauser) wait() <-chan struct{} {
+	if p == nil {
+		return closed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.resumed
+}
+
+// do runs f while holding the Pauser's lock, so that f can change the Plan's Status without racing
+// Pause or Resume. This is safe to call on a nil Pauser.
+func (p *Pauser) do(f func()) {
+	if p == nil {
+		f()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f()
+}
+
+// close prevents any further Pause and releases anything waiting on a Resume. This is called by End.
+// This is safe to call on a nil Pauser.
+func (p *Pauser) close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done = true
+	if p.resumed != closed {
+		close(p.resumed)
+		p.resumed = closed
+	}
+}
+
+// waitResume blocks while the Plan is paused. It returns once the Plan is resumed, a Stop is requested or
+// the Plan or Block runs past its Timeout.
+func waitResume(ctx context.Context, p *Pauser) {
+	select {
+	case <-p.wait():
+	case <-stopping(ctx):
+	case <-expired(ctx):
+	}
+}
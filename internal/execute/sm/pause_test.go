@@ -0,0 +1,179 @@
+package sm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gostdlib/base/context"
+
+	"github.com/element-of-surprise/coercion/workflow"
+)
+
+type failPlanUpdater struct {
+	fakeUpdater
+}
+
+func (f *failPlanUpdater) UpdatePlan(ctx context.Context, plan *workflow.Plan) error {
+	return fmt.Errorf("error")
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPauser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		status          workflow.Status
+		failStore       bool
+		done            bool
+		pause           bool
+		resume          bool
+		wantStatus      workflow.Status
+		wantPaused      bool
+		wantErr         bool
+		wantNotPausable bool
+		wantWrites      int
+	}{
+		{
+			name:       "Success: pause a Running Plan",
+			status:     workflow.Running,
+			pause:      true,
+			wantStatus: workflow.Paused,
+			wantPaused: true,
+			wantWrites: 1,
+		},
+		{
+			name:       "Success: pause then resume a Running Plan",
+			status:     workflow.Running,
+			pause:      true,
+			resume:     true,
+			wantStatus: workflow.Running,
+			wantWrites: 2,
+		},
+		{
+			name:       "Success: recovered Paused Plan starts paused",
+			status:     workflow.Paused,
+			wantStatus: workflow.Paused,
+			wantPaused: true,
+		},
+		{
+			name:       "Success: pause a Paused Plan does nothing",
+			status:     workflow.Paused,
+			pause:      true,
+			wantStatus: workflow.Paused,
+			wantPaused: true,
+		},
+		{
+			name:       "Success: resume a Running Plan does nothing",
+			status:     workflow.Running,
+			resume:     true,
+			wantStatus: workflow.Running,
+		},
+		{
+			name:            "Error: pause a Stopping Plan",
+			status:          workflow.Stopping,
+			pause:           true,
+			wantStatus:      workflow.Stopping,
+			wantErr:         true,
+			wantNotPausable: true,
+		},
+		{
+			name:            "Error: pause after End",
+			status:          workflow.Running,
+			done:            true,
+			pause:           true,
+			wantStatus:      workflow.Running,
+			wantErr:         true,
+			wantNotPausable: true,
+		},
+		{
+			name:       "Error: storage write fails, status is restored",
+			status:     workflow.Running,
+			failStore:  true,
+			pause:      true,
+			wantStatus: workflow.Running,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+
+		plan := &workflow.Plan{}
+		plan.State.Set(workflow.State{Status: test.status})
+
+		updater := &fakeUpdater{}
+		var p *Pauser
+		if test.failStore {
+			p = NewPauser(plan, &failPlanUpdater{}, nil)
+		} else {
+			p = NewPauser(plan, updater, nil)
+		}
+		if test.done {
+			p.close()
+		}
+
+		var err error
+		if test.pause {
+			err = p.Pause(ctx)
+		}
+		if test.resume && err == nil {
+			err = p.Resume(ctx)
+		}
+
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestPauser(%s): got err == nil, want err != nil", test.name)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestPauser(%s): got err == %s, want err == nil", test.name, err)
+		}
+		if got := errors.Is(err, ErrNotPausable); got != test.wantNotPausable {
+			t.Errorf("TestPauser(%s): got errors.Is(err, ErrNotPausable) == %v, want %v", test.name, got, test.wantNotPausable)
+		}
+		if got := plan.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestPauser(%s): got status %v, want %v", test.name, got, test.wantStatus)
+		}
+		if got := !isClosed(p.wait()); got != test.wantPaused {
+			t.Errorf("TestPauser(%s): got paused == %v, want %v", test.name, got, test.wantPaused)
+		}
+		if got := len(updater.plans); got != test.wantWrites {
+			t.Errorf("TestPauser(%s): got %d storage writes, want %d", test.name, got, test.wantWrites)
+		}
+
+		// close must always release anything waiting on a Resume.
+		p.close()
+		if !isClosed(p.wait()) {
+			t.Errorf("TestPauser(%s): close did not release waiters", test.name)
+		}
+	}
+}
+
+func TestWaitResume(t *testing.T) {
+	t.Parallel()
+
+	plan := &workflow.Plan{}
+	plan.State.Set(workflow.State{Status: workflow.Paused})
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		pause *Pauser
+	}{
+		{name: "nil Pauser does not block", ctx: context.Background()},
+		{name: "Stop releases a paused Plan", ctx: setStopping(context.Background()), pause: NewPauser(plan, &fakeUpdater{}, nil)},
+	}
+
+	// If waitResume does not return, the test will time out.
+	for _, test := range tests {
+		waitResume(test.ctx, test.pause)
+	}
+}
@@ -0,0 +1,26 @@
+package sm
+
+import (
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// readyWave readies a Block to start the next wave of its Ramp. It waits for the Ramp's Soak and then
+// runs the Block's WaveChecks. If a sequence in the wave has already started, the wave was readied before
+// the Plan was recovered and this returns nil right away. This returns ErrStopped or ErrTimeout if
+// the Plan is stopped or times out during the Soak.
+func (s *States) readyWave(ctx context.Context, b *workflow.Block, wave []*workflow.Sequence) error {
+	for _, seq := range wave {
+		if seq.State.Get().Status != workflow.NotStarted {
+			return nil
+		}
+	}
+
+	if err := after(ctx, b.Ramp.Soak); err != nil {
+		return err
+	}
+	if b.WaveChecks == nil {
+		return nil
+	}
+	return s.runChecksOnce(ctx, b.WaveChecks)
+}
@@ -0,0 +1,146 @@
+package sm
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/utils/clone"
+
+	"github.com/gostdlib/base/statemachine"
+)
+
+func TestExecuteSequencesRamp(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	slowAction := &workflow.Action{Plugin: plugins.Name, Timeout: 10 * time.Second, Req: plugins.Req{Sleep: 10 * time.Millisecond}}
+
+	seqs := func(n int) []*workflow.Sequence {
+		s := make([]*workflow.Sequence, 0, n)
+		for range n {
+			s = append(s, &workflow.Sequence{Actions: []*workflow.Action{clone.Action(ctx, slowAction, cloneOpts...)}})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name            string
+		ramp            *workflow.Ramp
+		seqs            []*workflow.Sequence
+		waveErr         error
+		started         workflow.Status // Status of the sequences in the second wave before the Block runs.
+		wantWaves       []int
+		wantPluginCalls int
+		wantWaveChecks  int
+		wantStatus      workflow.Status
+		wantErr         bool
+	}{
+		{
+			name:            "Success: canary and steps",
+			ramp:            &workflow.Ramp{Canary: 1, Steps: []int{50}, Soak: 20 * time.Millisecond},
+			seqs:            seqs(4),
+			wantWaves:       []int{1, 2, 4},
+			wantPluginCalls: 4,
+			wantWaveChecks:  2,
+			wantStatus:      workflow.Running,
+		},
+		{
+			name:            "Success: wave already started before recovery does not rerun WaveChecks",
+			ramp:            &workflow.Ramp{Canary: 1},
+			seqs:            seqs(3),
+			started:         workflow.Completed,
+			wantWaves:       []int{1, 3},
+			wantPluginCalls: 2,
+			wantWaveChecks:  0,
+			wantStatus:      workflow.Running,
+		},
+		{
+			name:            "Error: WaveChecks fail after the canary",
+			ramp:            &workflow.Ramp{Canary: 1},
+			seqs:            seqs(3),
+			waveErr:         errors.New("error"),
+			wantPluginCalls: 1,
+			wantWaveChecks:  1,
+			wantStatus:      workflow.Failed,
+			wantErr:         true,
+		},
+	}
+
+	plug := &plugins.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	for _, test := range tests {
+		plug.ResetCounts()
+
+		waveChecks := atomic.Int64{}
+		states := States{
+			registry: reg,
+			store:    &fakeUpdater{},
+			testChecksRunner: func(ctx context.Context, checks *workflow.Checks) error {
+				waveChecks.Add(1)
+				return test.waveErr
+			},
+		}
+
+		b := &workflow.Block{Concurrency: 4, Ramp: test.ramp, WaveChecks: &workflow.Checks{}, Sequences: test.seqs}
+		b.State.Set(workflow.State{Status: workflow.Running, Start: states.now()})
+		for _, seq := range b.Sequences {
+			seq.State.Set(workflow.State{})
+			for _, action := range seq.Actions {
+				action.State.Set(workflow.State{})
+			}
+		}
+		if test.started != workflow.NotStarted {
+			b.Sequences[1].State.Set(workflow.State{Status: test.started})
+		}
+
+		req := statemachine.Request[Data]{Ctx: context.Background()}
+		req.Data.blocks = []block{{block: b}}
+		req = states.ExecuteSequences(req)
+
+		if got := b.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestExecuteSequencesRamp(%s): got status == %v, want == %v", test.name, got, test.wantStatus)
+		}
+		switch {
+		case test.wantErr && req.Data.err == nil:
+			t.Errorf("TestExecuteSequencesRamp(%s): got err == nil, want err != nil", test.name)
+		case !test.wantErr && req.Data.err != nil:
+			t.Errorf("TestExecuteSequencesRamp(%s): got err == %s, want err == nil", test.name, req.Data.err)
+		}
+		if plug.Calls.Load() != int64(test.wantPluginCalls) {
+			t.Errorf("TestExecuteSequencesRamp(%s): got plugin calls == %v, want == %v", test.name, plug.Calls.Load(), test.wantPluginCalls)
+		}
+		if waveChecks.Load() != int64(test.wantWaveChecks) {
+			t.Errorf("TestExecuteSequencesRamp(%s): got WaveChecks runs == %v, want == %v", test.name, waveChecks.Load(), test.wantWaveChecks)
+		}
+
+		// Every sequence in a wave must start after the sequences of the wave before it ended and the Soak passed.
+		start := 0
+		for w, end := range test.wantWaves {
+			if w == 0 {
+				start = end
+				continue
+			}
+			for _, prev := range b.Sequences[:start] {
+				prevEnd := prev.State.Get().End
+				for i, seq := range b.Sequences[start:end] {
+					if seq.State.Get().Status != workflow.Completed || test.started != workflow.NotStarted {
+						continue
+					}
+					if got := seq.State.Get().Start.Sub(prevEnd); got < test.ramp.Soak {
+						t.Errorf("TestExecuteSequencesRamp(%s): sequence[%d] started %v after the wave before it ended, want at least %v", test.name, start+i, got, test.ramp.Soak)
+					}
+				}
+			}
+			start = end
+		}
+	}
+}
@@ -3,6 +3,7 @@ package sm
 import (
 	"time"
 
+	"github.com/element-of-surprise/coercion/internal/execute/sm/spans"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/context"
 	"github.com/gostdlib/base/statemachine"
@@ -21,6 +22,8 @@ func (s *States) Recovery(req statemachine.Request[Data]) statemachine.Request[D
 
 	plan := req.Data.Plan
 	req.Data.recovered = true
+	req.Ctx = withEvents(req.Ctx, req.Data.Events)
+	req.Ctx = withApprovals(req.Ctx, req.Data.Approvals)
 
 	s.fixPlan(plan)
 	if err := s.store.UpdatePlan(req.Ctx, plan); err != nil {
@@ -30,19 +33,31 @@ func (s *States) Recovery(req statemachine.Request[Data]) statemachine.Request[D
 	case workflow.NotStarted:
 		req.Next = nil
 		return req
-	case workflow.Completed, workflow.Failed, workflow.Stopped:
+	case workflow.Completed, workflow.Skipped, workflow.Failed, workflow.Stopped:
 		req.Next = s.End
 		return req
 	}
 	// Okay, we are in the running state. Let's setup to run.
 
 	req.Ctx = context.SetPlanID(req.Ctx, req.Data.Plan.ID)
+	req.Ctx = withKeys(req.Ctx, plan)
+	req.Ctx, req.Data.span = spans.StartPlan(req.Ctx, s.tracerProvider, plan, true)
+	s.metrics.PlanStarted(req.Ctx, true)
+	req.Data.metered = true
+	if plan.State.Get().Status == workflow.Stopping {
+		// We crashed while stopping. Every state will see the Stop and we finish stopping, which
+		// still runs the DeferredActions and DeferredChecks.
+		req.Ctx = setStopping(req.Ctx)
+	} else {
+		req = s.watchStop(req)
+	}
+	req.Ctx, req.Data.stopTimer = s.withTimeout(req.Ctx, plan.State.Get().Start, plan.Timeout)
 
 	// Setup our internal block objects that are used to track the state of the blocks.
 	for _, b := range req.Data.Plan.Blocks {
-		req.Data.blocks = append(req.Data.blocks, block{block: b, contCheckResult: make(chan error, 1)})
+		req.Data.blocks = append(req.Data.blocks, block{block: b, contCheckResult: newContResult()})
 	}
-	req.Data.contCheckResult = make(chan error, 1)
+	req.Data.contCheckResult = newContResult()
 
 	req.Next = s.PlanBypassChecks
 	return req
@@ -152,13 +167,14 @@ func fixChecks(c *workflow.Checks) {
 		state.Status = workflow.Failed
 		state.End = time.Now()
 		c.State.Set(state)
-	case completed == len(c.Actions):
+	case completed == len(c.Actions) && c.SoakDuration == 0:
 		state := c.State.Get()
 		state.Status = workflow.Completed
 		state.End = time.Now()
 		c.State.Set(state)
 	default:
-		// If we get here, checks are Running but haven't completed yet (or have no actions).
+		// If we get here, checks are Running but haven't completed yet (or have no actions),
+		// or they were between the rounds of a soak, which starts again.
 		// If there are no failures or stops, reset all actions and the checks to NotStarted
 		// so they can run fresh after recovery.
 		for _, a := range c.Actions {
@@ -168,19 +184,23 @@ func fixChecks(c *workflow.Checks) {
 	}
 }
 
-func fixSeq(s *workflow.Sequence) {
-	if s.State.Get().Status != workflow.Running {
+// fixSeq resolves a Sequence that was Running at crash time. A Sequence that failed or completed
+// but whose Undos or DeferredActions have not finished is left Running so that execSeq finishes them.
+// A Sequence that failed with Retries left is also left Running so that execSeq retries it.
+func (s *States) fixSeq(seq *workflow.Sequence) {
+	if seq.State.Get().Status != workflow.Running {
 		return
 	}
+	s.fixDeferredActions(seq.DeferredActions)
 
 	stopped := 0
-	for _, a := range s.Actions {
+	for _, a := range seq.Actions {
 		if a.State.Get().Status == workflow.Stopped {
 			stopped++
 		}
 	}
 	if stopped > 0 {
-		for _, a := range s.Actions {
+		for _, a := range seq.Actions {
 			if a.State.Get().Status == workflow.Running {
 				state := a.State.Get()
 				state.Status = workflow.Stopped
@@ -188,20 +208,23 @@ func fixSeq(s *workflow.Sequence) {
 				a.State.Set(state)
 			}
 		}
-		state := s.State.Get()
+		state := seq.State.Get()
 		state.Status = workflow.Stopped
 		state.End = time.Now()
-		s.State.Set(state)
+		seq.State.Set(state)
 		return
 	}
 
 	completed := 0
 	running := 0
 	failed := 0
-	for _, a := range s.Actions {
+	for _, a := range seq.Actions {
 		fixAction(a)
+		if a.Undo != nil {
+			fixAction(a.Undo)
+		}
 		switch a.State.Get().Status {
-		case workflow.Completed:
+		case workflow.Completed, workflow.Skipped:
 			completed++
 		case workflow.Running:
 			running++
@@ -214,22 +237,28 @@ func fixSeq(s *workflow.Sequence) {
 
 	switch {
 	case stopped > 0:
-		state := s.State.Get()
+		state := seq.State.Get()
 		state.Status = workflow.Stopped
 		state.End = time.Now()
-		s.State.Set(state)
+		seq.State.Set(state)
 	case failed > 0:
-		state := s.State.Get()
+		if !deferredActionsTerminal(seq.DeferredActions) || !undosFinished(seq.Actions) || retriesLeft(seq) {
+			return
+		}
+		state := seq.State.Get()
 		state.Status = workflow.Failed
 		state.End = time.Now()
-		s.State.Set(state)
+		seq.State.Set(state)
 	case completed == 0 && running == 0:
-		s.State.Set(workflow.State{Status: workflow.NotStarted})
-	case completed == len(s.Actions):
-		state := s.State.Get()
+		seq.State.Set(workflow.State{Status: workflow.NotStarted})
+	case completed == len(seq.Actions):
+		if !deferredActionsTerminal(seq.DeferredActions) {
+			return
+		}
+		state := seq.State.Get()
 		state.Status = workflow.Completed
 		state.End = time.Now()
-		s.State.Set(state)
+		seq.State.Set(state)
 	}
 }
 
@@ -395,11 +424,12 @@ func (s *States) fixBlock(b *workflow.Block) {
 	if b.State.Get().Status != workflow.Running {
 		return
 	}
+	s.fixDeferredActions(b.DeferredActions)
 	if b.BypassChecks != nil {
 		fixChecks(b.BypassChecks)
 		if b.BypassChecks.State.Get().Status == workflow.Completed {
 			state := b.State.Get()
-			state.Status = workflow.Completed
+			state.Status = workflow.Skipped
 			b.State.Set(state)
 			return
 		}
@@ -431,12 +461,21 @@ func (s *States) fixBlock(b *workflow.Block) {
 		}
 		fixChecks(b.PostChecks)
 	}
+	if b.WaveChecks != nil {
+		if b.WaveChecks.State.Get().Status == workflow.Failed {
+			state := b.State.Get()
+			state.Status = workflow.Failed
+			b.State.Set(state)
+			return
+		}
+		fixChecks(b.WaveChecks)
+	}
 
 	var completed, failed, stopped, running int
 	for _, seq := range b.Sequences {
-		fixSeq(seq)
+		s.fixSeq(seq)
 		switch seq.State.Get().Status {
-		case workflow.Completed:
+		case workflow.Completed, workflow.Skipped:
 			completed++
 		case workflow.Failed:
 			failed++
@@ -470,14 +509,14 @@ func (s *States) fixBlock(b *workflow.Block) {
 }
 
 func (s *States) fixPlan(p *workflow.Plan) {
-	if p.State.Get().Status != workflow.Running {
+	if !p.State.Get().Status.Active() {
 		return
 	}
 	if p.BypassChecks != nil {
 		fixChecks(p.BypassChecks)
 		if checksCompleted(p.BypassChecks) {
 			state := p.State.Get()
-			state.Status = workflow.Completed
+			state.Status = workflow.Skipped
 			p.State.Set(state)
 			return
 		}
@@ -525,7 +564,7 @@ func (s *States) fixPlan(p *workflow.Plan) {
 			return
 		}
 		switch b.State.Get().Status {
-		case workflow.Completed:
+		case workflow.Completed, workflow.Skipped:
 			completed++
 		case workflow.Running:
 			running++
@@ -533,7 +572,9 @@ func (s *States) fixPlan(p *workflow.Plan) {
 			failed++
 		}
 	}
-	if failed > 0 {
+	// Blocks that run at the same time may still be Running after one has failed. Those are recovered
+	// and the Plan fails once they end.
+	if failed > 0 && running == 0 {
 		state := p.State.Get()
 		state.Status = workflow.Failed
 		state.End = time.Now()
@@ -585,7 +626,7 @@ func skipRecoveredChecks(c *workflow.Checks) bool {
 	return false
 }
 
-// skipBlock returns true if the block is completed and should be skipped.
+// skipBlock returns true if the block has finished, including being Skipped, and should not be executed.
 // This does not cover all states, because the Plan should have been fixed before this is called.
 func skipBlock(b block) bool {
 	return isCompleted(b.block)
@@ -601,7 +642,7 @@ func isCompleted(o workflow.Object) bool {
 		return false
 	}
 	switch state.GetState().Status {
-	case workflow.Completed, workflow.Failed, workflow.Stopped:
+	case workflow.Completed, workflow.Skipped, workflow.Failed, workflow.Stopped:
 		return true
 	}
 	return false
@@ -9,6 +9,7 @@ import (
 	"github.com/element-of-surprise/coercion/plugins/registry"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
+	"github.com/gostdlib/base/statemachine"
 	"github.com/kylelemons/godebug/pretty"
 )
 
@@ -116,6 +117,19 @@ func TestFixChecks(t *testing.T) {
 			checks: newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now, End: now}, []*workflow.Action{newActionWithStateAndAttempts(&workflow.State{Status: workflow.Running, Start: now}, []workflow.Attempt{{Start: now}})}),
 			want:   newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.NotStarted}, []*workflow.Action{newActionWithStateAndAttempts(&workflow.State{Status: workflow.NotStarted}, nil)}),
 		},
+		{
+			name: "soaking checks between rounds, resets",
+			checks: func() *workflow.Checks {
+				c := newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed, Start: now, End: now.Add(1)}, []workflow.Attempt{{Start: now, End: now.Add(1)}})})
+				c.SoakDuration = time.Minute
+				return c
+			}(),
+			want: func() *workflow.Checks {
+				c := newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.NotStarted}, []*workflow.Action{newActionWithStateAndAttempts(&workflow.State{Status: workflow.NotStarted}, nil)})
+				c.SoakDuration = time.Minute
+				return c
+			}(),
+		},
 	}
 
 	for _, test := range tests {
@@ -214,10 +228,84 @@ func TestFixSeq(t *testing.T) {
 				newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed}, nil),
 			}),
 		},
+		{
+			name: "running sequence, actions completed, unfinished DeferredActions, stays running",
+			seq: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed}, nil),
+				})
+				seq.DeferredActions = newDA(newDABatch(workflow.Always, false, "ok"))
+				seq.DeferredActions.State.Set(workflow.State{Status: workflow.Running, Start: now})
+				return seq
+			}(),
+			want: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed}, nil),
+				})
+				seq.DeferredActions = newDA(newDABatch(workflow.Always, false, "ok"))
+				return seq
+			}(),
+		},
+		{
+			name: "running sequence, action failed, Retries left, stays running",
+			seq: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+				seq.Retries = 1
+				return seq
+			}(),
+			want: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+				seq.Retries = 1
+				return seq
+			}(),
+		},
+		{
+			name: "running sequence, action failed, Retries used, fails",
+			seq: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+				seq.Retries = 1
+				seq.Attempts.Set([]workflow.SeqAttempt{{}})
+				return seq
+			}(),
+			want: func() *workflow.Sequence {
+				seq := newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Failed, Start: now}, []*workflow.Action{
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+				seq.Retries = 1
+				seq.Attempts.Set([]workflow.SeqAttempt{{}})
+				return seq
+			}(),
+		},
+		{
+			name: "running sequence, action failed, unfinished Undo, stays running",
+			seq: func() *workflow.Sequence {
+				a := newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed}, nil)
+				a.Undo = newActionWithStateAndAttempts(&workflow.State{Status: workflow.Running}, nil)
+				return newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					a,
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+			}(),
+			want: func() *workflow.Sequence {
+				a := newActionWithStateAndAttempts(&workflow.State{Status: workflow.Completed}, nil)
+				a.Undo = newActionWithStateAndAttempts(&workflow.State{Status: workflow.NotStarted}, nil)
+				return newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.Running, Start: now}, []*workflow.Action{
+					a,
+					newActionWithStateAndAttempts(&workflow.State{Status: workflow.Failed}, nil),
+				})
+			}(),
+		},
 	}
 
 	for _, test := range tests {
-		fixSeq(test.seq)
+		s := &States{}
+		s.fixSeq(test.seq)
 		if test.seq.State.Get().Status == workflow.Stopped {
 			if test.seq.State.Get().End.IsZero() {
 				t.Errorf("TestFixSeq(%s): got seq.State.End == 0, want non-zero", test.name)
@@ -247,6 +335,9 @@ func TestFixSeq(t *testing.T) {
 		if diff := pConfig.Compare(test.want, test.seq); diff != "" {
 			t.Errorf("TestFixSeq(%s): -want/+got:\n%s", test.name, diff)
 		}
+		if got, want := test.seq.State.Get().Status, test.want.State.Get().Status; got != want {
+			t.Errorf("TestFixSeq(%s): got status %v, want %v", test.name, got, want)
+		}
 	}
 }
 
@@ -317,11 +408,11 @@ func TestFixBlock(t *testing.T) {
 			}, nil, nil, nil, nil),
 		},
 		{
-			name: "running block, bypass checks completed, block state completed",
+			name: "running block, bypass checks completed, block state skipped",
 			b: newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Running}, []*workflow.Sequence{
 				newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.NotStarted}, nil),
 			}, newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Completed}, nil), nil, nil, nil),
-			want: newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Completed}, []*workflow.Sequence{
+			want: newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Skipped}, []*workflow.Sequence{
 				newSequenceWithStateAndActionsRecov(&workflow.State{Status: workflow.NotStarted}, nil),
 			}, newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Completed}, nil), nil, nil, nil),
 		},
@@ -352,9 +443,9 @@ func TestFixPlan(t *testing.T) {
 			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Completed}, nil, nil, nil, nil, nil, nil),
 		},
 		{
-			name: "running plan, bypass checks completed, plan completes",
+			name: "running plan, bypass checks completed, plan skipped",
 			plan: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Running}, nil, newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Completed}, nil), nil, nil, nil, nil),
-			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Completed}, nil, newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Completed}, nil), nil, nil, nil, nil),
+			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Skipped}, nil, newChecksWithStateAndActionsRecov(&workflow.State{Status: workflow.Completed}, nil), nil, nil, nil, nil),
 		},
 		{
 			name: "running plan, prechecks failed, plan fails",
@@ -383,6 +474,29 @@ func TestFixPlan(t *testing.T) {
 				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Completed}, nil, nil, nil, nil, nil),
 			}, nil, nil, nil, nil, nil),
 		},
+		{
+			name: "stopping plan, block failed, plan fails",
+			plan: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Stopping}, []*workflow.Block{
+				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Failed}, nil, nil, nil, nil, nil),
+			}, nil, nil, nil, nil, nil),
+			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Failed}, nil, nil, nil, nil, nil, nil),
+		},
+		{
+			name: "stopping plan, blocks not finished, plan is still stopping",
+			plan: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Stopping}, []*workflow.Block{
+				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Completed}, nil, nil, nil, nil, nil),
+				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.NotStarted}, nil, nil, nil, nil, nil),
+			}, nil, nil, nil, nil, nil),
+			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Stopping}, nil, nil, nil, nil, nil, nil),
+		},
+		{
+			name: "paused plan, blocks not finished, plan is still paused",
+			plan: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Paused}, []*workflow.Block{
+				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Completed}, nil, nil, nil, nil, nil),
+				newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.NotStarted}, nil, nil, nil, nil, nil),
+			}, nil, nil, nil, nil, nil),
+			want: newPlanWithStateBlocksChecks(&workflow.State{Status: workflow.Paused}, nil, nil, nil, nil, nil, nil),
+		},
 	}
 
 	for _, test := range tests {
@@ -398,6 +512,37 @@ func TestFixPlan(t *testing.T) {
 	}
 }
 
+func TestRecoveryStopping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      workflow.Status
+		wantStopped bool
+	}{
+		{name: "Running plan does not see a Stop", status: workflow.Running},
+		{name: "Stopping plan finishes stopping", status: workflow.Stopping, wantStopped: true},
+	}
+
+	for _, test := range tests {
+		plan := newPlanWithStateBlocksChecks(&workflow.State{Status: test.status}, []*workflow.Block{
+			newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.Completed}, nil, nil, nil, nil, nil),
+			newBlockWithStateSeqsChecks(&workflow.State{Status: workflow.NotStarted}, nil, nil, nil, nil, nil),
+		}, nil, nil, nil, nil, nil)
+
+		s := &States{store: &fakeUpdater{}}
+		req := s.Recovery(statemachine.Request[Data]{Ctx: context.Background(), Data: Data{Plan: plan}})
+		req.Data.stopWatch.close()
+
+		if got := stopRequested(req.Ctx); got != test.wantStopped {
+			t.Errorf("TestRecoveryStopping(%s): got stopRequested == %v, want %v", test.name, got, test.wantStopped)
+		}
+		if methodName(req.Next) != methodName(s.PlanBypassChecks) {
+			t.Errorf("TestRecoveryStopping(%s): got next %v, want %v", test.name, methodName(req.Next), methodName(s.PlanBypassChecks))
+		}
+	}
+}
+
 func TestChecksFailed(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,138 @@
+package sm
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// retrySeq readies a Sequence that failed for another run if it has retries left. The Undos of the run
+// are run, then it waits for the RetryPolicy's backoff, records the run in the Sequence's Attempts and
+// resets its Actions. It returns false if the Sequence should not be retried. That is if it has no retries
+// left, an Undo failed or the wait was cut short by a Stop, a Timeout or the Context being cancelled.
+func (s *States) retrySeq(ctx context.Context, seq *workflow.Sequence) bool {
+	if !retriesLeft(seq) || stopRequested(ctx) || timedOut(ctx) {
+		return false
+	}
+	retry := len(seq.Attempts.Get()) + 1
+	end := s.now()
+
+	if err := s.runUndos(ctx, seq.Actions); err != nil {
+		return false
+	}
+
+	policy := workflow.DefaultRetryPolicy()
+	if seq.RetryPolicy != nil {
+		policy = *seq.RetryPolicy
+	}
+	if err := after(ctx, retryDelay(policy, retry)); err != nil {
+		return false
+	}
+
+	seq.Attempts.Append(seqAttempt(seq, end))
+	if err := s.store.UpdateSequence(ctx, seq); err != nil {
+		log.Fatalf("failed to write Sequence: %v", err)
+	}
+	for _, a := range seq.Actions {
+		resetActions([]*workflow.Action{a})
+		if err := s.store.UpdateAction(ctx, a); err != nil {
+			log.Fatalf("failed to write Action: %v", err)
+		}
+		if a.Undo != nil {
+			resetActions([]*workflow.Action{a.Undo})
+			if err := s.store.UpdateAction(ctx, a.Undo); err != nil {
+				log.Fatalf("failed to write Action: %v", err)
+			}
+		}
+	}
+	return true
+}
+
+// retriesLeft reports if the Sequence can be retried again.
+func retriesLeft(seq *workflow.Sequence) bool {
+	return len(seq.Attempts.Get()) < seq.Retries
+}
+
+// seqAttempt records the current run of seq, which ended at end. The run started when its first
+// Action started, which is the Start of the Sequence for the first run.
+func seqAttempt(seq *workflow.Sequence, end time.Time) workflow.SeqAttempt {
+	return runAttempt(seq.Actions, seq.State.Get().Start, end)
+}
+
+// runAttempt records the current run of actions, which ended at end. The run started when its first
+// Action started, or at start if no Action started.
+func runAttempt(actions []*workflow.Action, start, end time.Time) workflow.SeqAttempt {
+	sa := workflow.SeqAttempt{
+		Actions: make([]workflow.ActionResult, 0, len(actions)),
+		End:     end,
+	}
+	for _, a := range actions {
+		state := a.State.Get()
+		sa.Actions = append(
+			sa.Actions,
+			workflow.ActionResult{
+				ID:       a.ID,
+				Status:   state.Status,
+				Attempts: len(a.Attempts.Get()),
+				Err:      a.FinalAttempt().Err,
+				Start:    state.Start,
+				End:      state.End,
+			},
+		)
+		if !state.Start.IsZero() && (sa.Start.IsZero() || state.Start.Before(sa.Start)) {
+			sa.Start = state.Start
+		}
+	}
+	if sa.Start.IsZero() {
+		sa.Start = start
+	}
+	return sa
+}
+
+// retryChecks readies WaitUntil Checks whose round of Actions failed for another round. It waits for the
+// Checks' Delay, records the round in the Checks' Attempts and resets its Actions. It returns false if the
+// Checks should not be run again. That is if the Checks have no WaitUntil, the next round would start after
+// WaitUntil has passed or the wait was cut short by a Stop, a Timeout or the Context being cancelled.
+func (s *States) retryChecks(ctx context.Context, checks *workflow.Checks) bool {
+	if checks.WaitUntil <= 0 || stopRequested(ctx) || timedOut(ctx) {
+		return false
+	}
+	start := checks.State.Get().Start
+	end := s.now()
+	if end.Add(checks.Delay).After(start.Add(checks.WaitUntil)) {
+		return false
+	}
+
+	if err := after(ctx, checks.Delay); err != nil {
+		return false
+	}
+
+	checks.Attempts.Append(runAttempt(checks.Actions, start, end))
+	if err := s.store.UpdateChecks(ctx, checks); err != nil {
+		log.Fatalf("failed to write Checks: %v", err)
+	}
+	for _, a := range checks.Actions {
+		resetActions([]*workflow.Action{a})
+		if err := s.store.UpdateAction(ctx, a); err != nil {
+			log.Fatalf("failed to write Action: %v", err)
+		}
+	}
+	return true
+}
+
+// retryDelay returns how long to wait before retry number retry of a Sequence, starting with 1.
+func retryDelay(policy workflow.RetryPolicy, retry int) time.Duration {
+	d := float64(policy.InitialInterval)
+	for i := 1; i < retry && d < float64(policy.MaxInterval); i++ {
+		d *= policy.Multiplier
+	}
+	d = min(d, float64(policy.MaxInterval))
+	if policy.RandomizationFactor > 0 {
+		delta := policy.RandomizationFactor * d
+		d += delta * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,278 @@
+package sm
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gostdlib/base/context"
+
+	"github.com/element-of-surprise/coercion/plugins"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+)
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := workflow.RetryPolicy{
+		InitialInterval: time.Second,
+		Multiplier:      2,
+		MaxInterval:     5 * time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		policy workflow.RetryPolicy
+		retry  int
+		min    time.Duration
+		max    time.Duration
+	}{
+		{name: "first retry", policy: policy, retry: 1, min: time.Second, max: time.Second},
+		{name: "second retry", policy: policy, retry: 2, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "third retry", policy: policy, retry: 3, min: 4 * time.Second, max: 4 * time.Second},
+		{name: "capped at MaxInterval", policy: policy, retry: 10, min: 5 * time.Second, max: 5 * time.Second},
+		{
+			name: "randomized",
+			policy: func() workflow.RetryPolicy {
+				p := policy
+				p.RandomizationFactor = 0.5
+				return p
+			}(),
+			retry: 2,
+			min:   time.Second,
+			max:   3 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		got := retryDelay(test.policy, test.retry)
+		if got < test.min || got > test.max {
+			t.Errorf("TestRetryDelay(%s): got %v, want between %v and %v", test.name, got, test.min, test.max)
+		}
+	}
+}
+
+func TestSeqAttempt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	pErr := &plugins.Error{Message: "error"}
+
+	completed := &workflow.Action{ID: workflow.NewV7()}
+	completed.Attempts.Set([]workflow.Attempt{{Start: now, End: now.Add(time.Second)}})
+	completed.State.Set(workflow.State{Status: workflow.Completed, Start: now, End: now.Add(time.Second)})
+
+	failed := &workflow.Action{ID: workflow.NewV7()}
+	failed.Attempts.Set([]workflow.Attempt{{Err: pErr}, {Err: pErr}})
+	failed.State.Set(workflow.State{Status: workflow.Failed, Start: now.Add(time.Second), End: now.Add(2 * time.Second)})
+
+	notStarted := &workflow.Action{ID: workflow.NewV7()}
+	notStarted.State.Set(workflow.State{Status: workflow.NotStarted})
+
+	seq := &workflow.Sequence{Actions: []*workflow.Action{completed, failed, notStarted}}
+	seq.State.Set(workflow.State{Status: workflow.Running, Start: now.Add(-time.Second)})
+
+	end := now.Add(3 * time.Second)
+	got := seqAttempt(seq, end)
+
+	want := workflow.SeqAttempt{
+		Actions: []workflow.ActionResult{
+			{ID: completed.ID, Status: workflow.Completed, Attempts: 1, Start: now, End: now.Add(time.Second)},
+			{ID: failed.ID, Status: workflow.Failed, Attempts: 2, Err: pErr, Start: now.Add(time.Second), End: now.Add(2 * time.Second)},
+			{ID: notStarted.ID, Status: workflow.NotStarted},
+		},
+		Start: now,
+		End:   end,
+	}
+	if !got.Equal(want) {
+		t.Errorf("TestSeqAttempt: got %+v, want %+v", got, want)
+	}
+}
+
+func TestExecSeqRetries(t *testing.T) {
+	t.Parallel()
+
+	policy := &workflow.RetryPolicy{InitialInterval: time.Millisecond, Multiplier: 1, MaxInterval: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		actions      []*workflow.Action
+		retries      int
+		failures     int
+		wantErr      bool
+		wantStatus   workflow.Status
+		wantAttempts int
+		wantRuns     []string
+	}{
+		{
+			name:       "Success: no retries needed",
+			actions:    []*workflow.Action{newUndoAction("a", workflow.NotStarted, time.Time{}, ""), newUndoAction("flaky", workflow.NotStarted, time.Time{}, "")},
+			retries:    2,
+			wantStatus: workflow.Completed,
+			wantRuns:   []string{"a", "flaky"},
+		},
+		{
+			name:         "Success: retry after a failure",
+			actions:      []*workflow.Action{newUndoAction("a", workflow.NotStarted, time.Time{}, "undo-a"), newUndoAction("flaky", workflow.NotStarted, time.Time{}, "")},
+			retries:      2,
+			failures:     1,
+			wantStatus:   workflow.Completed,
+			wantAttempts: 1,
+			wantRuns:     []string{"a", "flaky", "undo-a", "a", "flaky"},
+		},
+		{
+			name:         "Error: retries exhausted",
+			actions:      []*workflow.Action{newUndoAction("a", workflow.NotStarted, time.Time{}, ""), newUndoAction("flaky", workflow.NotStarted, time.Time{}, "")},
+			retries:      1,
+			failures:     3,
+			wantErr:      true,
+			wantStatus:   workflow.Failed,
+			wantAttempts: 1,
+			wantRuns:     []string{"a", "flaky", "a", "flaky"},
+		},
+		{
+			name:       "Error: failed Undo stops retries",
+			actions:    []*workflow.Action{newUndoAction("a", workflow.NotStarted, time.Time{}, "error"), newUndoAction("flaky", workflow.NotStarted, time.Time{}, "")},
+			retries:    2,
+			failures:   1,
+			wantErr:    true,
+			wantStatus: workflow.Failed,
+			wantRuns:   []string{"a", "flaky", "error"},
+		},
+	}
+
+	for _, test := range tests {
+		rec := &undoRecorder{now: time.Now(), fail: map[string]int{"flaky": test.failures}}
+		// Like runAction, an Action that already finished is not run again.
+		runner := func(ctx context.Context, action *workflow.Action, updater storage.ActionUpdater) error {
+			switch action.State.Get().Status {
+			case workflow.Completed:
+				return nil
+			case workflow.Failed:
+				return fmt.Errorf("error")
+			}
+			return rec.run(ctx, action, updater)
+		}
+
+		seq := newSequenceWithState("seq", test.actions, &workflow.State{})
+		seq.Retries = test.retries
+		seq.RetryPolicy = policy
+		states := &States{
+			store:            &fakeUpdater{},
+			testActionRunner: runner,
+		}
+
+		err := states.execSeq(context.Background(), seq)
+		if (err != nil) != test.wantErr {
+			t.Errorf("TestExecSeqRetries(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if got := seq.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestExecSeqRetries(%s): got seq status == %v, want %v", test.name, got, test.wantStatus)
+		}
+		if got := len(seq.Attempts.Get()); got != test.wantAttempts {
+			t.Errorf("TestExecSeqRetries(%s): got %d Attempts, want %d", test.name, got, test.wantAttempts)
+		}
+		for _, attempt := range seq.Attempts.Get() {
+			if got := attempt.Actions[1].Status; got != workflow.Failed {
+				t.Errorf("TestExecSeqRetries(%s): got recorded flaky status == %v, want %v", test.name, got, workflow.Failed)
+			}
+		}
+		if !slices.Equal(rec.runs, test.wantRuns) {
+			t.Errorf("TestExecSeqRetries(%s): got runs %v, want %v", test.name, rec.runs, test.wantRuns)
+		}
+	}
+}
+
+func TestRunChecksOnceWaitUntil(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		waitUntil    time.Duration
+		failures     int
+		wantErr      bool
+		wantStatus   workflow.Status
+		wantRounds   int
+		wantAttempts int
+	}{
+		{
+			name:       "Error: no WaitUntil fails on the first failed round",
+			failures:   1,
+			wantErr:    true,
+			wantStatus: workflow.Failed,
+			wantRounds: 1,
+		},
+		{
+			name:         "Success: rounds until the checks pass",
+			waitUntil:    time.Minute,
+			failures:     2,
+			wantStatus:   workflow.Completed,
+			wantRounds:   3,
+			wantAttempts: 2,
+		},
+		{
+			name:       "Error: WaitUntil passes before the checks pass",
+			waitUntil:  50 * time.Millisecond,
+			failures:   1000,
+			wantErr:    true,
+			wantStatus: workflow.Failed,
+		},
+	}
+
+	for _, test := range tests {
+		rounds := 0
+		runner := func(ctx context.Context, actions []*workflow.Action) error {
+			rounds++
+			status := workflow.Completed
+			if rounds <= test.failures {
+				status = workflow.Failed
+			}
+			for _, a := range actions {
+				a.State.Set(workflow.State{Status: status, Start: time.Now(), End: time.Now()})
+			}
+			if status == workflow.Failed {
+				return fmt.Errorf("error")
+			}
+			return nil
+		}
+
+		checks := &workflow.Checks{
+			Delay:     10 * time.Millisecond,
+			WaitUntil: test.waitUntil,
+			Actions:   []*workflow.Action{{ID: workflow.NewV7(), Name: "ready"}},
+		}
+		states := &States{
+			store:                     &fakeUpdater{},
+			testActionsParallelRunner: runner,
+		}
+
+		err := states.runChecksOnce(context.Background(), checks)
+		if (err != nil) != test.wantErr {
+			t.Errorf("TestRunChecksOnceWaitUntil(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if got := checks.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestRunChecksOnceWaitUntil(%s): got status == %v, want %v", test.name, got, test.wantStatus)
+		}
+		attempts := checks.Attempts.Get()
+		// When WaitUntil passes, the number of rounds depends on timing, but every round but the last is recorded.
+		if test.wantRounds == 0 {
+			if rounds < 2 || len(attempts) != rounds-1 {
+				t.Errorf("TestRunChecksOnceWaitUntil(%s): got %d rounds and %d Attempts, want more than 1 round and an Attempt for each but the last", test.name, rounds, len(attempts))
+			}
+			continue
+		}
+		if rounds != test.wantRounds {
+			t.Errorf("TestRunChecksOnceWaitUntil(%s): got %d rounds, want %d", test.name, rounds, test.wantRounds)
+		}
+		if len(attempts) != test.wantAttempts {
+			t.Errorf("TestRunChecksOnceWaitUntil(%s): got %d Attempts, want %d", test.name, len(attempts), test.wantAttempts)
+		}
+		for _, attempt := range attempts {
+			if got := attempt.Actions[0].Status; got != workflow.Failed {
+				t.Errorf("TestRunChecksOnceWaitUntil(%s): got recorded status == %v, want %v", test.name, got, workflow.Failed)
+			}
+		}
+	}
+}
@@ -5,28 +5,51 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/element-of-surprise/coercion/internal/execute/sm/actions"
+	"github.com/element-of-surprise/coercion/internal/execute/sm/metrics"
+	"github.com/element-of-surprise/coercion/internal/execute/sm/spans"
+	"github.com/element-of-surprise/coercion/plugins"
 	"github.com/element-of-surprise/coercion/plugins/registry"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/context"
 	"github.com/element-of-surprise/coercion/workflow/storage"
 	"github.com/element-of-surprise/coercion/workflow/utils/walk"
 
+	"github.com/google/uuid"
 	"github.com/gostdlib/base/statemachine"
 	"github.com/gostdlib/base/telemetry/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var ErrInternalFailure = errors.New("internal failure")
+var (
+	ErrInternalFailure = errors.New("internal failure")
+	// ErrStopped is returned when work was not done because a Stop was requested.
+	ErrStopped = errors.New("stopped")
+	// ErrTimeout is returned when work was not done because the Plan or Block ran past its Timeout.
+	ErrTimeout = errors.New("timed out")
+	// ErrNotPausable is returned when a Plan cannot be paused because it is not Running.
+	ErrNotPausable = errors.New("plan is not running and cannot be paused")
+	// ErrNotApprovable is returned when an Approval does not exist or has already been decided.
+	ErrNotApprovable = errors.New("approval cannot be decided")
+	// ErrWindowClosed is returned when ContChecks fail because a maintenance window with StopOnClose closed.
+	ErrWindowClosed = errors.New("maintenance window closed")
+)
 
 // block is a wrapper around a workflow.Block that contains additional information for the statemachine.
 type block struct {
 	block *workflow.Block
+	// span is the span for the Block. It is the parent of the spans for the Block's children.
+	span trace.Span
 
 	contCancel      context.CancelFunc
-	contCheckResult chan error
+	contCheckResult *contResult
+	// stopTimer releases the timer for the Block's Timeout. This is nil until the Block starts.
+	stopTimer func()
 }
 
 // Data represents the data that is passed between states.
@@ -37,16 +60,38 @@ type Data struct {
 	// RecoveryStarted is the channel that will complete once recovery has finished updating the data store
 	// with the fixed state of the Plan and is off to execute the next state.
 	RecoveryStarted chan struct{}
+	// Stop is closed when a user requests that the Plan be stopped. If nil, the Plan cannot be stopped.
+	Stop <-chan struct{}
+	// Pause is used to pause and resume the Plan. If nil, the Plan cannot be paused.
+	Pause *Pauser
+	// Approvals is used to decide the Approvals in the Plan. If nil, the Plan cannot have Approvals.
+	Approvals *Approvals
+	// Events sends a workflow.Event for each change written to storage. If nil, no Events are sent.
+	Events *Events
 
 	// recovered indicates whether we are recovering a Plan after a crash.
 	recovered bool
-
-	// blocks is a list of blocks that are being executed. These are removed as each block is completed.
+	// stopped indicates that a Stop was requested and the statemachine stopped work on the Plan.
+	stopped bool
+	// stopWatch watches Stop for a Stop request.
+	stopWatch *stopWatch
+	// timedOut indicates that the Plan or a Block ran past its Timeout.
+	timedOut bool
+	// stopTimer releases the timer for the Plan's Timeout.
+	stopTimer func()
+	// span is the span for the Plan.
+	span trace.Span
+	// metered indicates the Plan was recorded as started in our metrics and must be recorded as ended.
+	metered bool
+
+	// blocks are the blocks of the Plan until ExecuteBlock runs them. In the statemachine of each Block,
+	// it only has that block.
 	blocks []block
 	// contCancel is the context.CancelFunc that will cancel the continuous check for the Plan.
 	contCancel context.CancelFunc
-	// contCheckResult is the channel that will receive the result of the continuous check for the Plan.
-	contCheckResult chan error
+	// contCheckResult is the result of the continuous check for the Plan. It is shared with the statemachine
+	// of each Block, so all of the Blocks that are running see a failure.
+	contCheckResult *contResult
 
 	err error
 }
@@ -54,24 +99,67 @@ type Data struct {
 // contChecks will check if any of the continuous checks on the Plan or the current block have failed.
 // If a check has failed, the type of the object that failed is returned (OTPlan or OTBlock).
 func (d Data) contChecksPassing() (workflow.ObjectType, error) {
+	if err := d.contCheckResult.failed(); err != nil {
+		return workflow.OTPlan, err
+	}
 	if len(d.blocks) == 0 {
-		select {
-		case err := <-d.contCheckResult:
-			return workflow.OTPlan, err
-		default:
-			return workflow.OTUnknown, nil
-		}
+		return workflow.OTUnknown, nil
 	}
-	select {
-	case err := <-d.contCheckResult:
-		return workflow.OTPlan, err
-	case err := <-d.blocks[0].contCheckResult:
+	if err := d.blocks[0].contCheckResult.failed(); err != nil {
 		return workflow.OTBlock, err
-	default:
 	}
 	return workflow.OTUnknown, nil
 }
 
+// contResult is the result of ContChecks. Every reader sees the result, which a channel of results
+// could only give to one of them.
+type contResult struct {
+	// done is closed once the ContChecks have ended.
+	done chan struct{}
+	once sync.Once
+	// err is why the ContChecks failed. It is set before done is closed.
+	err error
+}
+
+func newContResult() *contResult {
+	return &contResult{done: make(chan struct{})}
+}
+
+// end records that the ContChecks have ended with err, which is nil if they did not fail. Only the
+// first call has an effect.
+func (c *contResult) end(err error) {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+// failed returns the error the ContChecks failed with, or nil if they have not ended or did not fail.
+// It does not block.
+func (c *contResult) failed() error {
+	if c == nil {
+		return nil
+	}
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// wait waits for the ContChecks to end and returns the error they failed with.
+func (c *contResult) wait() error {
+	if c == nil {
+		return nil
+	}
+	<-c.done
+	return c.err
+}
+
 type nower func() time.Time
 
 // actionRunner is a function that runs an action. We use this to fake out the action runner in tests.
@@ -87,11 +175,18 @@ type actionsParallelRunner func(ctx context.Context, actions []*workflow.Action)
 type States struct {
 	store    storage.Vault
 	registry *registry.Register
+	// tracerProvider provides the trace.Tracer for the span of each Plan. If nil, the TracerProvider
+	// of the span in the Context is used.
+	tracerProvider trace.TracerProvider
+	// metrics records our metrics. This can be nil.
+	metrics *metrics.Metrics
 
 	actionsSM actions.Runner
 
 	// nower is the function that returns the current time. This is set to time.Now by default.
 	nower nower
+	// locks is the lock manager for the Locks of Blocks and Sequences across all Plans.
+	locks *lockTable
 
 	// testChecksRunner is the function that runs checks. If set, runChecksOnce calls this and returns.
 	// We use this to fake out the check runner in tests.
@@ -103,14 +198,38 @@ type States struct {
 	testActionRunner actionRunner
 }
 
-// New creates a new States statemachine.
-func New(store storage.Vault, registry *registry.Register) (*States, error) {
+// Option is an optional argument for New.
+type Option func(*States)
+
+// WithClock sets the function that returns the current time. This is the time that is recorded in the
+// State of objects and that maintenance windows are evaluated at. If not set, time.Now is used.
+func WithClock(now func() time.Time) Option {
+	return func(s *States) {
+		s.nower = now
+	}
+}
+
+// New creates a new States statemachine. tp is used to create a span for each Plan. If tp is nil,
+// the TracerProvider of the span in the Context of the Plan's run is used. Metrics are recorded
+// with the context.Meter of ctx.
+func New(ctx context.Context, store storage.Vault, registry *registry.Register, tp trace.TracerProvider, options ...Option) (*States, error) {
 	if store == nil {
 		return nil, fmt.Errorf("store is required")
 	}
+	m, err := metrics.New(context.Meter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not create metrics: %w", err)
+	}
 	s := &States{
-		store:    store,
-		registry: registry,
+		store:          eventStore{Vault: store},
+		registry:       registry,
+		tracerProvider: tp,
+		metrics:        m,
+		actionsSM:      actions.New(m),
+		locks:          newLockTable(),
+	}
+	for _, o := range options {
+		o(s)
 	}
 	return s, nil
 }
@@ -121,11 +240,17 @@ func (s *States) Start(req statemachine.Request[Data]) statemachine.Request[Data
 	plan := req.Data.Plan
 
 	req.Ctx = context.SetPlanID(req.Ctx, req.Data.Plan.ID)
+	req.Ctx = withEvents(req.Ctx, req.Data.Events)
+	req.Ctx = withApprovals(req.Ctx, req.Data.Approvals)
+	req.Ctx = withKeys(req.Ctx, plan)
+	req.Ctx, req.Data.span = spans.StartPlan(req.Ctx, s.tracerProvider, plan, false)
+	s.metrics.PlanStarted(req.Ctx, false)
+	req.Data.metered = true
 
 	for _, b := range req.Data.Plan.Blocks {
-		req.Data.blocks = append(req.Data.blocks, block{block: b, contCheckResult: make(chan error, 1)})
+		req.Data.blocks = append(req.Data.blocks, block{block: b, contCheckResult: newContResult()})
 	}
-	req.Data.contCheckResult = make(chan error, 1)
+	req.Data.contCheckResult = newContResult()
 
 	startTime := s.now()
 	state := plan.State.Get()
@@ -138,6 +263,9 @@ func (s *States) Start(req statemachine.Request[Data]) statemachine.Request[Data
 		log.Fatalf("failed to write Plan: %v", err)
 	}
 
+	req = s.watchStop(req)
+	req.Ctx, req.Data.stopTimer = s.withTimeout(req.Ctx, startTime, plan.Timeout)
+
 	// Copy req for the background goroutine to avoid race with req.Next assignment below
 	reqCopy := req
 	_ = context.Tasks(req.Ctx).Once(
@@ -163,7 +291,7 @@ func (s *States) updateLastUpdate(ctx context.Context, req statemachine.Request[
 		case <-ctx.Done():
 			return
 		case <-t.C:
-			if req.Data.Plan.State.Get().Status != workflow.Running {
+			if !req.Data.Plan.State.Get().Status.Active() {
 				return
 			}
 			s.runtimeUpdate(req.Ctx, req.Data.Plan)
@@ -183,7 +311,7 @@ func (s *States) runtimeUpdate(ctx context.Context, plan *workflow.Plan) {
 }
 
 // PlanBypassChecks runs all the gates on the Plan. If any of the gates fail,
-// or no gates are present, the Plan is executed.
+// or no gates are present, the Plan is executed. Otherwise the Plan and its Blocks are Skipped.
 func (s *States) PlanBypassChecks(req statemachine.Request[Data]) statemachine.Request[Data] {
 	defer func() {
 		if err := s.store.UpdatePlan(req.Ctx, req.Data.Plan); err != nil {
@@ -191,6 +319,12 @@ func (s *States) PlanBypassChecks(req statemachine.Request[Data]) statemachine.R
 		}
 	}()
 
+	if stopRequested(req.Ctx) {
+		req.Data.stopped = true
+		req.Next = s.PlanDeferredActions
+		return req
+	}
+
 	if skipRecoveredChecks(req.Data.Plan.BypassChecks) {
 		req.Next = s.PlanPreChecks
 		return req
@@ -198,8 +332,11 @@ func (s *States) PlanBypassChecks(req statemachine.Request[Data]) statemachine.R
 
 	skip := s.runBypasses(req.Ctx, req.Data.Plan.BypassChecks)
 	if skip {
-		if req.Data.contCheckResult != nil {
-			close(req.Data.contCheckResult)
+		req.Data.contCheckResult.end(nil)
+		for _, b := range req.Data.Plan.Blocks {
+			if b.State.Get().Status == workflow.NotStarted {
+				s.skip(req.Ctx, b)
+			}
 		}
 		req.Next = s.End
 		return req
@@ -216,6 +353,12 @@ func (s *States) PlanPreChecks(req statemachine.Request[Data]) statemachine.Requ
 		}
 	}()
 
+	if stopRequested(req.Ctx) {
+		req.Data.stopped = true
+		req.Next = s.PlanDeferredActions
+		return req
+	}
+
 	if skipRecoveredChecks(req.Data.Plan.PreChecks) {
 		req.Next = s.PlanStartContChecks
 		return req
@@ -242,67 +385,338 @@ func (s *States) PlanStartContChecks(req statemachine.Request[Data]) statemachin
 		context.Pool(req.Ctx).Submit(
 			ctx,
 			func() {
-				s.runContChecks(ctx, req.Data.Plan.ContChecks, req.Data.contCheckResult)
+				s.runContChecks(ctx, req.Data.Plan.ContChecks, req.Data.Plan.Windows, req.Data.contCheckResult)
 			},
 		)
 	} else {
-		close(req.Data.contCheckResult)
+		req.Data.contCheckResult.end(nil)
 	}
 
 	req.Next = s.ExecuteBlock
 	return req
 }
 
-// ExecuteBlock executes the current block.
+// ExecuteBlock runs the Blocks of the Plan. A Block starts once every Block in its DependsOn has finished,
+// in the order of the Plan, with at most the Plan's Concurrency Blocks running at a time. Each Block runs in
+// its own statemachine that starts at StartBlock. Once a Block fails, the ContChecks of the Plan fail, a Stop
+// is requested or the Plan runs past its Timeout, no more Blocks are started and we wait for the running
+// Blocks to end. A Block that is waiting for its maintenance windows or Locks is running, so the wait does
+// not hold up the other Blocks.
 func (s *States) ExecuteBlock(req statemachine.Request[Data]) statemachine.Request[Data] {
-	// No more blocks, the Plan is done.
-	if len(req.Data.blocks) == 0 {
+	limit := max(req.Data.Plan.Concurrency, 1)
+
+	byKey := map[uuid.UUID]*workflow.Block{}
+	for _, b := range req.Data.Plan.Blocks {
+		if b.Key != uuid.Nil {
+			byKey[b.Key] = b
+		}
+	}
+	// ended are the Blocks that have ended. A Block is not finished until its statemachine returns,
+	// as it is Completed before its ExitDelay.
+	ended := map[*workflow.Block]bool{}
+	finished := func(key uuid.UUID) bool {
+		b := byKey[key]
+		if !ended[b] {
+			return false
+		}
+		switch b.State.Get().Status {
+		case workflow.Completed, workflow.Skipped:
+			return true
+		}
+		return false
+	}
+
+	failed := false
+	pending := make([]block, 0, len(req.Data.blocks))
+	for _, h := range req.Data.blocks {
+		if h.block.State.Get().Status == workflow.Failed {
+			failed = true
+		}
+		if skipBlock(h) {
+			ended[h.block] = true
+			continue
+		}
+		pending = append(pending, h)
+	}
+
+	// haltCtx is cancelled once no more Blocks may start, so that Blocks waiting for their maintenance
+	// windows or Locks give up.
+	haltCtx, halt := context.WithCancel(req.Ctx)
+	defer halt()
+
+	results := make(chan blockResult, len(pending))
+	started := make([]bool, len(pending))
+	running := 0
+	for {
+		// The Blocks that are running see a failed ContCheck of the Plan themselves, but no more are started.
+		if err := req.Data.contCheckResult.failed(); err != nil && req.Data.err == nil {
+			req.Data.err = err
+		}
+		halted := failed || req.Data.stopped || req.Data.err != nil || timedOut(req.Ctx)
+		if halted {
+			halt()
+		}
+		i := nextBlock(req.Data.recovered, pending, started, finished, halted || running >= limit)
+		if i >= 0 {
+			h := pending[i]
+			recovering := req.Data.recovered && h.block.State.Get().Status == workflow.Running
+			if !recovering {
+				// Don't start another block while we are paused. Running blocks continue.
+				waitResume(req.Ctx, req.Data.Pause)
+
+				// A Stop was requested, let the running blocks finish but don't start new ones.
+				if stopRequested(req.Ctx) {
+					req.Data.stopped = true
+					continue
+				}
+			}
+
+			started[i] = true
+			running++
+			context.Pool(req.Ctx).Submit(
+				context.WithoutCancel(req.Ctx),
+				func() {
+					if !recovering {
+						if r, ok := s.readyBlock(haltCtx, req, h); !ok {
+							results <- r
+							return
+						}
+					}
+					results <- s.runBlock(req, h)
+				},
+			)
+			continue
+		}
+
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		ended[r.block] = true
+		if r.err != nil && req.Data.err == nil {
+			req.Data.err = r.err
+		}
+		if r.stopped {
+			req.Data.stopped = true
+		}
+		if r.timedOut {
+			req.Data.timedOut = true
+		}
+		if r.status == workflow.Failed {
+			failed = true
+		}
+	}
+	req.Data.blocks = nil
+
+	// Blocks that did not start because the Plan ran past its Timeout are marked Stopped by End.
+	if timedOut(req.Ctx) {
+		req.Data.timedOut = true
+		if req.Data.err == nil {
+			req.Data.err = planTimeoutErr(req.Data.Plan)
+		}
+	}
+
+	switch {
+	case failed || req.Data.stopped || req.Data.timedOut || req.Data.err != nil:
+		req.Next = s.PlanDeferredActions
+	case slices.Contains(started, false):
+		req.Data.err = fmt.Errorf("blocks were never ready to start: %w", ErrInternalFailure)
+		req.Next = s.PlanDeferredActions
+	default:
 		req.Next = s.PlanPostChecks
-		return req
 	}
+	return req
+}
 
-	h := req.Data.blocks[0]
+// blockResult is the result of running a Block in its own statemachine.
+type blockResult struct {
+	block    *workflow.Block
+	status   workflow.Status
+	err      error
+	stopped  bool
+	timedOut bool
+}
 
-	defer func() {
-		if err := s.store.UpdateBlock(req.Ctx, h.block); err != nil {
+// nextBlock returns the index of the next Block in pending to start or -1 if there is none. A recovered
+// Block that was Running is always started. Otherwise the first Block that has not started and whose
+// DependsOn have finished is started, unless full is set.
+func nextBlock(recovered bool, pending []block, started []bool, finished func(uuid.UUID) bool, full bool) int {
+	if recovered {
+		for i, h := range pending {
+			if !started[i] && h.block.State.Get().Status == workflow.Running {
+				return i
+			}
+		}
+	}
+	if full {
+		return -1
+	}
+	for i, h := range pending {
+		if !started[i] && h.block.Ready(finished) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readyBlock waits until the maintenance windows of the Plan and the Block in h are open and the Block holds
+// its Locks. ctx is cancelled once no more Blocks may start. If the Block must not start, this returns false
+// and the result for it.
+func (s *States) readyBlock(ctx context.Context, req statemachine.Request[Data], h block) (blockResult, bool) {
+	update := func(ctx context.Context) error { return s.store.UpdateBlock(ctx, h.block) }
+
+	err := s.waitWindows(ctx, req.Data.Plan.Windows, h.block.Windows)
+	if err == nil {
+		err = s.lock(ctx, h.block.ID, h.block.Locks, update)
+	}
+	// The Locks can be granted as we are told to not start, or the Plan's ContChecks failed while we waited.
+	if err == nil && (ctx.Err() != nil || req.Data.contCheckResult.failed() != nil) {
+		s.unlock(ctx, h.block.ID, h.block.Locks, update)
+		err = context.Canceled
+	}
+
+	r := blockResult{block: h.block, status: h.block.State.Get().Status}
+	switch {
+	case err == nil:
+		return r, true
+	case errors.Is(err, ErrStopped):
+		r.stopped = true
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.Canceled):
+		// ExecuteBlock sees why it halted itself. The Block is left NotStarted.
+	default:
+		setEnded(&h.block.State, workflow.Failed, s.now())
+		if err := update(context.WithoutCancel(ctx)); err != nil {
 			log.Fatalf("failed to write Block: %v", err)
 		}
-	}()
+		r.status = workflow.Failed
+		r.err = fmt.Errorf("block(%s): %w", h.block.Name, err)
+	}
+	return r, false
+}
+
+// runBlock runs the Block in h in its own statemachine, starting with StartBlock. It returns once the Block
+// has ended or it did not start.
+func (s *States) runBlock(req statemachine.Request[Data], h block) blockResult {
+	req.Data.blocks = []block{h}
+	req.Data.err = nil
+	req.Data.stopped = false
+	req.Data.timedOut = false
+	req.Next = s.StartBlock
+
+	req, err := statemachine.Run("block", req)
+	if err != nil && req.Data.err == nil {
+		req.Data.err = err
+	}
+	// The block has written its final State, so it can let go of its Locks.
+	s.unlock(req.Ctx, h.block.ID, h.block.Locks, func(ctx context.Context) error { return s.store.UpdateBlock(ctx, h.block) })
+	return blockResult{
+		block:    h.block,
+		status:   h.block.State.Get().Status,
+		err:      req.Data.err,
+		stopped:  req.Data.stopped,
+		timedOut: req.Data.timedOut,
+	}
+}
+
+// StartBlock starts the current block. This is the first state of the statemachine for each Block.
+func (s *States) StartBlock(req statemachine.Request[Data]) statemachine.Request[Data] {
+	h := req.Data.blocks[0]
 
-	if skipBlock(h) {
-		if len(req.Data.blocks) == 1 {
-			req.Data.blocks = nil
-		} else {
-			req.Data.blocks = req.Data.blocks[1:]
+	// A Stop was requested, don't start the block.
+	if stopRequested(req.Ctx) {
+		req.Data.stopped = true
+		req.Next = nil
+		// We recovered a Plan that was stopping in the middle of a Block. That Block still needs
+		// its DeferredChecks run. Its ContChecks were never started, so nothing will end the result.
+		if req.Data.recovered && h.block.GetState().Status == workflow.Running {
+			req = s.startBlockSpan(req)
+			stopBlock(h.block)
+			h.contCheckResult.end(nil)
+			req.Next = s.BlockDeferredChecks
 		}
-		req.Next = s.ExecuteBlock
 		return req
 	}
 
+	defer func() {
+		if err := s.store.UpdateBlock(req.Ctx, h.block); err != nil {
+			log.Fatalf("failed to write Block: %v", err)
+		}
+	}()
+
 	if req.Data.recovered && h.block.GetState().Status == workflow.Running {
+		req = s.startBlockSpan(req)
+		req = s.startBlockTimer(req)
 		s.handleRecoveredSeqs(req, h.block)
 		if h.block.State.Get().Status != workflow.Running {
 			req.Next = s.BlockDeferredChecks
+			return req
 		}
-	} else {
-		if err := after(req.Ctx, h.block.EntranceDelay); err != nil {
-			state := h.block.State.Get()
-			state.Status = workflow.Stopped
-			h.block.State.Set(state)
-			req.Data.err = err
-			req.Next = s.PlanDeferredActions
+		req.Next = s.BlockBypassChecks
+		return req
+	}
+
+	// The Plan ran past its Timeout, don't start the block. End marks it Stopped.
+	if timedOut(req.Ctx) {
+		req.Data.timedOut = true
+		req.Next = nil
+		return req
+	}
+
+	skip, err := skipWhen(req.Ctx, h.block.When)
+	if err != nil {
+		now := s.now()
+		setEnded(&h.block.State, workflow.Failed, now)
+		req.Data.err = err
+		req.Next = nil
+		return req
+	}
+	if skip {
+		s.skip(req.Ctx, h.block)
+		req.Next = nil
+		return req
+	}
+
+	if err := after(req.Ctx, h.block.EntranceDelay); err != nil {
+		if errors.Is(err, ErrTimeout) {
+			req.Data.timedOut = true
+			req.Next = nil
 			return req
 		}
 		state := h.block.State.Get()
-		state.Status = workflow.Running
-		state.Start = s.now()
+		state.Status = workflow.Stopped
 		h.block.State.Set(state)
+		req.Data.stopped = true
+		req.Next = nil
+		return req
 	}
+	state := h.block.State.Get()
+	state.Status = workflow.Running
+	state.Start = s.now()
+	h.block.State.Set(state)
+	req = s.startBlockSpan(req)
+	req = s.startBlockTimer(req)
 
 	req.Next = s.BlockBypassChecks
 	return req
 }
 
+// startBlockSpan starts the span for the current Block. Until BlockEnd, the spans of the Block's
+// children are created under it.
+func (s *States) startBlockSpan(req statemachine.Request[Data]) statemachine.Request[Data] {
+	h := &req.Data.blocks[0]
+	req.Ctx, h.span = spans.Start(req.Ctx, "block", h.block.ID, h.block.Name)
+	return req
+}
+
+// startBlockTimer starts the timer for the Timeout of the current Block, which is measured from when
+// the Block started. Until BlockEnd, the Block's children see its deadline.
+func (s *States) startBlockTimer(req statemachine.Request[Data]) statemachine.Request[Data] {
+	h := &req.Data.blocks[0]
+	req.Ctx, h.stopTimer = s.withTimeout(req.Ctx, h.block.State.Get().Start, h.block.Timeout)
+	return req
+}
+
 func (s *States) handleRecoveredSeqs(req statemachine.Request[Data], b *workflow.Block) {
 	// Detach from cancellation so recovered sequences run to completion, but keep the request's
 	// context values (pool, tracing, plan ID). Mirrors ExecuteSequences.
@@ -336,16 +750,23 @@ func (s *States) handleRecoveredSeqs(req statemachine.Request[Data], b *workflow
 	}
 	_ = g.Wait(ctx)
 
+	// A failure is recorded even if we were stopped, so it is not hidden by the Stop.
 	if s.exceededFailures(b, &failed) {
 		state := b.State.Get()
 		state.Status = workflow.Failed
 		b.State.Set(state)
 		req.Data.err = fmt.Errorf("block(%s) has exceeded the tolerated failures", b.Name)
+		return
+	}
+
+	// ExecuteSequences will see the Stop request and route the Block to its DeferredChecks.
+	if stopped.Load() > 0 {
+		stopBlock(b)
 	}
 }
 
 // BlockBypassChecks runs all the gates on the Block. If any of the gates fail,
-// or no gates are present, the Block is executed.
+// or no gates are present, the Block is executed. Otherwise BlockEnd records the Block as Skipped.
 func (s *States) BlockBypassChecks(req statemachine.Request[Data]) statemachine.Request[Data] {
 	h := req.Data.blocks[0]
 
@@ -355,19 +776,66 @@ func (s *States) BlockBypassChecks(req statemachine.Request[Data]) statemachine.
 		}
 	}()
 
+	if stopRequested(req.Ctx) {
+		stopBlock(h.block)
+		req.Data.stopped = true
+		req.Next = s.BlockDeferredChecks
+		return req
+	}
+
+	// The ContChecks have not started, so nothing else will end their result.
+	if timedOut(req.Ctx) {
+		h.contCheckResult.end(nil)
+		return s.timeoutBlock(req)
+	}
+
 	if h.block.BypassChecks == nil || h.block.BypassChecks.State.Get().Status == workflow.Failed {
-		req.Next = s.BlockPreChecks
+		req.Next = s.BlockApproval
 		return req
 	}
 	skip := s.runBypasses(req.Ctx, h.block.BypassChecks)
 	if skip {
-		if h.contCheckResult != nil {
-			close(h.contCheckResult)
-		}
+		h.contCheckResult.end(nil)
 		req.Next = s.BlockEnd
 		return req
 	}
-	req.Next = s.BlockPreChecks
+	req.Next = s.BlockApproval
+	return req
+}
+
+// BlockApproval waits at the gate of the current block's Approval until it is decided. If the Approval is
+// rejected or expires, the block fails.
+func (s *States) BlockApproval(req statemachine.Request[Data]) statemachine.Request[Data] {
+	h := req.Data.blocks[0]
+
+	defer func() {
+		if err := s.store.UpdateBlock(req.Ctx, h.block); err != nil {
+			log.Fatalf("failed to write Block: %v", err)
+		}
+	}()
+
+	err := s.waitApproval(req.Ctx, h.block.Approval)
+	if err == nil {
+		req.Next = s.BlockPreChecks
+		return req
+	}
+
+	// The ContChecks have not started, so nothing else will end their result.
+	h.contCheckResult.end(nil)
+	switch {
+	case errors.Is(err, ErrStopped):
+		stopBlock(h.block)
+		req.Data.stopped = true
+		req.Next = s.BlockDeferredChecks
+		return req
+	case errors.Is(err, ErrTimeout):
+		return s.timeoutBlock(req)
+	}
+	state := h.block.State.Get()
+	state.Status = workflow.Failed
+	h.block.State.Set(state)
+	req.Data.err = err
+	req.Next = s.BlockDeferredChecks
 	return req
 }
 
@@ -381,6 +849,19 @@ func (s *States) BlockPreChecks(req statemachine.Request[Data]) statemachine.Req
 		}
 	}()
 
+	if stopRequested(req.Ctx) {
+		stopBlock(h.block)
+		req.Data.stopped = true
+		req.Next = s.BlockDeferredChecks
+		return req
+	}
+
+	// The ContChecks have not started, so nothing else will end their result.
+	if timedOut(req.Ctx) {
+		h.contCheckResult.end(nil)
+		return s.timeoutBlock(req)
+	}
+
 	if h.block.PreChecks == nil || h.block.PreChecks.State.Get().Status == workflow.Completed {
 		req.Next = s.BlockStartContChecks
 		return req
@@ -412,7 +893,7 @@ func (s *States) BlockStartContChecks(req statemachine.Request[Data]) statemachi
 	}()
 
 	if h.block.ContChecks == nil {
-		close(h.contCheckResult)
+		h.contCheckResult.end(nil)
 		req.Next = s.ExecuteSequences
 		return req
 	}
@@ -427,7 +908,7 @@ func (s *States) BlockStartContChecks(req statemachine.Request[Data]) statemachi
 	context.Pool(req.Ctx).Submit(
 		ctx,
 		func() {
-			s.runContChecks(ctx, h.block.ContChecks, h.contCheckResult)
+			s.runContChecks(ctx, h.block.ContChecks, h.block.Windows, h.contCheckResult)
 		},
 	)
 
@@ -435,7 +916,10 @@ func (s *States) BlockStartContChecks(req statemachine.Request[Data]) statemachi
 	return req
 }
 
-// ExecuteSequences executes the sequences of the current block.
+// ExecuteSequences executes the sequences of the current block. If the block has a Ramp, the sequences
+// are run in waves and each wave waits for the one before it to finish, its Soak and the WaveChecks.
+// Once the Block or Plan runs past its Timeout, no more sequences are started and the running sequences
+// stop after their current Action.
 func (s *States) ExecuteSequences(req statemachine.Request[Data]) statemachine.Request[Data] {
 	h := req.Data.blocks[0]
 
@@ -455,55 +939,101 @@ func (s *States) ExecuteSequences(req statemachine.Request[Data]) statemachine.R
 	pool := context.Pool(req.Ctx).Limited(req.Ctx, "ExecuteSequences", h.block.Concurrency)
 	g := pool.Group()
 
-	for i := 0; i < len(h.block.Sequences); i++ {
-		seq := h.block.Sequences[i]
-		seqStatus := seq.State.Get().Status
-		if seqStatus == workflow.Completed || seqStatus == workflow.Failed {
-			continue
-		}
+	start := 0
+waves:
+	for w, end := range h.block.Ramp.Waves(len(h.block.Sequences)) {
+		if w > 0 {
+			// A wave only starts once every sequence in the wave before it has finished.
+			g.Wait(context.WithoutCancel(req.Ctx))
+			if s.exceededFailures(h.block, &failures) {
+				break waves
+			}
 
-		if _, err := req.Data.contChecksPassing(); err != nil {
-			state := h.block.State.Get()
-			state.Status = workflow.Failed
-			h.block.State.Set(state)
-			req.Data.err = err
-			req.Next = s.BlockDeferredChecks
-			return req
+			err := s.readyWave(req.Ctx, h.block, h.block.Sequences[start:end])
+			switch {
+			case errors.Is(err, ErrStopped):
+				req.Data.stopped = true
+				break waves
+			case errors.Is(err, ErrTimeout):
+				break waves
+			case err != nil:
+				state := h.block.State.Get()
+				state.Status = workflow.Failed
+				h.block.State.Set(state)
+				req.Data.err = fmt.Errorf("block(%s) WaveChecks failed before wave %d: %w", h.block.Name, w, err)
+				req.Next = s.BlockDeferredChecks
+				return req
+			}
 		}
 
-		if s.exceededFailures(h.block, &failures) {
-			state := h.block.State.Get()
-			state.Status = workflow.Failed
-			h.block.State.Set(state)
-			req.Data.err = fmt.Errorf("block(%s) has exceeded the tolerated failures", h.block.Name)
-			req.Next = s.BlockDeferredChecks
-			return req
-		}
+		for i := start; i < end; i++ {
+			seq := h.block.Sequences[i]
+			switch seq.State.Get().Status {
+			case workflow.Completed, workflow.Failed, workflow.Skipped:
+				continue
+			}
 
-		limiter <- struct{}{}
-		g.Go(
-			context.WithoutCancel(req.Ctx),
-			func(ctx context.Context) error {
-				defer func() { <-limiter }()
+			// We take our slot before looking for a Pause or Stop, otherwise one that arrives while we wait
+			// for a slot would be missed. The slot doesn't need to be returned if we don't start the sequence.
+			limiter <- struct{}{}
 
-				// Defense in depth to make sure we don't run more than we should.
-				if s.exceededFailures(h.block, &failures) {
-					return fmt.Errorf("exceeded tolerated failures")
-				}
+			// Don't start another sequence while we are paused. Running sequences continue.
+			waitResume(req.Ctx, req.Data.Pause)
 
-				err := s.execSeq(ctx, seq)
-				if err != nil {
-					failures.Add(1)
-				}
-				return err
-			},
-		)
+			// A Stop was requested, let the running sequences finish but don't start new ones.
+			if stopRequested(req.Ctx) {
+				req.Data.stopped = true
+				break waves
+			}
+
+			if timedOut(req.Ctx) {
+				break waves
+			}
+
+			if _, err := req.Data.contChecksPassing(); err != nil {
+				state := h.block.State.Get()
+				state.Status = workflow.Failed
+				h.block.State.Set(state)
+				req.Data.err = err
+				req.Next = s.BlockDeferredChecks
+				return req
+			}
+
+			if s.exceededFailures(h.block, &failures) {
+				state := h.block.State.Get()
+				state.Status = workflow.Failed
+				h.block.State.Set(state)
+				req.Data.err = fmt.Errorf("block(%s) has exceeded the tolerated failures", h.block.Name)
+				req.Next = s.BlockDeferredChecks
+				return req
+			}
+
+			g.Go(
+				context.WithoutCancel(req.Ctx),
+				func(ctx context.Context) error {
+					defer func() { <-limiter }()
+
+					// Defense in depth to make sure we don't run more than we should.
+					if s.exceededFailures(h.block, &failures) {
+						return fmt.Errorf("exceeded tolerated failures")
+					}
+
+					err := s.execSeq(ctx, seq)
+					if seq.State.Get().Status == workflow.Failed {
+						failures.Add(1)
+					}
+					return err
+				},
+			)
+		}
+		start = end
 	}
 
 	g.Wait(context.WithoutCancel(req.Ctx)) // We don't care about the error here, we just want to wait for all sequences to finish.'
 
 	// Need to recheck in case the last sequence failed and sent us over the edge.
-	if h.block.ToleratedFailures >= 0 && failures.Load() > int64(h.block.ToleratedFailures) {
+	// This is checked before a Stop so that a failure is not hidden by the Stop.
+	if s.exceededFailures(h.block, &failures) {
 		state := h.block.State.Get()
 		state.Status = workflow.Failed
 		h.block.State.Set(state)
@@ -512,12 +1042,24 @@ func (s *States) ExecuteSequences(req statemachine.Request[Data]) statemachine.R
 		return req
 	}
 
+	// A timeout is checked before a Stop, as sequences that were cut short by it are Stopped.
+	if timedOut(req.Ctx) && !seqsFinished(h.block) {
+		return s.timeoutBlock(req)
+	}
+
+	if req.Data.stopped || seqsStopped(h.block) {
+		stopBlock(h.block)
+		req.Data.stopped = true
+		req.Next = s.BlockDeferredChecks
+		return req
+	}
+
 	req.Next = s.BlockPostChecks
 	return req
 }
 
 func (s *States) exceededFailures(block *workflow.Block, failures *atomic.Int64) bool {
-	if block.ToleratedFailures >= 0 && failures.Load() > int64(block.ToleratedFailures) {
+	if n := block.MaxFailures(); n >= 0 && failures.Load() > int64(n) {
 		return true
 	}
 	return false
@@ -536,8 +1078,15 @@ func (s *States) BlockPostChecks(req statemachine.Request[Data]) statemachine.Re
 		return req
 	}
 
-	err := s.runChecksOnce(req.Ctx, h.block.PostChecks)
-	if err != nil {
+	err := s.runSoakChecks(req.Ctx, h.block.PostChecks)
+	switch {
+	case errors.Is(err, ErrStopped):
+		stopBlock(h.block)
+		req.Data.stopped = true
+		return req
+	case errors.Is(err, ErrTimeout):
+		return s.timeoutBlock(req)
+	case err != nil:
 		state := h.block.State.Get()
 		state.Status = workflow.Failed
 		h.block.State.Set(state)
@@ -573,10 +1122,11 @@ func (s *States) BlockDeferredChecks(req statemachine.Request[Data]) statemachin
 	return req
 }
 
-// BlockEnd ends the current block and moves to the next block.
+// BlockEnd ends the current block. This is the last state of the statemachine for each Block.
 func (s *States) BlockEnd(req statemachine.Request[Data]) statemachine.Request[Data] {
 	h := req.Data.blocks[0]
 
+	req.Next = nil
 	defer func() {
 		state := h.block.State.Get()
 		state.End = s.now()
@@ -584,13 +1134,24 @@ func (s *States) BlockEnd(req statemachine.Request[Data]) statemachine.Request[D
 		if err := s.store.UpdateBlock(req.Ctx, h.block); err != nil {
 			log.Fatalf("failed to write Block: %v", err)
 		}
+		if h.span != nil {
+			spans.End(h.span, state.Status, nil)
+		}
+		if h.stopTimer != nil {
+			h.stopTimer()
+		}
 	}()
 
 	// Don't use checksCompleted() here, we want to run the block if it is not completed.
 	if h.block.BypassChecks != nil && h.block.BypassChecks.State.Get().Status == workflow.Completed {
 		state := h.block.State.Get()
-		state.Status = workflow.Completed
+		state.Status = workflow.Skipped
 		h.block.State.Set(state)
+		for _, seq := range h.block.Sequences {
+			if seq.State.Get().Status == workflow.NotStarted {
+				s.skip(req.Ctx, seq)
+			}
+		}
 	} else {
 		// For safety reasons, we always check this so we don't get goroutine leaks.
 		if h.contCancel != nil {
@@ -599,50 +1160,58 @@ func (s *States) BlockEnd(req statemachine.Request[Data]) statemachine.Request[D
 
 		// Stop our cont checks if they are still running, get the final result.
 		if h.block.ContChecks != nil {
-			var err error
-			for err = range h.contCheckResult {
-				if err != nil {
-					break
-				}
-			}
-			if err != nil {
+			if err := h.contCheckResult.wait(); err != nil {
 				state := h.block.State.Get()
 				state.Status = workflow.Failed
 				h.block.State.Set(state)
 				req.Data.err = err
-				req.Next = s.PlanDeferredActions
-				return req
 			}
 		}
 
-		if h.block.State.Get().Status == workflow.Running {
+		switch h.block.State.Get().Status {
+		case workflow.Running:
 			state := h.block.State.Get()
 			state.Status = workflow.Completed
 			h.block.State.Set(state)
-		} else {
+		case workflow.Stopped:
+			req.Data.stopped = true
+		default:
 			state := h.block.State.Get()
 			state.Status = workflow.Failed
 			h.block.State.Set(state)
-			req.Next = s.PlanDeferredActions
+		}
+
+		// Undo what the Block did before its DeferredActions run. The Undos of a Sequence that
+		// failed have already run and are not run again.
+		if h.block.State.Get().Status == workflow.Failed {
+			s.runUndos(req.Ctx, blockActions(h.block))
+		}
+
+		// The DeferredActions see the final outcome of the Block and can still fail it.
+		completed := h.block.State.Get().Status == workflow.Completed
+		if s.execDeferredActions(req.Ctx, h.block.DeferredActions, !completed) && completed {
+			state := h.block.State.Get()
+			state.Status = workflow.Failed
+			h.block.State.Set(state)
+			req.Data.err = fmt.Errorf("block(%s) DeferredActions failed", h.block.Name)
+		}
+		if h.block.State.Get().Status != workflow.Completed {
 			return req
 		}
 
 		if err := after(req.Ctx, h.block.ExitDelay); err != nil {
+			// The Block has Completed, a deadline only cuts its ExitDelay short.
+			if errors.Is(err, ErrTimeout) {
+				return req
+			}
 			state := h.block.State.Get()
 			state.Status = workflow.Stopped
 			h.block.State.Set(state)
-			req.Data.err = err
-			req.Next = s.PlanDeferredActions
+			req.Data.stopped = true
 			return req
 		}
 	}
 
-	if len(req.Data.blocks) == 1 {
-		req.Data.blocks = nil
-	} else {
-		req.Data.blocks = req.Data.blocks[1:]
-	}
-	req.Next = s.ExecuteBlock
 	return req
 }
 
@@ -662,18 +1231,33 @@ func (s *States) PlanPostChecks(req statemachine.Request[Data]) statemachine.Req
 	}
 
 	if req.Data.Plan.ContChecks != nil {
-		for err := range req.Data.contCheckResult {
-			if err != nil {
-				req.Data.err = err
-				return req
-			}
+		if err := req.Data.contCheckResult.wait(); err != nil {
+			req.Data.err = err
+			return req
 		}
 	}
 
+	if timedOut(req.Ctx) {
+		req.Data.timedOut = true
+		req.Data.err = planTimeoutErr(req.Data.Plan)
+		return req
+	}
+
+	if stopRequested(req.Ctx) {
+		req.Data.stopped = true
+		return req
+	}
+
 	if req.Data.Plan.PostChecks != nil && !isCompleted(req.Data.Plan.PostChecks) {
-		if err := s.runChecksOnce(req.Ctx, req.Data.Plan.PostChecks); err != nil {
+		err := s.runSoakChecks(req.Ctx, req.Data.Plan.PostChecks)
+		switch {
+		case errors.Is(err, ErrStopped):
+			req.Data.stopped = true
+		case errors.Is(err, ErrTimeout):
+			req.Data.timedOut = true
+			req.Data.err = planTimeoutErr(req.Data.Plan)
+		case err != nil:
 			req.Data.err = err
-			return req
 		}
 	}
 	return req
@@ -703,38 +1287,45 @@ func (s *States) PlanDeferredChecks(req statemachine.Request[Data]) statemachine
 // based on the Plan's accumulated state prior to DeferredChecks (which run after this
 // state). It always transitions to PlanDeferredChecks. If any batch with FailElement=true
 // fails, the DeferredActions container is marked Failed and finalStates will fail the Plan
-// with FRDeferredAction. Idempotent across recovery: if DeferredActions is already
-// in a terminal state, this is a no-op.
+// with FRDeferredAction. A stopped or timed out Plan counts as failed. Idempotent across recovery: if
+// DeferredActions is already in a terminal state, this is a no-op.
 func (s *States) PlanDeferredActions(req statemachine.Request[Data]) statemachine.Request[Data] {
 	req.Next = s.PlanDeferredChecks
 
-	da := req.Data.Plan.DeferredActions
+	failed := req.Data.stopped || req.Data.timedOut || planHasFailed(req.Data.Plan, req.Data.err)
+	s.execDeferredActions(req.Ctx, req.Data.Plan.DeferredActions, failed)
+	return req
+}
+
+// execDeferredActions runs the DeferredActions batches of a Plan, Block or Sequence chosen by failed,
+// which is if the element failed or was stopped. It returns true if a batch with FailElement=true failed.
+// If da is already in a terminal state, it is not run again and this returns true if it Failed.
+// A nil da does nothing.
+func (s *States) execDeferredActions(ctx context.Context, da *workflow.DeferredActions, failed bool) bool {
 	if da == nil {
-		return req
+		return false
 	}
 
 	defer func() {
-		if err := s.store.UpdateDeferredActions(req.Ctx, da); err != nil {
+		if err := s.store.UpdateDeferredActions(ctx, da); err != nil {
 			log.Fatalf("failed to write DeferredActions: %v", err)
 		}
 	}()
 
 	if isCompleted(da) {
-		return req
+		return da.State.Get().Status == workflow.Failed
 	}
 
 	state := da.State.Get()
 	state.Status = workflow.Running
 	state.Start = s.now()
 	da.State.Set(state)
-	if err := s.store.UpdateDeferredActions(req.Ctx, da); err != nil {
+	if err := s.store.UpdateDeferredActions(ctx, da); err != nil {
 		log.Fatalf("failed to write DeferredActions: %v", err)
 	}
 
-	failed := planHasFailed(req.Data.Plan, req.Data.err)
 	batches := selectDeferredBatches(da.DeferredBatches, failed)
-
-	failElementTripped := s.runDeferredActions(req.Ctx, batches)
+	failElementTripped := s.runDeferredActions(ctx, batches)
 
 	state = da.State.Get()
 	state.End = s.now()
@@ -744,7 +1335,7 @@ func (s *States) PlanDeferredActions(req statemachine.Request[Data]) statemachin
 		state.Status = workflow.Completed
 	}
 	da.State.Set(state)
-	return req
+	return failElementTripped
 }
 
 // selectDeferredBatches filters batches whose When matches the plan outcome.
@@ -794,11 +1385,34 @@ func planHasFailed(p *workflow.Plan, reqErr error) bool {
 // This will do the calculations of the final state of the Plan.
 func (s *States) End(req statemachine.Request[Data]) statemachine.Request[Data] {
 	plan := req.Data.Plan
+
+	// No Stop or Pause can be requested once we are here. If a Stop was recorded after the last state
+	// that looked for one, we still finish as stopped.
+	req.Data.Pause.close()
+	req.Data.stopWatch.close()
+	if req.Data.stopTimer != nil {
+		req.Data.stopTimer()
+	}
+	if plan.State.Get().Status == workflow.Stopping {
+		req.Data.stopped = true
+	}
+
 	defer func() {
+		if req.Data.stopped || req.Data.timedOut {
+			s.stopUnfinished(plan)
+		}
+		// Locks are normally let go when their Block or Sequence finishes. This makes sure none are kept.
+		s.unlockPlan(req.Ctx, plan)
 		state := plan.State.Get()
 		state.End = s.now()
 		plan.State.Set(state)
 		s.writeEverything(req.Ctx, plan)
+		if req.Data.span != nil {
+			spans.End(req.Data.span, state.Status, nil)
+		}
+		if req.Data.metered {
+			s.metrics.PlanEnded(req.Ctx, plan)
+		}
 	}()
 
 	// Extra cancel, defense in depth.
@@ -900,9 +1514,10 @@ func (s *States) runPreChecks(ctx context.Context, preChecks *workflow.Checks, c
 		})
 	}
 
+	// This is the first round of the ContChecks, so it counts towards their FailureThreshold.
 	if contChecks != nil {
 		g.Go(ctx, func(ctx context.Context) error {
-			return s.runChecksOnce(ctx, contChecks)
+			return s.runContRound(ctx, contChecks)
 		})
 	}
 
@@ -910,10 +1525,12 @@ func (s *States) runPreChecks(ctx context.Context, preChecks *workflow.Checks, c
 }
 
 // runContChecks runs the ContChecks in a loop with a delay between each run until the Context is cancelled.
-// It writes the final result to the given channel. If a check fails before the Context is cancelled, the
-// error is written to the channel and the function returns.
-func (s *States) runContChecks(ctx context.Context, checks *workflow.Checks, resultCh chan error) {
-	defer close(resultCh)
+// It records the final result in result. If a check fails before the Context is cancelled, the error is
+// recorded and the function returns. Failed rounds within the checks' FailureThreshold are tolerated. If
+// windows has StopOnClose set, the checks fail with ErrWindowClosed when it closes.
+func (s *States) runContChecks(ctx context.Context, checks *workflow.Checks, windows *workflow.Windows, result *contResult) {
+	closed, stopClosed := s.windowClosed(windows)
+	defer stopClosed()
 
 	// If the delay is less than or equal to 0, we set it to 1ns to avoid a panic,
 	// since time.NewTicker panics if the duration is less than or equal to 0.
@@ -924,9 +1541,9 @@ func (s *States) runContChecks(ctx context.Context, checks *workflow.Checks, res
 
 	// Run checks immediately on start to ensure they transition to Running state,
 	// even if the plan completes before the first ticker fires.
-	err := s.runChecksOnce(context.WithoutCancel(ctx), checks)
-	resultCh <- err
-	if err != nil {
+	if err := s.runContRound(ctx, checks); err != nil {
+		s.metrics.ContCheckFailed(ctx)
+		result.end(err)
 		return
 	}
 
@@ -937,23 +1554,154 @@ func (s *States) runContChecks(ctx context.Context, checks *workflow.Checks, res
 		t.Reset(delay)
 		select {
 		case <-ctx.Done():
+			result.end(nil)
+			return
+		case <-closed:
+			err := s.closeChecks(ctx, checks)
+			s.metrics.ContCheckFailed(ctx)
+			result.end(err)
 			return
 		case <-t.C:
-			err := s.runChecksOnce(context.WithoutCancel(ctx), checks)
-			resultCh <- err
-			if err != nil {
+			if err := s.runContRound(ctx, checks); err != nil {
+				s.metrics.ContCheckFailed(ctx)
+				result.end(err)
 				return
 			}
 		}
 	}
 }
 
-// runChecksOnce runs Checks once and writes the result to the store.
-func (s *States) runChecksOnce(ctx context.Context, checks *workflow.Checks) error {
+// maxContRounds is the number of rounds of ContChecks that are kept in their Attempts. Past it, the oldest
+// round that passed is dropped, or the oldest round if none passed, so the failed rounds that the
+// FailureThreshold counts are kept for as long as they can be.
+const maxContRounds = 100
+
+// runContRound runs a round of ContChecks and records it in the checks' Attempts, so they hold the history
+// of the rounds. If the round fails before the checks' FailureThreshold is reached, the checks are left
+// Completed and this returns nil.
+func (s *States) runContRound(ctx context.Context, checks *workflow.Checks) error {
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.runChecksOnce(ctx, checks)
+	state := checks.State.Get()
+	attempts := append(checks.Attempts.Get(), runAttempt(checks.Actions, state.Start, state.End))
+	if len(attempts) > maxContRounds {
+		i := slices.IndexFunc(attempts, func(a workflow.SeqAttempt) bool { return !roundFailed(a) })
+		attempts = slices.Delete(attempts, max(i, 0), max(i, 0)+1)
+	}
+	checks.Attempts.Set(attempts)
+
+	tolerated := err != nil && checks.FailureThreshold != nil && !thresholdReached(checks.FailureThreshold, attempts)
+	if tolerated {
+		for _, a := range checks.Actions {
+			resetActions([]*workflow.Action{a})
+			if err := s.store.UpdateAction(ctx, a); err != nil {
+				log.Fatalf("failed to write Action: %v", err)
+			}
+		}
+		state.Status = workflow.Completed
+		checks.State.Set(state)
+	}
+	if err := s.store.UpdateChecks(ctx, checks); err != nil {
+		log.Fatalf("failed to write Checks: %v", err)
+	}
+	if tolerated {
+		return nil
+	}
+	return err
+}
+
+// thresholdReached reports if the rounds of ContChecks in attempts, of which the last has failed, reach the
+// FailureThreshold.
+func thresholdReached(f *workflow.FailureThreshold, attempts []workflow.SeqAttempt) bool {
+	end := attempts[len(attempts)-1].End
+	consecutive, failures := 0, 0
+	counting := true
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if !roundFailed(attempts[i]) {
+			counting = false
+			continue
+		}
+		if counting {
+			consecutive++
+		}
+		if end.Sub(attempts[i].End) <= f.Window {
+			failures++
+		}
+	}
+	if f.Consecutive > 0 && consecutive >= f.Consecutive {
+		return true
+	}
+	if f.Failures > 0 && failures >= f.Failures {
+		return true
+	}
+	return false
+}
+
+// roundFailed reports if an Action failed in the round of checks in a.
+func roundFailed(a workflow.SeqAttempt) bool {
+	return slices.ContainsFunc(a.Actions, func(r workflow.ActionResult) bool { return r.Status == workflow.Failed })
+}
+
+// runSoakChecks runs PostChecks. If the checks have a SoakDuration, they are run every Delay until SoakDuration
+// has passed since the first round started and fail on the first failed round. The checks stay Running between
+// rounds, so a Plan recovered during the soak starts the soak again. This returns ErrStopped or ErrTimeout
+// if the Plan is stopped or times out between rounds.
+func (s *States) runSoakChecks(ctx context.Context, checks *workflow.Checks) error {
+	if checks.SoakDuration <= 0 {
+		return s.runChecksOnce(ctx, checks)
+	}
+
+	start := s.now()
+	deadline := start.Add(checks.SoakDuration)
+	for {
+		if err := s.runChecksOnce(ctx, checks); err != nil {
+			s.setSoakState(ctx, checks, workflow.Failed, start)
+			return err
+		}
+		if s.now().Add(checks.Delay).After(deadline) {
+			break
+		}
+		s.setSoakState(ctx, checks, workflow.Running, start)
+
+		if err := after(ctx, checks.Delay); err != nil {
+			status := workflow.Failed
+			if errors.Is(err, ErrStopped) {
+				status = workflow.Stopped
+			}
+			s.setSoakState(ctx, checks, status, start)
+			return err
+		}
+	}
+	s.setSoakState(ctx, checks, workflow.Completed, start)
+	return nil
+}
+
+// setSoakState sets the status of checks that are soaking and writes them to the store. The Start of the
+// checks is kept at the start of the first round.
+func (s *States) setSoakState(ctx context.Context, checks *workflow.Checks, status workflow.Status, start time.Time) {
+	state := checks.State.Get()
+	state.Status = status
+	state.Start = start
+	if status != workflow.Running {
+		state.End = s.now()
+	}
+	checks.State.Set(state)
+	if err := s.store.UpdateChecks(ctx, checks); err != nil {
+		log.Fatalf("failed to write Checks: %v", err)
+	}
+}
+
+// runChecksOnce runs Checks once and writes the result to the store. If the Checks have a WaitUntil,
+// a failed round of Actions is run again every Delay until a round succeeds or WaitUntil passes.
+func (s *States) runChecksOnce(ctx context.Context, checks *workflow.Checks) (err error) {
 	if s.testChecksRunner != nil {
 		return s.testChecksRunner(ctx, checks)
 	}
 
+	ctx, span := spans.Start(ctx, "checks", checks.ID, "")
+	defer func() { spans.End(span, checks.State.Get().Status, err) }()
+
 	resetActions(checks.Actions)
 
 	state := checks.State.Get()
@@ -975,10 +1723,19 @@ func (s *States) runChecksOnce(ctx context.Context, checks *workflow.Checks) err
 		checks.State.Set(state)
 	}()
 
-	if err := s.runActionsParallel(ctx, checks.Actions); err != nil {
+	for {
+		err = s.runActionsParallel(ctx, checks.Actions)
+		if err == nil || !s.retryChecks(ctx, checks) {
+			break
+		}
+	}
+	if err != nil {
 		state := checks.State.Get()
 		state.Status = workflow.Failed
 		checks.State.Set(state)
+		if checks.WaitUntil > 0 {
+			return fmt.Errorf("checks did not pass within WaitUntil(%v): %w", checks.WaitUntil, err)
+		}
 		return err
 	}
 	state = checks.State.Get()
@@ -1017,8 +1774,11 @@ func (s *States) runActionsParallel(ctx context.Context, actions []*workflow.Act
 }
 
 // execSeq executes a sequence of actions. Any Job failures fail the Sequnence. The Job may retry
-// based on the retry policy.
-func (s *States) execSeq(ctx context.Context, seq *workflow.Sequence) error {
+// based on the retry policy. If the Sequence fails and has Retries left, it is run again from its
+// first Action after retrySeq has readied it. If a Stop is requested or the Block or Plan runs past its
+// Timeout, the running Action is allowed to finish, but no more Actions are started and the Sequence
+// is marked Stopped.
+func (s *States) execSeq(ctx context.Context, seq *workflow.Sequence) (err error) {
 	defer func() {
 		if err := s.store.UpdateSequence(ctx, seq); err != nil {
 			log.Fatalf("failed to write Sequence: %v", err)
@@ -1026,7 +1786,7 @@ func (s *States) execSeq(ctx context.Context, seq *workflow.Sequence) error {
 	}()
 
 	switch seq.State.Get().Status {
-	case workflow.Completed:
+	case workflow.Completed, workflow.Skipped:
 		return nil
 	case workflow.Failed:
 		for _, action := range seq.Actions {
@@ -1036,8 +1796,30 @@ func (s *States) execSeq(ctx context.Context, seq *workflow.Sequence) error {
 			}
 		}
 		// Well, this shouldn't happen, but we have a failed sequence with no failed actions.
-		return fmt.Errorf("bug: sequence %s is already failed, but can't figure out why", seq.Name)
+		return fmt.Errorf("sequence %s is already failed", seq.Name)
+	}
+
+	skip, err := skipWhen(ctx, seq.When)
+	if err != nil {
+		now := s.now()
+		setEnded(&seq.State, workflow.Failed, now)
+		return err
+	}
+	if skip {
+		s.skip(ctx, seq)
+		return nil
+	}
+
+	// The sequence only starts once it holds its Locks, which it keeps through its retries. They are let
+	// go once it has its final State, which is written with them.
+	update := func(ctx context.Context) error { return s.store.UpdateSequence(ctx, seq) }
+	if err := s.lock(ctx, seq.ID, seq.Locks, update); err != nil {
+		return err
 	}
+	defer s.unlock(ctx, seq.ID, seq.Locks, update)
+
+	ctx, span := spans.Start(ctx, "sequence", seq.ID, seq.Name)
+	defer func() { spans.End(span, seq.State.Get().Status, err) }()
 
 	state := seq.State.Get()
 	state.Status = workflow.Running
@@ -1046,24 +1828,66 @@ func (s *States) execSeq(ctx context.Context, seq *workflow.Sequence) error {
 	if err := s.store.UpdateSequence(ctx, seq); err != nil {
 		log.Fatalf("failed to write Sequence: %v", err)
 	}
+	s.metrics.SequenceRunning(ctx, 1)
+	defer s.metrics.SequenceRunning(ctx, -1)
 	defer func() {
 		state := seq.State.Get()
 		state.End = s.now()
 		seq.State.Set(state)
 	}()
-
-	for _, action := range seq.Actions {
-		if err := s.runAction(ctx, action, s.store); err != nil {
+	// This runs before the deferred End above, once the Sequence has its final status.
+	defer func() {
+		if seq.State.Get().Status == workflow.Failed {
+			s.runUndos(ctx, seq.Actions)
+		}
+		completed := seq.State.Get().Status == workflow.Completed
+		if s.execDeferredActions(ctx, seq.DeferredActions, !completed) && completed {
 			state := seq.State.Get()
 			state.Status = workflow.Failed
 			seq.State.Set(state)
-			return err
+			err = fmt.Errorf("sequence(%s) DeferredActions failed", seq.Name)
+		}
+	}()
+
+	// A rejected or expired Approval fails the sequence before any of its Actions run. A retry
+	// does not wait at the gate again.
+	if err = s.waitApproval(ctx, seq.Approval); err == nil {
+		for {
+			err = s.runSeqActions(ctx, seq)
+			if err == nil || errors.Is(err, ErrStopped) || errors.Is(err, ErrTimeout) || !s.retrySeq(ctx, seq) {
+				break
+			}
 		}
 	}
 
 	state = seq.State.Get()
-	state.Status = workflow.Completed
+	switch {
+	case err == nil:
+		state.Status = workflow.Completed
+	case errors.Is(err, ErrStopped), errors.Is(err, ErrTimeout):
+		state.Status = workflow.Stopped
+	default:
+		state.Status = workflow.Failed
+	}
 	seq.State.Set(state)
+	return err
+}
+
+// runSeqActions runs the Actions of seq in order and returns the error of the first one that fails.
+// If a Stop is requested, no more Actions are started and ErrStopped is returned. If the Block or Plan
+// has run past its Timeout, no more Actions are started and ErrTimeout is returned.
+func (s *States) runSeqActions(ctx context.Context, seq *workflow.Sequence) error {
+	for _, action := range seq.Actions {
+		if stopRequested(ctx) {
+			return ErrStopped
+		}
+		if timedOut(ctx) {
+			return ErrTimeout
+		}
+		if err := s.runAction(ctx, action, s.store); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1154,14 +1978,27 @@ func (s *States) runAction(ctx context.Context, action *workflow.Action, updater
 		}
 	}()
 	switch action.State.Get().Status {
-	case workflow.Completed:
+	case workflow.Completed, workflow.Skipped:
 		return nil
 	case workflow.Failed:
 		attempts := action.Attempts.Get()
 		return attempts[len(attempts)-1].Err
 	}
 
+	skip, err := skipWhen(ctx, action.When)
+	if err != nil {
+		now := s.now()
+		action.Attempts.Append(workflow.Attempt{Err: &plugins.Error{Message: err.Error(), Permanent: true}, Start: now, End: now})
+		setEnded(&action.State, workflow.Failed, now)
+		return err
+	}
+	if skip {
+		s.skip(ctx, action)
+		return nil
+	}
+
 	ctx = context.SetActionID(ctx, action.ID)
+	ctx, span := spans.Start(ctx, "action", action.ID, action.Name, spans.Plugin.String(action.Plugin))
 
 	req := statemachine.Request[actions.Data]{
 		Ctx: ctx,
@@ -1169,10 +2006,12 @@ func (s *States) runAction(ctx context.Context, action *workflow.Action, updater
 			Action:   action,
 			Updater:  updater,
 			Registry: s.registry,
+			Keys:     keysFrom(ctx).actionKeys(),
 		},
 		Next: s.actionsSM.Start,
 	}
-	_, err := statemachine.Run("run action statemachine", req)
+	_, err = statemachine.Run("run action statemachine", req)
+	spans.End(span, action.State.Get().Status, err)
 	if err != nil {
 		return err
 	}
@@ -1211,6 +2050,10 @@ func after(ctx context.Context, d time.Duration) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
+	case <-stopping(ctx):
+		return ErrStopped
+	case <-expired(ctx):
+		return ErrTimeout
 	case <-t.C:
 	}
 	return nil
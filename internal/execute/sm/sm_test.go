@@ -14,9 +14,11 @@ import (
 	"github.com/element-of-surprise/coercion/plugins/registry"
 	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/builder"
+	"github.com/element-of-surprise/coercion/workflow/storage"
 	"github.com/element-of-surprise/coercion/workflow/storage/noop"
 	"github.com/element-of-surprise/coercion/workflow/storage/sqlite"
 	"github.com/element-of-surprise/coercion/workflow/utils/clone"
+	"github.com/google/uuid"
 	"github.com/gostdlib/base/statemachine"
 )
 
@@ -248,17 +250,19 @@ func TestPlanStartContChecks(t *testing.T) {
 				Plan: &workflow.Plan{
 					ContChecks: contChecks,
 				},
-				contCheckResult: make(chan error, 1),
+				contCheckResult: newContResult(),
 			},
 		}
 
 		req = states.PlanStartContChecks(req)
 		if test.action != nil {
-			<-req.Data.contCheckResult
 			if req.Data.contCancel == nil {
 				t.Errorf("TestPlanStartContChecks(%s): got req.Data.contCancel == nil, want req.Data.contCancel != nil", test.name)
+			} else {
+				req.Data.contCancel()
 			}
 		}
+		req.Data.contCheckResult.wait()
 		if methodName(req.Next) != methodName(states.ExecuteBlock) {
 			t.Errorf("TestPlanStartContChecks(%s): got req.Next == %s, want req.Next == %s", test.name, methodName(req.Next), methodName(states.ExecuteSequences))
 		}
@@ -270,37 +274,106 @@ func TestExecuteBlocks(t *testing.T) {
 
 	states := &States{} // Used to get the method name of a state for wantNextState
 
+	newBlock := func(key uuid.UUID, status workflow.Status, dependsOn ...uuid.UUID) *workflow.Block {
+		b := &workflow.Block{Key: key, DependsOn: dependsOn, Concurrency: 1}
+		b.State.Set(workflow.State{Status: status})
+		return b
+	}
+	keys := []uuid.UUID{workflow.NewV7(), workflow.NewV7(), workflow.NewV7()}
+
 	tests := []struct {
 		name          string
-		block         block
+		concurrency   int
+		blocks        []*workflow.Block
+		stopped       bool
+		wantStatuses  []workflow.Status
+		wantErr       bool
+		wantStopped   bool
 		wantNextState statemachine.State[Data]
 	}{
 		{
-			name:          "No more blocks",
+			name:          "Success: no blocks",
 			wantNextState: states.PlanPostChecks,
 		},
 		{
-			name: "Have a block",
-			block: block{
-				block: func() *workflow.Block {
-					b := &workflow.Block{}
-					b.State.Set(workflow.State{})
-					return b
-				}(),
+			name:        "Success: blocks run after the blocks they depend on",
+			concurrency: 1,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.NotStarted),
+				newBlock(keys[1], workflow.NotStarted, keys[2]),
+				newBlock(keys[2], workflow.NotStarted),
+			},
+			wantStatuses:  []workflow.Status{workflow.Completed, workflow.Completed, workflow.Completed},
+			wantNextState: states.PlanPostChecks,
+		},
+		{
+			name:        "Success: blocks run concurrently",
+			concurrency: 2,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.NotStarted),
+				newBlock(keys[1], workflow.NotStarted, keys[0]),
+				newBlock(keys[2], workflow.NotStarted, keys[0]),
+			},
+			wantStatuses:  []workflow.Status{workflow.Completed, workflow.Completed, workflow.Completed},
+			wantNextState: states.PlanPostChecks,
+		},
+		{
+			name:        "Success: finished blocks are not run again",
+			concurrency: 1,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.Skipped),
+				newBlock(keys[1], workflow.NotStarted, keys[0]),
+			},
+			wantStatuses:  []workflow.Status{workflow.Skipped, workflow.Completed},
+			wantNextState: states.PlanPostChecks,
+		},
+		{
+			name:        "Error: a failed block stops new blocks",
+			concurrency: 1,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.Failed),
+				newBlock(keys[1], workflow.NotStarted),
+			},
+			wantStatuses:  []workflow.Status{workflow.Failed, workflow.NotStarted},
+			wantNextState: states.PlanDeferredActions,
+		},
+		{
+			name:        "Error: a block is never ready",
+			concurrency: 1,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.NotStarted, keys[2]),
+			},
+			wantStatuses:  []workflow.Status{workflow.NotStarted},
+			wantErr:       true,
+			wantNextState: states.PlanDeferredActions,
+		},
+		{
+			name:        "Stop requested",
+			concurrency: 1,
+			blocks: []*workflow.Block{
+				newBlock(keys[0], workflow.NotStarted),
 			},
-			wantNextState: states.BlockBypassChecks,
+			stopped:       true,
+			wantStatuses:  []workflow.Status{workflow.NotStarted},
+			wantStopped:   true,
+			wantNextState: states.PlanDeferredActions,
 		},
 	}
 
 	for _, test := range tests {
 		states := &States{store: &fakeUpdater{}}
 		var blocks []block
-		if test.block.block != nil {
-			blocks = append(blocks, test.block)
+		for _, b := range test.blocks {
+			blocks = append(blocks, block{block: b, contCheckResult: newContResult()})
+		}
+		ctx := context.Background()
+		if test.stopped {
+			ctx = setStopping(ctx)
 		}
 		req := statemachine.Request[Data]{
-			Ctx: context.Background(),
+			Ctx: ctx,
 			Data: Data{
+				Plan:   &workflow.Plan{Blocks: test.blocks, Concurrency: test.concurrency},
 				blocks: blocks,
 			},
 		}
@@ -308,11 +381,206 @@ func TestExecuteBlocks(t *testing.T) {
 		if methodName(req.Next) != methodName(test.wantNextState) {
 			t.Errorf("TestExecuteBlocks(%s): got next state = %v, want %v", test.name, methodName(req.Next), methodName(test.wantNextState))
 		}
-		if len(req.Data.blocks) != 0 {
-			if req.Data.blocks[0].block.State.Get().Status != workflow.Running {
-				t.Errorf("TestExecuteBlocks(%s): got block state = %v, want %v", test.name, req.Data.blocks[0].block.State.Get().Status, workflow.Running)
+		if test.wantErr != (req.Data.err != nil) {
+			t.Errorf("TestExecuteBlocks(%s): got err == %v, want err == %v", test.name, req.Data.err, test.wantErr)
+		}
+		if req.Data.stopped != test.wantStopped {
+			t.Errorf("TestExecuteBlocks(%s): got stopped == %v, want stopped == %v", test.name, req.Data.stopped, test.wantStopped)
+		}
+		for i, b := range test.blocks {
+			if b.State.Get().Status != test.wantStatuses[i] {
+				t.Errorf("TestExecuteBlocks(%s): got block(%d) state = %v, want %v", test.name, i, b.State.Get().Status, test.wantStatuses[i])
+			}
+		}
+		for _, b := range test.blocks {
+			for _, k := range b.DependsOn {
+				for _, dep := range test.blocks {
+					if dep.Key == k && b.State.Get().Start.Before(dep.State.Get().End) {
+						t.Errorf("TestExecuteBlocks(%s): a block started before a block it depends on ended", test.name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestExecuteBlockPlanContChecksFail tests that the ContChecks of the Plan failing while two Blocks are
+// running is seen by both of them and that no more Blocks are started.
+func TestExecuteBlockPlanContChecksFail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		seqs         int
+		wantStatuses []workflow.Status
+	}{
+		{
+			name:         "running blocks start no more sequences",
+			seqs:         3,
+			wantStatuses: []workflow.Status{workflow.Failed, workflow.Failed, workflow.NotStarted},
+		},
+		{
+			name:         "no more blocks start",
+			seqs:         1,
+			wantStatuses: []workflow.Status{workflow.Completed, workflow.Completed, workflow.NotStarted},
+		},
+	}
+
+	for _, test := range tests {
+		build, err := builder.New("test", "test", builder.WithConcurrency(2))
+		if err != nil {
+			panic(err)
+		}
+
+		// Each Block pauses in its first sequence until the ContChecks of the Plan have failed.
+		pause := make(chan struct{})
+		var started []chan struct{}
+		for i := 0; i < len(test.wantStatuses); i++ {
+			build.AddBlock(
+				builder.BlockArgs{
+					Name:        fmt.Sprintf("block%d", i),
+					Descr:       "block",
+					Concurrency: 1,
+				},
+			)
+			for j := 0; j < test.seqs; j++ {
+				req := plugins.Req{}
+				if j == 0 {
+					req.Started = make(chan struct{})
+					req.PauseUntil = pause
+					started = append(started, req.Started)
+				}
+				build.AddSequence(&workflow.Sequence{Name: "seq", Descr: "seq"})
+				build.AddAction(&workflow.Action{Name: "action", Descr: "action", Plugin: plugins.Name, Timeout: 10 * time.Second, Req: req})
+				build.Up()
+			}
+			build.Up()
+		}
+
+		p, err := build.Plan()
+		if err != nil {
+			panic(err)
+		}
+		for _, b := range p.Blocks {
+			b.State.Set(workflow.State{})
+			for _, seq := range b.Sequences {
+				seq.State.Set(workflow.State{})
+				for _, action := range seq.Actions {
+					action.State.Set(workflow.State{})
+				}
 			}
 		}
+
+		plug := &plugins.Plugin{AlwaysRespond: true}
+		reg := registry.New()
+		reg.Register(plug)
+		// The fakeUpdater can't clone the channels in the requests.
+		states := &States{registry: reg, store: &noop.Vault{}}
+
+		var blocks []block
+		for _, b := range p.Blocks {
+			blocks = append(blocks, block{block: b, contCheckResult: newContResult()})
+		}
+		result := newContResult()
+		req := statemachine.Request[Data]{
+			Ctx:  context.Background(),
+			Data: Data{Plan: p, blocks: blocks, contCheckResult: result},
+		}
+
+		done := make(chan statemachine.Request[Data])
+		go func() { done <- states.ExecuteBlock(req) }()
+
+		// Only two Blocks run at a time, so we fail the ContChecks once both are running.
+		<-started[0]
+		<-started[1]
+		result.end(fmt.Errorf("error"))
+		close(pause)
+		req = <-done
+
+		if req.Data.err == nil {
+			t.Errorf("TestExecuteBlockPlanContChecksFail(%s): got err == nil, want err != nil", test.name)
+		}
+		if methodName(req.Next) != methodName(states.PlanDeferredActions) {
+			t.Errorf("TestExecuteBlockPlanContChecksFail(%s): got next state = %v, want %v", test.name, methodName(req.Next), methodName(states.PlanDeferredActions))
+		}
+		for i, b := range p.Blocks {
+			if b.State.Get().Status != test.wantStatuses[i] {
+				t.Errorf("TestExecuteBlockPlanContChecksFail(%s): got block(%d) status = %v, want %v", test.name, i, b.State.Get().Status, test.wantStatuses[i])
+			}
+			for j, seq := range b.Sequences[1:] {
+				if seq.State.Get().Status != workflow.NotStarted {
+					t.Errorf("TestExecuteBlockPlanContChecksFail(%s): got block(%d) seq(%d) status = %v, want %v", test.name, i, j+1, seq.State.Get().Status, workflow.NotStarted)
+				}
+			}
+		}
+	}
+}
+
+func TestStartBlock(t *testing.T) {
+	t.Parallel()
+
+	states := &States{} // Used to get the method name of a state for wantNextState
+
+	tests := []struct {
+		name            string
+		when            workflow.WhenCond
+		stopped         bool
+		wantBlockStatus workflow.Status
+		wantErr         bool
+		wantStopped     bool
+		wantNextState   statemachine.State[Data]
+	}{
+		{
+			name:            "Success: block starts",
+			wantBlockStatus: workflow.Running,
+			wantNextState:   states.BlockBypassChecks,
+		},
+		{
+			name:            "Success: When is false",
+			when:            "false",
+			wantBlockStatus: workflow.Skipped,
+		},
+		{
+			name:            "Error: When is not a bool",
+			when:            `meta.a`,
+			wantBlockStatus: workflow.Failed,
+			wantErr:         true,
+		},
+		{
+			name:            "Stop requested",
+			stopped:         true,
+			wantBlockStatus: workflow.NotStarted,
+			wantStopped:     true,
+		},
+	}
+
+	for _, test := range tests {
+		states := &States{store: &fakeUpdater{}}
+		b := &workflow.Block{When: test.when}
+		b.State.Set(workflow.State{})
+		ctx := context.Background()
+		if test.stopped {
+			ctx = setStopping(ctx)
+		}
+		req := statemachine.Request[Data]{
+			Ctx: ctx,
+			Data: Data{
+				blocks: []block{{block: b}},
+			},
+		}
+		req = states.StartBlock(req)
+		if methodName(req.Next) != methodName(test.wantNextState) {
+			t.Errorf("TestStartBlock(%s): got next state = %v, want %v", test.name, methodName(req.Next), methodName(test.wantNextState))
+		}
+		if test.wantErr != (req.Data.err != nil) {
+			t.Errorf("TestStartBlock(%s): got err == %v, want err == %v", test.name, req.Data.err, test.wantErr)
+		}
+		if req.Data.stopped != test.wantStopped {
+			t.Errorf("TestStartBlock(%s): got stopped == %v, want stopped == %v", test.name, req.Data.stopped, test.wantStopped)
+		}
+		if b.State.Get().Status != test.wantBlockStatus {
+			t.Errorf("TestStartBlock(%s): got block state = %v, want %v", test.name, b.State.Get().Status, test.wantBlockStatus)
+		}
 	}
 }
 
@@ -335,7 +603,7 @@ func TestBlockBypassChecks(t *testing.T) {
 				b.State.Set(workflow.State{})
 				return b
 			}(),
-			wantNextState: states.BlockPreChecks,
+			wantNextState: states.BlockApproval,
 		},
 		{
 			name: "BypassChecks succeed",
@@ -367,7 +635,7 @@ func TestBlockBypassChecks(t *testing.T) {
 				return fmt.Errorf("error")
 			},
 			wantBlockStatus: workflow.Running,
-			wantNextState:   states.BlockPreChecks,
+			wantNextState:   states.BlockApproval,
 		},
 	}
 
@@ -517,7 +785,7 @@ func TestBlockStartContChecks(t *testing.T) {
 						block: &workflow.Block{
 							ContChecks: contChecks,
 						},
-						contCheckResult: make(chan error, 1),
+						contCheckResult: newContResult(),
 					},
 				},
 			},
@@ -525,8 +793,9 @@ func TestBlockStartContChecks(t *testing.T) {
 
 		req = states.BlockStartContChecks(req)
 		if test.action != nil {
-			<-req.Data.blocks[0].contCheckResult
+			req.Data.blocks[0].contCancel()
 		}
+		req.Data.blocks[0].contCheckResult.wait()
 		if methodName(req.Next) != methodName(states.ExecuteSequences) {
 			t.Errorf("TestBlockStartContChecks(%s): got req.Next == %s, want req.Next == %s", test.name, methodName(req.Next), methodName(states.ExecuteSequences))
 		}
@@ -550,6 +819,7 @@ func TestExecuteSequences(t *testing.T) {
 		name            string
 		block           *workflow.Block
 		contCheckFail   bool
+		stop            bool
 		wantPluginCalls int
 		wantStatus      workflow.Status
 		wantErr         bool
@@ -597,6 +867,34 @@ func TestExecuteSequences(t *testing.T) {
 			wantStatus:      workflow.Failed,
 			wantErr:         true,
 		},
+		{
+			name: "Success: Tolerated failure percent not exceeded",
+			block: &workflow.Block{
+				ToleratedFailurePercent: 50,
+				Concurrency:             1,
+				Sequences: []*workflow.Sequence{
+					clone.Sequence(ctx, sequenceWithFailure, cloneOpts...),
+					clone.Sequence(ctx, sequenceWithSuccess, cloneOpts...),
+					clone.Sequence(ctx, sequenceWithSuccess, cloneOpts...),
+				},
+			},
+			wantPluginCalls: 3,
+		},
+		{
+			name: "Error: Exceed tolerated failure percent",
+			block: &workflow.Block{
+				ToleratedFailurePercent: 50,
+				Concurrency:             1,
+				Sequences: []*workflow.Sequence{
+					clone.Sequence(ctx, sequenceWithFailure, cloneOpts...),
+					clone.Sequence(ctx, sequenceWithFailure, cloneOpts...), // 50% of 3 rounds down to 1, we should die after this.
+					clone.Sequence(ctx, sequenceWithSuccess, cloneOpts...), // Never should be called.
+				},
+			},
+			wantPluginCalls: 2,
+			wantStatus:      workflow.Failed,
+			wantErr:         true,
+		},
 		{
 			name: "Error: Continuous Checks fail",
 			block: &workflow.Block{
@@ -610,6 +908,18 @@ func TestExecuteSequences(t *testing.T) {
 			wantStatus:    workflow.Failed,
 			wantErr:       true,
 		},
+		{
+			name: "Success: Stop requested, nothing is started",
+			block: &workflow.Block{
+				ToleratedFailures: 0,
+				Concurrency:       1,
+				Sequences: []*workflow.Sequence{
+					clone.Sequence(ctx, sequenceWithSuccess, cloneOpts...), // Never should be called.
+				},
+			},
+			stop:       true,
+			wantStatus: workflow.Stopped,
+		},
 		{
 			name: "Success",
 			block: &workflow.Block{
@@ -640,12 +950,14 @@ func TestExecuteSequences(t *testing.T) {
 		req := statemachine.Request[Data]{
 			Ctx: context.Background(),
 		}
+		if test.stop {
+			req.Ctx = setStopping(req.Ctx)
+		}
 		req.Data.blocks = []block{{block: test.block}}
 		test.block.State.Set(workflow.State{})
 		if test.contCheckFail {
-			req.Data.contCheckResult = make(chan error, 1)
-			req.Data.contCheckResult <- fmt.Errorf("error")
-			close(req.Data.contCheckResult)
+			req.Data.contCheckResult = newContResult()
+			req.Data.contCheckResult.end(fmt.Errorf("error"))
 		}
 
 		for _, seq := range test.block.Sequences {
@@ -664,6 +976,9 @@ func TestExecuteSequences(t *testing.T) {
 		if plug.Calls.Load() != int64(test.wantPluginCalls) {
 			t.Errorf("TestExecuteSequences(%s): got plugin calls == %v, want == %v", test.name, plug.Calls.Load(), test.wantPluginCalls)
 		}
+		if test.stop != req.Data.stopped {
+			t.Errorf("TestExecuteSequences(%s): got stopped == %v, want == %v", test.name, req.Data.stopped, test.stop)
+		}
 	}
 }
 
@@ -738,6 +1053,70 @@ func TestExecuteSequencesConcurrency(t *testing.T) {
 	}
 }
 
+func TestContChecksPassing(t *testing.T) {
+	t.Parallel()
+
+	// result returns a contResult. If ended is set, the checks have ended with err.
+	result := func(ended bool, err error) *contResult {
+		r := newContResult()
+		if ended {
+			r.end(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		plan     *contResult
+		block    *contResult
+		wantType workflow.ObjectType
+		wantErr  bool
+	}{
+		{
+			name:  "Success: checks running",
+			plan:  result(false, nil),
+			block: result(false, nil),
+		},
+		{
+			name:  "Success: checks passed",
+			plan:  result(true, nil),
+			block: result(true, nil),
+		},
+		{
+			name:     "Error: plan checks failed",
+			plan:     result(true, fmt.Errorf("error")),
+			block:    result(true, nil),
+			wantType: workflow.OTPlan,
+			wantErr:  true,
+		},
+		{
+			name:     "Error: block checks failed",
+			plan:     result(true, nil),
+			block:    result(true, fmt.Errorf("error")),
+			wantType: workflow.OTBlock,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		d := Data{contCheckResult: test.plan, blocks: []block{{contCheckResult: test.block}}}
+
+		// The result is read more than once, as every Block that is running reads it.
+		for range 2 {
+			gotType, err := d.contChecksPassing()
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("TestContChecksPassing(%s): got err == nil, want err != nil", test.name)
+			case !test.wantErr && err != nil:
+				t.Fatalf("TestContChecksPassing(%s): got err == %s, want err == nil", test.name, err)
+			}
+			if gotType != test.wantType {
+				t.Fatalf("TestContChecksPassing(%s): got type %v, want %v", test.name, gotType, test.wantType)
+			}
+		}
+	}
+}
+
 func TestBlockPostChecks(t *testing.T) {
 	t.Parallel()
 
@@ -820,18 +1199,14 @@ func TestBlockPostChecks(t *testing.T) {
 func TestBlockEnd(t *testing.T) {
 	t.Parallel()
 
-	// This is simply used to get the name next State we expect.
-	// We create new ones in the tests to avoid having a shared one.
-	states := &States{}
-
 	tests := []struct {
 		name            string
 		data            Data
 		contCheckResult error
 		wantErr         bool
 		wantBlockStatus workflow.Status
-		wantNextState   statemachine.State[Data]
-		wantBlocksLen   int
+		wantDAStatus    workflow.Status
+		wantBatches     []workflow.Status
 	}{
 		{
 			name: "Error: contchecks failure",
@@ -841,8 +1216,6 @@ func TestBlockEnd(t *testing.T) {
 			contCheckResult: fmt.Errorf("error"),
 			wantErr:         true,
 			wantBlockStatus: workflow.Failed,
-			wantNextState:   states.PlanDeferredActions,
-			wantBlocksLen:   1,
 		},
 		{
 			name: "Success: bypasschecks success",
@@ -855,32 +1228,89 @@ func TestBlockEnd(t *testing.T) {
 					return b
 				}()}},
 			},
-			wantBlockStatus: workflow.Completed,
-			wantNextState:   states.ExecuteBlock,
+			wantBlockStatus: workflow.Skipped,
 		},
 		{
-			name: "Success: no more blocks",
+			name: "Success: block completed",
 			data: Data{
 				blocks: []block{{}},
 			},
 			wantBlockStatus: workflow.Completed,
-			wantNextState:   states.ExecuteBlock,
-			wantBlocksLen:   0,
 		},
 		{
-			name: "Success: more blocks",
+			name: "Success: block completed runs OnSuccess DeferredActions",
 			data: Data{
-				blocks: []block{{}, {}},
+				blocks: []block{{block: &workflow.Block{
+					DeferredActions: newDA(
+						newDABatch(workflow.OnFailure, false, "untouched"),
+						newDABatch(workflow.OnSuccess, true, "ok"),
+					),
+				}}},
 			},
 			wantBlockStatus: workflow.Completed,
-			wantNextState:   states.ExecuteBlock,
-			wantBlocksLen:   1,
+			wantDAStatus:    workflow.Completed,
+			wantBatches:     []workflow.Status{workflow.NotStarted, workflow.Completed},
+		},
+		{
+			name: "Error: contchecks failure runs OnFailure DeferredActions",
+			data: Data{
+				blocks: []block{{block: &workflow.Block{
+					ContChecks: &workflow.Checks{},
+					DeferredActions: newDA(
+						newDABatch(workflow.OnFailure, false, "ok"),
+						newDABatch(workflow.OnSuccess, false, "untouched"),
+					),
+				}}},
+			},
+			contCheckResult: fmt.Errorf("error"),
+			wantErr:         true,
+			wantBlockStatus: workflow.Failed,
+			wantDAStatus:    workflow.Completed,
+			wantBatches:     []workflow.Status{workflow.Completed, workflow.NotStarted},
+		},
+		{
+			name: "Error: FailElement DeferredActions batch fails the block",
+			data: Data{
+				blocks: []block{{block: &workflow.Block{
+					DeferredActions: newDA(newDABatch(workflow.Always, true, "error")),
+				}}},
+			},
+			wantErr:         true,
+			wantBlockStatus: workflow.Failed,
+			wantDAStatus:    workflow.Failed,
+			wantBatches:     []workflow.Status{workflow.Failed},
+		},
+		{
+			name: "Success: bypassed block does not run DeferredActions",
+			data: Data{
+				blocks: []block{{block: func() *workflow.Block {
+					b := &workflow.Block{DeferredActions: newDA(newDABatch(workflow.Always, false, "untouched"))}
+					c := &workflow.Checks{}
+					c.State.Set(workflow.State{Status: workflow.Completed})
+					b.BypassChecks = c
+					return b
+				}()}},
+			},
+			wantBlockStatus: workflow.Skipped,
+			wantDAStatus:    workflow.NotStarted,
+			wantBatches:     []workflow.Status{workflow.NotStarted},
 		},
 	}
 
 	for _, test := range tests {
 		states := &States{
 			store: &fakeUpdater{},
+			testActionRunner: func(ctx context.Context, action *workflow.Action, updater storage.ActionUpdater) error {
+				state := action.State.Get()
+				state.Status = workflow.Completed
+				if action.Name == "error" {
+					state.Status = workflow.Failed
+					action.State.Set(state)
+					return fmt.Errorf("error")
+				}
+				action.State.Set(state)
+				return nil
+			},
 		}
 		for i, block := range test.data.blocks {
 			if block.block == nil {
@@ -900,14 +1330,9 @@ func TestBlockEnd(t *testing.T) {
 			Data: test.data,
 		}
 
-		req.Data.blocks[0].contCheckResult = make(chan error, 1)
-		if test.contCheckResult != nil {
-			req.Data.blocks[0].contCheckResult = make(chan error, 1)
-			req.Data.blocks[0].contCheckResult <- test.contCheckResult
-		}
-		close(req.Data.blocks[0].contCheckResult)
+		req.Data.blocks[0].contCheckResult = newContResult()
+		req.Data.blocks[0].contCheckResult.end(test.contCheckResult)
 
-		// We store this here because blocks is shrunk after the call.
 		block := req.Data.blocks[0].block
 
 		req = states.BlockEnd(req)
@@ -918,11 +1343,8 @@ func TestBlockEnd(t *testing.T) {
 		if block.State.Get().Status != test.wantBlockStatus {
 			t.Errorf("TestBlockEnd(%s): got block status == %v, want block status == %v", test.name, block.State.Get().Status, test.wantBlockStatus)
 		}
-		if methodName(req.Next) != methodName(test.wantNextState) {
-			t.Errorf("TestBlockEnd(%s): got next state == %v, want next state == %v", test.name, methodName(req.Next), methodName(test.wantNextState))
-		}
-		if len(req.Data.blocks) != test.wantBlocksLen {
-			t.Errorf("TestBlockEnd(%s): got blocks len == %v, want blocks len == %v", test.name, len(req.Data.blocks), test.wantBlocksLen)
+		if req.Next != nil {
+			t.Errorf("TestBlockEnd(%s): got next state == %v, want next state == nil", test.name, methodName(req.Next))
 		}
 		if ctx.Err() == nil {
 			if block.BypassChecks == nil {
@@ -931,8 +1353,19 @@ func TestBlockEnd(t *testing.T) {
 				t.Errorf("TestBlockEnd(%s): context for continuous checks should have been cancelled", test.name)
 			}
 		}
-		if states.store.(*fakeUpdater).calls.Load() != 1 {
-			t.Errorf("TestBlockEnd(%s): got store calls == %v, want store calls == 1", test.name, states.store.(*fakeUpdater).calls.Load())
+		if len(states.store.(*fakeUpdater).blocks) != 1 {
+			t.Errorf("TestBlockEnd(%s): got block writes == %v, want block writes == 1", test.name, len(states.store.(*fakeUpdater).blocks))
+		}
+		if block.DeferredActions == nil {
+			continue
+		}
+		if got := block.DeferredActions.State.Get().Status; got != test.wantDAStatus {
+			t.Errorf("TestBlockEnd(%s): got DeferredActions status == %v, want %v", test.name, got, test.wantDAStatus)
+		}
+		for i, b := range block.DeferredActions.DeferredBatches {
+			if got := b.State.Get().Status; got != test.wantBatches[i] {
+				t.Errorf("TestBlockEnd(%s): got batch[%d] status == %v, want %v", test.name, i, got, test.wantBatches[i])
+			}
 		}
 	}
 }
@@ -995,13 +1428,10 @@ func TestPlanPostChecks(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 
 		// Simulates that we are done waiting for the continuous checks.`
-		var results chan error
+		var results *contResult
 		if test.plan.ContChecks != nil {
-			results = make(chan error, 1)
-			if test.contCheckResult != nil {
-				results <- test.contCheckResult
-			}
-			close(results)
+			results = newContResult()
+			results.end(test.contCheckResult)
 		}
 
 		req := statemachine.Request[Data]{
@@ -1065,6 +1495,51 @@ func TestEnd(t *testing.T) {
 	}
 }
 
+func TestStopUnfinished(t *testing.T) {
+	t.Parallel()
+
+	completed := &workflow.Sequence{Name: "completed"}
+	completed.State.Set(workflow.State{Status: workflow.Completed})
+	running := &workflow.Sequence{Name: "running", Actions: []*workflow.Action{{Name: "running"}, {Name: "notStarted"}}}
+	running.State.Set(workflow.State{Status: workflow.Running})
+	running.Actions[0].State.Set(workflow.State{Status: workflow.Running})
+	running.Actions[1].State.Set(workflow.State{Status: workflow.NotStarted})
+
+	b := &workflow.Block{Sequences: []*workflow.Sequence{completed, running}}
+	b.State.Set(workflow.State{Status: workflow.Stopped})
+
+	batch := &workflow.DeferBatch{When: workflow.OnSuccess}
+	batch.State.Set(workflow.State{Status: workflow.NotStarted})
+	da := &workflow.DeferredActions{DeferredBatches: []*workflow.DeferBatch{batch}}
+	da.State.Set(workflow.State{Status: workflow.Completed})
+
+	plan := &workflow.Plan{Blocks: []*workflow.Block{b}, DeferredActions: da}
+	plan.State.Set(workflow.State{Status: workflow.Running})
+
+	states := &States{}
+	states.stopUnfinished(plan)
+
+	tests := []struct {
+		name string
+		obj  stater
+		want workflow.Status
+	}{
+		{name: "Plan is not touched", obj: plan, want: workflow.Running},
+		{name: "Stopped Block stays Stopped", obj: b, want: workflow.Stopped},
+		{name: "Completed Sequence stays Completed", obj: completed, want: workflow.Completed},
+		{name: "Running Sequence is Stopped", obj: running, want: workflow.Stopped},
+		{name: "Running Action is Stopped", obj: running.Actions[0], want: workflow.Stopped},
+		{name: "NotStarted Action is Stopped", obj: running.Actions[1], want: workflow.Stopped},
+		{name: "unselected DeferBatch is not touched", obj: batch, want: workflow.NotStarted},
+	}
+
+	for _, test := range tests {
+		if got := test.obj.GetState().Status; got != test.want {
+			t.Errorf("TestStopUnfinished(%s): got status %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
 // TestRuntimeUpdate tests the runtimeUpdate function to ensure it correctly
 // updates the plan when more than 5 minutes have passed since the last update.
 // This tests the fix for a bug where the time comparison was backwards
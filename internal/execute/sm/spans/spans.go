@@ -0,0 +1,104 @@
+// Package spans has helpers for the OpenTelemetry spans recorded for the objects in a Plan.
+package spans
+
+import (
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// TracerName is the name of the trace.Tracer used for the spans of a Plan.
+const TracerName = "github.com/element-of-surprise/coercion"
+
+// Attribute keys that are set on our spans.
+const (
+	// PlanID is the ID of the Plan the object belongs to.
+	PlanID = attribute.Key("coercion.plan.id")
+	// ID is the ID of the object.
+	ID = attribute.Key("coercion.id")
+	// Name is the name of the object.
+	Name = attribute.Key("coercion.name")
+	// Status is the final Status of the object.
+	Status = attribute.Key("coercion.status")
+	// Recovered is set on a Plan's span if the Plan was recovered.
+	Recovered = attribute.Key("coercion.recovered")
+	// Plugin is the name of the plugin an Action uses.
+	Plugin = attribute.Key("coercion.plugin")
+	// Retry is the retry number of an Attempt, starting at 0 for the first Attempt.
+	Retry = attribute.Key("coercion.retry")
+	// ErrCode is the plugins.Error code of an Attempt that failed.
+	ErrCode = attribute.Key("coercion.error.code")
+)
+
+// tracerKey is the key for the trace.Tracer of a Plan in context.Value.
+type tracerKey struct{}
+
+// StartPlan starts the root span for plan. If tp is nil, the TracerProvider of the span in ctx is used.
+// If ctx already has a span, such as that of the request that started the Plan, the Plan's span is its child.
+// The Tracer is attached to the returned Context and used by Start. We can't rely on the TracerProvider of
+// the span in the Context for this, as other packages may put non-recording spans there.
+func StartPlan(ctx context.Context, tp trace.TracerProvider, plan *workflow.Plan, recovered bool) (context.Context, trace.Span) {
+	if tp == nil {
+		tp = trace.SpanFromContext(ctx).TracerProvider()
+	}
+	t := tp.Tracer(TracerName)
+	ctx = context.WithValue(ctx, tracerKey{}, t)
+	return t.Start(
+		ctx,
+		"plan "+plan.Name,
+		trace.WithAttributes(
+			PlanID.String(plan.ID.String()),
+			ID.String(plan.ID.String()),
+			Name.String(plan.Name),
+			Recovered.Bool(recovered),
+		),
+	)
+}
+
+// Start starts a span for an object in a Plan that is a child of the span in ctx. kind is the kind of
+// object, such as "block", and is used with the name to name the span.
+func Start(ctx context.Context, kind string, id uuid.UUID, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(
+		attrs,
+		PlanID.String(context.PlanID(ctx).String()),
+		ID.String(id.String()),
+		Name.String(name),
+	)
+	spanName := kind
+	if name != "" {
+		spanName += " " + name
+	}
+	return tracer(ctx).Start(
+		ctx,
+		spanName,
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// tracer returns the trace.Tracer attached by StartPlan. If there is none, the Tracer is taken from the
+// span in ctx.
+func tracer(ctx context.Context) trace.Tracer {
+	if t, ok := ctx.Value(tracerKey{}).(trace.Tracer); ok {
+		return t
+	}
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+}
+
+// End records the final Status of an object and err on span and ends it.
+func End(span trace.Span, status workflow.Status, err error) {
+	span.SetAttributes(Status.String(status.String()))
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case status == workflow.Failed:
+		span.SetStatus(codes.Error, status.String())
+	default:
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
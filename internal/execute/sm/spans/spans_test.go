@@ -0,0 +1,53 @@
+package spans
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+func TestSpans(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		status     workflow.Status
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "Completed", status: workflow.Completed, wantStatus: codes.Ok},
+		{name: "Stopped", status: workflow.Stopped, wantStatus: codes.Ok},
+		{name: "Failed", status: workflow.Failed, wantStatus: codes.Error},
+		{name: "Error", status: workflow.Completed, err: errors.New("error"), wantStatus: codes.Error},
+	}
+
+	for _, test := range tests {
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		plan := &workflow.Plan{ID: workflow.NewV7(), Name: "plan"}
+		ctx := context.SetPlanID(context.Background(), plan.ID)
+		ctx, planSpan := StartPlan(ctx, tp, plan, false)
+		_, span := Start(ctx, "block", workflow.NewV7(), "block")
+		End(span, test.status, test.err)
+		End(planSpan, workflow.Completed, nil)
+
+		ended := recorder.Ended()
+		if len(ended) != 2 {
+			t.Fatalf("TestSpans(%s): got %d spans, want 2", test.name, len(ended))
+		}
+		child, parent := ended[0], ended[1]
+		if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("TestSpans(%s): child span is not a child of the Plan's span", test.name)
+		}
+		if child.Status().Code != test.wantStatus {
+			t.Errorf("TestSpans(%s): got span status %v, want %v", test.name, child.Status().Code, test.wantStatus)
+		}
+	}
+}
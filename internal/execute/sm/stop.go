@@ -0,0 +1,155 @@
+package sm
+
+import (
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/utils/walk"
+
+	"github.com/gostdlib/base/statemachine"
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// stoppingKey is a key for the channel in context.Value that is closed once the Plan is Stopping.
+type stoppingKey struct{}
+
+// stopWatch watches for a Stop request on a Plan. It is stopped by End.
+type stopWatch struct {
+	// end is closed by End to end the watch.
+	end chan struct{}
+	// done is closed when the watch has exited.
+	done chan struct{}
+}
+
+// close ends the watch and waits for it to exit. This is safe to call on a nil stopWatch.
+func (w *stopWatch) close() {
+	if w == nil {
+		return
+	}
+	close(w.end)
+	<-w.done
+}
+
+// watchStop watches req.Data.Stop for a Stop request. When one arrives, the Plan's Status is changed to
+// Stopping and written to storage before any state can see the Stop via stopRequested. Writing the
+// Status first means that if we crash while stopping, recovery finishes the stop instead of restarting
+// the Plan.
+func (s *States) watchStop(req statemachine.Request[Data]) statemachine.Request[Data] {
+	stopping := make(chan struct{})
+	req.Ctx = context.WithValue(req.Ctx, stoppingKey{}, (<-chan struct{})(stopping))
+
+	w := &stopWatch{end: make(chan struct{}), done: make(chan struct{})}
+	req.Data.stopWatch = w
+
+	ctx := req.Ctx
+	plan := req.Data.Plan
+	stop := req.Data.Stop
+	pause := req.Data.Pause
+	context.Pool(ctx).Submit(
+		ctx,
+		func() {
+			defer close(w.done)
+
+			select {
+			case <-w.end:
+				return
+			case <-stop:
+			}
+			pause.do(func() { s.markStopping(ctx, plan) })
+			close(stopping)
+		},
+	)
+	return req
+}
+
+// markStopping changes a Running or Paused Plan to Stopping and writes it to storage.
+func (s *States) markStopping(ctx context.Context, plan *workflow.Plan) {
+	state := plan.State.Get()
+	if state.Status != workflow.Running && state.Status != workflow.Paused {
+		return
+	}
+	state.Status = workflow.Stopping
+	plan.State.Set(state)
+	if err := s.store.UpdatePlan(context.WithoutCancel(ctx), plan); err != nil {
+		log.Fatalf("failed to write Plan: %v", err)
+	}
+}
+
+// setStopping attaches an already closed stopping channel to the Context. This is used when we recover
+// a Plan that was already Stopping.
+func setStopping(ctx context.Context) context.Context {
+	stopping := make(chan struct{})
+	close(stopping)
+	return context.WithValue(ctx, stoppingKey{}, (<-chan struct{})(stopping))
+}
+
+// stopping returns the channel that is closed once a Stop has been recorded for the Plan. If there
+// is none, this returns a nil channel, which blocks forever.
+func stopping(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(stoppingKey{}).(<-chan struct{})
+	return ch
+}
+
+// stopRequested reports if a Stop was requested for the Plan and it has been recorded in storage.
+func stopRequested(ctx context.Context) bool {
+	select {
+	case <-stopping(ctx):
+		return true
+	default:
+		return false
+	}
+}
+
+// seqsStopped reports if any Sequence in the Block was stopped.
+func seqsStopped(b *workflow.Block) bool {
+	for _, seq := range b.Sequences {
+		if seq.State.Get().Status == workflow.Stopped {
+			return true
+		}
+	}
+	return false
+}
+
+// stopBlock marks the Block as Stopped.
+func stopBlock(b *workflow.Block) {
+	state := b.State.Get()
+	state.Status = workflow.Stopped
+	b.State.Set(state)
+}
+
+// stopUnfinished marks all objects in the Plan that are NotStarted or Running as Stopped. DeferredActions are
+// not touched, as batches that were not selected to run are expected to stay NotStarted.
+func (s *States) stopUnfinished(plan *workflow.Plan) {
+	for item := range walk.Plan(plan) {
+		switch item.Value.Type() {
+		case workflow.OTPlan, workflow.OTDeferredActions, workflow.OTBatch:
+			continue
+		}
+		if inDeferredActions(item) || isUndo(item) {
+			continue
+		}
+		st := item.Value.(stater)
+		state := st.GetState()
+		switch state.Status {
+		case workflow.NotStarted, workflow.Running:
+			state.Status = workflow.Stopped
+			state.End = s.now()
+			st.SetState(state)
+		}
+	}
+}
+
+// isUndo reports if the item is the Undo of an Action. An Undo only runs if its Action's Sequence
+// or Block fails, so it is left as it is.
+func isUndo(item walk.Item) bool {
+	return len(item.Chain) > 0 && item.Chain[len(item.Chain)-1].Type() == workflow.OTAction
+}
+
+// inDeferredActions reports if the item is inside of a DeferredActions.
+func inDeferredActions(item walk.Item) bool {
+	for _, o := range item.Chain {
+		if o.Type() == workflow.OTDeferredActions {
+			return true
+		}
+	}
+	return false
+}
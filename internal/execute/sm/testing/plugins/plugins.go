@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/element-of-surprise/coercion/plugins"
+	"github.com/element-of-surprise/coercion/workflow"
 	"github.com/element-of-surprise/coercion/workflow/context"
 
+	"github.com/google/uuid"
 	"github.com/gostdlib/base/retry/exponential"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Name is the name of the testing plugin.
@@ -26,6 +29,29 @@ type Req struct {
 	Started chan struct{} `json:"-"`
 	// PauseUntil is a channel that Execute() will block on until closed.
 	PauseUntil chan struct{} `json:"-"`
+	// From is the Key of an earlier Action. If set, Arg is replaced by the Arg of that Action's Resp.
+	From uuid.UUID
+}
+
+// Inputs implements workflow.Resolver.Inputs().
+func (r Req) Inputs() []workflow.Input {
+	if r.From == uuid.Nil {
+		return nil
+	}
+	return []workflow.Input{{Key: r.From, Resp: Resp{}}}
+}
+
+// Resolve implements workflow.Resolver.Resolve().
+func (r Req) Resolve(resps map[uuid.UUID]any) (any, error) {
+	if r.From == uuid.Nil {
+		return r, nil
+	}
+	resp, ok := resps[r.From].(Resp)
+	if !ok {
+		return nil, fmt.Errorf("response for Key(%s) was %T, not Resp", r.From, resps[r.From])
+	}
+	r.Arg = resp.Arg
+	return r, nil
 }
 
 type Resp struct {
@@ -103,6 +129,9 @@ func (h *Plugin) Execute(ctx context.Context, req any) (any, *plugins.Error) {
 		if r.Arg == "error" {
 			return nil, &plugins.Error{Message: "error"}
 		}
+		if arg, ok := strings.CutPrefix(r.Arg, "echo:"); ok {
+			return Resp{Arg: arg}, nil
+		}
 		if strings.ToLower(r.Arg) == "actionid" {
 			id := context.ActionID(ctx).String()
 			return Resp{Arg: id}, nil
@@ -111,6 +140,10 @@ func (h *Plugin) Execute(ctx context.Context, req any) (any, *plugins.Error) {
 			id := context.PlanID(ctx).String()
 			return Resp{Arg: id}, nil
 		}
+		if strings.ToLower(r.Arg) == "spanid" {
+			id := trace.SpanContextFromContext(ctx).SpanID().String()
+			return Resp{Arg: id}, nil
+		}
 		return Resp{Arg: "ok"}, nil
 	}
 
@@ -0,0 +1,79 @@
+package sm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+
+	"github.com/gostdlib/base/statemachine"
+)
+
+// timeoutKey is a key for the channel in context.Value that is closed once the Plan or Block has run
+// past its deadline.
+type timeoutKey struct{}
+
+// withTimeout sets the deadline of a Plan or Block that started at start and has Timeout d. The deadline
+// is given to plugins with context.SetDeadline and a channel that is closed at the deadline is attached to
+// the Context. If the Context already has an earlier deadline, such as the Plan's for a Block, that one is
+// used. The returned function releases the timer. If d <= 0, ctx is returned as it is.
+func (s *States) withTimeout(ctx context.Context, start time.Time, d time.Duration) (context.Context, func()) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	ctx = context.SetDeadline(ctx, start.Add(d))
+	deadline, _ := context.Deadline(ctx)
+
+	ch := make(chan struct{})
+	t := time.AfterFunc(deadline.Sub(s.now()), func() { close(ch) })
+	return context.WithValue(ctx, timeoutKey{}, (<-chan struct{})(ch)), func() { t.Stop() }
+}
+
+// expired returns the channel that is closed once the Plan or Block has run past its deadline. If there
+// is no deadline, this returns a nil channel, which blocks forever.
+func expired(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(timeoutKey{}).(<-chan struct{})
+	return ch
+}
+
+// timedOut reports if the Plan or Block has run past its deadline.
+func timedOut(ctx context.Context) bool {
+	select {
+	case <-expired(ctx):
+		return true
+	default:
+		return false
+	}
+}
+
+// planTimeoutErr returns the error for a Plan that ran past its Timeout.
+func planTimeoutErr(p *workflow.Plan) error {
+	return fmt.Errorf("plan did not finish within its Timeout(%v): %w", p.Timeout, ErrTimeout)
+}
+
+// timeoutBlock fails the current Block because it or the Plan ran past its deadline. The Block's
+// DeferredChecks and DeferredActions still run.
+func (s *States) timeoutBlock(req statemachine.Request[Data]) statemachine.Request[Data] {
+	h := req.Data.blocks[0]
+
+	state := h.block.State.Get()
+	state.Status = workflow.Failed
+	h.block.State.Set(state)
+	req.Data.timedOut = true
+	req.Data.err = fmt.Errorf("block(%s) did not finish before its deadline: %w", h.block.Name, ErrTimeout)
+	req.Next = s.BlockDeferredChecks
+	return req
+}
+
+// seqsFinished reports if every Sequence in the Block has finished running.
+func seqsFinished(b *workflow.Block) bool {
+	for _, seq := range b.Sequences {
+		switch seq.State.Get().Status {
+		case workflow.Completed, workflow.Failed, workflow.Skipped:
+		default:
+			return false
+		}
+	}
+	return true
+}
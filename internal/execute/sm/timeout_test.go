@@ -0,0 +1,221 @@
+package sm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/internal/execute/sm/testing/plugins"
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/utils/clone"
+
+	"github.com/gostdlib/base/statemachine"
+)
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	s := &States{}
+	now := s.now()
+
+	ctx, stop := s.withTimeout(context.Background(), now, 0)
+	stop()
+	if _, ok := context.Deadline(ctx); ok {
+		t.Errorf("TestWithTimeout: got a deadline with no Timeout")
+	}
+	if expired(ctx) != nil {
+		t.Errorf("TestWithTimeout: got an expired channel with no Timeout")
+	}
+
+	planCtx, stopPlan := s.withTimeout(context.Background(), now, time.Hour)
+	defer stopPlan()
+	if timedOut(planCtx) {
+		t.Errorf("TestWithTimeout: Plan timed out before its deadline")
+	}
+
+	// A Block whose Timeout ends after the Plan's is held to the Plan's deadline.
+	blockCtx, stopBlock := s.withTimeout(planCtx, now, 2*time.Hour)
+	defer stopBlock()
+	if got, _ := context.Deadline(blockCtx); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("TestWithTimeout: got Block deadline %v, want the Plan's %v", got, now.Add(time.Hour))
+	}
+
+	// A Block that started before its Timeout has passed is timed out right away.
+	blockCtx, stopBlock = s.withTimeout(planCtx, now.Add(-time.Minute), time.Second)
+	defer stopBlock()
+	select {
+	case <-expired(blockCtx):
+	case <-time.After(10 * time.Second):
+		t.Fatalf("TestWithTimeout: Block did not time out")
+	}
+	if timedOut(planCtx) {
+		t.Errorf("TestWithTimeout: the Plan timed out with its Block")
+	}
+	if err := after(blockCtx, time.Hour); !errors.Is(err, ErrTimeout) {
+		t.Errorf("TestWithTimeout: after() got err == %v, want ErrTimeout", err)
+	}
+}
+
+func TestExecuteSequencesTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	slowAction := &workflow.Action{Plugin: plugins.Name, Timeout: 10 * time.Second, Req: plugins.Req{Sleep: 100 * time.Millisecond}}
+	successAction := &workflow.Action{Plugin: plugins.Name, Timeout: 10 * time.Second, Req: plugins.Req{Arg: "success"}}
+
+	tests := []struct {
+		name            string
+		start           time.Duration
+		timeout         time.Duration
+		seqs            []*workflow.Sequence
+		wantPluginCalls int
+		wantStatus      workflow.Status
+		wantSeqStatus   []workflow.Status
+		wantTimedOut    bool
+	}{
+		{
+			name:    "Success: finished before the Timeout",
+			timeout: time.Hour,
+			seqs: []*workflow.Sequence{
+				{Actions: []*workflow.Action{clone.Action(ctx, successAction, cloneOpts...)}},
+			},
+			wantPluginCalls: 1,
+			wantStatus:      workflow.Running,
+			wantSeqStatus:   []workflow.Status{workflow.Completed},
+		},
+		{
+			name:    "Error: Timeout passed before the Block ran sequences",
+			start:   -time.Minute,
+			timeout: time.Second,
+			seqs: []*workflow.Sequence{
+				{Actions: []*workflow.Action{clone.Action(ctx, successAction, cloneOpts...)}},
+			},
+			wantStatus:    workflow.Failed,
+			wantSeqStatus: []workflow.Status{workflow.NotStarted},
+			wantTimedOut:  true,
+		},
+		{
+			name:    "Error: Timeout passed while a sequence was running",
+			timeout: 20 * time.Millisecond,
+			seqs: []*workflow.Sequence{
+				{
+					Actions: []*workflow.Action{
+						clone.Action(ctx, slowAction, cloneOpts...),
+						clone.Action(ctx, successAction, cloneOpts...), // Never should be called.
+					},
+				},
+				{Actions: []*workflow.Action{clone.Action(ctx, successAction, cloneOpts...)}}, // Never should be called.
+			},
+			wantPluginCalls: 1,
+			wantStatus:      workflow.Failed,
+			wantSeqStatus:   []workflow.Status{workflow.Stopped, workflow.NotStarted},
+			wantTimedOut:    true,
+		},
+	}
+
+	plug := &plugins.Plugin{AlwaysRespond: true}
+	reg := registry.New()
+	reg.Register(plug)
+
+	for _, test := range tests {
+		plug.ResetCounts()
+
+		states := States{
+			registry: reg,
+			store:    &fakeUpdater{},
+		}
+
+		b := &workflow.Block{Concurrency: 1, Timeout: test.timeout, Sequences: test.seqs}
+		b.State.Set(workflow.State{Status: workflow.Running, Start: states.now().Add(test.start)})
+		for _, seq := range b.Sequences {
+			seq.State.Set(workflow.State{})
+			for _, action := range seq.Actions {
+				action.State.Set(workflow.State{})
+			}
+		}
+
+		req := statemachine.Request[Data]{Ctx: context.Background()}
+		req.Data.blocks = []block{{block: b}}
+		req = states.startBlockTimer(req)
+		// Let a Timeout that has already passed fire.
+		time.Sleep(10 * time.Millisecond)
+		req = states.ExecuteSequences(req)
+		req.Data.blocks[0].stopTimer()
+
+		if got := b.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestExecuteSequencesTimeout(%s): got status == %v, want == %v", test.name, got, test.wantStatus)
+		}
+		if req.Data.timedOut != test.wantTimedOut {
+			t.Errorf("TestExecuteSequencesTimeout(%s): got timedOut == %v, want == %v", test.name, req.Data.timedOut, test.wantTimedOut)
+		}
+		if test.wantTimedOut && !errors.Is(req.Data.err, ErrTimeout) {
+			t.Errorf("TestExecuteSequencesTimeout(%s): got err == %v, want ErrTimeout", test.name, req.Data.err)
+		}
+		if plug.Calls.Load() != int64(test.wantPluginCalls) {
+			t.Errorf("TestExecuteSequencesTimeout(%s): got plugin calls == %v, want == %v", test.name, plug.Calls.Load(), test.wantPluginCalls)
+		}
+		for i, seq := range b.Sequences {
+			if got := seq.State.Get().Status; got != test.wantSeqStatus[i] {
+				t.Errorf("TestExecuteSequencesTimeout(%s): sequence[%d] got status == %v, want == %v", test.name, i, got, test.wantSeqStatus[i])
+			}
+		}
+	}
+}
+
+func TestFinalStatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	failedDA := &workflow.DeferredActions{}
+	failedDA.State.Set(workflow.State{Status: workflow.Failed})
+
+	tests := []struct {
+		name       string
+		plan       func() *workflow.Plan
+		wantReason workflow.FailureReason
+	}{
+		{
+			name: "Success: Timeout with checks and blocks that did not finish",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					PostChecks: newChecksWithState(&workflow.State{Status: workflow.NotStarted}),
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Failed}),
+						newBlockWithState(&workflow.State{Status: workflow.NotStarted}),
+					},
+				}
+			},
+			wantReason: workflow.FRTimeout,
+		},
+		{
+			name: "Success: Timeout does not hide failed DeferredActions",
+			plan: func() *workflow.Plan {
+				return &workflow.Plan{
+					DeferredActions: failedDA,
+					Blocks: []*workflow.Block{
+						newBlockWithState(&workflow.State{Status: workflow.Failed}),
+					},
+				}
+			},
+			wantReason: workflow.FRDeferredAction,
+		},
+	}
+
+	for _, test := range tests {
+		plan := test.plan()
+		plan.State.Set(workflow.State{Status: workflow.Running})
+
+		f := finalStates{}
+		req := statemachine.Request[Data]{Ctx: context.Background(), Data: Data{Plan: plan, timedOut: true}, Next: f.start}
+		statemachine.Run("finalStates", req)
+
+		if got := plan.State.Get().Status; got != workflow.Failed {
+			t.Errorf("TestFinalStatesTimeout(%s): got status %v, want %v", test.name, got, workflow.Failed)
+		}
+		if plan.Reason != test.wantReason {
+			t.Errorf("TestFinalStatesTimeout(%s): got reason %v, want %v", test.name, plan.Reason, test.wantReason)
+		}
+	}
+}
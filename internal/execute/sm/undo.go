@@ -0,0 +1,61 @@
+package sm
+
+import (
+	"slices"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// runUndos runs the Undo of each Action in actions that Completed, in the reverse order that the Actions
+// finished. An Undo that has already finished is not run again. If an Undo fails, the Undos after it are
+// not run and its error is returned. The failure is recorded in the State of the Undo and does not change
+// the error of the Sequence or Block that failed.
+func (s *States) runUndos(ctx context.Context, actions []*workflow.Action) error {
+	for _, a := range undoOrder(actions) {
+		if err := s.runAction(ctx, a.Undo, s.store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undosFinished reports if the Undos of actions have finished. That is if every Undo that runs has
+// Completed or if one has Failed, which stops the rest.
+func undosFinished(actions []*workflow.Action) bool {
+	for _, a := range undoOrder(actions) {
+		switch a.Undo.State.Get().Status {
+		case workflow.Completed, workflow.Skipped:
+		case workflow.Failed:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// undoOrder returns the Actions in actions that Completed and have an Undo in the order their Undos
+// run, which is the reverse of the order that they finished. Actions that finished at the same time
+// are in the reverse of the order they are in actions.
+func undoOrder(actions []*workflow.Action) []*workflow.Action {
+	var order []*workflow.Action
+	for _, a := range slices.Backward(actions) {
+		if a.Undo != nil && a.State.Get().Status == workflow.Completed {
+			order = append(order, a)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b *workflow.Action) int {
+		return b.State.Get().End.Compare(a.State.Get().End)
+	})
+	return order
+}
+
+// blockActions returns the Actions in the Sequences of b.
+func blockActions(b *workflow.Block) []*workflow.Action {
+	var actions []*workflow.Action
+	for _, seq := range b.Sequences {
+		actions = append(actions, seq.Actions...)
+	}
+	return actions
+}
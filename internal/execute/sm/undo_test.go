@@ -0,0 +1,224 @@
+package sm
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gostdlib/base/context"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+)
+
+// undoRecorder is an action runner that records the names of the Actions it runs and sets their
+// State the way runAction would. An Action named "error" fails. An Action named in fail fails
+// that many times before it Completes.
+type undoRecorder struct {
+	mu   sync.Mutex
+	now  time.Time
+	runs []string
+	fail map[string]int
+}
+
+func (u *undoRecorder) run(ctx context.Context, action *workflow.Action, updater storage.ActionUpdater) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.now = u.now.Add(time.Second)
+	u.runs = append(u.runs, action.Name)
+	state := action.State.Get()
+	state.End = u.now
+	state.Status = workflow.Completed
+	if u.fail[action.Name] > 0 {
+		u.fail[action.Name]--
+		state.Status = workflow.Failed
+		action.State.Set(state)
+		return fmt.Errorf("error")
+	}
+	if action.Name == "error" {
+		state.Status = workflow.Failed
+		action.State.Set(state)
+		return fakeActionRunner(ctx, action, updater)
+	}
+	action.State.Set(state)
+	return nil
+}
+
+func newUndoAction(name string, status workflow.Status, end time.Time, undo string) *workflow.Action {
+	a := &workflow.Action{Name: name}
+	a.State.Set(workflow.State{Status: status, End: end})
+	if undo != "" {
+		a.Undo = &workflow.Action{Name: undo}
+	}
+	return a
+}
+
+func TestUndoOrder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		actions []*workflow.Action
+		want    []string
+	}{
+		{
+			name: "Success: no Undos",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.Completed, now, ""),
+			},
+		},
+		{
+			name: "Success: only Completed Actions with an Undo, reverse of finish order",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.Completed, now, "undo-a"),
+				newUndoAction("b", workflow.Completed, now.Add(2*time.Second), "undo-b"),
+				newUndoAction("c", workflow.Completed, now.Add(time.Second), "undo-c"),
+				newUndoAction("d", workflow.Failed, now.Add(3*time.Second), "undo-d"),
+				newUndoAction("e", workflow.NotStarted, time.Time{}, "undo-e"),
+				newUndoAction("f", workflow.Skipped, now, "undo-f"),
+			},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "Success: same finish time is reverse of Sequence order",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.Completed, now, "undo-a"),
+				newUndoAction("b", workflow.Completed, now, "undo-b"),
+			},
+			want: []string{"b", "a"},
+		},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, a := range undoOrder(test.actions) {
+			got = append(got, a.Name)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("TestUndoOrder(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUndosFinished(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	withUndo := func(a *workflow.Action, status workflow.Status) *workflow.Action {
+		a.Undo.State.Set(workflow.State{Status: status})
+		return a
+	}
+
+	tests := []struct {
+		name    string
+		actions []*workflow.Action
+		want    bool
+	}{
+		{
+			name:    "no Undos",
+			actions: []*workflow.Action{newUndoAction("a", workflow.Completed, now, "")},
+			want:    true,
+		},
+		{
+			name: "all Undos Completed",
+			actions: []*workflow.Action{
+				withUndo(newUndoAction("a", workflow.Completed, now, "undo-a"), workflow.Completed),
+				withUndo(newUndoAction("b", workflow.Completed, now.Add(time.Second), "undo-b"), workflow.Completed),
+			},
+			want: true,
+		},
+		{
+			name: "an Undo failed, the rest did not run",
+			actions: []*workflow.Action{
+				withUndo(newUndoAction("a", workflow.Completed, now, "undo-a"), workflow.NotStarted),
+				withUndo(newUndoAction("b", workflow.Completed, now.Add(time.Second), "undo-b"), workflow.Failed),
+			},
+			want: true,
+		},
+		{
+			name: "an Undo has not run",
+			actions: []*workflow.Action{
+				withUndo(newUndoAction("a", workflow.Completed, now, "undo-a"), workflow.NotStarted),
+				withUndo(newUndoAction("b", workflow.Completed, now.Add(time.Second), "undo-b"), workflow.Completed),
+			},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		if got := undosFinished(test.actions); got != test.want {
+			t.Errorf("TestUndosFinished(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestExecSeqUndo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		actions    []*workflow.Action
+		wantErr    bool
+		wantStatus workflow.Status
+		wantRuns   []string
+	}{
+		{
+			name: "Success: seq completed does not run Undos",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.NotStarted, time.Time{}, "undo-a"),
+				newUndoAction("b", workflow.NotStarted, time.Time{}, "undo-b"),
+			},
+			wantStatus: workflow.Completed,
+			wantRuns:   []string{"a", "b"},
+		},
+		{
+			name: "Error: seq failed runs Undos in reverse",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.NotStarted, time.Time{}, "undo-a"),
+				newUndoAction("b", workflow.NotStarted, time.Time{}, "undo-b"),
+				newUndoAction("error", workflow.NotStarted, time.Time{}, "undo-error"),
+				newUndoAction("c", workflow.NotStarted, time.Time{}, "undo-c"),
+			},
+			wantErr:    true,
+			wantStatus: workflow.Failed,
+			wantRuns:   []string{"a", "b", "error", "undo-b", "undo-a"},
+		},
+		{
+			name: "Error: failed Undo stops the rest",
+			actions: []*workflow.Action{
+				newUndoAction("a", workflow.NotStarted, time.Time{}, "undo-a"),
+				newUndoAction("b", workflow.NotStarted, time.Time{}, "error"),
+				newUndoAction("error", workflow.NotStarted, time.Time{}, ""),
+			},
+			wantErr:    true,
+			wantStatus: workflow.Failed,
+			wantRuns:   []string{"a", "b", "error", "error"},
+		},
+	}
+
+	for _, test := range tests {
+		rec := &undoRecorder{now: time.Now()}
+		seq := newSequenceWithState("seq", test.actions, &workflow.State{})
+		states := &States{
+			store:            &fakeUpdater{},
+			testActionRunner: rec.run,
+		}
+
+		err := states.execSeq(context.Background(), seq)
+		if (err != nil) != test.wantErr {
+			t.Errorf("TestExecSeqUndo(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if got := seq.State.Get().Status; got != test.wantStatus {
+			t.Errorf("TestExecSeqUndo(%s): got seq status == %v, want %v", test.name, got, test.wantStatus)
+		}
+		if !slices.Equal(rec.runs, test.wantRuns) {
+			t.Errorf("TestExecSeqUndo(%s): got runs %v, want %v", test.name, rec.runs, test.wantRuns)
+		}
+	}
+}
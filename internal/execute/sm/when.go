@@ -0,0 +1,69 @@
+package sm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/element-of-surprise/coercion/internal/when"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// skipWhen evaluates cond with the objects attached to the Context by withKeys. It returns true if
+// cond is false and the object it is on should be Skipped. An empty cond is never skipped.
+func skipWhen(ctx context.Context, cond workflow.WhenCond) (bool, error) {
+	if cond == "" {
+		return false, nil
+	}
+	e, err := when.Parse(string(cond))
+	if err != nil {
+		return false, fmt.Errorf("When(%s): %w", cond, err)
+	}
+	ok, err := e.Eval(keysFrom(ctx).vars())
+	if err != nil {
+		return false, fmt.Errorf("When(%s): %w", cond, err)
+	}
+	return !ok, nil
+}
+
+// setEnded sets the Status of v to status and its Start and End to now. Other fields, such as the ETag, are kept.
+func setEnded(v *workflow.AtomicValue[workflow.State], status workflow.Status, now time.Time) {
+	state := v.Get()
+	state.Status = status
+	state.Start = now
+	state.End = now
+	v.Set(state)
+}
+
+// skip marks o and the Sequences and Actions in it as Skipped and writes them to storage.
+// o must be a *workflow.Block, *workflow.Sequence or *workflow.Action.
+func (s *States) skip(ctx context.Context, o workflow.Object) {
+	now := s.now()
+	switch o := o.(type) {
+	case *workflow.Block:
+		setEnded(&o.State, workflow.Skipped, now)
+		if err := s.store.UpdateBlock(ctx, o); err != nil {
+			log.Fatalf("failed to write Block: %v", err)
+		}
+		for _, seq := range o.Sequences {
+			s.skip(ctx, seq)
+		}
+	case *workflow.Sequence:
+		setEnded(&o.State, workflow.Skipped, now)
+		if err := s.store.UpdateSequence(ctx, o); err != nil {
+			log.Fatalf("failed to write Sequence: %v", err)
+		}
+		for _, a := range o.Actions {
+			s.skip(ctx, a)
+		}
+	case *workflow.Action:
+		setEnded(&o.State, workflow.Skipped, now)
+		if err := s.store.UpdateAction(ctx, o); err != nil {
+			log.Fatalf("failed to write Action: %v", err)
+		}
+	default:
+		panic(fmt.Sprintf("bug: cannot skip %T", o))
+	}
+}
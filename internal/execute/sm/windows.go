@@ -0,0 +1,95 @@
+package sm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+
+	"github.com/gostdlib/base/telemetry/log"
+)
+
+// maxWindowTries is the number of times nextOpen looks for a time that all the Windows are open before
+// deciding that there is none.
+const maxWindowTries = 100
+
+// waitWindows waits until all of windows are open. A nil Windows is always open. This returns ErrStopped
+// if a Stop is requested and ErrTimeout if the Plan runs past its Timeout while waiting. If the windows
+// never open at the same time, this returns an error.
+func (s *States) waitWindows(ctx context.Context, windows ...*workflow.Windows) error {
+	for {
+		now := s.now()
+		next := nextOpen(now, windows...)
+		if next.IsZero() {
+			return fmt.Errorf("maintenance windows never open at the same time")
+		}
+		if !next.After(now) {
+			return nil
+		}
+
+		context.Log(ctx).Info("waiting for maintenance window", "opens", next)
+		if err := after(ctx, next.Sub(now)); err != nil {
+			return err
+		}
+	}
+}
+
+// nextOpen returns the first time at or after t that all of windows are open. If there is no such time,
+// this returns the zero time.
+func nextOpen(t time.Time, windows ...*workflow.Windows) time.Time {
+	for range maxWindowTries {
+		moved := false
+		for _, w := range windows {
+			n := w.NextOpen(t)
+			if n.IsZero() {
+				return time.Time{}
+			}
+			if n.After(t) {
+				t = n
+				moved = true
+			}
+		}
+		if !moved {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// windowClosed returns a channel that is closed when windows, if they have StopOnClose set, next close after
+// being open. If windows are not open now, this is when they close after they next open. The returned
+// func must be called to release the timer. If the windows never close, the channel is never closed.
+func (s *States) windowClosed(windows *workflow.Windows) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+	if windows == nil || !windows.StopOnClose {
+		return ch, func() {}
+	}
+
+	now := s.now()
+	open := windows.NextOpen(now)
+	if open.IsZero() {
+		return ch, func() {}
+	}
+	closes := windows.Closes(open)
+	if closes.IsZero() {
+		return ch, func() {}
+	}
+	t := time.AfterFunc(closes.Sub(now), func() { close(ch) })
+	return ch, func() { t.Stop() }
+}
+
+// closeChecks records ContChecks as Failed because their maintenance window closed and returns the error
+// for it.
+func (s *States) closeChecks(ctx context.Context, checks *workflow.Checks) error {
+	ctx = context.WithoutCancel(ctx)
+
+	state := checks.State.Get()
+	state.Status = workflow.Failed
+	state.End = s.now()
+	checks.State.Set(state)
+	if err := s.store.UpdateChecks(ctx, checks); err != nil {
+		log.Fatalf("failed to write Checks: %v", err)
+	}
+	return ErrWindowClosed
+}
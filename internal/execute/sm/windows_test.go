@@ -0,0 +1,141 @@
+package sm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+)
+
+// clockAt returns a clock that starts at t and moves with the real clock.
+func clockAt(t time.Time) func() time.Time {
+	offset := t.Sub(time.Now())
+	return func() time.Time { return time.Now().Add(offset) }
+}
+
+// hourWindow is open from 10:00 to 11:00 UTC each day.
+var hourWindow = &workflow.Windows{Allow: []workflow.Window{{Start: 10 * time.Hour, End: 11 * time.Hour}}}
+
+func TestNextOpen(t *testing.T) {
+	t.Parallel()
+
+	// This is a Wednesday.
+	now := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	weekend := &workflow.Windows{Allow: []workflow.Window{{Days: []time.Weekday{time.Saturday, time.Sunday}}}}
+	monday := &workflow.Windows{Allow: []workflow.Window{{Days: []time.Weekday{time.Monday}}}}
+
+	tests := []struct {
+		name    string
+		windows []*workflow.Windows
+		want    time.Time
+	}{
+		{name: "no windows", want: now},
+		{name: "nil windows", windows: []*workflow.Windows{nil, nil}, want: now},
+		{name: "one window", windows: []*workflow.Windows{nil, hourWindow}, want: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{name: "both windows open", windows: []*workflow.Windows{hourWindow, weekend}, want: time.Date(2025, 1, 18, 10, 0, 0, 0, time.UTC)},
+		{name: "never open at the same time", windows: []*workflow.Windows{weekend, monday}},
+	}
+
+	for _, test := range tests {
+		if got := nextOpen(now, test.windows...); !got.Equal(test.want) {
+			t.Errorf("TestNextOpen(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWaitWindows(t *testing.T) {
+	t.Parallel()
+
+	opens := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	stopped := setStopping(context.Background())
+	never := []*workflow.Windows{
+		{Allow: []workflow.Window{{Days: []time.Weekday{time.Saturday}}}},
+		{Allow: []workflow.Window{{Days: []time.Weekday{time.Monday}}}},
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		windows []*workflow.Windows
+		wantErr error
+		wantAny bool
+	}{
+		{name: "Success: waits until the window opens", ctx: context.Background(), windows: []*workflow.Windows{hourWindow}},
+		{name: "Success: no windows", ctx: stopped},
+		{name: "Error: stopped", ctx: stopped, windows: []*workflow.Windows{hourWindow}, wantErr: ErrStopped},
+		{name: "Error: never open", ctx: context.Background(), windows: never, wantAny: true},
+	}
+
+	for _, test := range tests {
+		s := &States{nower: clockAt(opens.Add(-100 * time.Millisecond))}
+
+		err := s.waitWindows(test.ctx, test.windows...)
+		switch {
+		case test.wantAny:
+			if err == nil {
+				t.Errorf("TestWaitWindows(%s): got err == nil, want err != nil", test.name)
+			}
+			continue
+		case !errors.Is(err, test.wantErr):
+			t.Errorf("TestWaitWindows(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+			continue
+		}
+		if err == nil && len(test.windows) > 0 && s.now().Before(opens) {
+			t.Errorf("TestWaitWindows(%s): returned at %v, before the window opened at %v", test.name, s.now(), opens)
+		}
+	}
+}
+
+func TestRunContChecksWindowClosed(t *testing.T) {
+	t.Parallel()
+
+	closes := time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		windows *workflow.Windows
+		wantErr error
+	}{
+		{
+			name:    "Success: StopOnClose not set",
+			windows: hourWindow,
+		},
+		{
+			name:    "Error: window closed",
+			windows: &workflow.Windows{Allow: hourWindow.Allow, StopOnClose: true},
+			wantErr: ErrWindowClosed,
+		},
+	}
+
+	for _, test := range tests {
+		s := &States{
+			store: &fakeUpdater{},
+			nower: clockAt(closes.Add(-100 * time.Millisecond)),
+			testChecksRunner: func(ctx context.Context, checks *workflow.Checks) error {
+				return nil
+			},
+		}
+		checks := &workflow.Checks{Delay: time.Hour}
+		checks.State.Set(workflow.State{Status: workflow.Completed})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		result := newContResult()
+		go s.runContChecks(ctx, checks, test.windows, result)
+
+		select {
+		case <-result.done:
+		case <-time.After(time.Second):
+		}
+		cancel()
+		err := result.wait()
+
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("TestRunContChecksWindowClosed(%s): got err == %v, want err == %v", test.name, err, test.wantErr)
+		}
+		if test.wantErr != nil && checks.State.Get().Status != workflow.Failed {
+			t.Errorf("TestRunContChecksWindowClosed(%s): got checks status %v, want %v", test.name, checks.State.Get().Status, workflow.Failed)
+		}
+	}
+}
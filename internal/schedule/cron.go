@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch is how far past a time Cron.Next looks for the next match before it gives up.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// field is the allowed range of a field in a cron expression.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// descriptors are the shorthands that can be used instead of the five fields.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron is a parsed cron expression.
+type Cron struct {
+	src string
+	// minute, hour, dom, month and dow have a bit set for each value the field matches.
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set if the day of month or day of week field is "*".
+	domAny, dowAny bool
+}
+
+// ParseCron parses a cron expression with the five fields minute, hour, day of month, month and day of week.
+// Each field is "*", a value, a range "a-b" or a comma separated list of those, and each of those can
+// have a step "/n". Day of week is 0-7, where both 0 and 7 are Sunday. Names of months and days are not
+// supported. If both day of month and day of week are not "*", a day matches if either matches. The
+// descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly can be used instead.
+func ParseCron(expr string) (*Cron, error) {
+	src := strings.TrimSpace(expr)
+	if d, ok := descriptors[src]; ok {
+		expr = d
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression(%s) must have %d fields, had %d", src, len(fields), len(parts))
+	}
+
+	c := &Cron{src: src}
+	sets := [5]*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression(%s): %w", src, err)
+		}
+		*sets[i] = set
+	}
+	// 7 is also Sunday.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = parts[2] == "*"
+	c.dowAny = parts[4] == "*"
+
+	if c.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron expression(%s) never matches a time", src)
+	}
+	return c, nil
+}
+
+// parseField parses a single field of a cron expression into a set of the values it matches.
+func parseField(s string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%s field(%s) has an invalid step", f.name, item)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(a, f); err != nil {
+				return 0, err
+			}
+			if hi, err = value(b, f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s field(%s) has a range that ends before it starts", f.name, item)
+			}
+		default:
+			v, err := value(rng, f)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// A value with a step, such as 5/15, runs from the value to the end of the range.
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses s as a value of field f.
+func value(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s field has an invalid value(%s)", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s field has a value(%d) outside %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the source of the cron expression.
+func (c *Cron) String() string {
+	return c.src
+}
+
+// Next returns the first time after t that matches the cron expression. Matches are found in the Location
+// of t. If nothing matches within five years of t, this returns the zero time.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	end := t.Add(maxSearch)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(end) {
+		y, m, d := t.Date()
+		switch {
+		case !has(c.month, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !has(c.hour, t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports if the day of t matches the day of month and day of week fields.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "Success: every minute", expr: "* * * * *"},
+		{name: "Success: lists, ranges and steps", expr: "0,30 9-17 */2 1-6/2 1-5"},
+		{name: "Success: descriptor", expr: "@weekly"},
+		{name: "Success: Sunday as 7", expr: "0 0 * * 7"},
+		{name: "Error: empty", expr: "", wantErr: true},
+		{name: "Error: too few fields", expr: "* * * *", wantErr: true},
+		{name: "Error: value out of range", expr: "60 * * * *", wantErr: true},
+		{name: "Error: range ends before it starts", expr: "* 5-1 * * *", wantErr: true},
+		{name: "Error: zero step", expr: "*/0 * * * *", wantErr: true},
+		{name: "Error: names are not supported", expr: "* * * JAN *", wantErr: true},
+		{name: "Error: never matches", expr: "0 0 30 2 *", wantErr: true},
+	}
+
+	for _, test := range tests {
+		_, err := ParseCron(test.expr)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestParseCron(%s): got err == nil, want err != nil", test.name)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestParseCron(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// This is a Wednesday.
+	from := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{name: "every minute", expr: "* * * * *", want: time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{name: "step of minutes", expr: "*/15 * * * *", want: time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{name: "next hour", expr: "5 * * * *", want: time.Date(2025, 1, 15, 11, 5, 0, 0, time.UTC)},
+		{name: "next day", expr: "@daily", want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "day of week", expr: "30 8 * * 1", want: time.Date(2025, 1, 20, 8, 30, 0, 0, time.UTC)},
+		{name: "Sunday as 7", expr: "0 0 * * 7", want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month or day of week", expr: "0 0 20 * 5", want: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "next year", expr: "0 0 1 1 *", want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expr: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCron(test.expr)
+		if err != nil {
+			t.Fatalf("TestCronNext(%s): got err == %s, want err == nil", test.name, err)
+		}
+		if got := c.Next(from); !got.Equal(test.want) {
+			t.Errorf("TestCronNext(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
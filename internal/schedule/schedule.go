@@ -0,0 +1,342 @@
+// Package schedule starts Plans at a set time or on a recurring cron schedule. The Schedule of a Plan is
+// stored with the Plan in the storage.Vault, which allows schedules to be recovered after a restart.
+//
+// A Plan scheduled with StartAt is started itself when its time comes. A Plan scheduled with Schedule is a
+// template: each time its cron expression is due the Plan is cloned and the clone is submitted and started.
+// The template itself keeps a Status of workflow.Scheduled until it is stopped.
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/context"
+	"github.com/element-of-surprise/coercion/workflow/errors"
+	"github.com/element-of-surprise/coercion/workflow/storage"
+	"github.com/element-of-surprise/coercion/workflow/utils/clone"
+	"github.com/google/uuid"
+)
+
+// Submitter submits a Plan and returns its ID.
+type Submitter func(ctx context.Context, plan *workflow.Plan) (uuid.UUID, error)
+
+// Starter starts a Plan that was submitted.
+type Starter func(ctx context.Context, id uuid.UUID) error
+
+// Scheduler starts Plans on their Schedule.
+type Scheduler struct {
+	store  storage.Vault
+	submit Submitter
+	start  Starter
+	// ctx is used for the runs that are started by timers. It is never cancelled.
+	ctx context.Context
+	// now returns the current time. This is time.Now().UTC() except in tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*entry
+}
+
+// entry is a Plan that is waiting on its Schedule in this process.
+type entry struct {
+	mu   sync.Mutex
+	plan *workflow.Plan
+	// cron is the parsed Schedule.Cron. This is nil for a Plan scheduled with StartAt.
+	cron  *Cron
+	timer *time.Timer
+	// done is closed once the entry no longer owns the Plan because it was started or stopped.
+	done chan struct{}
+}
+
+// isDone reports if done has been closed. e.mu must be held.
+func (e *entry) isDone() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// New creates a new Scheduler. submit and start are used to submit and start the Plans that are scheduled.
+func New(ctx context.Context, store storage.Vault, submit Submitter, start Starter) *Scheduler {
+	return &Scheduler{
+		store:   store,
+		submit:  submit,
+		start:   start,
+		ctx:     context.WithoutCancel(ctx),
+		now:     func() time.Time { return time.Now().UTC() },
+		entries: map[uuid.UUID]*entry{},
+	}
+}
+
+// StartAt schedules the NotStarted Plan with id to be started at t. If t has passed, the Plan is started
+// right away.
+func (s *Scheduler) StartAt(ctx context.Context, id uuid.UUID, t time.Time, catchUp workflow.CatchUp) error {
+	t = t.UTC()
+	return s.add(ctx, id, nil, &workflow.Schedule{At: t, CatchUp: catchUp, Next: t})
+}
+
+// Schedule schedules the NotStarted Plan with id to be cloned and the clone submitted and started each time
+// the cron expression is due. See ParseCron for the expressions that are supported. Times are in UTC.
+func (s *Scheduler) Schedule(ctx context.Context, id uuid.UUID, cron string, catchUp workflow.CatchUp) error {
+	c, err := ParseCron(cron)
+	if err != nil {
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, err)
+	}
+	return s.add(ctx, id, c, &workflow.Schedule{Cron: c.String(), CatchUp: catchUp, Next: c.Next(s.now())})
+}
+
+// add records sched on the Plan with id, which is then Scheduled, and waits for the next run.
+func (s *Scheduler) add(ctx context.Context, id uuid.UUID, cron *Cron, sched *workflow.Schedule) error {
+	switch sched.CatchUp {
+	case workflow.CUOnce, workflow.CUSkip, workflow.CUAll:
+	default:
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("unknown CatchUp(%v)", sched.CatchUp))
+	}
+
+	plan, err := s.store.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	if status := plan.State.Get().Status; status != workflow.NotStarted {
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) must be NotStarted to be scheduled, was %v", id, status))
+	}
+
+	plan.Schedule = sched
+	state := plan.State.Get()
+	state.Status = workflow.Scheduled
+	plan.State.Set(state)
+	if err := s.store.UpdatePlan(ctx, plan); err != nil {
+		return err
+	}
+
+	e := &entry{plan: plan, cron: cron, done: make(chan struct{})}
+	s.mu.Lock()
+	s.entries[id] = e
+	s.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s.arm(e)
+	return nil
+}
+
+// Recover loads the Plans that are Scheduled in storage. Runs that were due while the Workstream was down
+// are started according to the CatchUp of their Schedule before this returns.
+func (s *Scheduler) Recover(ctx context.Context) error {
+	results, err := s.store.Search(ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Scheduled}})
+	if err != nil {
+		return err
+	}
+	var ids []uuid.UUID
+	for result := range results {
+		if result.Err != nil {
+			return result.Err
+		}
+		ids = append(ids, result.Result.ID)
+	}
+
+	for _, id := range ids {
+		plan, err := s.store.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		if plan.Schedule == nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("bug: plan(%s) is Scheduled, but has no Schedule", id))
+		}
+		e := &entry{plan: plan, done: make(chan struct{})}
+		if plan.Schedule.Cron != "" {
+			e.cron, err = ParseCron(plan.Schedule.Cron)
+			if err != nil {
+				return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("plan(%s): %w", id, err))
+			}
+		}
+		context.Log(ctx).Info("coercion: recovered schedule", "id", id, "next", plan.Schedule.Next)
+
+		s.mu.Lock()
+		s.entries[id] = e
+		s.mu.Unlock()
+		s.run(ctx, e, false)
+	}
+	return nil
+}
+
+// Stop stops the Plan with id from waiting on its Schedule. The Plan is recorded as Stopped with a Reason of
+// FRStopped. This returns false if the Plan is not waiting on a Schedule in this process.
+func (s *Scheduler) Stop(ctx context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	e, ok := s.entries[id]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// The Plan was started while we waited for the lock.
+	if e.isDone() {
+		return false, nil
+	}
+	e.timer.Stop()
+	s.finish(e)
+
+	state := e.plan.State.Get()
+	state.Status = workflow.Stopped
+	state.End = s.now()
+	e.plan.State.Set(state)
+	e.plan.Reason = workflow.FRStopped
+	return true, s.store.UpdatePlan(ctx, e.plan)
+}
+
+// Wait waits for a Plan with id that was scheduled with StartAt to be started. It returns an error if the
+// Plan is a template for a cron Schedule, as that is never run. If the Plan is not waiting on a Schedule in
+// this process, this returns immediately.
+func (s *Scheduler) Wait(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	e, ok := s.entries[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if e.cron != nil {
+		return errors.E(ctx, errors.CatUser, errors.TypeParameter, fmt.Errorf("plan(%s) is the template of a Schedule and is never run", id))
+	}
+
+	select {
+	case <-ctx.Done():
+		return context.Canceled
+	case <-e.done:
+		return nil
+	}
+}
+
+// arm sets the timer of e for its next run. e.mu must be held.
+func (s *Scheduler) arm(e *entry) {
+	e.timer = time.AfterFunc(e.plan.Schedule.Next.Sub(s.now()), func() { s.run(s.ctx, e, true) })
+}
+
+// finish removes e from the entries and closes its done. e.mu must be held.
+func (s *Scheduler) finish(e *entry) {
+	s.mu.Lock()
+	delete(s.entries, e.plan.ID)
+	s.mu.Unlock()
+	close(e.done)
+}
+
+// run starts the runs of e that are due. onTime is set if this was called by the timer for the next run,
+// which means the last run that is due is not a missed run.
+func (s *Scheduler) run(ctx context.Context, e *entry, onTime bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.isDone() {
+		return
+	}
+
+	sched := e.plan.Schedule
+	due, next := dueRuns(sched, e.cron, s.now())
+	if due == 0 {
+		s.arm(e)
+		return
+	}
+	runs := catchUp(sched.CatchUp, due, onTime)
+
+	if e.cron == nil {
+		s.startPlan(ctx, e, runs > 0)
+		return
+	}
+
+	// Record the next run before starting any, so a restart will not start these runs a second time.
+	sched.Next = next
+	if err := s.store.UpdatePlan(ctx, e.plan); err != nil {
+		context.Log(ctx).Error("could not record the next run of a schedule", "id", e.plan.ID, "error", err)
+	}
+	if runs > 0 {
+		for range runs {
+			id, err := s.startClone(ctx, e.plan)
+			if err != nil {
+				context.Log(ctx).Error("could not start a scheduled run", "id", e.plan.ID, "error", err)
+				continue
+			}
+			sched.LastRun = id
+		}
+		if err := s.store.UpdatePlan(ctx, e.plan); err != nil {
+			context.Log(ctx).Error("could not record the last run of a schedule", "id", e.plan.ID, "error", err)
+		}
+	}
+	s.arm(e)
+}
+
+// startPlan starts the Plan of e, which was scheduled with StartAt. If start is false the run was missed
+// and skipped, so the Plan is recorded as Stopped with FRMissed. e.mu must be held.
+func (s *Scheduler) startPlan(ctx context.Context, e *entry, start bool) {
+	defer s.finish(e)
+
+	state := e.plan.State.Get()
+	if !start {
+		state.Status = workflow.Stopped
+		state.End = s.now()
+		e.plan.State.Set(state)
+		e.plan.Reason = workflow.FRMissed
+		if err := s.store.UpdatePlan(ctx, e.plan); err != nil {
+			context.Log(ctx).Error("could not record a missed scheduled plan", "id", e.plan.ID, "error", err)
+		}
+		return
+	}
+
+	// The Plan goes back to NotStarted, which is the only Status a Plan can be started from.
+	state.Status = workflow.NotStarted
+	e.plan.State.Set(state)
+	if err := s.store.UpdatePlan(ctx, e.plan); err != nil {
+		context.Log(ctx).Error("could not start a scheduled plan", "id", e.plan.ID, "error", err)
+		return
+	}
+	if err := s.start(ctx, e.plan.ID); err != nil {
+		context.Log(ctx).Error("could not start a scheduled plan", "id", e.plan.ID, "error", err)
+	}
+}
+
+// startClone clones the template plan and submits and starts the clone. It returns the ID of the clone.
+func (s *Scheduler) startClone(ctx context.Context, plan *workflow.Plan) (uuid.UUID, error) {
+	id, err := s.submit(ctx, clone.Plan(ctx, plan, clone.WithKeepSecrets()))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, s.start(ctx, id)
+}
+
+// dueRuns returns the number of runs of sched that are due at now and the time of the first run after now.
+// cron is the parsed sched.Cron and is nil for a Schedule from StartAt, which has a single run.
+func dueRuns(sched *workflow.Schedule, cron *Cron, now time.Time) (due int, next time.Time) {
+	if cron == nil {
+		if sched.Next.After(now) {
+			return 0, sched.Next
+		}
+		return 1, time.Time{}
+	}
+
+	next = sched.Next
+	for !next.IsZero() && !next.After(now) {
+		due++
+		next = cron.Next(next)
+	}
+	return due, next
+}
+
+// catchUp returns how many of the due runs are started under policy. If onTime is set, the last run that
+// is due is on time and always started. The runs before it were missed.
+func catchUp(policy workflow.CatchUp, due int, onTime bool) int {
+	switch {
+	case due == 0:
+		return 0
+	case policy == workflow.CUAll:
+		return due
+	case policy == workflow.CUSkip && !onTime:
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/element-of-surprise/coercion/workflow"
+)
+
+func TestDueRuns(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 15, 10, 0, 30, 0, time.UTC)
+	hourly, err := ParseCron("@hourly")
+	if err != nil {
+		t.Fatalf("TestDueRuns: ParseCron: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		sched    *workflow.Schedule
+		cron     *Cron
+		wantDue  int
+		wantNext time.Time
+	}{
+		{
+			name:     "StartAt not due",
+			sched:    &workflow.Schedule{Next: now.Add(time.Minute)},
+			wantNext: now.Add(time.Minute),
+		},
+		{
+			name:    "StartAt due",
+			sched:   &workflow.Schedule{Next: now.Add(-time.Hour)},
+			wantDue: 1,
+		},
+		{
+			name:     "Cron not due",
+			sched:    &workflow.Schedule{Next: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+			cron:     hourly,
+			wantNext: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Cron due once",
+			sched:    &workflow.Schedule{Next: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)},
+			cron:     hourly,
+			wantDue:  1,
+			wantNext: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Cron missed runs",
+			sched:    &workflow.Schedule{Next: time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC)},
+			cron:     hourly,
+			wantDue:  4,
+			wantNext: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		due, next := dueRuns(test.sched, test.cron, now)
+		if due != test.wantDue {
+			t.Errorf("TestDueRuns(%s): got %d due, want %d", test.name, due, test.wantDue)
+		}
+		if !next.Equal(test.wantNext) {
+			t.Errorf("TestDueRuns(%s): got next %v, want %v", test.name, next, test.wantNext)
+		}
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy workflow.CatchUp
+		due    int
+		onTime bool
+		want   int
+	}{
+		{name: "nothing due", policy: workflow.CUAll, due: 0, onTime: true, want: 0},
+		{name: "Once on time", policy: workflow.CUOnce, due: 1, onTime: true, want: 1},
+		{name: "Once after missed runs", policy: workflow.CUOnce, due: 3, want: 1},
+		{name: "Skip on time", policy: workflow.CUSkip, due: 1, onTime: true, want: 1},
+		{name: "Skip late timer", policy: workflow.CUSkip, due: 3, onTime: true, want: 1},
+		{name: "Skip after missed runs", policy: workflow.CUSkip, due: 3, want: 0},
+		{name: "All after missed runs", policy: workflow.CUAll, due: 3, want: 3},
+	}
+
+	for _, test := range tests {
+		if got := catchUp(test.policy, test.due, test.onTime); got != test.want {
+			t.Errorf("TestCatchUp(%s): got %d runs, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package when parses and evaluates the When conditions of Blocks, Sequences and Actions.
+//
+// A condition is a CEL (https://cel.dev) expression that must evaluate to a bool. The variables in it
+// are Meta, Status and Resp. Any other variable is an error when the condition is parsed.
+//
+// Values passed to Eval are the types of JSON decoded into an any: nil, bool, float64, string,
+// map[string]any and []any. Selecting a key that a map does not have is an error when the condition is
+// evaluated. Use has(a.b) or "b" in a to check for a key.
+package when
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// The variables that are available to a When condition.
+const (
+	// Meta is the Plan's Meta decoded as JSON.
+	Meta = "meta"
+	// Status has the Status of each object with a Key by its Key.
+	Status = "status"
+	// Resp has the response of each Completed Action with a Key by its Key.
+	Resp = "resp"
+)
+
+// env is the CEL environment that conditions are compiled in.
+var env = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(Meta, cel.DynType),
+		cel.Variable(Status, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(Resp, cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// Expr is a parsed condition.
+type Expr struct {
+	src string
+	prg cel.Program
+}
+
+// String returns the source of the condition.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Parse parses the condition in s. The condition must only use known variables and must be able
+// to evaluate to a bool.
+func Parse(s string) (*Expr, error) {
+	env, err := env()
+	if err != nil {
+		return nil, fmt.Errorf("bug: cannot create CEL environment: %w", err)
+	}
+	ast, iss := env.Compile(s)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	switch ast.OutputType() {
+	case cel.BoolType, cel.DynType:
+	default:
+		return nil, fmt.Errorf("condition is a %s, not a bool", ast.OutputType())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{src: s, prg: prg}, nil
+}
+
+// Eval evaluates the condition with vars, which must have Meta, Status and Resp.
+func (e *Expr) Eval(vars map[string]any) (bool, error) {
+	v, _, err := e.prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition is a %s, not a bool", v.Type())
+	}
+	return b, nil
+}
@@ -0,0 +1,105 @@
+package when
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cond    string
+		wantErr bool
+	}{
+		{name: "Success: literal", cond: "true"},
+		{name: "Success: selectors and index", cond: `status["a"] == "Completed" && resp.b.list[0] >= -1.5`},
+		{name: "Success: parens and not", cond: `!(meta.env == 'prod' || meta.env == "dev")`},
+		{name: "Success: has and in", cond: `has(meta.env) && "a" in status`},
+		{name: "Error: empty", cond: "", wantErr: true},
+		{name: "Error: unterminated string", cond: `meta.a == "b`, wantErr: true},
+		{name: "Error: missing paren", cond: `(true`, wantErr: true},
+		{name: "Error: trailing token", cond: `true false`, wantErr: true},
+		{name: "Error: bad char", cond: `meta.a # 1`, wantErr: true},
+		{name: "Error: selector without name", cond: `meta.1`, wantErr: true},
+		{name: "Error: unknown variable", cond: `params.a == 1`, wantErr: true},
+		{name: "Error: not a bool", cond: `"Completed"`, wantErr: true},
+		{name: "Error: index status with a number", cond: `status[0] == "Completed"`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		e, err := Parse(test.cond)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestParse(%s): got err == nil, want err != nil", test.name)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestParse(%s): got err == %s, want err == nil", test.name, err)
+			continue
+		case err != nil:
+			continue
+		}
+		if e.String() != test.cond {
+			t.Errorf("TestParse(%s): got String() == %q, want %q", test.name, e.String(), test.cond)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]any{
+		"status": map[string]any{"a": "Completed", "b": "Failed"},
+		"resp":   map[string]any{"a": map[string]any{"count": 3.0, "names": []any{"x", "y"}}},
+		"meta":   nil,
+	}
+
+	tests := []struct {
+		name    string
+		cond    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "Success: equal", cond: `status.a == "Completed"`, want: true},
+		{name: "Success: not equal", cond: `status["b"] != "Failed"`, want: false},
+		{name: "Success: key not in map", cond: `"c" in status || has(status.c)`, want: false},
+		{name: "Success: meta is null", cond: `meta == null`, want: true},
+		{name: "Success: number compare", cond: `resp.a.count > 2 && resp.a.count <= 3`, want: true},
+		{name: "Success: number equal", cond: `resp.a.count == 3`, want: true},
+		{name: "Success: negative number", cond: `resp.a.count > -1`, want: true},
+		{name: "Success: list index", cond: `resp.a.names[1] == "y"`, want: true},
+		{name: "Success: string compare", cond: `"a" < "b"`, want: true},
+		{name: "Success: or short-circuits", cond: `true || resp.a.count`, want: true},
+		{name: "Success: and short-circuits", cond: `false && resp.a.count`, want: false},
+		{name: "Success: not", cond: `!(status.a == "Failed")`, want: true},
+		{name: "Error: missing key", cond: `status.c == "Completed"`, wantErr: true},
+		{name: "Error: select from null", cond: `meta.env == "prod"`, wantErr: true},
+		{name: "Error: list index out of range", cond: `resp.a.names[5] == "z"`, wantErr: true},
+		{name: "Error: result is not a bool", cond: `resp.a.count`, wantErr: true},
+		{name: "Error: and needs bools", cond: `true && resp.a.count`, wantErr: true},
+		{name: "Error: compare different types", cond: `resp.a.count < "a"`, wantErr: true},
+		{name: "Error: select from a string", cond: `status.a.b == "c"`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		e, err := Parse(test.cond)
+		if err != nil {
+			t.Errorf("TestEval(%s): Parse: %s", test.name, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestEval(%s): got err == nil, want err != nil", test.name)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestEval(%s): got err == %s, want err == nil", test.name, err)
+			continue
+		case err != nil:
+			continue
+		}
+		if got != test.want {
+			t.Errorf("TestEval(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:generate go tool github.com/johnsiilver/stringer -type=ApprovalStatus -linecomment
+
+// ApprovalStatus is the status of an Approval.
+type ApprovalStatus uint8
+
+const (
+	// APNotRequested represents an Approval whose gate has not been reached and that has not been decided.
+	APNotRequested ApprovalStatus = 0 // NotRequested
+	// APWaiting represents an Approval whose gate has been reached and that is waiting for a decision.
+	APWaiting ApprovalStatus = 1 // Waiting
+	// APApproved represents an Approval that was approved.
+	APApproved ApprovalStatus = 2 // Approved
+	// APRejected represents an Approval that was rejected.
+	APRejected ApprovalStatus = 3 // Rejected
+	// APExpired represents an Approval whose Expiry passed before it was decided.
+	APExpired ApprovalStatus = 4 // Expired
+)
+
+// Decided reports if the ApprovalStatus is a final decision.
+func (a ApprovalStatus) Decided() bool {
+	switch a {
+	case APApproved, APRejected, APExpired:
+		return true
+	}
+	return false
+}
+
+// Approval is a manual gate on a Block or Sequence. When the gate is reached, the Block or Sequence waits
+// until an operator approves it with Workstream.Approve or rejects it with Workstream.Reject. The gate of a
+// Block is after its BypassChecks and before its PreChecks. The gate of a Sequence is before its first Action.
+// A rejected or expired Approval fails the Block or Sequence. An Approval can be decided before its gate is
+// reached, in which case the gate does not wait.
+type Approval struct {
+	// Key identifies the Approval in calls to Workstream.Approve and Workstream.Reject. It must be unique
+	// within the Plan. Required.
+	Key string
+	// Expiry is how long to wait for a decision once the gate is reached. If it passes without a decision,
+	// the Approval is Expired. This defaults to 0, which waits forever.
+	Expiry time.Duration `json:",omitempty,format:iso8601"`
+
+	// Decision is the decision on the Approval. This should not be set by the user.
+	Decision AtomicValue[Decision]
+}
+
+// Decision is the decision on an Approval.
+// Nothing in Decision should be set by the user.
+type Decision struct {
+	// Status is the status of the Approval.
+	Status ApprovalStatus
+	// Approver is the identity of the operator that approved or rejected the Approval.
+	Approver string
+	// Requested is the time the gate was reached. This is zero if the Approval was decided before that.
+	Requested time.Time
+	// Decided is the time the Approval was approved, rejected or expired.
+	Decided time.Time
+}
+
+func (a *Approval) validate() error {
+	if strings.TrimSpace(a.Key) == "" {
+		return fmt.Errorf("Key is required")
+	}
+	if a.Expiry < 0 {
+		return fmt.Errorf("Expiry cannot be negative")
+	}
+	if a.Decision.Get() != (Decision{}) {
+		return fmt.Errorf("Decision should not be set by the user")
+	}
+	return nil
+}
+
+// validateApprovals validates that the Key of each Approval in p is unique.
+func validateApprovals(p *Plan) error {
+	seen := map[string]bool{}
+	add := func(a *Approval) error {
+		if a == nil {
+			return nil
+		}
+		if seen[a.Key] {
+			return fmt.Errorf("Approval(%s) is used more than once", a.Key)
+		}
+		seen[a.Key] = true
+		return nil
+	}
+
+	for _, b := range p.Blocks {
+		if err := add(b.Approval); err != nil {
+			return fmt.Errorf("Block(%s): %w", b.Name, err)
+		}
+		for _, seq := range b.Sequences {
+			if err := add(seq.Approval); err != nil {
+				return fmt.Errorf("Sequence(%s): %w", seq.Name, err)
+			}
+		}
+	}
+	return nil
+}
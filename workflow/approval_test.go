@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApprovalValidate(t *testing.T) {
+	t.Parallel()
+
+	decided := &Approval{Key: "deploy"}
+	decided.Decision.Set(Decision{Status: APApproved, Approver: "operator"})
+
+	tests := []struct {
+		name     string
+		approval *Approval
+		err      bool
+	}{
+		{name: "Success: Key", approval: &Approval{Key: "deploy"}},
+		{name: "Success: Key and Expiry", approval: &Approval{Key: "deploy", Expiry: time.Hour}},
+		{name: "Error: no Key", approval: &Approval{Expiry: time.Hour}, err: true},
+		{name: "Error: blank Key", approval: &Approval{Key: "  "}, err: true},
+		{name: "Error: negative Expiry", approval: &Approval{Key: "deploy", Expiry: -time.Hour}, err: true},
+		{name: "Error: Decision is set", approval: decided, err: true},
+	}
+
+	for _, test := range tests {
+		err := test.approval.validate()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestApprovalValidate(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestApprovalValidate(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+func TestValidateApprovals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		plan *Plan
+		err  bool
+	}{
+		{
+			name: "Success: no Approvals",
+			plan: &Plan{Blocks: []*Block{{Sequences: []*Sequence{{}}}}},
+		},
+		{
+			name: "Success: unique Keys",
+			plan: &Plan{
+				Blocks: []*Block{
+					{Approval: &Approval{Key: "block0"}, Sequences: []*Sequence{{Approval: &Approval{Key: "seq0"}}, {}}},
+					{Approval: &Approval{Key: "block1"}, Sequences: []*Sequence{{Approval: &Approval{Key: "seq1"}}}},
+				},
+			},
+		},
+		{
+			name: "Error: two Blocks use a Key",
+			plan: &Plan{
+				Blocks: []*Block{
+					{Approval: &Approval{Key: "deploy"}},
+					{Approval: &Approval{Key: "deploy"}},
+				},
+			},
+			err: true,
+		},
+		{
+			name: "Error: a Block and a Sequence use a Key",
+			plan: &Plan{
+				Blocks: []*Block{
+					{Approval: &Approval{Key: "deploy"}, Sequences: []*Sequence{{Approval: &Approval{Key: "deploy"}}}},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateApprovals(test.plan)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidateApprovals(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidateApprovals(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
@@ -83,6 +83,82 @@ func WithGroupID(id uuid.UUID) Option {
 	}
 }
 
+// WithConcurrency sets the number of Blocks in the Plan that can run at the same time.
+func WithConcurrency(n int) Option {
+	return func(b *BuildPlan) error {
+		if b.emitted {
+			return errors.New("cannot call WithConcurrency() after Plan() has been called")
+		}
+
+		if n < 1 {
+			return errors.New("concurrency must be at least 1")
+		}
+
+		b.current().(*workflow.Plan).Concurrency = n
+		return nil
+	}
+}
+
+// WithTimeout sets the amount of time the Plan has to finish once it starts.
+func WithTimeout(d time.Duration) Option {
+	return func(b *BuildPlan) error {
+		if b.emitted {
+			return errors.New("cannot call WithTimeout() after Plan() has been called")
+		}
+
+		if d < 0 {
+			return errors.New("timeout cannot be negative")
+		}
+
+		b.current().(*workflow.Plan).Timeout = d
+		return nil
+	}
+}
+
+// WithPriority sets the order that the Plan runs in if it is Queued. See workflow.Plan.Priority.
+func WithPriority(n int) Option {
+	return func(b *BuildPlan) error {
+		if b.emitted {
+			return errors.New("cannot call WithPriority() after Plan() has been called")
+		}
+
+		b.current().(*workflow.Plan).Priority = n
+		return nil
+	}
+}
+
+// WithDependsOn sets the Plans that must finish before the Plan runs. See workflow.PlanDep.
+func WithDependsOn(deps ...*workflow.PlanDep) Option {
+	return func(b *BuildPlan) error {
+		if b.emitted {
+			return errors.New("cannot call WithDependsOn() after Plan() has been called")
+		}
+
+		if len(deps) == 0 {
+			return errors.New("must have at least one PlanDep")
+		}
+
+		b.current().(*workflow.Plan).DependsOn = deps
+		return nil
+	}
+}
+
+// WithWindows sets the maintenance windows that the Blocks of the Plan only start in. See workflow.Windows.
+func WithWindows(w *workflow.Windows) Option {
+	return func(b *BuildPlan) error {
+		if b.emitted {
+			return errors.New("cannot call WithWindows() after Plan() has been called")
+		}
+
+		if w == nil {
+			return errors.New("windows must not be nil")
+		}
+
+		b.current().(*workflow.Plan).Windows = w
+		return nil
+	}
+}
+
 // New creates a new BuildPlan with the internal Plan object having the given
 // name and description.
 func New(name, descr string, options ...Option) (*BuildPlan, error) {
@@ -197,6 +273,9 @@ const (
 	BypassChecks ChecksType = 4
 	// DeferredChecks is a set of deferred checks.
 	DeferredChecks ChecksType = 5
+	// WaveChecks is a set of checks run between the waves of a Block's Ramp.
+	// This can only be added to a Block.
+	WaveChecks ChecksType = 6
 )
 
 // AddChecks adds a check to the current Plan or Block. This moves you into the check.
@@ -294,6 +373,13 @@ func (b *BuildPlan) AddChecks(cType ChecksType, check *workflow.Checks) *BuildPl
 			}
 			t.PostChecks = check
 			b.chain = append(b.chain, check)
+		case WaveChecks:
+			if t.WaveChecks != nil {
+				b.setErr(errors.New("cannot add WaveCheck to Block with existing WaveChecks"))
+				return b
+			}
+			t.WaveChecks = check
+			b.chain = append(b.chain, check)
 		case DeferredChecks:
 			if t.DeferredChecks != nil {
 				b.setErr(errors.New("cannot add DeferredCheck to Block with existing DeferredChecks"))
@@ -319,7 +405,23 @@ type BlockArgs struct {
 	Descr                    string
 	EntranceDelay, ExitDelay time.Duration
 	Concurrency              int
-	ToleratedFailures        int
+	// Ramp rolls the Block's Sequences out in waves. See workflow.Ramp.
+	Ramp              *workflow.Ramp
+	ToleratedFailures int
+	// ToleratedFailurePercent is the percentage of the Block's Sequences that can fail. See workflow.Block.
+	ToleratedFailurePercent int
+	// Timeout is the amount of time the Block has to finish once it starts.
+	Timeout time.Duration
+	// When is a condition that decides if the Block runs. See workflow.WhenCond.
+	When workflow.WhenCond
+	// DependsOn has the Keys of the Blocks that must finish before the Block starts.
+	DependsOn []uuid.UUID
+	// Approval is a manual gate the Block waits on before its PreChecks. See workflow.Approval.
+	Approval *workflow.Approval
+	// Windows are the maintenance windows the Block only starts in. See workflow.Windows.
+	Windows *workflow.Windows
+	// Locks are the resources the Block locks while it runs. See workflow.Locks.
+	Locks *workflow.Locks
 }
 
 // AddBlock adds a Block to the current workflow Plan. If at any other level of the plan hierarchy,
@@ -345,13 +447,21 @@ func (b *BuildPlan) AddBlock(args BlockArgs) *BuildPlan {
 	switch t := b.current().(type) {
 	case *workflow.Plan:
 		block := &workflow.Block{
-			Name:              args.Name,
-			Key:               args.Key,
-			Descr:             args.Descr,
-			EntranceDelay:     args.EntranceDelay,
-			ExitDelay:         args.ExitDelay,
-			Concurrency:       args.Concurrency,
-			ToleratedFailures: args.ToleratedFailures,
+			Name:                    args.Name,
+			Key:                     args.Key,
+			Descr:                   args.Descr,
+			EntranceDelay:           args.EntranceDelay,
+			ExitDelay:               args.ExitDelay,
+			Concurrency:             args.Concurrency,
+			Ramp:                    args.Ramp,
+			ToleratedFailures:       args.ToleratedFailures,
+			ToleratedFailurePercent: args.ToleratedFailurePercent,
+			Timeout:                 args.Timeout,
+			Approval:                args.Approval,
+			Windows:                 args.Windows,
+			Locks:                   args.Locks,
+			When:                    args.When,
+			DependsOn:               args.DependsOn,
 		}
 		t.Blocks = append(t.Blocks, block)
 		b.chain = append(b.chain, block)
@@ -436,9 +546,9 @@ func (b *BuildPlan) AddAction(action *workflow.Action) *BuildPlan {
 	return b
 }
 
-// AddDeferredActions adds a DeferredActions container to the current Plan and moves
-// into it. Only one DeferredActions may be attached to a Plan. Returns an error if the
-// current scope is not a Plan or if DeferredActions is already set.
+// AddDeferredActions adds a DeferredActions container to the current Plan, Block or Sequence
+// and moves into it. Only one DeferredActions may be attached to each. Returns an error if the
+// current scope is not a Plan, Block or Sequence or if DeferredActions is already set.
 func (b *BuildPlan) AddDeferredActions() *BuildPlan {
 	if b.emitted {
 		b.setErr(errors.New("cannot call AddDeferredActions() after Plan() has been called"))
@@ -458,6 +568,24 @@ func (b *BuildPlan) AddDeferredActions() *BuildPlan {
 		t.DeferredActions = da
 		b.chain = append(b.chain, da)
 		return b
+	case *workflow.Block:
+		if t.DeferredActions != nil {
+			b.setErr(errors.New("cannot add DeferredActions to Block with existing DeferredActions"))
+			return b
+		}
+		da := &workflow.DeferredActions{}
+		t.DeferredActions = da
+		b.chain = append(b.chain, da)
+		return b
+	case *workflow.Sequence:
+		if t.DeferredActions != nil {
+			b.setErr(errors.New("cannot add DeferredActions to Sequence with existing DeferredActions"))
+			return b
+		}
+		da := &workflow.DeferredActions{}
+		t.DeferredActions = da
+		b.chain = append(b.chain, da)
+		return b
 	}
 	b.setErr(fmt.Errorf("invalid type for AddDeferredActions(): %T", b.current()))
 	return b
@@ -403,6 +403,52 @@ func TestAddDeferredActions(t *testing.T) {
 	}
 }
 
+// TestAddDeferredActionsBlockSequence tests that AddDeferredActions attaches a DeferredActions to
+// the current Block or Sequence.
+func TestAddDeferredActionsBlockSequence(t *testing.T) {
+	builder, err := New("test", "test")
+	if err != nil {
+		panic(err)
+	}
+
+	builder.AddBlock(BlockArgs{Name: "b", Descr: "b", Concurrency: 1})
+	builder.AddDeferredActions()
+	builder.AddDeferBatch(&workflow.DeferBatch{When: workflow.Always, Sequence: workflow.Sequence{Name: "block", Descr: "block"}})
+	builder.AddAction(&workflow.Action{Name: "block", Descr: "block", Plugin: "p"})
+	builder.Up().Up()
+	builder.AddSequence(&workflow.Sequence{Name: "s", Descr: "s"})
+	builder.AddAction(&workflow.Action{Name: "a", Descr: "a", Plugin: "p"})
+	builder.AddDeferredActions()
+	builder.AddDeferBatch(&workflow.DeferBatch{When: workflow.OnFailure, Sequence: workflow.Sequence{Name: "seq", Descr: "seq"}})
+	builder.AddAction(&workflow.Action{Name: "seq", Descr: "seq", Plugin: "p"})
+
+	got, err := builder.Plan()
+	if err != nil {
+		t.Fatalf("TestAddDeferredActionsBlockSequence(builder.Plan()): unexpected error: %v", err)
+	}
+
+	if got.DeferredActions != nil {
+		t.Errorf("TestAddDeferredActionsBlockSequence: got Plan.DeferredActions != nil, want nil")
+	}
+	block := got.Blocks[0]
+	if block.DeferredActions == nil || len(block.DeferredActions.DeferredBatches) != 1 {
+		t.Fatalf("TestAddDeferredActionsBlockSequence(Block): want DeferredActions with 1 DeferBatch")
+	}
+	if block.DeferredActions.DeferredBatches[0].Name != "block" {
+		t.Errorf("TestAddDeferredActionsBlockSequence(Block): got DeferBatch %s, want block", block.DeferredActions.DeferredBatches[0].Name)
+	}
+	seq := block.Sequences[0]
+	if len(seq.Actions) != 1 {
+		t.Errorf("TestAddDeferredActionsBlockSequence(Sequence.Actions): got len %d, want 1", len(seq.Actions))
+	}
+	if seq.DeferredActions == nil || len(seq.DeferredActions.DeferredBatches) != 1 {
+		t.Fatalf("TestAddDeferredActionsBlockSequence(Sequence): want DeferredActions with 1 DeferBatch")
+	}
+	if seq.DeferredActions.DeferredBatches[0].Name != "seq" {
+		t.Errorf("TestAddDeferredActionsBlockSequence(Sequence): got DeferBatch %s, want seq", seq.DeferredActions.DeferredBatches[0].Name)
+	}
+}
+
 // TestAddDeferredActionsErrors covers the error branches of AddDeferredActions
 // and AddDeferBatch.
 func TestAddDeferredActionsErrors(t *testing.T) {
@@ -418,10 +464,29 @@ func TestAddDeferredActionsErrors(t *testing.T) {
 		run  func(*BuildPlan)
 	}{
 		{
-			name: "Error: AddDeferredActions outside Plan scope",
+			name: "Error: AddDeferredActions outside Plan, Block or Sequence scope",
+			run: func(bp *BuildPlan) {
+				bp.AddChecks(PreChecks, &workflow.Checks{})
+				bp.AddDeferredActions()
+			},
+		},
+		{
+			name: "Error: duplicate AddDeferredActions on a Block",
 			run: func(bp *BuildPlan) {
 				bp.AddBlock(BlockArgs{Name: "b", Descr: "b", Concurrency: 1})
 				bp.AddDeferredActions()
+				bp.Up()
+				bp.AddDeferredActions()
+			},
+		},
+		{
+			name: "Error: duplicate AddDeferredActions on a Sequence",
+			run: func(bp *BuildPlan) {
+				bp.AddBlock(BlockArgs{Name: "b", Descr: "b", Concurrency: 1})
+				bp.AddSequence(&workflow.Sequence{Name: "s", Descr: "s"})
+				bp.AddDeferredActions()
+				bp.Up()
+				bp.AddDeferredActions()
 			},
 		},
 		{
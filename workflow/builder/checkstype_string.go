@@ -14,11 +14,12 @@ func _() {
 	_ = x[PostChecks-3]
 	_ = x[BypassChecks-4]
 	_ = x[DeferredChecks-5]
+	_ = x[WaveChecks-6]
 }
 
-const _ChecksType_name = "UnknownPreChecksContChecksPostChecksBypassChecksDeferredChecks"
+const _ChecksType_name = "UnknownPreChecksContChecksPostChecksBypassChecksDeferredChecksWaveChecks"
 
-var _ChecksType_index = [...]uint8{0, 7, 16, 26, 36, 48, 62}
+var _ChecksType_index = [...]uint8{0, 7, 16, 26, 36, 48, 62, 72}
 
 func (i ChecksType) String() string {
 	idx := int(i) - 0
@@ -4,6 +4,8 @@
 package context
 
 import (
+	"time"
+
 	"github.com/element-of-surprise/coercion/workflow/errors"
 	"github.com/gostdlib/base/concurrency/background"
 	"github.com/gostdlib/base/concurrency/worker"
@@ -19,6 +21,9 @@ type planIDKey struct{}
 // actionIDKey is a key for the actionID in context.Value .
 type actionIDKey struct{}
 
+// deadlineKey is a key for the deadline of the running Plan or Block in context.Value .
+type deadlineKey struct{}
+
 // Background returns a non-nil, empty [Context]. It is never canceled, and has no deadline.
 // It is typically used by the main function, initialization, and tests, and as the top-level
 // Context for incoming requests. This differs from the Background() function in the context package
@@ -120,6 +125,33 @@ func SetActionID(ctx context.Context, id uuid.UUID) context.Context {
 	return context.WithValue(ctx, actionIDKey{}, id)
 }
 
+// SetDeadline sets the time that the running Plan or Block must finish by. If the Context already
+// has an earlier deadline, that one is kept. Unlike WithDeadline, this does not cancel the Context.
+func SetDeadline(ctx context.Context, t time.Time) context.Context {
+	if d, ok := Deadline(ctx); ok && d.Before(t) {
+		return ctx
+	}
+	return context.WithValue(ctx, deadlineKey{}, t)
+}
+
+// Deadline returns the time that the running Plan or Block must finish by. ok is false if neither
+// has a Timeout. This is not the same as ctx.Deadline(), which is when the Context is cancelled.
+func Deadline(ctx context.Context) (t time.Time, ok bool) {
+	t, ok = ctx.Value(deadlineKey{}).(time.Time)
+	return t, ok
+}
+
+// Budget returns how much time is left before the Deadline of the running Plan or Block. This is 0
+// once the Deadline has passed. A plugin can use this to avoid starting work it cannot finish.
+// ok is false if neither has a Timeout.
+func Budget(ctx context.Context) (remaining time.Duration, ok bool) {
+	t, ok := Deadline(ctx)
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(t), 0), true
+}
+
 // SetEOptions sets the error options for the context. This overrides the same options
 // set by the WithEOptions function. This allows you to do things like cause
 // stack traces to be printed on errors in a specific call.
@@ -2,6 +2,7 @@ package context
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gostdlib/base/context"
 
@@ -37,3 +38,38 @@ func TestActionID(t *testing.T) {
 		t.Fatalf("TestActionID: got %s, want %s", got, want)
 	}
 }
+
+func TestBudget(t *testing.T) {
+	ctx := context.Background()
+
+	if _, ok := Budget(ctx); ok {
+		t.Fatalf("TestBudget: got ok == true with no deadline, want ok == false")
+	}
+
+	plan := time.Now().Add(time.Hour)
+	ctx = SetDeadline(ctx, plan)
+	if got, _ := Deadline(ctx); !got.Equal(plan) {
+		t.Fatalf("TestBudget: got Deadline %v, want %v", got, plan)
+	}
+
+	// A later deadline, such as a Block's Timeout that ends after the Plan's, does not replace an earlier one.
+	bctx := SetDeadline(ctx, plan.Add(time.Hour))
+	if got, _ := Deadline(bctx); !got.Equal(plan) {
+		t.Fatalf("TestBudget: got Deadline %v after a later deadline, want %v", got, plan)
+	}
+
+	block := time.Now().Add(time.Minute)
+	bctx = SetDeadline(ctx, block)
+	if got, _ := Deadline(bctx); !got.Equal(block) {
+		t.Fatalf("TestBudget: got Deadline %v after an earlier deadline, want %v", got, block)
+	}
+	remaining, ok := Budget(bctx)
+	if !ok || remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("TestBudget: got Budget %v, %v, want (0, 1m], true", remaining, ok)
+	}
+
+	past := SetDeadline(ctx, time.Now().Add(-time.Minute))
+	if remaining, _ := Budget(past); remaining != 0 {
+		t.Fatalf("TestBudget: got Budget %v after the deadline, want 0", remaining)
+	}
+}
@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// validateDependsOn validates that the DependsOn of each Block in p only has the Keys of other Blocks
+// in p and that the Blocks do not depend on each other in a cycle.
+func validateDependsOn(p *Plan) error {
+	byKey := map[uuid.UUID]*Block{}
+	for _, b := range p.Blocks {
+		if b.Key != uuid.Nil {
+			byKey[b.Key] = b
+		}
+	}
+
+	for _, b := range p.Blocks {
+		seen := map[uuid.UUID]bool{}
+		for _, k := range b.DependsOn {
+			dep, ok := byKey[k]
+			switch {
+			case !ok:
+				return fmt.Errorf("Block(%s): DependsOn(%s) is not the Key of a Block", b.Name, k)
+			case dep == b:
+				return fmt.Errorf("Block(%s): cannot depend on itself", b.Name)
+			case seen[k]:
+				return fmt.Errorf("Block(%s): DependsOn(%s) is listed more than once", b.Name, k)
+			}
+			seen[k] = true
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := map[*Block]int{}
+	var visit func(b *Block, path []string) error
+	visit = func(b *Block, path []string) error {
+		path = append(path, b.Name)
+		switch marks[b] {
+		case visiting:
+			return fmt.Errorf("Blocks have a DependsOn cycle: %v", path)
+		case visited:
+			return nil
+		}
+		marks[b] = visiting
+		for _, k := range b.DependsOn {
+			if err := visit(byKey[k], path); err != nil {
+				return err
+			}
+		}
+		marks[b] = visited
+		return nil
+	}
+	for _, b := range p.Blocks {
+		if err := visit(b, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blocksBefore returns the Blocks in p that always finish before each Block starts. When only one Block
+// runs at a time, this is every Block that runs before it. Otherwise it is the Blocks it depends on,
+// directly or through other Blocks. p must have passed validateDependsOn.
+func blocksBefore(p *Plan) map[*Block][]*Block {
+	before := make(map[*Block][]*Block, len(p.Blocks))
+
+	if p.Concurrency <= 1 {
+		order := blockOrder(p)
+		for i, b := range order {
+			before[b] = order[:i]
+		}
+		return before
+	}
+
+	byKey := map[uuid.UUID]*Block{}
+	for _, b := range p.Blocks {
+		if b.Key != uuid.Nil {
+			byKey[b.Key] = b
+		}
+	}
+	for _, b := range p.Blocks {
+		seen := map[*Block]bool{}
+		var add func(from *Block)
+		add = func(from *Block) {
+			for _, k := range from.DependsOn {
+				dep := byKey[k]
+				if seen[dep] {
+					continue
+				}
+				seen[dep] = true
+				add(dep)
+				before[b] = append(before[b], dep)
+			}
+		}
+		add(b)
+	}
+	return before
+}
+
+// blockOrder returns the Blocks of p in the order they start when only one Block runs at a time. This is
+// the order of p.Blocks, except a Block that depends on a later Block starts after it. p must have passed
+// validateDependsOn.
+func blockOrder(p *Plan) []*Block {
+	finished := map[uuid.UUID]bool{}
+	started := make(map[*Block]bool, len(p.Blocks))
+	order := make([]*Block, 0, len(p.Blocks))
+
+	for len(order) < len(p.Blocks) {
+		var next *Block
+		for _, b := range p.Blocks {
+			if !started[b] && b.Ready(func(k uuid.UUID) bool { return finished[k] }) {
+				next = b
+				break
+			}
+		}
+		if next == nil { // Can only happen with a cycle, which Validate does not allow.
+			return order
+		}
+		started[next] = true
+		order = append(order, next)
+		if next.Key != uuid.Nil {
+			finished[next.Key] = true
+		}
+	}
+	return order
+}
+
+// Ready returns true if every Block in b's DependsOn has finished. finished reports if the Block with
+// the Key has finished.
+func (b *Block) Ready(finished func(key uuid.UUID) bool) bool {
+	for _, k := range b.DependsOn {
+		if !finished(k) {
+			return false
+		}
+	}
+	return true
+}
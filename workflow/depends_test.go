@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateDependsOn(t *testing.T) {
+	t.Parallel()
+
+	keys := []uuid.UUID{NewV7(), NewV7(), NewV7()}
+
+	tests := []struct {
+		name   string
+		blocks []*Block
+		err    bool
+	}{
+		{
+			name:   "Success: no dependencies",
+			blocks: []*Block{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			name: "Success: depends on a later Block",
+			blocks: []*Block{
+				{Name: "a", Key: keys[0], DependsOn: []uuid.UUID{keys[1]}},
+				{Name: "b", Key: keys[1]},
+			},
+		},
+		{
+			name: "Success: diamond",
+			blocks: []*Block{
+				{Name: "a", Key: keys[0]},
+				{Name: "b", Key: keys[1], DependsOn: []uuid.UUID{keys[0]}},
+				{Name: "c", Key: keys[2], DependsOn: []uuid.UUID{keys[0]}},
+				{Name: "d", DependsOn: []uuid.UUID{keys[1], keys[2]}},
+			},
+		},
+		{
+			name:   "Error: unknown Key",
+			blocks: []*Block{{Name: "a", DependsOn: []uuid.UUID{keys[0]}}},
+			err:    true,
+		},
+		{
+			name:   "Error: depends on itself",
+			blocks: []*Block{{Name: "a", Key: keys[0], DependsOn: []uuid.UUID{keys[0]}}},
+			err:    true,
+		},
+		{
+			name: "Error: duplicate Key",
+			blocks: []*Block{
+				{Name: "a", Key: keys[0]},
+				{Name: "b", DependsOn: []uuid.UUID{keys[0], keys[0]}},
+			},
+			err: true,
+		},
+		{
+			name: "Error: cycle",
+			blocks: []*Block{
+				{Name: "a", Key: keys[0], DependsOn: []uuid.UUID{keys[2]}},
+				{Name: "b", Key: keys[1], DependsOn: []uuid.UUID{keys[0]}},
+				{Name: "c", Key: keys[2], DependsOn: []uuid.UUID{keys[1]}},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateDependsOn(&Plan{Blocks: test.blocks})
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidateDependsOn(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidateDependsOn(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+func TestBlocksBefore(t *testing.T) {
+	t.Parallel()
+
+	keys := []uuid.UUID{NewV7(), NewV7(), NewV7()}
+	a := &Block{Name: "a", Key: keys[0], DependsOn: []uuid.UUID{keys[2]}}
+	b := &Block{Name: "b", Key: keys[1]}
+	c := &Block{Name: "c", Key: keys[2], DependsOn: []uuid.UUID{keys[1]}}
+	blocks := []*Block{a, b, c}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		want        map[*Block][]*Block
+	}{
+		{
+			name:        "One Block at a time",
+			concurrency: 1,
+			want:        map[*Block][]*Block{b: {}, c: {b}, a: {b, c}},
+		},
+		{
+			name:        "Blocks run concurrently",
+			concurrency: 2,
+			want:        map[*Block][]*Block{a: {b, c}, c: {b}},
+		},
+	}
+
+	for _, test := range tests {
+		got := blocksBefore(&Plan{Blocks: blocks, Concurrency: test.concurrency})
+		for _, blk := range blocks {
+			if len(got[blk]) != len(test.want[blk]) {
+				t.Errorf("TestBlocksBefore(%s): Block(%s): got %d Blocks before, want %d", test.name, blk.Name, len(got[blk]), len(test.want[blk]))
+				continue
+			}
+			for i := range got[blk] {
+				if got[blk][i] != test.want[blk][i] {
+					t.Errorf("TestBlocksBefore(%s): Block(%s): got Block(%s) at %d, want Block(%s)", test.name, blk.Name, got[blk][i].Name, i, test.want[blk][i].Name)
+				}
+			}
+		}
+	}
+}
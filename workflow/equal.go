@@ -3,6 +3,7 @@ package workflow
 import (
 	"bytes"
 	"reflect"
+	"slices"
 
 	"github.com/element-of-surprise/coercion/plugins"
 )
@@ -69,6 +70,24 @@ func (p *Plan) Equal(other *Plan) bool {
 	if !sliceOfObjectsEqual(p.Blocks, other.Blocks) {
 		return false
 	}
+	if p.Concurrency != other.Concurrency {
+		return false
+	}
+	if p.Timeout != other.Timeout {
+		return false
+	}
+	if p.Priority != other.Priority {
+		return false
+	}
+	if !scheduleEqual(p.Schedule, other.Schedule) {
+		return false
+	}
+	if !windowsEqual(p.Windows, other.Windows) {
+		return false
+	}
+	if !slices.EqualFunc(p.DependsOn, other.DependsOn, planDepEqual) {
+		return false
+	}
 	if !stateEqual(p.State.Get(), other.State.Get()) {
 		return false
 	}
@@ -101,12 +120,24 @@ func (c *Checks) Equal(other *Checks) bool {
 	if c.Delay != other.Delay {
 		return false
 	}
+	if c.WaitUntil != other.WaitUntil {
+		return false
+	}
+	if c.SoakDuration != other.SoakDuration {
+		return false
+	}
+	if !failureThresholdEqual(c.FailureThreshold, other.FailureThreshold) {
+		return false
+	}
 	if !sliceOfObjectsEqual(c.Actions, other.Actions) {
 		return false
 	}
 	if !stateEqual(c.State.Get(), other.State.Get()) {
 		return false
 	}
+	if !sliceOfObjectsEqual(c.Attempts.Get(), other.Attempts.Get()) {
+		return false
+	}
 
 	return true
 }
@@ -139,6 +170,12 @@ func (b *Block) Equal(other *Block) bool {
 	if b.ExitDelay != other.ExitDelay {
 		return false
 	}
+	if b.When != other.When {
+		return false
+	}
+	if !slices.Equal(b.DependsOn, other.DependsOn) {
+		return false
+	}
 	if !checksEqual(b.BypassChecks, other.BypassChecks) {
 		return false
 	}
@@ -151,18 +188,42 @@ func (b *Block) Equal(other *Block) bool {
 	if !checksEqual(b.PostChecks, other.PostChecks) {
 		return false
 	}
+	if !checksEqual(b.WaveChecks, other.WaveChecks) {
+		return false
+	}
 	if !checksEqual(b.DeferredChecks, other.DeferredChecks) {
 		return false
 	}
+	if !deferredActionsEqual(b.DeferredActions, other.DeferredActions) {
+		return false
+	}
 	if !sliceOfObjectsEqual(b.Sequences, other.Sequences) {
 		return false
 	}
 	if b.Concurrency != other.Concurrency {
 		return false
 	}
+	if !rampEqual(b.Ramp, other.Ramp) {
+		return false
+	}
 	if b.ToleratedFailures != other.ToleratedFailures {
 		return false
 	}
+	if b.ToleratedFailurePercent != other.ToleratedFailurePercent {
+		return false
+	}
+	if b.Timeout != other.Timeout {
+		return false
+	}
+	if !approvalEqual(b.Approval, other.Approval) {
+		return false
+	}
+	if !windowsEqual(b.Windows, other.Windows) {
+		return false
+	}
+	if !locksEqual(b.Locks, other.Locks) {
+		return false
+	}
 	if !stateEqual(b.State.Get(), other.State.Get()) {
 		return false
 	}
@@ -192,9 +253,30 @@ func (s *Sequence) Equal(other *Sequence) bool {
 	if s.Descr != other.Descr {
 		return false
 	}
+	if s.When != other.When {
+		return false
+	}
 	if !sliceOfObjectsEqual(s.Actions, other.Actions) {
 		return false
 	}
+	if !deferredActionsEqual(s.DeferredActions, other.DeferredActions) {
+		return false
+	}
+	if s.Retries != other.Retries {
+		return false
+	}
+	if !retryPolicyEqual(s.RetryPolicy, other.RetryPolicy) {
+		return false
+	}
+	if !approvalEqual(s.Approval, other.Approval) {
+		return false
+	}
+	if !locksEqual(s.Locks, other.Locks) {
+		return false
+	}
+	if !sliceOfObjectsEqual(s.Attempts.Get(), other.Attempts.Get()) {
+		return false
+	}
 	if !stateEqual(s.State.Get(), other.State.Get()) {
 		return false
 	}
@@ -202,6 +284,112 @@ func (s *Sequence) Equal(other *Sequence) bool {
 	return true
 }
 
+// Equal returns true if the SeqAttempt objects are equal.
+// Only compares public fields.
+func (s SeqAttempt) Equal(other SeqAttempt) bool {
+	if !sliceOfObjectsEqual(s.Actions, other.Actions) {
+		return false
+	}
+	if !s.Start.Equal(other.Start) {
+		return false
+	}
+	if !s.End.Equal(other.End) {
+		return false
+	}
+
+	return true
+}
+
+// Equal returns true if the ActionResult objects are equal.
+// Only compares public fields.
+func (a ActionResult) Equal(other ActionResult) bool {
+	if a.ID != other.ID {
+		return false
+	}
+	if a.Status != other.Status {
+		return false
+	}
+	if a.Attempts != other.Attempts {
+		return false
+	}
+	if !pluginErrorEqual(a.Err, other.Err) {
+		return false
+	}
+	if !a.Start.Equal(other.Start) {
+		return false
+	}
+	if !a.End.Equal(other.End) {
+		return false
+	}
+
+	return true
+}
+
+func retryPolicyEqual(a, b *RetryPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func failureThresholdEqual(a, b *FailureThreshold) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func approvalEqual(a, b *Approval) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Key != b.Key || a.Expiry != b.Expiry {
+		return false
+	}
+	ad, bd := a.Decision.Get(), b.Decision.Get()
+	return ad.Status == bd.Status && ad.Approver == bd.Approver && ad.Requested.Equal(bd.Requested) && ad.Decided.Equal(bd.Decided)
+}
+
+func scheduleEqual(a, b *Schedule) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.At.Equal(b.At) && a.Cron == b.Cron && a.CatchUp == b.CatchUp && a.Next.Equal(b.Next) && a.LastRun == b.LastRun
+}
+
+func planDepEqual(a, b *PlanDep) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID == b.ID && a.GroupID == b.GroupID && a.On == b.On && slices.Equal(a.Plans, b.Plans)
+}
+
+func windowsEqual(a, b *Windows) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Location != b.Location || a.StopOnClose != b.StopOnClose || !slices.Equal(a.Blackouts, b.Blackouts) {
+		return false
+	}
+	return slices.EqualFunc(a.Allow, b.Allow, func(x, y Window) bool {
+		return x.Start == y.Start && x.End == y.End && slices.Equal(x.Days, y.Days)
+	})
+}
+
+func locksEqual(a, b *Locks) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slices.Equal(a.Shared, b.Shared) && slices.Equal(a.Exclusive, b.Exclusive) && a.Held.Get() == b.Held.Get()
+}
+
+func rampEqual(a, b *Ramp) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Canary == b.Canary && a.Soak == b.Soak && slices.Equal(a.Steps, b.Steps)
+}
+
 // Equal returns true if the DeferredActions objects are equal.
 // Only compares public fields.
 func (d *DeferredActions) Equal(other *DeferredActions) bool {
@@ -277,6 +465,12 @@ func (a *Action) Equal(other *Action) bool {
 	if a.Retries != other.Retries {
 		return false
 	}
+	if a.When != other.When {
+		return false
+	}
+	if !a.Undo.Equal(other.Undo) {
+		return false
+	}
 	if !reflect.DeepEqual(a.Req, other.Req) {
 		return false
 	}
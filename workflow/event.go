@@ -0,0 +1,23 @@
+package workflow
+
+import (
+	"github.com/google/uuid"
+)
+
+// Event is a change in the state of an object in a Plan. Events are sent when the change is written to storage.
+type Event struct {
+	// Type is the type of the object that changed.
+	Type ObjectType
+	// ID is the ID of the object that changed.
+	ID uuid.UUID
+	// OldStatus is the Status of the object before the change. For a Snapshot Event, this is the same as NewStatus.
+	OldStatus Status
+	// NewStatus is the Status of the object after the change.
+	NewStatus Status
+	// Attempt is set when an Action has a new Attempt. For a Snapshot Event, this is the last Attempt of the Action.
+	// Otherwise it is nil.
+	Attempt *Attempt
+	// Snapshot indicates the Event is from the stored state of the Plan and not a change. Snapshot Events
+	// are sent first, so that a subscriber that joins late has the state of every object before any change.
+	Snapshot bool
+}
@@ -16,19 +16,23 @@ func _() {
 	_ = x[FRDeferredCheck-450]
 	_ = x[FRDeferredAction-475]
 	_ = x[FRStopped-500]
+	_ = x[FRTimeout-550]
+	_ = x[FRMissed-575]
 	_ = x[FRExceedRecovery-600]
 }
 
 const (
-	_FailureReason_name_0 = "FRUnknown"
-	_FailureReason_name_1 = "FRPreCheck"
-	_FailureReason_name_2 = "FRBlock"
-	_FailureReason_name_3 = "FRPostCheck"
-	_FailureReason_name_4 = "FRContCheck"
-	_FailureReason_name_5 = "FRDeferredCheck"
-	_FailureReason_name_6 = "FRDeferredAction"
-	_FailureReason_name_7 = "FRStopped"
-	_FailureReason_name_8 = "FRExceedRecovery"
+	_FailureReason_name_0  = "FRUnknown"
+	_FailureReason_name_1  = "FRPreCheck"
+	_FailureReason_name_2  = "FRBlock"
+	_FailureReason_name_3  = "FRPostCheck"
+	_FailureReason_name_4  = "FRContCheck"
+	_FailureReason_name_5  = "FRDeferredCheck"
+	_FailureReason_name_6  = "FRDeferredAction"
+	_FailureReason_name_7  = "FRStopped"
+	_FailureReason_name_8  = "FRTimeout"
+	_FailureReason_name_9  = "FRMissed"
+	_FailureReason_name_10 = "FRExceedRecovery"
 )
 
 func (i FailureReason) String() string {
@@ -49,8 +53,12 @@ func (i FailureReason) String() string {
 		return _FailureReason_name_6
 	case i == 500:
 		return _FailureReason_name_7
-	case i == 600:
+	case i == 550:
 		return _FailureReason_name_8
+	case i == 575:
+		return _FailureReason_name_9
+	case i == 600:
+		return _FailureReason_name_10
 	default:
 		return "FailureReason(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"github.com/gostdlib/base/context"
+)
+
+// Hooks are called as the objects in a Plan change state. A Hook is called after the change has been written
+// to storage. Hooks for a Plan are called one at a time in the order the changes happened, but on a goroutine
+// separate from the Plan, so a slow Hook never blocks the Plan. A Hook that panics is logged and the panic is
+// recovered. The objects passed must not be modified and may reflect later changes than the one the Hook is for.
+// Embed NoOpHooks to implement only the Hooks you need.
+type Hooks interface {
+	// OnPlanStart is called when a Plan starts. It is not called for a Plan that is recovered.
+	OnPlanStart(ctx context.Context, p *Plan)
+	// OnPlanEnd is called when a Plan reaches a final Status.
+	OnPlanEnd(ctx context.Context, p *Plan)
+	// OnChecksStart is called when Checks start running.
+	OnChecksStart(ctx context.Context, c *Checks)
+	// OnChecksEnd is called when Checks reach a final Status.
+	OnChecksEnd(ctx context.Context, c *Checks)
+	// OnBlockStart is called when a Block starts.
+	OnBlockStart(ctx context.Context, b *Block)
+	// OnBlockEnd is called when a Block reaches a final Status.
+	OnBlockEnd(ctx context.Context, b *Block)
+	// OnSequenceStart is called when a Sequence starts.
+	OnSequenceStart(ctx context.Context, s *Sequence)
+	// OnSequenceEnd is called when a Sequence reaches a final Status.
+	OnSequenceEnd(ctx context.Context, s *Sequence)
+	// OnActionStart is called when an Action starts.
+	OnActionStart(ctx context.Context, a *Action)
+	// OnActionAttempt is called when an Action has finished an Attempt.
+	OnActionAttempt(ctx context.Context, a *Action, attempt Attempt)
+	// OnActionEnd is called when an Action reaches a final Status.
+	OnActionEnd(ctx context.Context, a *Action)
+}
+
+// NoOpHooks implements Hooks with methods that do nothing.
+type NoOpHooks struct{}
+
+func (NoOpHooks) OnPlanStart(ctx context.Context, p *Plan)                        {}
+func (NoOpHooks) OnPlanEnd(ctx context.Context, p *Plan)                          {}
+func (NoOpHooks) OnChecksStart(ctx context.Context, c *Checks)                    {}
+func (NoOpHooks) OnChecksEnd(ctx context.Context, c *Checks)                      {}
+func (NoOpHooks) OnBlockStart(ctx context.Context, b *Block)                      {}
+func (NoOpHooks) OnBlockEnd(ctx context.Context, b *Block)                        {}
+func (NoOpHooks) OnSequenceStart(ctx context.Context, s *Sequence)                {}
+func (NoOpHooks) OnSequenceEnd(ctx context.Context, s *Sequence)                  {}
+func (NoOpHooks) OnActionStart(ctx context.Context, a *Action)                    {}
+func (NoOpHooks) OnActionAttempt(ctx context.Context, a *Action, attempt Attempt) {}
+func (NoOpHooks) OnActionEnd(ctx context.Context, a *Action)                      {}
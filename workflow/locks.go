@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Locks are named resources, such as "cluster/eastus-1", that a Block or Sequence locks before it starts
+// and holds until it finishes. A resource can be locked by any number of Shared holders or by a single
+// Exclusive holder, across all the Plans of a Workstream. A Block or Sequence waits until all of its
+// Locks can be taken at the same time. Locks are granted in the order they were asked for.
+//
+// The Locks of a Block are held while its Sequences wait for theirs. Resources that are used together
+// should be locked by the same Block or Sequence, otherwise two Plans can wait on each other until their
+// Timeout.
+type Locks struct {
+	// Shared are the resources that are locked so that others can hold Shared locks on them at the same time.
+	Shared []string `json:",omitempty"`
+	// Exclusive are the resources that are locked so that nothing else can hold a lock on them.
+	Exclusive []string `json:",omitempty"`
+
+	// Held is set while the Locks are held. It is written to storage so that the Locks are taken again if
+	// the Plan is recovered. This should not be set by the user.
+	Held AtomicValue[bool]
+}
+
+// Names returns the names of all the resources in the Locks.
+func (l *Locks) Names() []string {
+	if l == nil {
+		return nil
+	}
+	return slices.Concat(l.Shared, l.Exclusive)
+}
+
+func (l *Locks) validate() error {
+	names := l.Names()
+	if len(names) == 0 {
+		return fmt.Errorf("must have Shared or Exclusive")
+	}
+	seen := make(map[string]bool, len(names))
+	for _, n := range names {
+		if strings.TrimSpace(n) == "" {
+			return fmt.Errorf("cannot have an empty resource name")
+		}
+		if seen[n] {
+			return fmt.Errorf("resource(%s) is locked more than once", n)
+		}
+		seen[n] = true
+	}
+	if l.Held.Get() {
+		return fmt.Errorf("Held should not be set by the user")
+	}
+	return nil
+}
+
+// validateSeqLocks validates that the Sequences of b do not lock a resource that b locks, which they
+// would wait on forever.
+func validateSeqLocks(b *Block) error {
+	names := b.Locks.Names()
+	if len(names) == 0 {
+		return nil
+	}
+	for _, seq := range b.Sequences {
+		if seq == nil {
+			continue
+		}
+		for _, n := range seq.Locks.Names() {
+			if slices.Contains(names, n) {
+				return fmt.Errorf("Sequence(%s) locks resource(%s) that its Block locks", seq.Name, n)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import (
+	"testing"
+)
+
+func TestLocksValidate(t *testing.T) {
+	t.Parallel()
+
+	held := &Locks{Exclusive: []string{"cluster"}}
+	held.Held.Set(true)
+
+	tests := []struct {
+		name  string
+		locks *Locks
+		err   bool
+	}{
+		{name: "Success: Shared", locks: &Locks{Shared: []string{"cluster/eastus-1", "cluster/westus-1"}}},
+		{name: "Success: Exclusive", locks: &Locks{Exclusive: []string{"cluster/eastus-1"}}},
+		{name: "Success: Shared and Exclusive", locks: &Locks{Shared: []string{"region"}, Exclusive: []string{"cluster"}}},
+		{name: "Error: empty", locks: &Locks{}, err: true},
+		{name: "Error: blank name", locks: &Locks{Shared: []string{" "}}, err: true},
+		{name: "Error: duplicate name", locks: &Locks{Exclusive: []string{"cluster", "cluster"}}, err: true},
+		{name: "Error: Shared and Exclusive", locks: &Locks{Shared: []string{"cluster"}, Exclusive: []string{"cluster"}}, err: true},
+		{name: "Error: Held is set", locks: held, err: true},
+	}
+
+	for _, test := range tests {
+		err := test.locks.validate()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestLocksValidate(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestLocksValidate(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/element-of-surprise/coercion/workflow/errors"
+	"github.com/gostdlib/base/context"
+
+	"github.com/google/uuid"
+)
+
+//go:generate go tool github.com/johnsiilver/stringer -type=Outcome -linecomment
+
+// Outcome is how the Plans in a PlanDep must finish for the PlanDep to be met.
+type Outcome uint8
+
+const (
+	// OCPass is met when the Plans are Completed. This is the default.
+	OCPass Outcome = 0 // Pass
+	// OCFail is met when the Plans are Failed or Stopped.
+	OCFail Outcome = 1 // Fail
+)
+
+// PlanDep is a dependency of a Plan on other Plans, which are named by ID or by GroupID. A Plan that is
+// started with PlanDeps in its DependsOn has a Status of Waiting until all of them are met and then runs.
+// If a PlanDep can no longer be met, because one of its Plans finished another way or was Skipped, the
+// Plan is Skipped. Plans cannot depend on each other in a cycle, which is checked when a Plan is started.
+type PlanDep struct {
+	// ID is the ID of a Plan that must finish. Either ID or GroupID must be set.
+	ID uuid.UUID `json:",omitzero"`
+	// GroupID depends on all the Plans with this GroupID, other than the Plan itself. These are the Plans
+	// with the GroupID that were submitted when the Plan is started. Either ID or GroupID must be set.
+	GroupID uuid.UUID `json:",omitzero"`
+	// On is how the Plans must finish. This defaults to OCPass.
+	On Outcome `json:",omitempty"`
+
+	// Plans are the IDs of the Plans that the PlanDep waits on. They are recorded when the Plan is started,
+	// so a recovered Plan waits on the same Plans. Should not be set by the user.
+	Plans []uuid.UUID `json:",omitempty"`
+}
+
+func (d *PlanDep) validate() error {
+	if d == nil {
+		return errors.New("cannot be nil")
+	}
+	if (d.ID == uuid.Nil) == (d.GroupID == uuid.Nil) {
+		return errors.New("must have either ID or GroupID")
+	}
+	switch d.On {
+	case OCPass, OCFail:
+	default:
+		return fmt.Errorf("unknown On(%v)", d.On)
+	}
+	if len(d.Plans) != 0 {
+		return errors.New("Plans should not be set by the user")
+	}
+	return nil
+}
+
+// validatePlanDeps validates the DependsOn of a Plan.
+func validatePlanDeps(deps []*PlanDep) error {
+	seen := map[uuid.UUID]bool{}
+	for i, d := range deps {
+		if err := d.validate(); err != nil {
+			return fmt.Errorf("PlanDep(%d): %w", i, err)
+		}
+		id := d.ID
+		if id == uuid.Nil {
+			id = d.GroupID
+		}
+		if seen[id] {
+			return fmt.Errorf("PlanDep(%d): %s is listed more than once", i, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// Pipeline chains Plans with Edges, such as a rollback Plan that only runs if a deploy Plan fails. Submit
+// a Pipeline with Workstream.SubmitPipeline, which records each Edge as a PlanDep in the DependsOn of
+// the Plan it goes to. Each Plan must then be started.
+type Pipeline struct {
+	// Plans are the Plans in the Pipeline. Each must have a different Name. Required.
+	Plans []*Plan
+	// Edges chain the Plans. A Plan that Edges go to runs once all of them are taken and is Skipped if any
+	// of them is not. The Edges cannot form a cycle. Optional.
+	Edges []Edge
+}
+
+// Edge chains two Plans in a Pipeline.
+type Edge struct {
+	// From is the Name of the Plan that must finish. Required.
+	From string
+	// To is the Name of the Plan that runs once From has finished. Required.
+	To string
+	// On is how From must finish for the Edge to be taken. This defaults to OCPass.
+	On Outcome
+}
+
+// ValidatePipeline validates the Plans and Edges of the Pipeline. The Plans themselves are validated
+// with Validate when they are submitted.
+func ValidatePipeline(p *Pipeline) error {
+	if err := validatePipeline(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Pipeline validation: %w", err))
+	}
+	return nil
+}
+
+func validatePipeline(p *Pipeline) error {
+	if p == nil {
+		return errors.New("cannot have a nil Pipeline")
+	}
+	if len(p.Plans) == 0 {
+		return errors.New("at least one plan is required")
+	}
+
+	byName := make(map[string]*Plan, len(p.Plans))
+	for _, plan := range p.Plans {
+		if plan == nil {
+			return errors.New("cannot have a nil Plan")
+		}
+		if strings.TrimSpace(plan.Name) == "" {
+			return errors.New("Plan name is required")
+		}
+		if byName[plan.Name] != nil {
+			return fmt.Errorf("Plan(%s) is in the Pipeline more than once", plan.Name)
+		}
+		byName[plan.Name] = plan
+	}
+
+	to := map[string][]string{}
+	seen := map[Edge]bool{}
+	for _, e := range p.Edges {
+		switch {
+		case byName[e.From] == nil:
+			return fmt.Errorf("Edge(%s->%s): From is not the Name of a Plan", e.From, e.To)
+		case byName[e.To] == nil:
+			return fmt.Errorf("Edge(%s->%s): To is not the Name of a Plan", e.From, e.To)
+		case e.From == e.To:
+			return fmt.Errorf("Edge(%s->%s): a Plan cannot have an Edge to itself", e.From, e.To)
+		}
+		switch e.On {
+		case OCPass, OCFail:
+		default:
+			return fmt.Errorf("Edge(%s->%s): unknown On(%v)", e.From, e.To, e.On)
+		}
+		key := Edge{From: e.From, To: e.To}
+		if seen[key] {
+			return fmt.Errorf("Edge(%s->%s) is listed more than once", e.From, e.To)
+		}
+		seen[key] = true
+		to[e.To] = append(to[e.To], e.From)
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("Edges have a cycle: %v", path)
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		for _, from := range to[name] {
+			if err := visit(from, path); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+		return nil
+	}
+	for _, plan := range p.Plans {
+		if err := visit(plan.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidatePlanDeps(t *testing.T) {
+	t.Parallel()
+
+	id, group := NewV7(), NewV7()
+
+	tests := []struct {
+		name string
+		deps []*PlanDep
+		err  bool
+	}{
+		{name: "Success: ID", deps: []*PlanDep{{ID: id}}},
+		{name: "Success: GroupID on fail", deps: []*PlanDep{{GroupID: group, On: OCFail}}},
+		{name: "Success: ID and GroupID", deps: []*PlanDep{{ID: id}, {GroupID: group}}},
+		{name: "Error: nil PlanDep", deps: []*PlanDep{nil}, err: true},
+		{name: "Error: no ID or GroupID", deps: []*PlanDep{{}}, err: true},
+		{name: "Error: ID and GroupID", deps: []*PlanDep{{ID: id, GroupID: group}}, err: true},
+		{name: "Error: unknown On", deps: []*PlanDep{{ID: id, On: 5}}, err: true},
+		{name: "Error: Plans is set", deps: []*PlanDep{{ID: id, Plans: []uuid.UUID{id}}}, err: true},
+		{name: "Error: ID listed twice", deps: []*PlanDep{{ID: id}, {ID: id, On: OCFail}}, err: true},
+	}
+
+	for _, test := range tests {
+		err := validatePlanDeps(test.deps)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidatePlanDeps(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidatePlanDeps(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	t.Parallel()
+
+	plans := func(names ...string) []*Plan {
+		var p []*Plan
+		for _, n := range names {
+			p = append(p, &Plan{Name: n})
+		}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		err      bool
+	}{
+		{
+			name:     "Success: one Plan",
+			pipeline: &Pipeline{Plans: plans("deploy")},
+		},
+		{
+			name: "Success: pass and fail Edges",
+			pipeline: &Pipeline{
+				Plans: plans("deploy", "verify", "rollback"),
+				Edges: []Edge{{From: "deploy", To: "verify"}, {From: "deploy", To: "rollback", On: OCFail}},
+			},
+		},
+		{
+			name: "Success: Plan with two Edges to it",
+			pipeline: &Pipeline{
+				Plans: plans("east", "west", "verify"),
+				Edges: []Edge{{From: "east", To: "verify"}, {From: "west", To: "verify"}},
+			},
+		},
+		{name: "Error: nil Pipeline", err: true},
+		{name: "Error: no Plans", pipeline: &Pipeline{}, err: true},
+		{name: "Error: nil Plan", pipeline: &Pipeline{Plans: []*Plan{nil}}, err: true},
+		{name: "Error: Plan without a Name", pipeline: &Pipeline{Plans: plans(" ")}, err: true},
+		{name: "Error: Plan Name is used twice", pipeline: &Pipeline{Plans: plans("deploy", "deploy")}, err: true},
+		{
+			name:     "Error: Edge From is not a Plan",
+			pipeline: &Pipeline{Plans: plans("deploy"), Edges: []Edge{{From: "build", To: "deploy"}}},
+			err:      true,
+		},
+		{
+			name:     "Error: Edge To is not a Plan",
+			pipeline: &Pipeline{Plans: plans("deploy"), Edges: []Edge{{From: "deploy", To: "verify"}}},
+			err:      true,
+		},
+		{
+			name:     "Error: Edge to itself",
+			pipeline: &Pipeline{Plans: plans("deploy"), Edges: []Edge{{From: "deploy", To: "deploy"}}},
+			err:      true,
+		},
+		{
+			name:     "Error: unknown On",
+			pipeline: &Pipeline{Plans: plans("deploy", "verify"), Edges: []Edge{{From: "deploy", To: "verify", On: 5}}},
+			err:      true,
+		},
+		{
+			name: "Error: Edge listed twice",
+			pipeline: &Pipeline{
+				Plans: plans("deploy", "verify"),
+				Edges: []Edge{{From: "deploy", To: "verify"}, {From: "deploy", To: "verify", On: OCFail}},
+			},
+			err: true,
+		},
+		{
+			name: "Error: cycle",
+			pipeline: &Pipeline{
+				Plans: plans("deploy", "verify", "rollback"),
+				Edges: []Edge{{From: "deploy", To: "verify"}, {From: "verify", To: "rollback"}, {From: "rollback", To: "deploy", On: OCFail}},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidatePipeline(test.pipeline)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidatePipeline(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidatePipeline(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
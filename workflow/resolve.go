@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Input is the response of an earlier Action that is used by a Resolver.
+type Input struct {
+	// Key is the Key of the Action whose response is used.
+	Key uuid.UUID
+	// Resp is a zero value of the response type that is expected. This must be the same type as
+	// the Response() of the plugin that the Action uses.
+	Resp any
+}
+
+// Resolver can be implemented by the Req of an Action to use the responses of earlier Actions.
+// An Action in a Sequence can use the response of an Action that comes before it in the same Sequence
+// or of any Action in a Sequence of a Block that always finishes before its Block starts. When the Plan
+// runs one Block at a time, that is any Block that runs earlier. Otherwise it is a Block that its Block
+// depends on through DependsOn. This is checked by Validate. An Action that is not
+// in a Sequence, such as a Checks Action, can only have a Resolver Req that has no Inputs.
+//
+// Before the plugin is executed, Resolve is called with the response of the final Attempt of each
+// Input, keyed by Input.Key. What Resolve returns is passed to the plugin instead of the Req. The Req
+// is stored as it is, so a Plan that is recovered resolves the Req again from the stored responses.
+// Resolve must not modify the Req. If Resolve returns an error, the Action fails without being retried.
+type Resolver interface {
+	// Inputs returns the responses that are used by Resolve.
+	Inputs() []Input
+	// Resolve returns the request to pass to the plugin.
+	Resolve(resps map[uuid.UUID]any) (any, error)
+}
+
+// validateInputs validates that each Action whose Req is a Resolver only uses the responses of Actions
+// that have finished before it runs and that the responses are the expected type.
+func validateInputs(p *Plan) error {
+	inSeqs := map[*Action]bool{}
+
+	blocksDone := blocksBefore(p)
+	for _, b := range p.Blocks {
+		// done has the Actions in the Blocks that finish before this Block starts.
+		done := map[uuid.UUID]*Action{}
+		for _, db := range blocksDone[b] {
+			for _, s := range db.Sequences {
+				for _, a := range s.Actions {
+					if a.Key != uuid.Nil {
+						done[a.Key] = a
+					}
+				}
+			}
+		}
+		for _, s := range b.Sequences {
+			before := maps.Clone(done)
+			for _, a := range s.Actions {
+				inSeqs[a] = true
+				if err := a.validateInputs(before); err != nil {
+					return fmt.Errorf("Action(%s): %w", a.Name, err)
+				}
+				if a.Key != uuid.Nil {
+					before[a.Key] = a
+				}
+			}
+		}
+	}
+
+	for _, a := range planActions(p) {
+		if inSeqs[a] {
+			continue
+		}
+		if r, ok := a.Req.(Resolver); ok && len(r.Inputs()) > 0 {
+			return fmt.Errorf("Action(%s): only an Action in a Sequence can have a Req with Inputs", a.Name)
+		}
+	}
+	return nil
+}
+
+// validateInputs validates the Inputs of a's Req if it is a Resolver. before has the Actions
+// that finish before a runs by their Key.
+func (a *Action) validateInputs(before map[uuid.UUID]*Action) error {
+	r, ok := a.Req.(Resolver)
+	if !ok {
+		return nil
+	}
+	for _, in := range r.Inputs() {
+		ref, ok := before[in.Key]
+		if !ok {
+			return fmt.Errorf("input(%s) is not the Key of an Action that finishes before this Action", in.Key)
+		}
+		plug := ref.register.Plugin(ref.Plugin)
+		if plug == nil {
+			return fmt.Errorf("input(%s): plugin %q not found", in.Key, ref.Plugin)
+		}
+		if got, want := reflect.TypeOf(in.Resp), reflect.TypeOf(plug.Response()); got != want {
+			return fmt.Errorf("input(%s): expects a response of type %v, but Action(%s) returns %v", in.Key, got, ref.Name, want)
+		}
+	}
+	return nil
+}
+
+// planActions returns all the Actions in p.
+func planActions(p *Plan) []*Action {
+	var actions []*Action
+	addChecks := func(checks ...*Checks) {
+		for _, c := range checks {
+			if c != nil {
+				actions = append(actions, c.Actions...)
+			}
+		}
+	}
+
+	addDeferred := func(da *DeferredActions) {
+		if da == nil {
+			return
+		}
+		for _, batch := range da.DeferredBatches {
+			if batch != nil {
+				actions = append(actions, batch.Actions...)
+			}
+		}
+	}
+
+	addChecks(p.BypassChecks, p.PreChecks, p.ContChecks, p.PostChecks, p.DeferredChecks)
+	addDeferred(p.DeferredActions)
+	for _, b := range p.Blocks {
+		addChecks(b.BypassChecks, b.PreChecks, b.ContChecks, b.PostChecks, b.WaveChecks, b.DeferredChecks)
+		addDeferred(b.DeferredActions)
+		for _, s := range b.Sequences {
+			for _, a := range s.Actions {
+				actions = append(actions, a)
+				if a.Undo != nil {
+					actions = append(actions, a.Undo)
+				}
+			}
+			addDeferred(s.DeferredActions)
+		}
+	}
+	return actions
+}
@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/element-of-surprise/coercion/plugins/registry"
+)
+
+// resolverReq is a Req that uses the responses of the Actions with keys.
+type resolverReq struct {
+	keys []uuid.UUID
+	resp any
+}
+
+func (r resolverReq) Inputs() []Input {
+	inputs := make([]Input, 0, len(r.keys))
+	for _, k := range r.keys {
+		inputs = append(inputs, Input{Key: k, Resp: r.resp})
+	}
+	return inputs
+}
+
+func (r resolverReq) Resolve(resps map[uuid.UUID]any) (any, error) {
+	return "resolved", nil
+}
+
+func TestValidateInputs(t *testing.T) {
+	t.Parallel()
+
+	reg := registry.New()
+	reg.Register(validatePlugin{})
+
+	k0, k1, k2 := NewV7(), NewV7(), NewV7()
+	action := func(key uuid.UUID, req any) *Action {
+		return &Action{Key: key, Name: "action", Plugin: "validatePlugin", Req: req, register: reg}
+	}
+	uses := func(keys ...uuid.UUID) resolverReq {
+		return resolverReq{keys: keys, resp: struct{}{}}
+	}
+
+	tests := []struct {
+		name string
+		plan func() *Plan
+		err  bool
+	}{
+		{
+			name: "Success: uses earlier Action in the same Sequence and an Action in an earlier Block",
+			plan: func() *Plan {
+				return &Plan{
+					Blocks: []*Block{
+						{Sequences: []*Sequence{{Actions: []*Action{action(k0, "")}}}},
+						{Sequences: []*Sequence{{Actions: []*Action{action(k1, ""), action(k2, uses(k0, k1))}}}},
+					},
+				}
+			},
+		},
+		{
+			name: "Success: Checks Action has a Resolver with no Inputs",
+			plan: func() *Plan {
+				return &Plan{
+					PreChecks: &Checks{Actions: []*Action{action(uuid.Nil, uses())}},
+					Blocks:    []*Block{{Sequences: []*Sequence{{Actions: []*Action{action(k0, "")}}}}},
+				}
+			},
+		},
+		{
+			name: "Error: uses a later Action in the same Sequence",
+			plan: func() *Plan {
+				return &Plan{
+					Blocks: []*Block{
+						{Sequences: []*Sequence{{Actions: []*Action{action(k0, uses(k1)), action(k1, "")}}}},
+					},
+				}
+			},
+			err: true,
+		},
+		{
+			name: "Error: uses an Action in another Sequence of the same Block",
+			plan: func() *Plan {
+				return &Plan{
+					Blocks: []*Block{
+						{
+							Sequences: []*Sequence{
+								{Actions: []*Action{action(k0, "")}},
+								{Actions: []*Action{action(k1, uses(k0))}},
+							},
+						},
+					},
+				}
+			},
+			err: true,
+		},
+		{
+			name: "Error: uses a Key that does not exist",
+			plan: func() *Plan {
+				return &Plan{
+					Blocks: []*Block{{Sequences: []*Sequence{{Actions: []*Action{action(k0, uses(k2))}}}}},
+				}
+			},
+			err: true,
+		},
+		{
+			name: "Error: expects the wrong response type",
+			plan: func() *Plan {
+				return &Plan{
+					Blocks: []*Block{
+						{Sequences: []*Sequence{{Actions: []*Action{action(k0, ""), action(k1, resolverReq{keys: []uuid.UUID{k0}, resp: ""})}}}},
+					},
+				}
+			},
+			err: true,
+		},
+		{
+			name: "Error: Checks Action has Inputs",
+			plan: func() *Plan {
+				return &Plan{
+					PostChecks: &Checks{Actions: []*Action{action(uuid.Nil, uses(k0))}},
+					Blocks:     []*Block{{Sequences: []*Sequence{{Actions: []*Action{action(k0, "")}}}}},
+				}
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateInputs(test.plan())
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidateInputs(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidateInputs(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate go tool github.com/johnsiilver/stringer -type=CatchUp -linecomment
+
+// CatchUp is what a Schedule does about runs that were due while the Workstream was down.
+type CatchUp uint8
+
+const (
+	// CUOnce starts a single run for all the runs that were missed. This is the default.
+	CUOnce CatchUp = 0 // Once
+	// CUSkip does not start any of the runs that were missed. A Plan scheduled with Workstream.StartAt
+	// that missed its run is Stopped with FRMissed.
+	CUSkip CatchUp = 1 // Skip
+	// CUAll starts a run for each of the runs that were missed.
+	CUAll CatchUp = 2 // All
+)
+
+// Schedule is when a Plan is started. This is set by Workstream.StartAt or Workstream.Schedule and should not
+// be set by the user. While the Plan is waiting on its Schedule, it has a Status of Scheduled.
+type Schedule struct {
+	// At is the time a Plan scheduled with Workstream.StartAt is started. The Plan itself is started.
+	At time.Time `json:",omitzero"`
+	// Cron is the cron expression of a Plan scheduled with Workstream.Schedule. Each time it is due, the Plan
+	// is cloned and the clone is submitted and started. The Plan itself is a template that is never run.
+	Cron string `json:",omitempty"`
+	// CatchUp is what is done about runs that were due while the Workstream was down.
+	CatchUp CatchUp `json:",omitempty"`
+	// Next is the time of the next run.
+	Next time.Time `json:",omitzero"`
+	// LastRun is the ID of the Plan of the last run started by a Cron Schedule.
+	LastRun uuid.UUID `json:",omitzero"`
+}
@@ -9,32 +9,56 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[NotStarted-0]
+	_ = x[Scheduled-25]
+	_ = x[Waiting-40]
+	_ = x[Queued-50]
 	_ = x[Running-100]
+	_ = x[Paused-120]
+	_ = x[Stopping-150]
 	_ = x[Completed-200]
+	_ = x[Skipped-250]
 	_ = x[Failed-300]
 	_ = x[Stopped-400]
 }
 
 const (
-	_Status_name_0 = "NotStarted"
-	_Status_name_1 = "Running"
-	_Status_name_2 = "Completed"
-	_Status_name_3 = "Failed"
-	_Status_name_4 = "Stopped"
+	_Status_name_0  = "NotStarted"
+	_Status_name_1  = "Scheduled"
+	_Status_name_2  = "Waiting"
+	_Status_name_3  = "Queued"
+	_Status_name_4  = "Running"
+	_Status_name_5  = "Paused"
+	_Status_name_6  = "Stopping"
+	_Status_name_7  = "Completed"
+	_Status_name_8  = "Skipped"
+	_Status_name_9  = "Failed"
+	_Status_name_10 = "Stopped"
 )
 
 func (i Status) String() string {
 	switch {
 	case i == 0:
 		return _Status_name_0
-	case i == 100:
+	case i == 25:
 		return _Status_name_1
-	case i == 200:
+	case i == 40:
 		return _Status_name_2
-	case i == 300:
+	case i == 50:
 		return _Status_name_3
-	case i == 400:
+	case i == 100:
 		return _Status_name_4
+	case i == 120:
+		return _Status_name_5
+	case i == 150:
+		return _Status_name_6
+	case i == 200:
+		return _Status_name_7
+	case i == 250:
+		return _Status_name_8
+	case i == 300:
+		return _Status_name_9
+	case i == 400:
+		return _Status_name_10
 	default:
 		return "Status(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
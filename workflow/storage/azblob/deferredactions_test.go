@@ -183,3 +183,124 @@ func TestDeferredActionsFetchMissing(t *testing.T) {
 		t.Fatalf("TestDeferredActionsFetchMissing: got err == nil, want NotFound")
 	}
 }
+
+// TestBlockSequenceDeferredActionsRoundTrip verifies that the DeferredActions of a Block and a Sequence
+// are uploaded with the Block, read back by fetchBlock and deleted with the Block.
+func TestBlockSequenceDeferredActionsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClient, del := setupDeleterTest(t)
+	prefix := "test"
+
+	newDA := func(name string) *workflow.DeferredActions {
+		action := &workflow.Action{
+			ID:      workflow.NewV7(),
+			Name:    name,
+			Descr:   name,
+			Plugin:  testPlugins.HelloPluginName,
+			Timeout: 30 * time.Second,
+			Req:     testPlugins.HelloReq{Say: name},
+		}
+		action.State.Set(workflow.State{Status: workflow.NotStarted})
+		batch := &workflow.DeferBatch{When: workflow.Always}
+		batch.ID = workflow.NewV7()
+		batch.Name = name
+		batch.Descr = name
+		batch.Actions = []*workflow.Action{action}
+		batch.State.Set(workflow.State{Status: workflow.NotStarted})
+		da := &workflow.DeferredActions{ID: workflow.NewV7(), DeferredBatches: []*workflow.DeferBatch{batch}}
+		da.State.Set(workflow.State{Status: workflow.NotStarted})
+		return da
+	}
+
+	planID := workflow.NewV7()
+	seqAction := &workflow.Action{
+		ID:      workflow.NewV7(),
+		Name:    "sequence action",
+		Descr:   "sequence action",
+		Plugin:  testPlugins.HelloPluginName,
+		Timeout: 30 * time.Second,
+		Req:     testPlugins.HelloReq{Say: "sequence"},
+	}
+	seqAction.State.Set(workflow.State{Status: workflow.NotStarted})
+	seq := &workflow.Sequence{
+		ID:              workflow.NewV7(),
+		Name:            "sequence",
+		Descr:           "sequence",
+		Actions:         []*workflow.Action{seqAction},
+		DeferredActions: newDA("sequence cleanup"),
+	}
+	seq.State.Set(workflow.State{Status: workflow.NotStarted})
+	block := &workflow.Block{
+		ID:              workflow.NewV7(),
+		Name:            "block",
+		Descr:           "block",
+		Sequences:       []*workflow.Sequence{seq},
+		DeferredActions: newDA("block cleanup"),
+	}
+	block.State.Set(workflow.State{Status: workflow.NotStarted})
+	plan := &workflow.Plan{ID: planID, Name: "plan", Descr: "plan", Blocks: []*workflow.Block{block}}
+	plan.State.Set(workflow.State{Status: workflow.NotStarted})
+
+	containerName := containerForPlan(prefix, planID)
+	if err := fakeClient.EnsureContainer(ctx, containerName); err != nil {
+		t.Fatalf("TestBlockSequenceDeferredActionsRoundTrip: EnsureContainer: %s", err)
+	}
+	u := &uploader{
+		mu:          planlocks.New(ctx),
+		client:      fakeClient,
+		prefix:      prefix,
+		planObjPool: context.Pool(ctx).Limited(ctx, "", 5),
+		blockPool:   context.Pool(ctx).Limited(ctx, "", 5),
+		leafObjPool: context.Pool(ctx).Limited(ctx, "", 20),
+	}
+	if err := u.uploadSubObjects(ctx, containerName, plan); err != nil {
+		t.Fatalf("TestBlockSequenceDeferredActionsRoundTrip: uploadSubObjects: %s", err)
+	}
+
+	got, err := del.reader.fetchBlock(ctx, containerName, planID, block.ID)
+	if err != nil {
+		t.Fatalf("TestBlockSequenceDeferredActionsRoundTrip: fetchBlock: %s", err)
+	}
+	for _, test := range []struct {
+		name string
+		got  *workflow.DeferredActions
+		want *workflow.DeferredActions
+	}{
+		{name: "block", got: got.DeferredActions, want: block.DeferredActions},
+		{name: "sequence", got: got.Sequences[0].DeferredActions, want: seq.DeferredActions},
+	} {
+		if test.got == nil {
+			t.Errorf("TestBlockSequenceDeferredActionsRoundTrip(%s): DeferredActions == nil, want non-nil", test.name)
+			continue
+		}
+		if test.got.ID != test.want.ID {
+			t.Errorf("TestBlockSequenceDeferredActionsRoundTrip(%s): DeferredActions ID = %v, want %v", test.name, test.got.ID, test.want.ID)
+		}
+		if len(test.got.DeferredBatches) != 1 || len(test.got.DeferredBatches[0].Actions) != 1 {
+			t.Errorf("TestBlockSequenceDeferredActionsRoundTrip(%s): want 1 DeferBatch with 1 Action", test.name)
+			continue
+		}
+		if test.got.DeferredBatches[0].Name != test.want.DeferredBatches[0].Name {
+			t.Errorf("TestBlockSequenceDeferredActionsRoundTrip(%s): DeferBatch name = %q, want %q", test.name, test.got.DeferredBatches[0].Name, test.want.DeferredBatches[0].Name)
+		}
+	}
+
+	if err := del.deleteBlockBlobs(ctx, containerName, planID, block); err != nil {
+		t.Fatalf("TestBlockSequenceDeferredActionsRoundTrip: deleteBlockBlobs: %s", err)
+	}
+	for _, da := range []*workflow.DeferredActions{block.DeferredActions, seq.DeferredActions} {
+		if fakeClient.BlobExists(containerName, deferredActionsBlobName(planID, da.ID)) {
+			t.Errorf("TestBlockSequenceDeferredActionsRoundTrip: DeferredActions blob should be deleted")
+		}
+		for _, batch := range da.DeferredBatches {
+			if fakeClient.BlobExists(containerName, deferBatchBlobName(planID, batch.ID)) {
+				t.Errorf("TestBlockSequenceDeferredActionsRoundTrip: DeferBatch blob should be deleted")
+			}
+			if fakeClient.BlobExists(containerName, actionBlobName(planID, batch.Actions[0].ID)) {
+				t.Errorf("TestBlockSequenceDeferredActionsRoundTrip: DeferBatch action blob should be deleted")
+			}
+		}
+	}
+}
@@ -128,7 +128,7 @@ func (d deleter) deleteBlockBlobs(ctx context.Context, containerName string, pla
 	}
 
 	// Delete block's checks
-	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 		if checks != nil {
 			if err := d.deleteChecksBlobs(ctx, containerName, planID, checks); err != nil {
 				return err
@@ -136,6 +136,13 @@ func (d deleter) deleteBlockBlobs(ctx context.Context, containerName string, pla
 		}
 	}
 
+	// Delete block's DeferredActions
+	if block.DeferredActions != nil {
+		if err := d.deleteDeferredActionsBlobs(ctx, containerName, planID, block.DeferredActions); err != nil {
+			return err
+		}
+	}
+
 	// Delete block's sequences
 	for _, seq := range block.Sequences {
 		if err := d.deleteSequenceBlobs(ctx, containerName, planID, seq); err != nil {
@@ -146,7 +153,7 @@ func (d deleter) deleteBlockBlobs(ctx context.Context, containerName string, pla
 	return nil
 }
 
-// deleteSequenceBlobs deletes all blobs for a sequence and its actions.
+// deleteSequenceBlobs deletes all blobs for a sequence, its actions, their Undos and its DeferredActions.
 func (d deleter) deleteSequenceBlobs(ctx context.Context, containerName string, planID uuid.UUID, seq *workflow.Sequence) error {
 	// Delete sequence blob
 	seqBlob := sequenceBlobName(planID, seq.ID)
@@ -156,11 +163,23 @@ func (d deleter) deleteSequenceBlobs(ctx context.Context, containerName string,
 		}
 	}
 
-	// Delete sequence's actions
+	// Delete sequence's actions and their Undos
 	for _, action := range seq.Actions {
 		if err := d.deleteActionBlob(ctx, containerName, planID, action.ID); err != nil {
 			return err
 		}
+		if action.Undo != nil {
+			if err := d.deleteActionBlob(ctx, containerName, planID, action.Undo.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Delete sequence's DeferredActions
+	if seq.DeferredActions != nil {
+		if err := d.deleteDeferredActionsBlobs(ctx, containerName, planID, seq.DeferredActions); err != nil {
+			return err
+		}
 	}
 
 	return nil
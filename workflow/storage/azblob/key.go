@@ -94,7 +94,7 @@ func recoveryContainerName(ctx context.Context, reader reader, cn string) (strin
 
 	notCompleted := 0
 	for _, lr := range results {
-		if lr.State.Status == workflow.NotStarted || lr.State.Status == workflow.Running {
+		if lr.State.Status == workflow.NotStarted || lr.State.Status == workflow.Scheduled || lr.State.Status.Active() {
 			notCompleted++
 		}
 	}
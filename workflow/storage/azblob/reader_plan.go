@@ -103,7 +103,7 @@ func (r reader) fetchPlanFromContainer(ctx context.Context, id uuid.UUID) (*work
 	}
 
 	containerName := containerForPlan(r.prefix, id)
-	if pm.State.Status == workflow.Running {
+	if pm.State.Status.Active() {
 		return r.fetchRunningPlan(ctx, containerName, id, pm.ListResult)
 	}
 	return r.fetchNonRunningPlan(ctx, containerName, id)
@@ -192,13 +192,19 @@ func (r reader) fetchRunningPlan(ctx context.Context, containerName string, id u
 	}
 
 	plan := &workflow.Plan{
-		ID:         id,
-		Name:       lr.Name,
-		Descr:      lr.Descr,
-		GroupID:    lr.GroupID,
-		Meta:       entry.Meta,
-		SubmitTime: lr.SubmitTime,
-		Reason:     entry.Reason,
+		ID:          id,
+		Name:        lr.Name,
+		Descr:       lr.Descr,
+		GroupID:     lr.GroupID,
+		Meta:        entry.Meta,
+		SubmitTime:  lr.SubmitTime,
+		Reason:      entry.Reason,
+		Concurrency: entry.Concurrency,
+		Timeout:     entry.Timeout,
+		Priority:    entry.Priority,
+		Schedule:    entry.Schedule,
+		Windows:     entry.Windows,
+		DependsOn:   entry.DependsOn,
 	}
 	plan.State.Set(lr.State)
 
@@ -309,7 +315,7 @@ func (r reader) fetchChecks(ctx context.Context, containerName string, planID, c
 	return checks, nil
 }
 
-// fetchBlock downloads a Block object and all its sub-objects (Checks and Sequences).
+// fetchBlock downloads a Block object and all its sub-objects (Checks, DeferredActions and Sequences).
 func (r reader) fetchBlock(ctx context.Context, containerName string, planID, blockID uuid.UUID) (*workflow.Block, error) {
 	blobName := blockBlobName(planID, blockID)
 	data, err := r.client.GetBlob(ctx, containerName, blobName)
@@ -331,15 +337,27 @@ func (r reader) fetchBlock(ctx context.Context, containerName string, planID, bl
 	}
 	block.SetPlanID(planID)
 
-	// Fetch all check objects and sequences concurrently; each writes a distinct field/index.
+	// Fetch all check objects, deferred actions and sequences concurrently; each writes a distinct field/index.
 	g := worker.Default().Limited(ctx, "azBlobReaderBlock", fetchConcurrency).Group()
 
 	r.goFetchChecks(ctx, &g, containerName, planID, entry.BypassChecks, func(c *workflow.Checks) { block.BypassChecks = c })
 	r.goFetchChecks(ctx, &g, containerName, planID, entry.PreChecks, func(c *workflow.Checks) { block.PreChecks = c })
 	r.goFetchChecks(ctx, &g, containerName, planID, entry.PostChecks, func(c *workflow.Checks) { block.PostChecks = c })
 	r.goFetchChecks(ctx, &g, containerName, planID, entry.ContChecks, func(c *workflow.Checks) { block.ContChecks = c })
+	r.goFetchChecks(ctx, &g, containerName, planID, entry.WaveChecks, func(c *workflow.Checks) { block.WaveChecks = c })
 	r.goFetchChecks(ctx, &g, containerName, planID, entry.DeferredChecks, func(c *workflow.Checks) { block.DeferredChecks = c })
 
+	if entry.DeferredActions != uuid.Nil {
+		g.Go(ctx, func(ctx context.Context) error {
+			da, err := r.fetchDeferredActions(ctx, containerName, planID, entry.DeferredActions)
+			if err != nil {
+				return err
+			}
+			block.DeferredActions = da
+			return nil
+		})
+	}
+
 	block.Sequences = make([]*workflow.Sequence, len(entry.Sequences))
 	for i, seqID := range entry.Sequences {
 		g.Go(ctx, func(ctx context.Context) error {
@@ -359,7 +377,7 @@ func (r reader) fetchBlock(ctx context.Context, containerName string, planID, bl
 	return block, nil
 }
 
-// fetchSequence downloads a Sequence object and all its Actions.
+// fetchSequence downloads a Sequence object, all its Actions and its DeferredActions.
 func (r reader) fetchSequence(ctx context.Context, containerName string, planID, sequenceID uuid.UUID) (*workflow.Sequence, error) {
 	blobName := sequenceBlobName(planID, sequenceID)
 	data, err := r.client.GetBlob(ctx, containerName, blobName)
@@ -394,6 +412,16 @@ func (r reader) fetchSequence(ctx context.Context, containerName string, planID,
 			return nil
 		})
 	}
+	if entry.DeferredActions != uuid.Nil {
+		g.Go(ctx, func(ctx context.Context) error {
+			da, err := r.fetchDeferredActions(ctx, containerName, planID, entry.DeferredActions)
+			if err != nil {
+				return err
+			}
+			seq.DeferredActions = da
+			return nil
+		})
+	}
 	if err := unwrapGroup(g.Wait(ctx)); err != nil {
 		return nil, err
 	}
@@ -412,12 +440,19 @@ func (r reader) fetchAction(ctx context.Context, containerName string, planID, a
 		return nil, errors.E(ctx, errors.CatInternal, errors.TypeStorageGet, fmt.Errorf("failed to download action blob: %w", err))
 	}
 
-	action, err := entryToAction(ctx, r.reg, data)
+	action, undoID, err := entryToAction(ctx, r.reg, data)
 	if err != nil {
 		return nil, errors.E(ctx, errors.CatInternal, errors.TypeStorageGet, fmt.Errorf("failed to convert entry to action: %w", err))
 	}
 	action.SetPlanID(planID)
 
+	if undoID != uuid.Nil {
+		action.Undo, err = r.fetchAction(ctx, containerName, planID, undoID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch undo action: %w", err)
+		}
+	}
+
 	return action, nil
 }
 
@@ -444,18 +479,35 @@ func (r reader) setRegistry(plan *workflow.Plan) error {
 	// Set registry on all block/sequence actions
 	for _, block := range plan.Blocks {
 		// Block checks
-		for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+		for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 			if checks != nil {
 				for _, action := range checks.Actions {
 					action.SetRegister(r.reg)
 				}
 			}
 		}
+		if block.DeferredActions != nil {
+			for _, batch := range block.DeferredActions.DeferredBatches {
+				for _, action := range batch.Actions {
+					action.SetRegister(r.reg)
+				}
+			}
+		}
 
 		// Sequence actions
 		for _, seq := range block.Sequences {
 			for _, action := range seq.Actions {
 				action.SetRegister(r.reg)
+				if action.Undo != nil {
+					action.Undo.SetRegister(r.reg)
+				}
+			}
+			if seq.DeferredActions != nil {
+				for _, batch := range seq.DeferredActions.DeferredBatches {
+					for _, action := range batch.Actions {
+						action.SetRegister(r.reg)
+					}
+				}
 			}
 		}
 	}
@@ -552,7 +604,7 @@ func (r reader) fixActions(ctx context.Context, plan *workflow.Plan) error {
 	// Fix all actions in blocks
 	for _, block := range plan.Blocks {
 		// Block-level checks
-		for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+		for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 			if checks != nil {
 				for _, action := range checks.Actions {
 					if err := fixAction(action); err != nil {
@@ -561,6 +613,15 @@ func (r reader) fixActions(ctx context.Context, plan *workflow.Plan) error {
 				}
 			}
 		}
+		if block.DeferredActions != nil {
+			for _, batch := range block.DeferredActions.DeferredBatches {
+				for _, action := range batch.Actions {
+					if err := fixAction(action); err != nil {
+						return err
+					}
+				}
+			}
+		}
 
 		// Sequence actions
 		for _, seq := range block.Sequences {
@@ -568,6 +629,20 @@ func (r reader) fixActions(ctx context.Context, plan *workflow.Plan) error {
 				if err := fixAction(action); err != nil {
 					return err
 				}
+				if action.Undo != nil {
+					if err := fixAction(action.Undo); err != nil {
+						return err
+					}
+				}
+			}
+			if seq.DeferredActions != nil {
+				for _, batch := range seq.DeferredActions.DeferredBatches {
+					for _, action := range batch.Actions {
+						if err := fixAction(action); err != nil {
+							return err
+						}
+					}
+				}
 			}
 		}
 	}
@@ -116,7 +116,7 @@ func (r recovery) recoverPlan(ctx context.Context, containerName string, planID
 
 	// If the object is finished, no recovery needed. Since the object is the last thing to change,
 	// we don't need consistency checks here.
-	if pom.State.Status > workflow.Running {
+	if pom.State.Status > workflow.Running && !pom.State.Status.Active() {
 		return nil
 	}
 
@@ -126,7 +126,7 @@ func (r recovery) recoverPlan(ctx context.Context, containerName string, planID
 	}
 
 	// Only recover if the plan is not currently running
-	if plan.State.Get().Status == workflow.Running {
+	if plan.State.Get().Status.Active() {
 		return nil // Plan is running, don't interfere
 	}
 
@@ -180,7 +180,7 @@ func (r recovery) ensureBlockBlob(ctx context.Context, c creator, containerName
 
 	g := worker.Default().Limited(ctx, "azBlobRecoveryBlock", fetchConcurrency).Group()
 
-	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 		if checks == nil {
 			continue
 		}
@@ -258,8 +258,13 @@ func (r recovery) ensureActionBlob(ctx context.Context, c creator, containerName
 		if err := r.uploader.uploadActionBlob(ctx, containerName, planID, action, pos); err != nil {
 			return fmt.Errorf("failed to recreate action blob: %w", err)
 		}
+		// uploadActionBlob also uploads the Undo.
+		return nil
 	}
 
+	if action.Undo != nil {
+		return r.ensureActionBlob(ctx, c, containerName, planID, action.Undo, pos)
+	}
 	return nil
 }
 
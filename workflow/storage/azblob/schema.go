@@ -134,58 +134,85 @@ type planEntry struct {
 	StateEnd        time.Time              `json:"stateEnd,omitempty"`
 	SubmitTime      time.Time              `json:"submitTime"`
 	Reason          workflow.FailureReason `json:"reason,omitempty"`
+	Concurrency     int                    `json:"concurrency,omitempty"`
+	Timeout         time.Duration          `json:"timeout,omitempty,format:iso8601"`
+	Priority        int                    `json:"priority,omitempty"`
+	Schedule        *workflow.Schedule     `json:"schedule,omitempty"`
+	Windows         *workflow.Windows      `json:"windows,omitempty"`
+	DependsOn       []*workflow.PlanDep    `json:"dependsOn,omitempty"`
 }
 
 // blocksEntry represents a Block object in blob storage.
 type blocksEntry struct {
-	Type              workflow.ObjectType `json:"type"`
-	ID                uuid.UUID           `json:"id"`
-	Key               uuid.UUID           `json:"key,omitempty"`
-	PlanID            uuid.UUID           `json:"planID"`
-	Name              string              `json:"name"`
-	Descr             string              `json:"descr"`
-	Pos               int                 `json:"pos"`
-	EntranceDelay     time.Duration       `json:"entranceDelay,omitempty,format:iso8601"`
-	ExitDelay         time.Duration       `json:"exitDelay,omitempty,format:iso8601"`
-	BypassChecks      uuid.UUID           `json:"bypassChecks,omitempty"`
-	PreChecks         uuid.UUID           `json:"preChecks,omitempty"`
-	PostChecks        uuid.UUID           `json:"postChecks,omitempty"`
-	ContChecks        uuid.UUID           `json:"contChecks,omitempty"`
-	DeferredChecks    uuid.UUID           `json:"deferredChecks,omitempty"`
-	Sequences         []uuid.UUID         `json:"sequences,omitempty"`
-	Concurrency       int                 `json:"concurrency"`
-	ToleratedFailures int                 `json:"toleratedFailures"`
-	StateStatus       workflow.Status     `json:"stateStatus"`
-	StateStart        time.Time           `json:"stateStart,omitzero"`
-	StateEnd          time.Time           `json:"stateEnd,omitzero"`
+	Type                    workflow.ObjectType `json:"type"`
+	ID                      uuid.UUID           `json:"id"`
+	Key                     uuid.UUID           `json:"key,omitempty"`
+	PlanID                  uuid.UUID           `json:"planID"`
+	Name                    string              `json:"name"`
+	Descr                   string              `json:"descr"`
+	Pos                     int                 `json:"pos"`
+	EntranceDelay           time.Duration       `json:"entranceDelay,omitempty,format:iso8601"`
+	ExitDelay               time.Duration       `json:"exitDelay,omitempty,format:iso8601"`
+	When                    workflow.WhenCond   `json:"when,omitempty"`
+	BypassChecks            uuid.UUID           `json:"bypassChecks,omitempty"`
+	PreChecks               uuid.UUID           `json:"preChecks,omitempty"`
+	PostChecks              uuid.UUID           `json:"postChecks,omitempty"`
+	ContChecks              uuid.UUID           `json:"contChecks,omitempty"`
+	WaveChecks              uuid.UUID           `json:"waveChecks,omitempty"`
+	DeferredChecks          uuid.UUID           `json:"deferredChecks,omitempty"`
+	DeferredActions         uuid.UUID           `json:"deferredActions,omitempty"`
+	Sequences               []uuid.UUID         `json:"sequences,omitempty"`
+	DependsOn               []uuid.UUID         `json:"dependsOn,omitempty"`
+	Concurrency             int                 `json:"concurrency"`
+	Ramp                    *workflow.Ramp      `json:"ramp,omitempty"`
+	ToleratedFailures       int                 `json:"toleratedFailures"`
+	ToleratedFailurePercent int                 `json:"toleratedFailurePercent,omitempty"`
+	Timeout                 time.Duration       `json:"timeout,omitempty,format:iso8601"`
+	Approval                *workflow.Approval  `json:"approval,omitempty"`
+	Windows                 *workflow.Windows   `json:"windows,omitempty"`
+	Locks                   *workflow.Locks     `json:"locks,omitempty"`
+	StateStatus             workflow.Status     `json:"stateStatus"`
+	StateStart              time.Time           `json:"stateStart,omitzero"`
+	StateEnd                time.Time           `json:"stateEnd,omitzero"`
 }
 
 // checksEntry represents a Checks object in blob storage.
 type checksEntry struct {
-	Type        workflow.ObjectType `json:"type"`
-	ID          uuid.UUID           `json:"id"`
-	Key         uuid.UUID           `json:"key,omitempty"`
-	PlanID      uuid.UUID           `json:"planID"`
-	Actions     []uuid.UUID         `json:"actions,omitempty"`
-	Delay       time.Duration       `json:"delay,omitempty,format:iso8601"`
-	StateStatus workflow.Status     `json:"stateStatus"`
-	StateStart  time.Time           `json:"stateStart,omitzero"`
-	StateEnd    time.Time           `json:"stateEnd,omitzero"`
+	Type             workflow.ObjectType        `json:"type"`
+	ID               uuid.UUID                  `json:"id"`
+	Key              uuid.UUID                  `json:"key,omitempty"`
+	PlanID           uuid.UUID                  `json:"planID"`
+	Actions          []uuid.UUID                `json:"actions,omitempty"`
+	Delay            time.Duration              `json:"delay,omitempty,format:iso8601"`
+	WaitUntil        time.Duration              `json:"waitUntil,omitempty,format:iso8601"`
+	SoakDuration     time.Duration              `json:"soakDuration,omitempty,format:iso8601"`
+	FailureThreshold *workflow.FailureThreshold `json:"failureThreshold,omitempty"`
+	Attempts         []workflow.SeqAttempt      `json:"attempts,omitempty"`
+	StateStatus      workflow.Status            `json:"stateStatus"`
+	StateStart       time.Time                  `json:"stateStart,omitzero"`
+	StateEnd         time.Time                  `json:"stateEnd,omitzero"`
 }
 
 // sequencesEntry represents a Sequence object in blob storage.
 type sequencesEntry struct {
-	Type        workflow.ObjectType `json:"type"`
-	ID          uuid.UUID           `json:"id"`
-	Key         uuid.UUID           `json:"key,omitempty"`
-	PlanID      uuid.UUID           `json:"planID"`
-	Name        string              `json:"name"`
-	Descr       string              `json:"descr"`
-	Pos         int                 `json:"pos"`
-	Actions     []uuid.UUID         `json:"actions,omitempty"`
-	StateStatus workflow.Status     `json:"stateStatus"`
-	StateStart  time.Time           `json:"stateStart,omitzero"`
-	StateEnd    time.Time           `json:"stateEnd,omitzero"`
+	Type            workflow.ObjectType   `json:"type"`
+	ID              uuid.UUID             `json:"id"`
+	Key             uuid.UUID             `json:"key,omitempty"`
+	PlanID          uuid.UUID             `json:"planID"`
+	Name            string                `json:"name"`
+	Descr           string                `json:"descr"`
+	Pos             int                   `json:"pos"`
+	When            workflow.WhenCond     `json:"when,omitempty"`
+	Actions         []uuid.UUID           `json:"actions,omitempty"`
+	DeferredActions uuid.UUID             `json:"deferredActions,omitempty"`
+	Retries         int                   `json:"retries,omitempty"`
+	RetryPolicy     *workflow.RetryPolicy `json:"retryPolicy,omitempty"`
+	Approval        *workflow.Approval    `json:"approval,omitempty"`
+	Locks           *workflow.Locks       `json:"locks,omitempty"`
+	Attempts        []workflow.SeqAttempt `json:"attempts,omitempty"`
+	StateStatus     workflow.Status       `json:"stateStatus"`
+	StateStart      time.Time             `json:"stateStart,omitzero"`
+	StateEnd        time.Time             `json:"stateEnd,omitzero"`
 }
 
 // actionsEntry represents an Action object in blob storage.
@@ -200,6 +227,8 @@ type actionsEntry struct {
 	Plugin      string              `json:"plugin"`
 	Timeout     time.Duration       `json:"timeout,format:iso8601"`
 	Retries     int                 `json:"retries"`
+	When        workflow.WhenCond   `json:"when,omitempty"`
+	Undo        uuid.UUID           `json:"undo,omitzero"`
 	Req         []byte              `json:"req,omitempty"`
 	Attempts    []byte              `json:"attempts,omitempty"`
 	StateStatus workflow.Status     `json:"stateStatus"`
@@ -226,6 +255,12 @@ func planToPlanEntry(p *workflow.Plan) (planEntry, error) {
 		Meta:        p.Meta,
 		SubmitTime:  p.SubmitTime,
 		Reason:      p.Reason,
+		Concurrency: p.Concurrency,
+		Timeout:     p.Timeout,
+		Priority:    p.Priority,
+		Schedule:    p.Schedule,
+		Windows:     p.Windows,
+		DependsOn:   p.DependsOn,
 		StateStatus: workflow.NotStarted,
 	}
 
@@ -273,18 +308,26 @@ func blockToEntry(b *workflow.Block, pos int) (blocksEntry, error) {
 	}
 
 	entry := blocksEntry{
-		Type:              workflow.OTBlock,
-		ID:                b.ID,
-		Key:               b.Key,
-		PlanID:            b.GetPlanID(),
-		Name:              b.Name,
-		Descr:             b.Descr,
-		Pos:               pos,
-		EntranceDelay:     b.EntranceDelay,
-		ExitDelay:         b.ExitDelay,
-		Concurrency:       b.Concurrency,
-		ToleratedFailures: b.ToleratedFailures,
-		StateStatus:       workflow.NotStarted,
+		Type:                    workflow.OTBlock,
+		ID:                      b.ID,
+		Key:                     b.Key,
+		PlanID:                  b.GetPlanID(),
+		Name:                    b.Name,
+		Descr:                   b.Descr,
+		Pos:                     pos,
+		EntranceDelay:           b.EntranceDelay,
+		ExitDelay:               b.ExitDelay,
+		When:                    b.When,
+		DependsOn:               b.DependsOn,
+		Concurrency:             b.Concurrency,
+		Ramp:                    b.Ramp,
+		ToleratedFailures:       b.ToleratedFailures,
+		ToleratedFailurePercent: b.ToleratedFailurePercent,
+		Timeout:                 b.Timeout,
+		Approval:                b.Approval,
+		Windows:                 b.Windows,
+		Locks:                   b.Locks,
+		StateStatus:             workflow.NotStarted,
 	}
 
 	if state := b.State.Get(); state != (workflow.State{}) {
@@ -306,9 +349,15 @@ func blockToEntry(b *workflow.Block, pos int) (blocksEntry, error) {
 	if b.ContChecks != nil {
 		entry.ContChecks = b.ContChecks.ID
 	}
+	if b.WaveChecks != nil {
+		entry.WaveChecks = b.WaveChecks.ID
+	}
 	if b.DeferredChecks != nil {
 		entry.DeferredChecks = b.DeferredChecks.ID
 	}
+	if b.DeferredActions != nil {
+		entry.DeferredActions = b.DeferredActions.ID
+	}
 
 	entry.Sequences = make([]uuid.UUID, len(b.Sequences))
 	for i, s := range b.Sequences {
@@ -321,14 +370,22 @@ func blockToEntry(b *workflow.Block, pos int) (blocksEntry, error) {
 // entryToBlock converts a blocksEntry back to a workflow.Block.
 func entryToBlock(entry blocksEntry) (*workflow.Block, error) {
 	b := &workflow.Block{
-		ID:                entry.ID,
-		Key:               entry.Key,
-		Name:              entry.Name,
-		Descr:             entry.Descr,
-		EntranceDelay:     entry.EntranceDelay,
-		ExitDelay:         entry.ExitDelay,
-		Concurrency:       entry.Concurrency,
-		ToleratedFailures: entry.ToleratedFailures,
+		ID:                      entry.ID,
+		Key:                     entry.Key,
+		Name:                    entry.Name,
+		Descr:                   entry.Descr,
+		EntranceDelay:           entry.EntranceDelay,
+		ExitDelay:               entry.ExitDelay,
+		When:                    entry.When,
+		DependsOn:               entry.DependsOn,
+		Concurrency:             entry.Concurrency,
+		Ramp:                    entry.Ramp,
+		ToleratedFailures:       entry.ToleratedFailures,
+		ToleratedFailurePercent: entry.ToleratedFailurePercent,
+		Timeout:                 entry.Timeout,
+		Approval:                entry.Approval,
+		Windows:                 entry.Windows,
+		Locks:                   entry.Locks,
 	}
 	b.State.Set(workflow.State{
 		Status: entry.StateStatus,
@@ -350,12 +407,16 @@ func checksToEntry(c *workflow.Checks) (checksEntry, error) {
 	}
 
 	entry := checksEntry{
-		Type:        workflow.OTCheck,
-		ID:          c.ID,
-		Key:         c.Key,
-		PlanID:      c.GetPlanID(),
-		Delay:       c.Delay,
-		StateStatus: workflow.NotStarted,
+		Type:             workflow.OTCheck,
+		ID:               c.ID,
+		Key:              c.Key,
+		PlanID:           c.GetPlanID(),
+		Delay:            c.Delay,
+		WaitUntil:        c.WaitUntil,
+		SoakDuration:     c.SoakDuration,
+		FailureThreshold: c.FailureThreshold,
+		Attempts:         c.Attempts.Get(),
+		StateStatus:      workflow.NotStarted,
 	}
 
 	if state := c.State.Get(); state != (workflow.State{}) {
@@ -375,9 +436,15 @@ func checksToEntry(c *workflow.Checks) (checksEntry, error) {
 // entryToChecks converts a checksEntry back to a workflow.Checks.
 func entryToChecks(entry checksEntry) (*workflow.Checks, error) {
 	c := &workflow.Checks{
-		ID:    entry.ID,
-		Key:   entry.Key,
-		Delay: entry.Delay,
+		ID:               entry.ID,
+		Key:              entry.Key,
+		Delay:            entry.Delay,
+		WaitUntil:        entry.WaitUntil,
+		SoakDuration:     entry.SoakDuration,
+		FailureThreshold: entry.FailureThreshold,
+	}
+	if len(entry.Attempts) > 0 {
+		c.Attempts.Set(entry.Attempts)
 	}
 	c.State.Set(workflow.State{
 		Status: entry.StateStatus,
@@ -408,6 +475,12 @@ func sequenceToEntry(s *workflow.Sequence, pos int) (sequencesEntry, error) {
 		Name:        s.Name,
 		Descr:       s.Descr,
 		Pos:         pos,
+		When:        s.When,
+		Retries:     s.Retries,
+		RetryPolicy: s.RetryPolicy,
+		Approval:    s.Approval,
+		Locks:       s.Locks,
+		Attempts:    s.Attempts.Get(),
 		StateStatus: workflow.NotStarted,
 	}
 
@@ -421,6 +494,9 @@ func sequenceToEntry(s *workflow.Sequence, pos int) (sequencesEntry, error) {
 	for i, a := range s.Actions {
 		entry.Actions[i] = a.ID
 	}
+	if s.DeferredActions != nil {
+		entry.DeferredActions = s.DeferredActions.ID
+	}
 
 	return entry, nil
 }
@@ -428,10 +504,18 @@ func sequenceToEntry(s *workflow.Sequence, pos int) (sequencesEntry, error) {
 // entryToSequence converts a sequencesEntry back to a workflow.Sequence.
 func entryToSequence(entry sequencesEntry) (*workflow.Sequence, error) {
 	s := &workflow.Sequence{
-		ID:    entry.ID,
-		Key:   entry.Key,
-		Name:  entry.Name,
-		Descr: entry.Descr,
+		ID:          entry.ID,
+		Key:         entry.Key,
+		Name:        entry.Name,
+		Descr:       entry.Descr,
+		When:        entry.When,
+		Retries:     entry.Retries,
+		RetryPolicy: entry.RetryPolicy,
+		Approval:    entry.Approval,
+		Locks:       entry.Locks,
+	}
+	if len(entry.Attempts) > 0 {
+		s.Attempts.Set(entry.Attempts)
 	}
 	s.State.Set(workflow.State{
 		Status: entry.StateStatus,
@@ -463,6 +547,8 @@ func actionToEntry(a *workflow.Action, pos int) (actionsEntry, error) {
 		Plugin:      a.Plugin,
 		Timeout:     a.Timeout,
 		Retries:     a.Retries,
+		When:        a.When,
+		Undo:        a.Undo.GetID(),
 		StateStatus: workflow.NotStarted,
 	}
 
@@ -492,12 +578,13 @@ func actionToEntry(a *workflow.Action, pos int) (actionsEntry, error) {
 	return entry, nil
 }
 
-// entryToAction converts an actionsEntry back to a workflow.Action.
-func entryToAction(ctx context.Context, reg *registry.Register, response []byte) (*workflow.Action, error) {
+// entryToAction converts an actionsEntry back to a workflow.Action. The Undo of the Action is stored in
+// its own blob, so this returns the ID of the Undo, which is uuid.Nil if there is none.
+func entryToAction(ctx context.Context, reg *registry.Register, response []byte) (*workflow.Action, uuid.UUID, error) {
 	var err error
 	var resp actionsEntry
 	if err = json.Unmarshal(response, &resp); err != nil {
-		return nil, errors.E(ctx, errors.CatInternal, errors.TypeStorageGet, fmt.Errorf("failed to unmarshal action: %w", err))
+		return nil, uuid.Nil, errors.E(ctx, errors.CatInternal, errors.TypeStorageGet, fmt.Errorf("failed to unmarshal action: %w", err))
 	}
 
 	a := &workflow.Action{
@@ -508,6 +595,7 @@ func entryToAction(ctx context.Context, reg *registry.Register, response []byte)
 		Plugin:  resp.Plugin,
 		Timeout: resp.Timeout,
 		Retries: resp.Retries,
+		When:    resp.When,
 	}
 	a.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -518,7 +606,7 @@ func entryToAction(ctx context.Context, reg *registry.Register, response []byte)
 
 	plug := reg.Plugin(a.Plugin)
 	if plug == nil {
-		return nil, fmt.Errorf("couldn't find plugin %s", a.Plugin)
+		return nil, uuid.Nil, fmt.Errorf("couldn't find plugin %s", a.Plugin)
 	}
 	b := resp.Req
 	if len(b) > 0 {
@@ -526,11 +614,11 @@ func entryToAction(ctx context.Context, reg *registry.Register, response []byte)
 		if req != nil {
 			if reflect.TypeOf(req).Kind() != reflect.Pointer {
 				if err := json.Unmarshal(b, &req); err != nil {
-					return nil, fmt.Errorf("couldn't unmarshal request: %w", err)
+					return nil, uuid.Nil, fmt.Errorf("couldn't unmarshal request: %w", err)
 				}
 			} else {
 				if err := json.Unmarshal(b, req); err != nil {
-					return nil, fmt.Errorf("couldn't unmarshal request: %w", err)
+					return nil, uuid.Nil, fmt.Errorf("couldn't unmarshal request: %w", err)
 				}
 			}
 			a.Req = req
@@ -541,12 +629,12 @@ func entryToAction(ctx context.Context, reg *registry.Register, response []byte)
 	if len(b) > 0 {
 		attempts, err := decodeAttempts(ctx, b, plug)
 		if err != nil {
-			return nil, fmt.Errorf("couldn't decode attempts: %w", err)
+			return nil, uuid.Nil, fmt.Errorf("couldn't decode attempts: %w", err)
 		}
 		a.Attempts.Set(attempts)
 	}
 
-	return a, nil
+	return a, resp.Undo, nil
 }
 
 // decodeAttempts decodes a JSON array of JSON encoded attempts as byte slices into a slice of attempts.
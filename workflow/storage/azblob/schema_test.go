@@ -62,6 +62,9 @@ func TestPlanToEntry(t *testing.T) {
 					SubmitTime: now,
 					PreChecks:  c,
 					Blocks:     []*workflow.Block{},
+					Priority:   2,
+					Schedule:   &workflow.Schedule{Cron: "@daily", Next: now},
+					DependsOn:  []*workflow.PlanDep{{GroupID: workflow.NewV7(), On: workflow.OCFail}},
 				}
 				p.State.Set(workflow.State{Status: workflow.NotStarted})
 				return p
@@ -113,6 +116,15 @@ func TestPlanToEntry(t *testing.T) {
 			if got.Type != workflow.OTPlan {
 				t.Errorf("TestPlanToEntry(%s): Type got %v, want %v", test.name, got.Type, workflow.OTPlan)
 			}
+			if got.Priority != test.plan.Priority {
+				t.Errorf("TestPlanToEntry(%s): Priority got %d, want %d", test.name, got.Priority, test.plan.Priority)
+			}
+			if got.Schedule != test.plan.Schedule {
+				t.Errorf("TestPlanToEntry(%s): Schedule got %+v, want %+v", test.name, got.Schedule, test.plan.Schedule)
+			}
+			if diff := pretty.Compare(test.plan.DependsOn, got.DependsOn); diff != "" {
+				t.Errorf("TestPlanToEntry(%s): DependsOn -want/+got:\n%s", test.name, diff)
+			}
 		})
 	}
 }
@@ -207,13 +219,16 @@ func TestEntryToBlock(t *testing.T) {
 		{
 			name: "Success: basic entry",
 			entry: blocksEntry{
-				Type:        workflow.OTBlock,
-				ID:          blockID,
-				PlanID:      planID,
-				Name:        "Test Block",
-				Descr:       "Test Description",
-				Pos:         0,
-				StateStatus: workflow.NotStarted,
+				Type:                    workflow.OTBlock,
+				ID:                      blockID,
+				PlanID:                  planID,
+				Name:                    "Test Block",
+				Descr:                   "Test Description",
+				Pos:                     0,
+				Timeout:                 time.Hour,
+				Ramp:                    &workflow.Ramp{Canary: 1, Steps: []int{50}},
+				ToleratedFailurePercent: 10,
+				StateStatus:             workflow.NotStarted,
 			},
 		},
 	}
@@ -232,6 +247,15 @@ func TestEntryToBlock(t *testing.T) {
 			if got.GetPlanID() != test.entry.PlanID {
 				t.Errorf("TestEntryToBlock(%s): PlanID got %v, want %v", test.name, got.GetPlanID(), test.entry.PlanID)
 			}
+			if got.Timeout != test.entry.Timeout {
+				t.Errorf("TestEntryToBlock(%s): Timeout got %v, want %v", test.name, got.Timeout, test.entry.Timeout)
+			}
+			if got.Ramp != test.entry.Ramp {
+				t.Errorf("TestEntryToBlock(%s): Ramp got %v, want %v", test.name, got.Ramp, test.entry.Ramp)
+			}
+			if got.ToleratedFailurePercent != test.entry.ToleratedFailurePercent {
+				t.Errorf("TestEntryToBlock(%s): ToleratedFailurePercent got %v, want %v", test.name, got.ToleratedFailurePercent, test.entry.ToleratedFailurePercent)
+			}
 		})
 	}
 }
@@ -302,6 +326,178 @@ func TestChecksToEntry(t *testing.T) {
 	}
 }
 
+func TestChecksEntryWaitUntil(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	c := &workflow.Checks{
+		ID:        workflow.NewV7(),
+		Delay:     time.Second,
+		WaitUntil: time.Minute,
+	}
+	c.Attempts.Set([]workflow.SeqAttempt{
+		{
+			Actions: []workflow.ActionResult{
+				{
+					ID:       workflow.NewV7(),
+					Status:   workflow.Failed,
+					Attempts: 1,
+					Err:      &plugins.Error{Message: "not ready"},
+					Start:    now,
+					End:      now,
+				},
+			},
+			Start: now,
+			End:   now,
+		},
+	})
+	c.State.Set(workflow.State{Status: workflow.Running, Start: now})
+	c.SetPlanID(workflow.NewV7())
+
+	entry, err := checksToEntry(c)
+	if err != nil {
+		t.Fatalf("TestChecksEntryWaitUntil: checksToEntry: %s", err)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("TestChecksEntryWaitUntil: json.Marshal: %s", err)
+	}
+	var got checksEntry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("TestChecksEntryWaitUntil: json.Unmarshal: %s", err)
+	}
+	checks, err := entryToChecks(got)
+	if err != nil {
+		t.Fatalf("TestChecksEntryWaitUntil: entryToChecks: %s", err)
+	}
+
+	if checks.WaitUntil != c.WaitUntil {
+		t.Errorf("TestChecksEntryWaitUntil: WaitUntil got %v, want %v", checks.WaitUntil, c.WaitUntil)
+	}
+	if diff := pretty.Compare(c.Attempts.Get(), checks.Attempts.Get()); diff != "" {
+		t.Errorf("TestChecksEntryWaitUntil: Attempts -want/+got:\n%s", diff)
+	}
+}
+
+func TestSequenceEntryApproval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	s := &workflow.Sequence{
+		ID:       workflow.NewV7(),
+		Name:     "seq",
+		Approval: &workflow.Approval{Key: "deploy", Expiry: time.Hour},
+	}
+	s.Approval.Decision.Set(workflow.Decision{Status: workflow.APApproved, Approver: "operator", Requested: now, Decided: now})
+	s.State.Set(workflow.State{Status: workflow.Running, Start: now})
+	s.SetPlanID(workflow.NewV7())
+
+	entry, err := sequenceToEntry(s, 0)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryApproval: sequenceToEntry: %s", err)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryApproval: json.Marshal: %s", err)
+	}
+	var got sequencesEntry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("TestSequenceEntryApproval: json.Unmarshal: %s", err)
+	}
+	seq, err := entryToSequence(got)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryApproval: entryToSequence: %s", err)
+	}
+
+	if seq.Approval == nil {
+		t.Fatalf("TestSequenceEntryApproval: Approval got nil, want %+v", s.Approval)
+	}
+	if seq.Approval.Key != s.Approval.Key || seq.Approval.Expiry != s.Approval.Expiry {
+		t.Errorf("TestSequenceEntryApproval: Approval got (%s, %v), want (%s, %v)", seq.Approval.Key, seq.Approval.Expiry, s.Approval.Key, s.Approval.Expiry)
+	}
+	if diff := pretty.Compare(s.Approval.Decision.Get(), seq.Approval.Decision.Get()); diff != "" {
+		t.Errorf("TestSequenceEntryApproval: Decision -want/+got:\n%s", diff)
+	}
+}
+
+func TestBlockEntryWindows(t *testing.T) {
+	t.Parallel()
+
+	b := &workflow.Block{
+		ID:   workflow.NewV7(),
+		Name: "block",
+		Windows: &workflow.Windows{
+			Location:    "America/New_York",
+			Allow:       []workflow.Window{{Days: []time.Weekday{time.Saturday}, Start: 22 * time.Hour, End: 2 * time.Hour}},
+			Blackouts:   []string{"2025-12-25"},
+			StopOnClose: true,
+		},
+	}
+	b.State.Set(workflow.State{Status: workflow.NotStarted})
+	b.SetPlanID(workflow.NewV7())
+
+	entry, err := blockToEntry(b, 0)
+	if err != nil {
+		t.Fatalf("TestBlockEntryWindows: blockToEntry: %s", err)
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("TestBlockEntryWindows: json.Marshal: %s", err)
+	}
+	var got blocksEntry
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("TestBlockEntryWindows: json.Unmarshal: %s", err)
+	}
+	block, err := entryToBlock(got)
+	if err != nil {
+		t.Fatalf("TestBlockEntryWindows: entryToBlock: %s", err)
+	}
+
+	if diff := pretty.Compare(b.Windows, block.Windows); diff != "" {
+		t.Errorf("TestBlockEntryWindows: Windows -want/+got:\n%s", diff)
+	}
+}
+
+func TestSequenceEntryLocks(t *testing.T) {
+	t.Parallel()
+
+	s := &workflow.Sequence{
+		ID:    workflow.NewV7(),
+		Name:  "seq",
+		Locks: &workflow.Locks{Shared: []string{"region/eastus"}, Exclusive: []string{"cluster/eastus-1"}},
+	}
+	s.Locks.Held.Set(true)
+	s.State.Set(workflow.State{Status: workflow.Running})
+	s.SetPlanID(workflow.NewV7())
+
+	entry, err := sequenceToEntry(s, 0)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryLocks: sequenceToEntry: %s", err)
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryLocks: json.Marshal: %s", err)
+	}
+	var got sequencesEntry
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("TestSequenceEntryLocks: json.Unmarshal: %s", err)
+	}
+	seq, err := entryToSequence(got)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryLocks: entryToSequence: %s", err)
+	}
+
+	if seq.Locks == nil {
+		t.Fatalf("TestSequenceEntryLocks: Locks got nil, want %+v", s.Locks)
+	}
+	if diff := pretty.Compare(s.Locks.Names(), seq.Locks.Names()); diff != "" {
+		t.Errorf("TestSequenceEntryLocks: Names -want/+got:\n%s", diff)
+	}
+	if !seq.Locks.Held.Get() {
+		t.Errorf("TestSequenceEntryLocks: Held got false, want true")
+	}
+}
+
 func TestSequenceToEntry(t *testing.T) {
 	t.Parallel()
 
@@ -362,6 +558,65 @@ func TestSequenceToEntry(t *testing.T) {
 	}
 }
 
+func TestSequenceEntryRetries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	policy := workflow.DefaultRetryPolicy()
+	s := &workflow.Sequence{
+		ID:          workflow.NewV7(),
+		Name:        "Test Sequence",
+		Descr:       "Test Description",
+		Retries:     2,
+		RetryPolicy: &policy,
+	}
+	s.Attempts.Set([]workflow.SeqAttempt{
+		{
+			Actions: []workflow.ActionResult{
+				{
+					ID:       workflow.NewV7(),
+					Status:   workflow.Failed,
+					Attempts: 1,
+					Err:      &plugins.Error{Message: "failed"},
+					Start:    now,
+					End:      now,
+				},
+			},
+			Start: now,
+			End:   now,
+		},
+	})
+	s.State.Set(workflow.State{Status: workflow.Running, Start: now})
+	s.SetPlanID(workflow.NewV7())
+
+	entry, err := sequenceToEntry(s, 0)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryRetries: sequenceToEntry: %s", err)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryRetries: json.Marshal: %s", err)
+	}
+	var got sequencesEntry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("TestSequenceEntryRetries: json.Unmarshal: %s", err)
+	}
+	seq, err := entryToSequence(got)
+	if err != nil {
+		t.Fatalf("TestSequenceEntryRetries: entryToSequence: %s", err)
+	}
+
+	if seq.Retries != s.Retries {
+		t.Errorf("TestSequenceEntryRetries: Retries got %d, want %d", seq.Retries, s.Retries)
+	}
+	if diff := pretty.Compare(s.RetryPolicy, seq.RetryPolicy); diff != "" {
+		t.Errorf("TestSequenceEntryRetries: RetryPolicy -want/+got:\n%s", diff)
+	}
+	if diff := pretty.Compare(s.Attempts.Get(), seq.Attempts.Get()); diff != "" {
+		t.Errorf("TestSequenceEntryRetries: Attempts -want/+got:\n%s", diff)
+	}
+}
+
 func TestActionToEntry(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,83 @@
+package azblob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gostdlib/base/context"
+
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/storage/azblob/internal/planlocks"
+	testPlugins "github.com/element-of-surprise/coercion/workflow/storage/sqlite/testing/plugins"
+)
+
+// TestUndoRoundTrip verifies that the Undo of an Action is uploaded in its own blob with the Action,
+// read back by fetchAction and deleted with the Sequence.
+func TestUndoRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClient, del := setupDeleterTest(t)
+	prefix := "test"
+
+	newAction := func(name string) *workflow.Action {
+		a := &workflow.Action{
+			ID:      workflow.NewV7(),
+			Name:    name,
+			Descr:   name,
+			Plugin:  testPlugins.HelloPluginName,
+			Timeout: 30 * time.Second,
+			Req:     testPlugins.HelloReq{Say: name},
+		}
+		a.State.Set(workflow.State{Status: workflow.NotStarted})
+		return a
+	}
+
+	planID := workflow.NewV7()
+	action := newAction("action")
+	action.Undo = newAction("undo")
+	seq := &workflow.Sequence{ID: workflow.NewV7(), Name: "sequence", Descr: "sequence", Actions: []*workflow.Action{action}}
+	seq.State.Set(workflow.State{Status: workflow.NotStarted})
+
+	containerName := containerForPlan(prefix, planID)
+	if err := fakeClient.EnsureContainer(ctx, containerName); err != nil {
+		t.Fatalf("TestUndoRoundTrip: EnsureContainer: %s", err)
+	}
+	u := &uploader{
+		mu:          planlocks.New(ctx),
+		client:      fakeClient,
+		prefix:      prefix,
+		planObjPool: context.Pool(ctx).Limited(ctx, "", 5),
+		blockPool:   context.Pool(ctx).Limited(ctx, "", 5),
+		leafObjPool: context.Pool(ctx).Limited(ctx, "", 20),
+	}
+	if err := u.uploadActionBlob(ctx, containerName, planID, action, 0); err != nil {
+		t.Fatalf("TestUndoRoundTrip: uploadActionBlob: %s", err)
+	}
+	if !fakeClient.BlobExists(containerName, actionBlobName(planID, action.Undo.ID)) {
+		t.Fatalf("TestUndoRoundTrip: Undo blob was not uploaded")
+	}
+
+	got, err := del.reader.fetchAction(ctx, containerName, planID, action.ID)
+	if err != nil {
+		t.Fatalf("TestUndoRoundTrip: fetchAction: %s", err)
+	}
+	if got.Undo == nil {
+		t.Fatalf("TestUndoRoundTrip: Undo == nil, want non-nil")
+	}
+	if got.Undo.ID != action.Undo.ID || got.Undo.Name != action.Undo.Name {
+		t.Errorf("TestUndoRoundTrip: Undo = %s(%s), want %s(%s)", got.Undo.Name, got.Undo.ID, action.Undo.Name, action.Undo.ID)
+	}
+	if got.Undo.Undo != nil {
+		t.Errorf("TestUndoRoundTrip: Undo.Undo != nil, want nil")
+	}
+
+	if err := del.deleteSequenceBlobs(ctx, containerName, planID, seq); err != nil {
+		t.Fatalf("TestUndoRoundTrip: deleteSequenceBlobs: %s", err)
+	}
+	for _, a := range []*workflow.Action{action, action.Undo} {
+		if fakeClient.BlobExists(containerName, actionBlobName(planID, a.ID)) {
+			t.Errorf("TestUndoRoundTrip: action(%s) blob should be deleted", a.Name)
+		}
+	}
+}
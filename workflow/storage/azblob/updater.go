@@ -99,10 +99,11 @@ func (u planUpdater) UpdatePlan(ctx context.Context, plan *workflow.Plan) error
 	u.mu.Lock(plan.ID)
 	defer u.mu.Unlock(plan.ID)
 
-	switch plan.State.Get().Status {
-	case workflow.NotStarted:
+	status := plan.State.Get().Status
+	switch {
+	case status == workflow.NotStarted:
 		return u.uploader.uploadPlan(ctx, plan, uptCreate)
-	case workflow.Running:
+	case status.Active():
 		return u.uploader.uploadPlan(ctx, plan, uptUpdate)
 	}
 	return u.uploader.uploadPlan(ctx, plan, uptComplete)
@@ -205,7 +205,7 @@ func (u *uploader) uploadBlockBlob(ctx context.Context, containerName string, pl
 		},
 	)
 
-	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+	for _, checks := range []*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 		if ctx.Err() != nil {
 			break
 		}
@@ -219,6 +219,10 @@ func (u *uploader) uploadBlockBlob(ctx context.Context, containerName string, pl
 		}
 	}
 
+	if err := u.goUploadDeferredActions(ctx, &g, containerName, planID, block.DeferredActions); err != nil {
+		return err
+	}
+
 	for i, seq := range block.Sequences {
 		g.Go(
 			ctx,
@@ -270,6 +274,10 @@ func (u *uploader) uploadSequenceBlob(ctx context.Context, containerName string,
 		)
 	}
 
+	if err := u.goUploadDeferredActions(ctx, &g, containerName, planID, seq.DeferredActions); err != nil {
+		return err
+	}
+
 	return g.Wait(ctx)
 }
 
@@ -334,5 +342,13 @@ func (u *uploader) uploadActionBlob(ctx context.Context, containerName string, p
 		return fmt.Errorf("failed to upload action blob: %w", err)
 	}
 
+	// The Undo is stored in its own blob. It is uploaded here instead of in a Group so that
+	// this never waits on a pool it may be running in.
+	if action.Undo != nil {
+		if err := u.uploadActionBlob(ctx, containerName, planID, action.Undo, pos); err != nil {
+			return fmt.Errorf("failed to upload undo action blob: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-json-experiment/json"
 	"github.com/google/uuid"
+	bSync "github.com/gostdlib/base/concurrency/sync"
 	"github.com/gostdlib/base/context"
 
 	"github.com/element-of-surprise/coercion/workflow"
@@ -89,3 +90,66 @@ func (u *uploader) uploadDeferBatchBlob(ctx context.Context, containerName strin
 
 	return g.Wait(ctx)
 }
+
+// goUploadDeferredActions adds the uploads for the DeferredActions of a Block or Sequence to g. This
+// is the DeferredActions blob, its DeferBatch blobs and their Action blobs. None of these uploads wait
+// on other uploads, so g can be a Group of the pool the caller is running in without deadlocking.
+// A nil da does nothing.
+func (u *uploader) goUploadDeferredActions(ctx context.Context, g *bSync.Group, containerName string, planID uuid.UUID, da *workflow.DeferredActions) error {
+	if da == nil {
+		return nil
+	}
+
+	entry, err := deferredActionsToEntry(da)
+	if err != nil {
+		return fmt.Errorf("failed to convert DeferredActions to entry: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DeferredActions: %w", err)
+	}
+	blobName := deferredActionsBlobName(planID, da.ID)
+	g.Go(
+		ctx,
+		func(ctx context.Context) error {
+			if err := u.client.UploadBlob(ctx, containerName, blobName, nil, data); err != nil {
+				return fmt.Errorf("failed to upload DeferredActions blob: %w", err)
+			}
+			return nil
+		},
+	)
+
+	for _, batch := range da.DeferredBatches {
+		entry, err := deferBatchToEntry(batch)
+		if err != nil {
+			return fmt.Errorf("failed to convert DeferBatch to entry: %w", err)
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal DeferBatch: %w", err)
+		}
+		blobName := deferBatchBlobName(planID, batch.ID)
+		g.Go(
+			ctx,
+			func(ctx context.Context) error {
+				if err := u.client.UploadBlob(ctx, containerName, blobName, nil, data); err != nil {
+					return fmt.Errorf("failed to upload DeferBatch blob: %w", err)
+				}
+				return nil
+			},
+		)
+
+		for i, action := range batch.Actions {
+			if ctx.Err() != nil {
+				break
+			}
+			g.Go(
+				ctx,
+				func(ctx context.Context) error {
+					return u.uploadActionBlob(ctx, containerName, planID, action, i)
+				},
+			)
+		}
+	}
+	return nil
+}
@@ -179,6 +179,14 @@ func planToEntry(swarm string, p *workflow.Plan) (plansEntry, error) {
 	if err != nil {
 		return plansEntry{}, fmt.Errorf("planToEntry(objsToIDs(blocks)): %w", err)
 	}
+	schedule, err := encodeSchedule(p.Schedule)
+	if err != nil {
+		return plansEntry{}, fmt.Errorf("can't encode plan.Schedule: %w", err)
+	}
+	dependsOn, err := encodePlanDeps(p.DependsOn)
+	if err != nil {
+		return plansEntry{}, fmt.Errorf("can't encode plan.DependsOn: %w", err)
+	}
 
 	plan := plansEntry{
 		PartitionKey: keyStr(p.ID),
@@ -195,6 +203,12 @@ func planToEntry(swarm string, p *workflow.Plan) (plansEntry, error) {
 		StateStart:   p.State.Get().Start,
 		StateEnd:     p.State.Get().End,
 		Reason:       p.Reason,
+		Concurrency:  p.Concurrency,
+		Timeout:      p.Timeout,
+		Priority:     p.Priority,
+		Schedule:     schedule,
+		DependsOn:    dependsOn,
+		Windows:      p.Windows,
 	}
 
 	if p.BypassChecks != nil {
@@ -256,18 +270,26 @@ func checkToEntry(iCtx *itemsContext, c *workflow.Checks) (checksEntry, error) {
 	if err != nil {
 		return checksEntry{}, fmt.Errorf("objsToIDs(checks.Actions): %w", err)
 	}
+	attempts, err := encodeSeqAttempts(c.Attempts.Get())
+	if err != nil {
+		return checksEntry{}, fmt.Errorf("can't encode checks.Attempts: %w", err)
+	}
 	return checksEntry{
-		PartitionKey: keyStr(iCtx.planID),
-		Swarm:        iCtx.swarm,
-		Type:         workflow.OTCheck,
-		ID:           c.ID,
-		Key:          c.Key,
-		PlanID:       iCtx.planID,
-		Actions:      actions,
-		Delay:        c.Delay,
-		StateStatus:  c.State.Get().Status,
-		StateStart:   c.State.Get().Start,
-		StateEnd:     c.State.Get().End,
+		PartitionKey:     keyStr(iCtx.planID),
+		Swarm:            iCtx.swarm,
+		Type:             workflow.OTCheck,
+		ID:               c.ID,
+		Key:              c.Key,
+		PlanID:           iCtx.planID,
+		Actions:          actions,
+		Delay:            c.Delay,
+		WaitUntil:        c.WaitUntil,
+		SoakDuration:     c.SoakDuration,
+		FailureThreshold: c.FailureThreshold,
+		Attempts:         attempts,
+		StateStatus:      c.State.Get().Status,
+		StateStart:       c.State.Get().Start,
+		StateEnd:         c.State.Get().End,
 	}, nil
 }
 
@@ -281,11 +303,14 @@ func blockToItem(iCtx *itemsContext, pos int, b *workflow.Block) error {
 		return err
 	}
 
-	for _, check := range [5]*workflow.Checks{b.BypassChecks, b.PreChecks, b.PostChecks, b.ContChecks, b.DeferredChecks} {
+	for _, check := range [6]*workflow.Checks{b.BypassChecks, b.PreChecks, b.PostChecks, b.ContChecks, b.WaveChecks, b.DeferredChecks} {
 		if err = checksToItems(iCtx, check); err != nil {
 			return fmt.Errorf("commitBlock(commitChecks): %w", err)
 		}
 	}
+	if err := deferredActionsToItems(iCtx, b.DeferredActions); err != nil {
+		return fmt.Errorf("commitBlock(deferredActionsToItems): %w", err)
+	}
 
 	if b.Sequences == nil {
 		return fmt.Errorf("commitBlock: block.Sequences cannot be nil")
@@ -314,25 +339,41 @@ func blockToEntry(iCtx *itemsContext, pos int, b *workflow.Block) (blocksEntry,
 	if err != nil {
 		return blocksEntry{}, fmt.Errorf("objsToIDs(sequences): %w", err)
 	}
+	approval, err := encodeApproval(b.Approval)
+	if err != nil {
+		return blocksEntry{}, fmt.Errorf("can't encode block.Approval: %w", err)
+	}
+	locks, err := encodeLocks(b.Locks)
+	if err != nil {
+		return blocksEntry{}, fmt.Errorf("can't encode block.Locks: %w", err)
+	}
 
 	block := blocksEntry{
-		PartitionKey:      keyStr(iCtx.planID),
-		Swarm:             iCtx.swarm,
-		Type:              workflow.OTBlock,
-		ID:                b.ID,
-		Key:               b.Key,
-		PlanID:            iCtx.planID,
-		Name:              b.Name,
-		Descr:             b.Descr,
-		Pos:               pos,
-		EntranceDelay:     b.EntranceDelay,
-		ExitDelay:         b.ExitDelay,
-		Sequences:         sequences,
-		Concurrency:       b.Concurrency,
-		ToleratedFailures: b.ToleratedFailures,
-		StateStatus:       b.State.Get().Status,
-		StateStart:        b.State.Get().Start,
-		StateEnd:          b.State.Get().End,
+		PartitionKey:            keyStr(iCtx.planID),
+		Swarm:                   iCtx.swarm,
+		Type:                    workflow.OTBlock,
+		ID:                      b.ID,
+		Key:                     b.Key,
+		PlanID:                  iCtx.planID,
+		Name:                    b.Name,
+		Descr:                   b.Descr,
+		Pos:                     pos,
+		EntranceDelay:           b.EntranceDelay,
+		ExitDelay:               b.ExitDelay,
+		When:                    b.When,
+		Sequences:               sequences,
+		DependsOn:               b.DependsOn,
+		Concurrency:             b.Concurrency,
+		Ramp:                    b.Ramp,
+		ToleratedFailures:       b.ToleratedFailures,
+		ToleratedFailurePercent: b.ToleratedFailurePercent,
+		Timeout:                 b.Timeout,
+		Windows:                 b.Windows,
+		Locks:                   locks,
+		Approval:                approval,
+		StateStatus:             b.State.Get().Status,
+		StateStart:              b.State.Get().Start,
+		StateEnd:                b.State.Get().End,
 	}
 
 	if b.BypassChecks != nil {
@@ -347,9 +388,15 @@ func blockToEntry(iCtx *itemsContext, pos int, b *workflow.Block) (blocksEntry,
 	if b.ContChecks != nil {
 		block.ContChecks = b.ContChecks.ID
 	}
+	if b.WaveChecks != nil {
+		block.WaveChecks = b.WaveChecks.ID
+	}
 	if b.DeferredChecks != nil {
 		block.DeferredChecks = b.DeferredChecks.ID
 	}
+	if b.DeferredActions != nil {
+		block.DeferredActions = b.DeferredActions.ID
+	}
 	return block, nil
 }
 
@@ -371,6 +418,9 @@ func seqToItems(iCtx *itemsContext, pos int, seq *workflow.Sequence) error {
 			return fmt.Errorf("planToEntry(commitAction): %w", err)
 		}
 	}
+	if err := deferredActionsToItems(iCtx, seq.DeferredActions); err != nil {
+		return fmt.Errorf("commitSequence(deferredActionsToItems): %w", err)
+	}
 	item, err := json.Marshal(sequence)
 	if err != nil {
 		return fmt.Errorf("failed to marshal item: %w", err)
@@ -389,8 +439,20 @@ func sequenceToEntry(iCtx *itemsContext, pos int, seq *workflow.Sequence) (seque
 	if err != nil {
 		return sequencesEntry{}, fmt.Errorf("objsToIDs(actions): %w", err)
 	}
+	attempts, err := encodeSeqAttempts(seq.Attempts.Get())
+	if err != nil {
+		return sequencesEntry{}, fmt.Errorf("can't encode sequence.Attempts: %w", err)
+	}
+	approval, err := encodeApproval(seq.Approval)
+	if err != nil {
+		return sequencesEntry{}, fmt.Errorf("can't encode sequence.Approval: %w", err)
+	}
+	locks, err := encodeLocks(seq.Locks)
+	if err != nil {
+		return sequencesEntry{}, fmt.Errorf("can't encode sequence.Locks: %w", err)
+	}
 
-	return sequencesEntry{
+	sequence := sequencesEntry{
 		PartitionKey: keyStr(iCtx.planID),
 		Swarm:        iCtx.swarm,
 		Type:         workflow.OTSequence,
@@ -400,11 +462,21 @@ func sequenceToEntry(iCtx *itemsContext, pos int, seq *workflow.Sequence) (seque
 		Name:         seq.Name,
 		Descr:        seq.Descr,
 		Pos:          pos,
+		When:         seq.When,
 		Actions:      actions,
+		Retries:      seq.Retries,
+		RetryPolicy:  seq.RetryPolicy,
+		Approval:     approval,
+		Locks:        locks,
+		Attempts:     attempts,
 		StateStatus:  seq.State.Get().Status,
 		StateStart:   seq.State.Get().Start,
 		StateEnd:     seq.State.Get().End,
-	}, nil
+	}
+	if seq.DeferredActions != nil {
+		sequence.DeferredActions = seq.DeferredActions.ID
+	}
+	return sequence, nil
 }
 
 func actionToItems(iCtx *itemsContext, pos int, a *workflow.Action) error {
@@ -424,6 +496,12 @@ func actionToItems(iCtx *itemsContext, pos int, a *workflow.Action) error {
 	iCtx.items = append(iCtx.items, item)
 	iCtx.m[a.ID.String()] = item
 
+	// The Undo is stored as its own item, like any other Action.
+	if a.Undo != nil {
+		if err := actionToItems(iCtx, pos, a.Undo); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -453,6 +531,8 @@ func actionToEntry(iCtx *itemsContext, pos int, a *workflow.Action) (actionsEntr
 		Plugin:       a.Plugin,
 		Timeout:      a.Timeout,
 		Retries:      a.Retries,
+		When:         a.When,
+		Undo:         a.Undo.GetID(),
 		Req:          req,
 		Attempts:     attempts,
 		StateStatus:  a.State.Get().Status,
@@ -501,6 +581,106 @@ func decodeAttempts(rawAttempts []byte, plug plugins.Plugin) ([]workflow.Attempt
 	return attempts, nil
 }
 
+// encodeSeqAttempts encodes the Attempts of a Sequence or Checks into a JSON array.
+func encodeSeqAttempts(attempts []workflow.SeqAttempt) ([]byte, error) {
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(attempts)
+}
+
+// encodePlanDeps encodes the DependsOn of a Plan. If there are none, this returns nil.
+func encodePlanDeps(deps []*workflow.PlanDep) ([]byte, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(deps)
+}
+
+// decodePlanDeps decodes the DependsOn of a Plan that was encoded with encodePlanDeps.
+func decodePlanDeps(rawDeps []byte) ([]*workflow.PlanDep, error) {
+	if rawDeps == nil {
+		return nil, nil
+	}
+	var deps []*workflow.PlanDep
+	if err := json.Unmarshal(rawDeps, &deps); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawDeps): %w", err)
+	}
+	return deps, nil
+}
+
+// encodeSchedule encodes the Schedule of a Plan. If there is no Schedule, this returns nil.
+func encodeSchedule(s *workflow.Schedule) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// decodeSchedule decodes the Schedule of a Plan that was encoded with encodeSchedule.
+func decodeSchedule(rawSchedule []byte) (*workflow.Schedule, error) {
+	if rawSchedule == nil {
+		return nil, nil
+	}
+	s := &workflow.Schedule{}
+	if err := json.Unmarshal(rawSchedule, s); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawSchedule): %w", err)
+	}
+	return s, nil
+}
+
+// encodeLocks encodes the Locks of a Block or Sequence. If there are no Locks, this returns nil.
+func encodeLocks(l *workflow.Locks) ([]byte, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// decodeLocks decodes the Locks of a Block or Sequence that were encoded with encodeLocks.
+func decodeLocks(rawLocks []byte) (*workflow.Locks, error) {
+	if rawLocks == nil {
+		return nil, nil
+	}
+	l := &workflow.Locks{}
+	if err := json.Unmarshal(rawLocks, l); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawLocks): %w", err)
+	}
+	return l, nil
+}
+
+// encodeApproval encodes the Approval of a Block or Sequence. If there is no Approval, this returns nil.
+func encodeApproval(a *workflow.Approval) ([]byte, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// decodeApproval decodes the Approval of a Block or Sequence that was encoded with encodeApproval.
+func decodeApproval(rawApproval []byte) (*workflow.Approval, error) {
+	if rawApproval == nil {
+		return nil, nil
+	}
+	a := &workflow.Approval{}
+	if err := json.Unmarshal(rawApproval, a); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawApproval): %w", err)
+	}
+	return a, nil
+}
+
+// decodeSeqAttempts decodes the Attempts of a Sequence or Checks that were encoded with encodeSeqAttempts.
+func decodeSeqAttempts(rawAttempts []byte) ([]workflow.SeqAttempt, error) {
+	if rawAttempts == nil {
+		return nil, nil
+	}
+	var attempts []workflow.SeqAttempt
+	if err := json.Unmarshal(rawAttempts, &attempts); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawAttempts): %w", err)
+	}
+	return attempts, nil
+}
+
 type ider interface {
 	GetID() uuid.UUID
 }
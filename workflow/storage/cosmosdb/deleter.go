@@ -142,9 +142,15 @@ func (d deleter) deleteBlocks(ctx context.Context, batch *azcosmos.Transactional
 		if err := d.deleteChecks(ctx, batch, block.ContChecks); err != nil {
 			return fmt.Errorf("couldn't delete block contchecks: %w", err)
 		}
+		if err := d.deleteChecks(ctx, batch, block.WaveChecks); err != nil {
+			return fmt.Errorf("couldn't delete block wavechecks: %w", err)
+		}
 		if err := d.deleteChecks(ctx, batch, block.DeferredChecks); err != nil {
 			return fmt.Errorf("couldn't delete block deferredchecks: %w", err)
 		}
+		if err := d.deleteDeferredActions(ctx, batch, block.DeferredActions); err != nil {
+			return fmt.Errorf("couldn't delete block deferredactions: %w", err)
+		}
 		if err := d.deleteSeqs(ctx, batch, block.Sequences); err != nil {
 			return fmt.Errorf("couldn't delete block sequences: %w", err)
 		}
@@ -197,6 +203,9 @@ func (d deleter) deleteSeqs(ctx context.Context, batch *azcosmos.TransactionalBa
 		if err := d.deleteActions(ctx, batch, seq.Actions); err != nil {
 			return fmt.Errorf("couldn't delete sequence actions: %w", err)
 		}
+		if err := d.deleteDeferredActions(ctx, batch, seq.DeferredActions); err != nil {
+			return fmt.Errorf("couldn't delete sequence deferredactions: %w", err)
+		}
 	}
 
 	for _, seq := range seqs {
@@ -272,6 +281,11 @@ func (d deleter) deleteActions(ctx context.Context, batch *azcosmos.Transactiona
 		}
 
 		batch.DeleteItem(action.ID.String(), itemOpt)
+		if action.Undo != nil {
+			if err := d.deleteActions(ctx, batch, []*workflow.Action{action.Undo}); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -696,7 +696,61 @@ func (f *fakeStorage) patchObject(op pathOps, o stateObject) {
 	switch op.Op {
 	case "set":
 		switch op.Path {
+		case "/approval":
+			approval, err := decodeApproval(op.Value.([]byte))
+			if err != nil {
+				panic(err)
+			}
+			switch v := o.(type) {
+			case *workflow.Block:
+				v.Approval = approval
+			case *workflow.Sequence:
+				v.Approval = approval
+			default:
+				panic(fmt.Sprintf("unsupported object(%T) for set op on /approval", o))
+			}
+		case "/locks":
+			locks, err := decodeLocks(op.Value.([]byte))
+			if err != nil {
+				panic(err)
+			}
+			switch v := o.(type) {
+			case *workflow.Block:
+				v.Locks = locks
+			case *workflow.Sequence:
+				v.Locks = locks
+			default:
+				panic(fmt.Sprintf("unsupported object(%T) for set op on /locks", o))
+			}
+		case "/schedule":
+			schedule, err := decodeSchedule(op.Value.([]byte))
+			if err != nil {
+				panic(err)
+			}
+			o.(*workflow.Plan).Schedule = schedule
+		case "/dependsOn":
+			deps, err := decodePlanDeps(op.Value.([]byte))
+			if err != nil {
+				panic(err)
+			}
+			o.(*workflow.Plan).DependsOn = deps
 		case "/attempts":
+			if seq, ok := o.(*workflow.Sequence); ok {
+				attempts, err := decodeSeqAttempts(op.Value.([]byte))
+				if err != nil {
+					panic(err)
+				}
+				seq.Attempts.Set(attempts)
+				return
+			}
+			if checks, ok := o.(*workflow.Checks); ok {
+				attempts, err := decodeSeqAttempts(op.Value.([]byte))
+				if err != nil {
+					panic(err)
+				}
+				checks.Attempts.Set(attempts)
+				return
+			}
 			action := o.(*workflow.Action)
 			plug := f.reg.Plugin(action.Plugin)
 			attempts, err := decodeAttempts(op.Value.([]byte), plug)
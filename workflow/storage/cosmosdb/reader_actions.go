@@ -22,6 +22,8 @@ SELECT
 	c.plugin,
 	c.timeout,
 	c.retries,
+	c.when,
+	c.undo,
 	c.req,
 	c.attempts,
 	c.stateStatus,
@@ -48,6 +50,8 @@ func (r reader) fetchActionsByIDs(ctx context.Context, planID azcosmos.Partition
 	}
 
 	actions := make([]*workflow.Action, 0, len(ids))
+	// undos holds the ID of the Undo of an action. These are fetched after the query finishes.
+	undos := map[*workflow.Action]uuid.UUID{}
 	parameters := []azcosmos.QueryParameter{
 		{
 			Name:  "@ids",
@@ -66,23 +70,38 @@ func (r reader) fetchActionsByIDs(ctx context.Context, planID azcosmos.Partition
 			return nil, fmt.Errorf("problem listing actions: %w", err)
 		}
 		for _, item := range res.Items {
-			action, err := r.docToAction(ctx, item)
+			action, undoID, err := r.docToAction(ctx, item)
 			if err != nil {
 				return nil, fmt.Errorf("problem listing items in actions: %w", err)
 			}
+			if undoID != uuid.Nil {
+				undos[action] = undoID
+			}
 			actions = append(actions, action)
 		}
 	}
 
+	for a, id := range undos {
+		undo, err := r.fetchActionsByIDs(ctx, planID, []uuid.UUID{id})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't fetch undo action: %w", err)
+		}
+		if len(undo) != 1 {
+			return nil, fmt.Errorf("couldn't find undo action(%s) for action(%s)", id, a.ID)
+		}
+		a.Undo = undo[0]
+	}
+
 	return actions, nil
 }
 
-// docToAction converts a cosmosdb document to a *workflow.Action.
-func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Action, error) {
+// docToAction converts a cosmosdb document to a *workflow.Action. The Undo of the Action is stored in
+// its own document, so this returns the ID of the Undo, which is uuid.Nil if there is none.
+func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Action, uuid.UUID, error) {
 	var err error
 	var resp actionsEntry
 	if err = json.Unmarshal(response, &resp); err != nil {
-		return nil, err
+		return nil, uuid.Nil, err
 	}
 
 	a := &workflow.Action{
@@ -93,6 +112,7 @@ func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Act
 		Plugin:  resp.Plugin,
 		Timeout: resp.Timeout,
 		Retries: resp.Retries,
+		When:    resp.When,
 	}
 	a.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -104,7 +124,7 @@ func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Act
 
 	plug := r.reg.Plugin(a.Plugin)
 	if plug == nil {
-		return nil, fmt.Errorf("couldn't find plugin %s", a.Plugin)
+		return nil, uuid.Nil, fmt.Errorf("couldn't find plugin %s", a.Plugin)
 	}
 
 	b := resp.Req
@@ -113,11 +133,11 @@ func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Act
 		if req != nil {
 			if reflect.TypeOf(req).Kind() != reflect.Pointer {
 				if err := json.Unmarshal(b, &req); err != nil {
-					return nil, fmt.Errorf("couldn't unmarshal request: %w", err)
+					return nil, uuid.Nil, fmt.Errorf("couldn't unmarshal request: %w", err)
 				}
 			} else {
 				if err := json.Unmarshal(b, req); err != nil {
-					return nil, fmt.Errorf("couldn't unmarshal request: %w", err)
+					return nil, uuid.Nil, fmt.Errorf("couldn't unmarshal request: %w", err)
 				}
 			}
 			a.Req = req
@@ -127,9 +147,9 @@ func (r reader) docToAction(ctx context.Context, response []byte) (*workflow.Act
 	if len(b) > 0 {
 		attempts, err := decodeAttempts(b, plug)
 		if err != nil {
-			return nil, fmt.Errorf("couldn't decode attempts: %w", err)
+			return nil, uuid.Nil, fmt.Errorf("couldn't decode attempts: %w", err)
 		}
 		a.Attempts.Set(attempts)
 	}
-	return a, nil
+	return a, resp.Undo, nil
 }
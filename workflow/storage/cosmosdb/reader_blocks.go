@@ -43,14 +43,28 @@ func (p reader) docToBlock(ctx context.Context, response *azcosmos.ItemResponse)
 	}
 
 	b := &workflow.Block{
-		ID:                resp.ID,
-		Key:               resp.Key,
-		Name:              resp.Name,
-		Descr:             resp.Descr,
-		EntranceDelay:     resp.EntranceDelay,
-		ExitDelay:         resp.ExitDelay,
-		Concurrency:       resp.Concurrency,
-		ToleratedFailures: resp.ToleratedFailures,
+		ID:                      resp.ID,
+		Key:                     resp.Key,
+		Name:                    resp.Name,
+		Descr:                   resp.Descr,
+		EntranceDelay:           resp.EntranceDelay,
+		ExitDelay:               resp.ExitDelay,
+		When:                    resp.When,
+		DependsOn:               resp.DependsOn,
+		Concurrency:             resp.Concurrency,
+		Ramp:                    resp.Ramp,
+		ToleratedFailures:       resp.ToleratedFailures,
+		ToleratedFailurePercent: resp.ToleratedFailurePercent,
+		Timeout:                 resp.Timeout,
+		Windows:                 resp.Windows,
+	}
+	b.Approval, err = decodeApproval(resp.Approval)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode block approval: %w", err)
+	}
+	b.Locks, err = decodeLocks(resp.Locks)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode block locks: %w", err)
 	}
 	b.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -77,10 +91,18 @@ func (p reader) docToBlock(ctx context.Context, response *azcosmos.ItemResponse)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get block postchecks: %w", err)
 	}
+	b.WaveChecks, err = p.idToCheck(ctx, k, resp.WaveChecks)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get block wavechecks: %w", err)
+	}
 	b.DeferredChecks, err = p.idToCheck(ctx, k, resp.DeferredChecks)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get block deferredchecks: %w", err)
 	}
+	b.DeferredActions, err = p.idToDeferredActions(ctx, k, resp.DeferredActions)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get block deferredactions: %w", err)
+	}
 	b.Sequences, err = p.idsToSequences(ctx, k, resp.Sequences)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read block sequences: %w", err)
@@ -45,9 +45,19 @@ func (p reader) docToChecks(ctx context.Context, planID azcosmos.PartitionKey, r
 	}
 
 	c := &workflow.Checks{
-		ID:    resp.ID,
-		Key:   resp.Key,
-		Delay: time.Duration(resp.Delay),
+		ID:               resp.ID,
+		Key:              resp.Key,
+		Delay:            time.Duration(resp.Delay),
+		WaitUntil:        time.Duration(resp.WaitUntil),
+		SoakDuration:     time.Duration(resp.SoakDuration),
+		FailureThreshold: resp.FailureThreshold,
+	}
+	attempts, err := decodeSeqAttempts(resp.Attempts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode checks attempts: %w", err)
+	}
+	if len(attempts) > 0 {
+		c.Attempts.Set(attempts)
 	}
 	c.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -29,12 +29,24 @@ func (p reader) docToPlan(ctx context.Context, response *azcosmos.ItemResponse)
 	}
 
 	plan := &workflow.Plan{
-		ID:         resp.PlanID,
-		GroupID:    resp.GroupID,
-		Name:       resp.Name,
-		Descr:      resp.Descr,
-		SubmitTime: resp.SubmitTime,
-		Reason:     resp.Reason,
+		ID:          resp.PlanID,
+		GroupID:     resp.GroupID,
+		Name:        resp.Name,
+		Descr:       resp.Descr,
+		SubmitTime:  resp.SubmitTime,
+		Reason:      resp.Reason,
+		Concurrency: resp.Concurrency,
+		Timeout:     resp.Timeout,
+		Priority:    resp.Priority,
+		Windows:     resp.Windows,
+	}
+	plan.Schedule, err = decodeSchedule(resp.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode plan schedule: %w", err)
+	}
+	plan.DependsOn, err = decodePlanDeps(resp.DependsOn)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode plan dependsOn: %w", err)
 	}
 	plan.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -42,10 +42,28 @@ func (p reader) docToSequence(ctx context.Context, planID azcosmos.PartitionKey,
 	}
 
 	s := &workflow.Sequence{
-		ID:    resp.ID,
-		Key:   resp.Key,
-		Name:  resp.Name,
-		Descr: resp.Descr,
+		ID:          resp.ID,
+		Key:         resp.Key,
+		Name:        resp.Name,
+		Descr:       resp.Descr,
+		When:        resp.When,
+		Retries:     resp.Retries,
+		RetryPolicy: resp.RetryPolicy,
+	}
+	s.Approval, err = decodeApproval(resp.Approval)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode sequence approval: %w", err)
+	}
+	s.Locks, err = decodeLocks(resp.Locks)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode sequence locks: %w", err)
+	}
+	attempts, err := decodeSeqAttempts(resp.Attempts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode sequence attempts: %w", err)
+	}
+	if len(attempts) > 0 {
+		s.Attempts.Set(attempts)
 	}
 	s.State.Set(workflow.State{
 		Status: resp.StateStatus,
@@ -58,6 +76,10 @@ func (p reader) docToSequence(ctx context.Context, planID azcosmos.PartitionKey,
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read sequence actions: %w", err)
 	}
+	s.DeferredActions, err = p.idToDeferredActions(ctx, planID, resp.DeferredActions)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read sequence deferredactions: %w", err)
+	}
 
 	return s, nil
 }
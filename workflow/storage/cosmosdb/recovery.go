@@ -15,7 +15,7 @@ type recovery struct {
 // an update could be missed because writing the object and the search entry is not atomic.
 // The service could die between these operations. This method is used to recover from that case.
 func (r recovery) Recovery(ctx context.Context) error {
-	stream, err := r.reader.Search(ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Running}})
+	stream, err := r.reader.Search(ctx, storage.Filters{ByStatus: []workflow.Status{workflow.Waiting, workflow.Queued, workflow.Running, workflow.Paused, workflow.Stopping}})
 	if err != nil {
 		return err
 	}
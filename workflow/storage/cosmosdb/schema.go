@@ -33,67 +33,94 @@ type plansEntry struct {
 	StateEnd        time.Time              `json:"stateEnd,omitempty"`
 	SubmitTime      time.Time              `json:"submitTime,omitempty"`
 	Reason          workflow.FailureReason `json:"reason,omitempty"`
+	Concurrency     int                    `json:"concurrency,omitempty"`
+	Timeout         time.Duration          `json:"timeout,omitempty,format:iso8601"`
+	Priority        int                    `json:"priority,omitempty"`
+	Schedule        []byte                 `json:"schedule,omitempty"`
+	DependsOn       []byte                 `json:"dependsOn,omitempty"`
+	Windows         *workflow.Windows      `json:"windows,omitempty"`
 
 	ETag azcore.ETag `json:"_etag,omitempty"`
 }
 
 type blocksEntry struct {
-	PartitionKey      string              `json:"partitionKey"`
-	Swarm             string              `json:"swarm"`
-	Type              workflow.ObjectType `json:"type,omitempty"`
-	ID                uuid.UUID           `json:"id,omitempty"`
-	Key               uuid.UUID           `json:"key,omitempty"`
-	PlanID            uuid.UUID           `json:"planID,omitempty"`
-	Name              string              `json:"name,omitempty"`
-	Descr             string              `json:"descr,omitempty"`
-	Pos               int                 `json:"pos,omitempty"`
-	EntranceDelay     time.Duration       `json:"entranceDelay,omitempty,format:iso8601"`
-	ExitDelay         time.Duration       `json:"exitDelay,omitempty,format:iso8601"`
-	BypassChecks      uuid.UUID           `json:"bypassChecks,omitempty"`
-	PreChecks         uuid.UUID           `json:"preChecks,omitempty"`
-	PostChecks        uuid.UUID           `json:"postChecks,omitempty"`
-	ContChecks        uuid.UUID           `json:"contChecks,omitempty"`
-	DeferredChecks    uuid.UUID           `json:"deferredChecks,omitempty"`
-	Sequences         []uuid.UUID         `json:"sequences,omitempty"`
-	Concurrency       int                 `json:"concurrency,omitempty"`
-	ToleratedFailures int                 `json:"toleratedFailures,omitempty"`
-	StateStatus       workflow.Status     `json:"stateStatus,omitempty"`
-	StateStart        time.Time           `json:"stateStart,omitempty"`
-	StateEnd          time.Time           `json:"stateEnd,omitempty"`
+	PartitionKey            string              `json:"partitionKey"`
+	Swarm                   string              `json:"swarm"`
+	Type                    workflow.ObjectType `json:"type,omitempty"`
+	ID                      uuid.UUID           `json:"id,omitempty"`
+	Key                     uuid.UUID           `json:"key,omitempty"`
+	PlanID                  uuid.UUID           `json:"planID,omitempty"`
+	Name                    string              `json:"name,omitempty"`
+	Descr                   string              `json:"descr,omitempty"`
+	Pos                     int                 `json:"pos,omitempty"`
+	EntranceDelay           time.Duration       `json:"entranceDelay,omitempty,format:iso8601"`
+	ExitDelay               time.Duration       `json:"exitDelay,omitempty,format:iso8601"`
+	When                    workflow.WhenCond   `json:"when,omitempty"`
+	BypassChecks            uuid.UUID           `json:"bypassChecks,omitempty"`
+	PreChecks               uuid.UUID           `json:"preChecks,omitempty"`
+	PostChecks              uuid.UUID           `json:"postChecks,omitempty"`
+	ContChecks              uuid.UUID           `json:"contChecks,omitempty"`
+	WaveChecks              uuid.UUID           `json:"waveChecks,omitempty"`
+	DeferredChecks          uuid.UUID           `json:"deferredChecks,omitempty"`
+	DeferredActions         uuid.UUID           `json:"deferredActions,omitempty"`
+	Sequences               []uuid.UUID         `json:"sequences,omitempty"`
+	DependsOn               []uuid.UUID         `json:"dependsOn,omitempty"`
+	Concurrency             int                 `json:"concurrency,omitempty"`
+	Ramp                    *workflow.Ramp      `json:"ramp,omitempty"`
+	ToleratedFailures       int                 `json:"toleratedFailures,omitempty"`
+	ToleratedFailurePercent int                 `json:"toleratedFailurePercent,omitempty"`
+	Timeout                 time.Duration       `json:"timeout,omitempty,format:iso8601"`
+	Approval                []byte              `json:"approval,omitempty"`
+	Windows                 *workflow.Windows   `json:"windows,omitempty"`
+	Locks                   []byte              `json:"locks,omitempty"`
+	StateStatus             workflow.Status     `json:"stateStatus,omitempty"`
+	StateStart              time.Time           `json:"stateStart,omitempty"`
+	StateEnd                time.Time           `json:"stateEnd,omitempty"`
 
 	ETag azcore.ETag `json:"_etag,omitempty"`
 }
 
 type checksEntry struct {
-	PartitionKey string              `json:"partitionKey"`
-	Swarm        string              `json:"swarm"`
-	Type         workflow.ObjectType `json:"type,omitempty"`
-	ID           uuid.UUID           `json:"id,omitempty"`
-	Key          uuid.UUID           `json:"key,omitempty"`
-	PlanID       uuid.UUID           `json:"planID,omitempty"`
-	Actions      []uuid.UUID         `json:"actions,omitempty"`
-	Delay        time.Duration       `json:"delay,omitempty,format:iso8601"`
-	StateStatus  workflow.Status     `json:"stateStatus,omitempty"`
-	StateStart   time.Time           `json:"stateStart,omitempty"`
-	StateEnd     time.Time           `json:"stateEnd,omitempty"`
+	PartitionKey     string                     `json:"partitionKey"`
+	Swarm            string                     `json:"swarm"`
+	Type             workflow.ObjectType        `json:"type,omitempty"`
+	ID               uuid.UUID                  `json:"id,omitempty"`
+	Key              uuid.UUID                  `json:"key,omitempty"`
+	PlanID           uuid.UUID                  `json:"planID,omitempty"`
+	Actions          []uuid.UUID                `json:"actions,omitempty"`
+	Delay            time.Duration              `json:"delay,omitempty,format:iso8601"`
+	WaitUntil        time.Duration              `json:"waitUntil,omitempty,format:iso8601"`
+	SoakDuration     time.Duration              `json:"soakDuration,omitempty,format:iso8601"`
+	FailureThreshold *workflow.FailureThreshold `json:"failureThreshold,omitempty"`
+	Attempts         []byte                     `json:"attempts,omitempty"`
+	StateStatus      workflow.Status            `json:"stateStatus,omitempty"`
+	StateStart       time.Time                  `json:"stateStart,omitempty"`
+	StateEnd         time.Time                  `json:"stateEnd,omitempty"`
 
 	ETag azcore.ETag `json:"_etag,omitempty"`
 }
 
 type sequencesEntry struct {
-	PartitionKey string              `json:"partitionKey"`
-	Swarm        string              `json:"swarm"`
-	Type         workflow.ObjectType `json:"type,omitempty"`
-	ID           uuid.UUID           `json:"id,omitempty"`
-	Key          uuid.UUID           `json:"key,omitempty"`
-	PlanID       uuid.UUID           `json:"planID,omitempty"`
-	Name         string              `json:"name,omitempty"`
-	Descr        string              `json:"descr,omitempty"`
-	Pos          int                 `json:"pos,omitempty"`
-	Actions      []uuid.UUID         `json:"actions,omitempty"`
-	StateStatus  workflow.Status     `json:"stateStatus,omitempty"`
-	StateStart   time.Time           `json:"stateStart,omitempty"`
-	StateEnd     time.Time           `json:"stateEnd,omitempty"`
+	PartitionKey    string                `json:"partitionKey"`
+	Swarm           string                `json:"swarm"`
+	Type            workflow.ObjectType   `json:"type,omitempty"`
+	ID              uuid.UUID             `json:"id,omitempty"`
+	Key             uuid.UUID             `json:"key,omitempty"`
+	PlanID          uuid.UUID             `json:"planID,omitempty"`
+	Name            string                `json:"name,omitempty"`
+	Descr           string                `json:"descr,omitempty"`
+	Pos             int                   `json:"pos,omitempty"`
+	When            workflow.WhenCond     `json:"when,omitempty"`
+	Actions         []uuid.UUID           `json:"actions,omitempty"`
+	DeferredActions uuid.UUID             `json:"deferredActions,omitempty"`
+	Retries         int                   `json:"retries,omitempty"`
+	RetryPolicy     *workflow.RetryPolicy `json:"retryPolicy,omitempty"`
+	Approval        []byte                `json:"approval,omitempty"`
+	Locks           []byte                `json:"locks,omitempty"`
+	Attempts        []byte                `json:"attempts,omitempty"`
+	StateStatus     workflow.Status       `json:"stateStatus,omitempty"`
+	StateStart      time.Time             `json:"stateStart,omitempty"`
+	StateEnd        time.Time             `json:"stateEnd,omitempty"`
 
 	ETag azcore.ETag `json:"_etag,omitempty"`
 }
@@ -146,6 +173,8 @@ type actionsEntry struct {
 	Plugin       string              `json:"plugin,omitempty"`
 	Timeout      time.Duration       `json:"timeout,omitempty,format:iso8601"`
 	Retries      int                 `json:"retries,omitempty"`
+	When         workflow.WhenCond   `json:"when,omitempty"`
+	Undo         uuid.UUID           `json:"undo,omitempty"`
 	Req          []byte              `json:"req,omitempty"`
 	Attempts     []byte              `json:"attempts,omitempty"`
 	StateStatus  workflow.Status     `json:"stateStatus,omitempty"`
@@ -49,7 +49,14 @@ func NewTestPlan() *workflow.Plan {
 	var plan *workflow.Plan
 	ctx := context.Background()
 
-	build, err := builder.New("test", "test", builder.WithGroupID(mustUUID()))
+	build, err := builder.New(
+		"test",
+		"test",
+		builder.WithGroupID(mustUUID()),
+		builder.WithTimeout(2*time.Hour),
+		builder.WithPriority(3),
+		builder.WithWindows(&workflow.Windows{Location: "America/New_York", Blackouts: []string{"2025-12-25"}}),
+	)
 	if err != nil {
 		panic(err)
 	}
@@ -64,6 +71,7 @@ func NewTestPlan() *workflow.Plan {
 		Descr:  "action",
 		Plugin: plugins.HelloPluginName,
 		Req:    plugins.HelloReq{Say: "hello"},
+		Undo:   &workflow.Action{Name: "undo", Descr: "undo", Plugin: plugins.HelloPluginName, Req: plugins.HelloReq{Say: "goodbye"}},
 		Attempts: func() workflow.AtomicSlice[workflow.Attempt] {
 			var a workflow.AtomicSlice[workflow.Attempt]
 			a.Set(
@@ -127,20 +135,32 @@ func NewTestPlan() *workflow.Plan {
 		ExitDelay:         1 * time.Second,
 		ToleratedFailures: 1,
 		Concurrency:       1,
+		Ramp:              &workflow.Ramp{Canary: 1, Steps: []int{50}, Soak: time.Minute},
+		Timeout:           time.Hour,
+		Approval:          &workflow.Approval{Key: "block", Expiry: time.Hour},
+		Windows: &workflow.Windows{
+			Allow:       []workflow.Window{{Days: []time.Weekday{time.Saturday, time.Sunday}, Start: 22 * time.Hour, End: 2 * time.Hour}},
+			StopOnClose: true,
+		},
+		Locks: &workflow.Locks{Exclusive: []string{"cluster/eastus-1"}},
 	})
 
-	build.AddChecks(builder.PreChecks, &workflow.Checks{})
+	build.AddChecks(builder.PreChecks, &workflow.Checks{Delay: time.Second, WaitUntil: time.Minute})
 	build.AddAction(checkAction1)
 	build.Up()
 
-	build.AddChecks(builder.ContChecks, &workflow.Checks{Delay: 1 * time.Minute})
+	build.AddChecks(builder.ContChecks, &workflow.Checks{Delay: 1 * time.Minute, FailureThreshold: &workflow.FailureThreshold{Consecutive: 3, Failures: 5, Window: 10 * time.Minute}})
 	build.AddAction(checkAction2)
 	build.Up()
 
-	build.AddChecks(builder.PostChecks, &workflow.Checks{})
+	build.AddChecks(builder.PostChecks, &workflow.Checks{Delay: time.Minute, SoakDuration: 15 * time.Minute})
 	build.AddAction(checkAction3)
 	build.Up()
 
+	build.AddChecks(builder.WaveChecks, &workflow.Checks{})
+	build.AddAction(clone.Action(ctx, checkAction3))
+	build.Up()
+
 	build.AddChecks(builder.DeferredChecks, &workflow.Checks{})
 	build.AddAction(checkAction4)
 	build.Up()
@@ -149,8 +169,35 @@ func NewTestPlan() *workflow.Plan {
 	build.AddAction(checkAction5)
 	build.Up()
 
-	build.AddSequence(&workflow.Sequence{Name: "sequence", Descr: "sequence"})
+	build.AddDeferredActions()
+	build.AddDeferBatch(&workflow.DeferBatch{
+		When:     workflow.Always,
+		Sequence: workflow.Sequence{Name: "block-batch", Descr: "block cleanup"},
+	})
+	build.AddAction(clone.Action(ctx, checkAction3))
+	build.Up()
+	build.Up()
+
+	retryPolicy := workflow.DefaultRetryPolicy()
+	build.AddSequence(
+		&workflow.Sequence{
+			Name:        "sequence",
+			Descr:       "sequence",
+			Retries:     2,
+			RetryPolicy: &retryPolicy,
+			Approval:    &workflow.Approval{Key: "sequence"},
+			Locks:       &workflow.Locks{Shared: []string{"region/eastus"}},
+		},
+	)
 	build.AddAction(seqAction1)
+	build.AddDeferredActions()
+	build.AddDeferBatch(&workflow.DeferBatch{
+		When:     workflow.OnFailure,
+		Sequence: workflow.Sequence{Name: "seq-batch", Descr: "sequence cleanup"},
+	})
+	build.AddAction(clone.Action(ctx, checkAction4))
+	build.Up()
+	build.Up()
 	build.Up()
 
 	plan, err = build.Plan()
@@ -172,11 +219,97 @@ func NewTestPlan() *workflow.Plan {
 				End:    time.Now().UTC(),
 			},
 		)
+		if seq, ok := item.Value.(*workflow.Sequence); ok && seq.Retries > 0 {
+			seq.Attempts.Set(
+				[]workflow.SeqAttempt{
+					{
+						Actions: []workflow.ActionResult{
+							{
+								ID:       seq.Actions[0].ID,
+								Status:   workflow.Failed,
+								Attempts: 1,
+								Err:      &pluglib.Error{Message: "failed"},
+								Start:    time.Now().UTC(),
+								End:      time.Now().UTC(),
+							},
+						},
+						Start: time.Now().UTC(),
+						End:   time.Now().UTC(),
+					},
+				},
+			)
+		}
+		if ap := approvalOf(item.Value); ap != nil {
+			ap.Decision.Set(
+				workflow.Decision{
+					Status:    workflow.APApproved,
+					Approver:  "operator",
+					Requested: time.Now(),
+					Decided:   time.Now(),
+				},
+			)
+		}
+		if l := locksOf(item.Value); l != nil {
+			l.Held.Set(true)
+		}
+		if checks, ok := item.Value.(*workflow.Checks); ok && (checks.WaitUntil > 0 || checks.FailureThreshold != nil) {
+			checks.Attempts.Set(
+				[]workflow.SeqAttempt{
+					{
+						Actions: []workflow.ActionResult{
+							{
+								ID:       checks.Actions[0].ID,
+								Status:   workflow.Failed,
+								Attempts: 1,
+								Err:      &pluglib.Error{Message: "not ready"},
+								Start:    time.Now().UTC(),
+								End:      time.Now().UTC(),
+							},
+						},
+						Start: time.Now().UTC(),
+						End:   time.Now().UTC(),
+					},
+				},
+			)
+		}
 	}
 
+	plan.Schedule = &workflow.Schedule{
+		Cron:    "0 * * * *",
+		CatchUp: workflow.CUAll,
+		Next:    time.Now().UTC().Truncate(time.Hour),
+		LastRun: mustUUID(),
+	}
+	dep := mustUUID()
+	plan.DependsOn = []*workflow.PlanDep{
+		{ID: dep, Plans: []uuid.UUID{dep}},
+		{GroupID: mustUUID(), On: workflow.OCFail, Plans: []uuid.UUID{mustUUID(), mustUUID()}},
+	}
 	return plan
 }
 
+// approvalOf returns the Approval of o if it is a Block or Sequence.
+func approvalOf(o workflow.Object) *workflow.Approval {
+	switch v := o.(type) {
+	case *workflow.Block:
+		return v.Approval
+	case *workflow.Sequence:
+		return v.Approval
+	}
+	return nil
+}
+
+// locksOf returns the Locks of o if it is a Block or Sequence.
+func locksOf(o workflow.Object) *workflow.Locks {
+	switch v := o.(type) {
+	case *workflow.Block:
+		return v.Locks
+	case *workflow.Sequence:
+		return v.Locks
+	}
+	return nil
+}
+
 func mustUUID() uuid.UUID {
 	id, err := uuid.NewV7()
 	if err != nil {
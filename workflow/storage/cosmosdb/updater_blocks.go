@@ -34,6 +34,20 @@ func (u blockUpdater) UpdateBlock(ctx context.Context, block *workflow.Block) er
 	patch.AppendReplace("/stateStatus", block.State.Get().Status)
 	patch.AppendReplace("/stateStart", block.State.Get().Start)
 	patch.AppendReplace("/stateEnd", block.State.Get().End)
+	if block.Approval != nil {
+		approval, err := encodeApproval(block.Approval)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/approval", approval)
+	}
+	if block.Locks != nil {
+		locks, err := encodeLocks(block.Locks)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/locks", locks)
+	}
 
 	itemOpt := itemOptions(u.defaultIOpts)
 	var ifMatchEtag *azcore.ETag = nil
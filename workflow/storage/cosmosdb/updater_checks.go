@@ -34,6 +34,11 @@ func (u checksUpdater) UpdateChecks(ctx context.Context, check *workflow.Checks)
 	patch.AppendReplace("/stateStatus", check.State.Get().Status)
 	patch.AppendReplace("/stateStart", check.State.Get().Start)
 	patch.AppendReplace("/stateEnd", check.State.Get().End)
+	attempts, err := encodeSeqAttempts(check.Attempts.Get())
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+	}
+	patch.AppendSet("/attempts", attempts)
 
 	itemOpt := itemOptions(u.defaultIOpts)
 	var ifMatchEtag *azcore.ETag = nil
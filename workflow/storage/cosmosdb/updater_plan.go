@@ -40,6 +40,20 @@ func (u planUpdater) UpdatePlan(ctx context.Context, p *workflow.Plan) error {
 	patch.AppendReplace("/stateStart", p.State.Get().Start)
 	patch.AppendReplace("/stateEnd", p.State.Get().End)
 	patch.AppendReplace("/submitTime", p.SubmitTime)
+	if p.Schedule != nil {
+		schedule, err := encodeSchedule(p.Schedule)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/schedule", schedule)
+	}
+	if len(p.DependsOn) > 0 {
+		dependsOn, err := encodePlanDeps(p.DependsOn)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/dependsOn", dependsOn)
+	}
 
 	itemOpt := itemOptions(u.defaultIOpts)
 	var ifMatchEtag *azcore.ETag = nil
@@ -34,6 +34,25 @@ func (u sequenceUpdater) UpdateSequence(ctx context.Context, seq *workflow.Seque
 	patch.AppendReplace("/stateStatus", seq.State.Get().Status)
 	patch.AppendReplace("/stateStart", seq.State.Get().Start)
 	patch.AppendReplace("/stateEnd", seq.State.Get().End)
+	attempts, err := encodeSeqAttempts(seq.Attempts.Get())
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+	}
+	patch.AppendSet("/attempts", attempts)
+	if seq.Approval != nil {
+		approval, err := encodeApproval(seq.Approval)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/approval", approval)
+	}
+	if seq.Locks != nil {
+		locks, err := encodeLocks(seq.Locks)
+		if err != nil {
+			return errors.E(ctx, errors.CatInternal, errors.TypeBug, err)
+		}
+		patch.AppendSet("/locks", locks)
+	}
 
 	itemOpt := itemOptions(u.defaultIOpts)
 	var ifMatchEtag *azcore.ETag = nil
@@ -34,9 +34,16 @@ const insertPlan = `
 		state_start,
 		state_end,
 		submit_time,
-		reason
+		reason,
+		concurrency,
+		timeout,
+		priority,
+		schedule,
+		windows,
+		depends_on
 	) VALUES ($id, $group_id, $name, $descr, $meta, $bypasschecks, $prechecks, $postchecks, $contchecks, $deferredchecks,
-	$deferredactions, $blocks, $state_status, $state_start, $state_end, $submit_time, $reason)`
+	$deferredactions, $blocks, $state_status, $state_start, $state_end, $submit_time, $reason, $concurrency, $timeout, $priority, $schedule,
+	$windows, $depends_on)`
 
 var zeroTime = time.Unix(0, 0)
 
@@ -87,6 +94,24 @@ func commitPlan(ctx context.Context, conn *sqlite.Conn, p *workflow.Plan, captur
 		stmt.SetInt64("$submit_time", p.SubmitTime.UnixNano())
 	}
 	stmt.SetInt64("$reason", int64(p.Reason))
+	stmt.SetInt64("$concurrency", int64(p.Concurrency))
+	stmt.SetInt64("$timeout", int64(p.Timeout))
+	stmt.SetInt64("$priority", int64(p.Priority))
+	schedule, err := encodeSchedule(p.Schedule)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("planToSQL: %w", err))
+	}
+	stmt.SetBytes("$schedule", schedule)
+	windows, err := encodeWindows(p.Windows)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("planToSQL: %w", err))
+	}
+	stmt.SetBytes("$windows", windows)
+	dependsOn, err := encodePlanDeps(p.DependsOn)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("planToSQL: %w", err))
+	}
+	stmt.SetBytes("$depends_on", dependsOn)
 
 	sStmt, err := stmt.Prepare(conn)
 	if err != nil {
@@ -133,11 +158,15 @@ const insertChecks = `
 		plan_id,
 		actions,
 		delay,
+		wait_until,
+		soak_duration,
+		failure_threshold,
+		attempts,
 		state_status,
 		state_start,
 		state_end
-	) VALUES ($id, $key, $plan_id, $actions, $delay,
-	$state_status, $state_start, $state_end)`
+	) VALUES ($id, $key, $plan_id, $actions, $delay, $wait_until, $soak_duration,
+	$failure_threshold, $attempts, $state_status, $state_start, $state_end)`
 
 func commitChecks(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, checks *workflow.Checks, capture *CaptureStmts) error {
 	if checks == nil {
@@ -150,11 +179,25 @@ func commitChecks(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, chec
 	if err != nil {
 		return err
 	}
+	attempts, err := encodeSeqAttempts(checks.Attempts.Get())
+	if err != nil {
+		return err
+	}
 	stmt.SetText("$id", checks.ID.String())
 	stmt.SetText("$key", checks.Key.String())
 	stmt.SetText("$plan_id", planID.String())
 	stmt.SetBytes("$actions", actions)
 	stmt.SetInt64("$delay", int64(checks.Delay))
+	stmt.SetInt64("$wait_until", int64(checks.WaitUntil))
+	stmt.SetInt64("$soak_duration", int64(checks.SoakDuration))
+	if checks.FailureThreshold != nil {
+		threshold, err := json.Marshal(checks.FailureThreshold)
+		if err != nil {
+			return fmt.Errorf("commitCheck: couldn't encode FailureThreshold: %w", err)
+		}
+		stmt.SetBytes("$failure_threshold", threshold)
+	}
+	stmt.SetBytes("$attempts", attempts)
 	stmt.SetInt64("$state_status", int64(checks.State.Get().Status))
 	stmt.SetInt64("$state_start", checks.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", checks.State.Get().End.UnixNano())
@@ -189,34 +232,60 @@ const insertBlock = `
 		pos,
 		entrancedelay,
 		exitdelay,
+		when_cond,
 		bypasschecks,
 		prechecks,
 		postchecks,
 		contchecks,
+		wavechecks,
 		deferredchecks,
+		deferredactions,
 		sequences,
+		depends_on,
 		concurrency,
+		ramp,
 		toleratedfailures,
+		toleratedfailurepercent,
+		timeout,
+		approval,
+		windows,
+		locks,
 		state_status,
 		state_start,
 		state_end
-	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $entrancedelay, $exitdelay, $bypasschecks, $prechecks, $postchecks, $contchecks, $deferredchecks,
-	$sequences, $concurrency, $toleratedfailures,$state_status, $state_start, $state_end)`
+	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $entrancedelay, $exitdelay, $when_cond, $bypasschecks, $prechecks, $postchecks, $contchecks, $wavechecks,
+	$deferredchecks, $deferredactions, $sequences, $depends_on, $concurrency, $ramp, $toleratedfailures, $toleratedfailurepercent, $timeout, $approval,
+	$windows, $locks, $state_status, $state_start, $state_end)`
 
 func commitBlock(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos int, block *workflow.Block, capture *CaptureStmts) error {
 	stmt := Stmt{}
 	stmt.Query(insertBlock)
 
-	for _, c := range [5]*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.DeferredChecks} {
+	for _, c := range [6]*workflow.Checks{block.BypassChecks, block.PreChecks, block.PostChecks, block.ContChecks, block.WaveChecks, block.DeferredChecks} {
 		if err := commitChecks(ctx, conn, planID, c, capture); err != nil {
 			return fmt.Errorf("commitBlock: %w", err)
 		}
 	}
+	if err := commitDeferredActions(ctx, conn, planID, block.DeferredActions, capture); err != nil {
+		return fmt.Errorf("commitBlock: %w", err)
+	}
 
 	sequences, err := idsToJSON(block.Sequences)
 	if err != nil {
 		return fmt.Errorf("commitBlock: %w", err)
 	}
+	approval, err := encodeApproval(block.Approval)
+	if err != nil {
+		return fmt.Errorf("commitBlock: %w", err)
+	}
+	windows, err := encodeWindows(block.Windows)
+	if err != nil {
+		return fmt.Errorf("commitBlock: %w", err)
+	}
+	locks, err := encodeLocks(block.Locks)
+	if err != nil {
+		return fmt.Errorf("commitBlock: %w", err)
+	}
 
 	stmt.SetText("$id", block.ID.String())
 	stmt.SetText("$key", block.Key.String())
@@ -226,6 +295,7 @@ func commitBlock(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos i
 	stmt.SetInt64("$pos", int64(pos))
 	stmt.SetInt64("$entrancedelay", int64(block.EntranceDelay))
 	stmt.SetInt64("$exitdelay", int64(block.ExitDelay))
+	stmt.SetText("$when_cond", string(block.When))
 	if block.BypassChecks != nil {
 		stmt.SetText("$bypasschecks", block.BypassChecks.ID.String())
 	}
@@ -238,12 +308,37 @@ func commitBlock(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos i
 	if block.ContChecks != nil {
 		stmt.SetText("$contchecks", block.ContChecks.ID.String())
 	}
+	if block.WaveChecks != nil {
+		stmt.SetText("$wavechecks", block.WaveChecks.ID.String())
+	}
 	if block.DeferredChecks != nil {
 		stmt.SetText("$deferredchecks", block.DeferredChecks.ID.String())
 	}
+	if block.DeferredActions != nil {
+		stmt.SetText("$deferredactions", block.DeferredActions.ID.String())
+	}
 	stmt.SetBytes("$sequences", sequences)
+	if len(block.DependsOn) > 0 {
+		dependsOn, err := json.Marshal(block.DependsOn)
+		if err != nil {
+			return fmt.Errorf("commitBlock(json.Marshal(dependsOn)): %w", err)
+		}
+		stmt.SetBytes("$depends_on", dependsOn)
+	}
 	stmt.SetInt64("$concurrency", int64(block.Concurrency))
+	if block.Ramp != nil {
+		ramp, err := json.Marshal(block.Ramp)
+		if err != nil {
+			return fmt.Errorf("commitBlock: couldn't encode Ramp: %w", err)
+		}
+		stmt.SetBytes("$ramp", ramp)
+	}
 	stmt.SetInt64("$toleratedfailures", int64(block.ToleratedFailures))
+	stmt.SetInt64("$toleratedfailurepercent", int64(block.ToleratedFailurePercent))
+	stmt.SetInt64("$timeout", int64(block.Timeout))
+	stmt.SetBytes("$approval", approval)
+	stmt.SetBytes("$windows", windows)
+	stmt.SetBytes("$locks", locks)
 	stmt.SetInt64("$state_status", int64(block.State.Get().Status))
 	stmt.SetInt64("$state_start", block.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", block.State.Get().End.UnixNano())
@@ -275,11 +370,19 @@ const insertSequence = `
 		name,
 		descr,
 		pos,
+		when_cond,
 		actions,
+		deferredactions,
+		retries,
+		retry_policy,
+		approval,
+		locks,
+		attempts,
 		state_status,
 		state_start,
 		state_end
-	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $actions, $state_status, $state_start, $state_end)`
+	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $when_cond, $actions, $deferredactions, $retries, $retry_policy,
+	$approval, $locks, $attempts, $state_status, $state_start, $state_end)`
 
 func commitSequence(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos int, seq *workflow.Sequence, capture *CaptureStmts) error {
 	stmt := Stmt{}
@@ -289,6 +392,21 @@ func commitSequence(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, po
 	if err != nil {
 		return fmt.Errorf("commitSequence: %w", err)
 	}
+	if err := commitDeferredActions(ctx, conn, planID, seq.DeferredActions, capture); err != nil {
+		return fmt.Errorf("commitSequence: %w", err)
+	}
+	attempts, err := encodeSeqAttempts(seq.Attempts.Get())
+	if err != nil {
+		return fmt.Errorf("commitSequence: %w", err)
+	}
+	approval, err := encodeApproval(seq.Approval)
+	if err != nil {
+		return fmt.Errorf("commitSequence: %w", err)
+	}
+	locks, err := encodeLocks(seq.Locks)
+	if err != nil {
+		return fmt.Errorf("commitSequence: %w", err)
+	}
 
 	stmt.SetText("$id", seq.ID.String())
 	stmt.SetText("$key", seq.Key.String())
@@ -296,7 +414,22 @@ func commitSequence(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, po
 	stmt.SetText("$name", seq.Name)
 	stmt.SetText("$descr", seq.Descr)
 	stmt.SetInt64("$pos", int64(pos))
+	stmt.SetText("$when_cond", string(seq.When))
 	stmt.SetBytes("$actions", actions)
+	if seq.DeferredActions != nil {
+		stmt.SetText("$deferredactions", seq.DeferredActions.ID.String())
+	}
+	stmt.SetInt64("$retries", int64(seq.Retries))
+	if seq.RetryPolicy != nil {
+		policy, err := json.Marshal(seq.RetryPolicy)
+		if err != nil {
+			return fmt.Errorf("commitSequence: couldn't encode RetryPolicy: %w", err)
+		}
+		stmt.SetBytes("$retry_policy", policy)
+	}
+	stmt.SetBytes("$approval", approval)
+	stmt.SetBytes("$locks", locks)
+	stmt.SetBytes("$attempts", attempts)
 	stmt.SetInt64("$state_status", int64(seq.State.Get().Status))
 	stmt.SetInt64("$state_start", seq.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", seq.State.Get().End.UnixNano())
@@ -331,12 +464,14 @@ const insertAction = `
 		plugin,
 		timeout,
 		retries,
+		when_cond,
+		undo,
 		req,
 		attempts,
 		state_status,
 		state_start,
 		state_end
-	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $plugin, $timeout, $retries, $req, $attempts,
+	) VALUES ($id, $key, $plan_id, $name, $descr, $pos, $plugin, $timeout, $retries, $when_cond, $undo, $req, $attempts,
 	$state_status, $state_start, $state_end)`
 
 func commitAction(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos int, action *workflow.Action, capture *CaptureStmts) error {
@@ -362,6 +497,14 @@ func commitAction(ctx context.Context, conn *sqlite.Conn, planID uuid.UUID, pos
 	stmt.SetText("$plugin", action.Plugin)
 	stmt.SetInt64("$timeout", int64(action.Timeout))
 	stmt.SetInt64("$retries", int64(action.Retries))
+	stmt.SetText("$when_cond", string(action.When))
+	if action.Undo != nil {
+		// The Undo is stored as its own row, like any other Action.
+		if err := commitAction(ctx, conn, planID, pos, action.Undo, capture); err != nil {
+			return fmt.Errorf("commitAction(undo): %w", err)
+		}
+		stmt.SetText("$undo", action.Undo.ID.String())
+	}
 	stmt.SetBytes("$req", req)
 	if attempts != nil {
 		stmt.SetBytes("$attempts", attempts)
@@ -420,6 +563,132 @@ func decodeAttempts(rawAttempts []byte, plug plugins.Plugin) ([]workflow.Attempt
 	return attempts, nil
 }
 
+// encodeSeqAttempts encodes the Attempts of a Sequence or Checks. If there are no attempts, this returns nil.
+func encodeSeqAttempts(attempts []workflow.SeqAttempt) ([]byte, error) {
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(attempts)
+	if err != nil {
+		return nil, fmt.Errorf("encodeSeqAttempts: %w", err)
+	}
+	return b, nil
+}
+
+// encodeSchedule encodes the Schedule of a Plan. If there is no Schedule, this returns nil.
+func encodeSchedule(s *workflow.Schedule) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("encodeSchedule: %w", err)
+	}
+	return b, nil
+}
+
+// decodeSchedule decodes the Schedule of a Plan that was encoded with encodeSchedule.
+func decodeSchedule(b []byte) (*workflow.Schedule, error) {
+	s := &workflow.Schedule{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("decodeSchedule: %w", err)
+	}
+	return s, nil
+}
+
+// encodeWindows encodes the Windows of a Plan or Block. If there are no Windows, this returns nil.
+func encodeWindows(w *workflow.Windows) ([]byte, error) {
+	if w == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("encodeWindows: %w", err)
+	}
+	return b, nil
+}
+
+// decodeWindows decodes the Windows of a Plan or Block that was encoded with encodeWindows.
+func decodeWindows(b []byte) (*workflow.Windows, error) {
+	w := &workflow.Windows{}
+	if err := json.Unmarshal(b, w); err != nil {
+		return nil, fmt.Errorf("decodeWindows: %w", err)
+	}
+	return w, nil
+}
+
+// encodePlanDeps encodes the DependsOn of a Plan. If there are none, this returns nil.
+func encodePlanDeps(deps []*workflow.PlanDep) ([]byte, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(deps)
+	if err != nil {
+		return nil, fmt.Errorf("encodePlanDeps: %w", err)
+	}
+	return b, nil
+}
+
+// decodePlanDeps decodes the DependsOn of a Plan that was encoded with encodePlanDeps.
+func decodePlanDeps(b []byte) ([]*workflow.PlanDep, error) {
+	var deps []*workflow.PlanDep
+	if err := json.Unmarshal(b, &deps); err != nil {
+		return nil, fmt.Errorf("decodePlanDeps: %w", err)
+	}
+	return deps, nil
+}
+
+// encodeLocks encodes the Locks of a Block or Sequence. If there are no Locks, this returns nil.
+func encodeLocks(l *workflow.Locks) ([]byte, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("encodeLocks: %w", err)
+	}
+	return b, nil
+}
+
+// decodeLocks decodes the Locks of a Block or Sequence that were encoded with encodeLocks.
+func decodeLocks(b []byte) (*workflow.Locks, error) {
+	l := &workflow.Locks{}
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("decodeLocks: %w", err)
+	}
+	return l, nil
+}
+
+// encodeApproval encodes the Approval of a Block or Sequence. If there is no Approval, this returns nil.
+func encodeApproval(a *workflow.Approval) ([]byte, error) {
+	if a == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("encodeApproval: %w", err)
+	}
+	return b, nil
+}
+
+// decodeApproval decodes the Approval of a Block or Sequence that was encoded with encodeApproval.
+func decodeApproval(b []byte) (*workflow.Approval, error) {
+	a := &workflow.Approval{}
+	if err := json.Unmarshal(b, a); err != nil {
+		return nil, fmt.Errorf("decodeApproval: %w", err)
+	}
+	return a, nil
+}
+
+// decodeSeqAttempts decodes the Attempts of a Sequence or Checks that were encoded with encodeSeqAttempts.
+func decodeSeqAttempts(b []byte) ([]workflow.SeqAttempt, error) {
+	var attempts []workflow.SeqAttempt
+	if err := json.Unmarshal(b, &attempts); err != nil {
+		return nil, fmt.Errorf("decodeSeqAttempts: %w", err)
+	}
+	return attempts, nil
+}
+
 type ider interface {
 	GetID() uuid.UUID
 }
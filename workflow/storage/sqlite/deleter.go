@@ -99,9 +99,15 @@ func (d deleter) deleteBlocks(ctx context.Context, conn *sqlite.Conn, blocks []*
 		if err := d.deleteChecks(ctx, conn, block.ContChecks); err != nil {
 			return fmt.Errorf("couldn't delete block contchecks: %w", err)
 		}
+		if err := d.deleteChecks(ctx, conn, block.WaveChecks); err != nil {
+			return fmt.Errorf("couldn't delete block wavechecks: %w", err)
+		}
 		if err := d.deleteChecks(ctx, conn, block.DeferredChecks); err != nil {
 			return fmt.Errorf("couldn't delete block deferredchecks: %w", err)
 		}
+		if err := d.deleteDeferredActions(ctx, conn, block.DeferredActions); err != nil {
+			return fmt.Errorf("couldn't delete block deferredactions: %w", err)
+		}
 		if err := d.deletesSeqs(ctx, conn, block.Sequences); err != nil {
 			return fmt.Errorf("couldn't delete block sequences: %w", err)
 		}
@@ -151,6 +157,9 @@ func (d deleter) deletesSeqs(ctx context.Context, conn *sqlite.Conn, seqs []*wor
 		if err := d.deleteActions(ctx, conn, seq.Actions); err != nil {
 			return fmt.Errorf("couldn't delete sequence actions: %w", err)
 		}
+		if err := d.deleteDeferredActions(ctx, conn, seq.DeferredActions); err != nil {
+			return fmt.Errorf("couldn't delete sequence deferredactions: %w", err)
+		}
 	}
 
 	for _, seq := range seqs {
@@ -224,6 +233,11 @@ func (d deleter) deleteActions(ctx context.Context, conn *sqlite.Conn, actions [
 		if err != nil {
 			return fmt.Errorf("problem deleting action: %w", err)
 		}
+		if action.Undo != nil {
+			if err := d.deleteActions(ctx, conn, []*workflow.Action{action.Undo}); err != nil {
+				return fmt.Errorf("couldn't delete undo action: %w", err)
+			}
+		}
 	}
 	return nil
 }
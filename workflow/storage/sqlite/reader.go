@@ -143,15 +143,17 @@ func (r reader) buildSearchQuery(filters storage.Filters) (string, []any, map[st
 			build.WriteString(" AND")
 		}
 		numFilters++ // I know this says inEffectual assignment and it is, but it is here for completeness.
+		build.WriteString(" (")
 		for i, s := range filters.ByStatus {
 			name := fmt.Sprintf("$status%d", i)
 			named[name] = int64(s)
 			if i == 0 {
-				build.WriteString(fmt.Sprintf(" state_status = %s", name))
+				build.WriteString(fmt.Sprintf("state_status = %s", name))
 			} else {
 				build.WriteString(fmt.Sprintf(" OR state_status = %s", name))
 			}
 		}
+		build.WriteString(")")
 	}
 
 	build.WriteString(" ORDER BY submit_time DESC;")
@@ -159,12 +161,12 @@ func (r reader) buildSearchQuery(filters storage.Filters) (string, []any, map[st
 	query := build.String()
 	if len(filters.ByIDs) > 0 {
 		var idArgs []any
-		query, idArgs = replaceWithIDs(query, "$id", filters.ByIDs)
+		query, idArgs = replaceWithIDs(query, "$ids", filters.ByIDs)
 		args = append(args, idArgs...)
 	}
 	if len(filters.ByGroupIDs) > 0 {
 		var groupArgs []any
-		query, groupArgs = replaceWithIDs(query, "$group_id", filters.ByGroupIDs)
+		query, groupArgs = replaceWithIDs(query, "$group_ids", filters.ByGroupIDs)
 		args = append(args, groupArgs...)
 	}
 	return query, args, named
@@ -35,6 +35,8 @@ func (r reader) fetchActionsByIDs(ctx context.Context, conn *sqlite.Conn, ids []
 	}
 
 	actions := make([]*workflow.Action, 0, len(ids))
+	// undos holds the ID of the Undo of an action. These are fetched after the query finishes.
+	undos := map[*workflow.Action]uuid.UUID{}
 
 	query, args := replaceWithIDs(fetchActionsByID, "$ids", ids)
 
@@ -48,6 +50,13 @@ func (r reader) fetchActionsByIDs(ctx context.Context, conn *sqlite.Conn, ids []
 				if err != nil {
 					return fmt.Errorf("couldn't convert row to action: %w", err)
 				}
+				if u := stmt.GetText("undo"); u != "" {
+					id, err := uuid.Parse(u)
+					if err != nil {
+						return fmt.Errorf("couldn't parse undo id: %w", err)
+					}
+					undos[a] = id
+				}
 				actions = append(actions, a)
 				return nil
 			},
@@ -56,6 +65,17 @@ func (r reader) fetchActionsByIDs(ctx context.Context, conn *sqlite.Conn, ids []
 	if err != nil {
 		return nil, fmt.Errorf("couldn't fetch actions by ids: %w", err)
 	}
+
+	for a, id := range undos {
+		undo, err := r.fetchActionsByIDs(ctx, conn, []uuid.UUID{id})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't fetch undo action: %w", err)
+		}
+		if len(undo) != 1 {
+			return nil, fmt.Errorf("couldn't find undo action(%s) for action(%s)", id, a.ID)
+		}
+		a.Undo = undo[0]
+	}
 	return actions, nil
 }
 
@@ -89,6 +109,7 @@ func (r reader) actionRowToAction(ctx context.Context, stmt *sqlite.Stmt) (*work
 	a.Plugin = stmt.GetText("plugin")
 	a.Timeout = time.Duration(stmt.GetInt64("timeout"))
 	a.Retries = int(stmt.GetInt64("retries"))
+	a.When = workflow.WhenCond(stmt.GetText("when_cond"))
 	state, err := fieldToState(stmt)
 	if err != nil {
 		return nil, fmt.Errorf("actionRowToAction: %w", err)
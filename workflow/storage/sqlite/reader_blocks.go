@@ -7,6 +7,7 @@ import (
 	"github.com/gostdlib/base/context"
 
 	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/go-json-experiment/json"
 	"github.com/google/uuid"
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
@@ -89,13 +90,46 @@ func (p reader) blockRowToBlock(ctx context.Context, conn *sqlite.Conn, stmt *sq
 	b.Descr = stmt.GetText("descr")
 	b.EntranceDelay = time.Duration(stmt.GetInt64("entrancedelay"))
 	b.ExitDelay = time.Duration(stmt.GetInt64("exitdelay"))
+	b.When = workflow.WhenCond(stmt.GetText("when_cond"))
 	state, err := fieldToState(stmt)
 	if err != nil {
 		return nil, fmt.Errorf("blockRowToBlock: %w", err)
 	}
 	b.State.Set(*state)
+	if fieldToBytes("depends_on", stmt) != nil {
+		b.DependsOn, err = fieldToIDs("depends_on", stmt)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read block depends_on: %w", err)
+		}
+	}
 	b.Concurrency = int(stmt.GetInt64("concurrency"))
+	if r := fieldToBytes("ramp", stmt); r != nil {
+		b.Ramp = &workflow.Ramp{}
+		if err := json.Unmarshal(r, b.Ramp); err != nil {
+			return nil, fmt.Errorf("couldn't decode block ramp: %w", err)
+		}
+	}
 	b.ToleratedFailures = int(stmt.GetInt64("toleratedfailures"))
+	b.ToleratedFailurePercent = int(stmt.GetInt64("toleratedfailurepercent"))
+	b.Timeout = time.Duration(stmt.GetInt64("timeout"))
+	if a := fieldToBytes("approval", stmt); a != nil {
+		b.Approval, err = decodeApproval(a)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode block approval: %w", err)
+		}
+	}
+	if w := fieldToBytes("windows", stmt); w != nil {
+		b.Windows, err = decodeWindows(w)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode block windows: %w", err)
+		}
+	}
+	if l := fieldToBytes("locks", stmt); l != nil {
+		b.Locks, err = decodeLocks(l)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode block locks: %w", err)
+		}
+	}
 	b.BypassChecks, err = p.fieldToCheck(ctx, "bypasschecks", conn, stmt)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read block bypasschecks: %w", err)
@@ -112,10 +146,18 @@ func (p reader) blockRowToBlock(ctx context.Context, conn *sqlite.Conn, stmt *sq
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read block postchecks: %w", err)
 	}
+	b.WaveChecks, err = p.fieldToCheck(ctx, "wavechecks", conn, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read block wavechecks: %w", err)
+	}
 	b.DeferredChecks, err = p.fieldToCheck(ctx, "deferredchecks", conn, stmt)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read block deferredchecks: %w", err)
 	}
+	b.DeferredActions, err = p.fieldToDeferredActions(ctx, conn, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read block deferredactions: %w", err)
+	}
 
 	b.Sequences, err = p.fieldToSequences(ctx, conn, stmt)
 	if err != nil {
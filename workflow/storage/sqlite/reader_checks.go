@@ -7,6 +7,7 @@ import (
 	"github.com/gostdlib/base/context"
 
 	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/go-json-experiment/json"
 	"github.com/google/uuid"
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
@@ -85,6 +86,21 @@ func (p reader) checksRowToChecks(ctx context.Context, conn *sqlite.Conn, stmt *
 		}
 	}
 	c.Delay = time.Duration(stmt.GetInt64("delay"))
+	c.WaitUntil = time.Duration(stmt.GetInt64("wait_until"))
+	c.SoakDuration = time.Duration(stmt.GetInt64("soak_duration"))
+	if f := fieldToBytes("failure_threshold", stmt); f != nil {
+		c.FailureThreshold = &workflow.FailureThreshold{}
+		if err := json.Unmarshal(f, c.FailureThreshold); err != nil {
+			return nil, fmt.Errorf("couldn't decode checks failure threshold: %w", err)
+		}
+	}
+	if b := fieldToBytes("attempts", stmt); b != nil {
+		attempts, err := decodeSeqAttempts(b)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode checks attempts: %w", err)
+		}
+		c.Attempts.Set(attempts)
+	}
 	state, err := fieldToState(stmt)
 	if err != nil {
 		return nil, fmt.Errorf("checksRowToChecks: %w", err)
@@ -12,7 +12,7 @@ import (
 	"zombiezen.com/go/sqlite/sqlitex"
 )
 
-// fieldToDeferredActions reads the "deferredactions" field from a plan row and
+// fieldToDeferredActions reads the "deferredactions" field from a plan, block or sequence row and
 // fetches the associated DeferredActions hierarchy. Returns nil if the field is empty.
 func (r reader) fieldToDeferredActions(ctx context.Context, conn *sqlite.Conn, stmt *sqlite.Stmt) (*workflow.DeferredActions, error) {
 	strID := stmt.GetText("deferredactions")
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gostdlib/base/context"
 
@@ -53,6 +54,27 @@ func (p reader) fetchPlan(ctx context.Context, id uuid.UUID) (*workflow.Plan, er
 					return fmt.Errorf("couldn't get plan submit time: %w", err)
 				}
 				plan.Reason = workflow.FailureReason(stmt.GetInt64("reason"))
+				plan.Concurrency = int(stmt.GetInt64("concurrency"))
+				plan.Timeout = time.Duration(stmt.GetInt64("timeout"))
+				plan.Priority = int(stmt.GetInt64("priority"))
+				if b := fieldToBytes("schedule", stmt); b != nil {
+					plan.Schedule, err = decodeSchedule(b)
+					if err != nil {
+						return fmt.Errorf("couldn't decode plan schedule: %w", err)
+					}
+				}
+				if b := fieldToBytes("windows", stmt); b != nil {
+					plan.Windows, err = decodeWindows(b)
+					if err != nil {
+						return fmt.Errorf("couldn't decode plan windows: %w", err)
+					}
+				}
+				if b := fieldToBytes("depends_on", stmt); b != nil {
+					plan.DependsOn, err = decodePlanDeps(b)
+					if err != nil {
+						return fmt.Errorf("couldn't decode plan depends_on: %w", err)
+					}
+				}
 				state, err := fieldToState(stmt)
 				if err != nil {
 					return fmt.Errorf("couldn't get plan state: %w", err)
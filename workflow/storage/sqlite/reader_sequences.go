@@ -6,6 +6,7 @@ import (
 	"github.com/gostdlib/base/context"
 
 	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/go-json-experiment/json"
 	"github.com/google/uuid"
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
@@ -85,6 +86,33 @@ func (p reader) sequenceRowToSequence(ctx context.Context, conn *sqlite.Conn, st
 	}
 	s.Name = stmt.GetText("name")
 	s.Descr = stmt.GetText("descr")
+	s.When = workflow.WhenCond(stmt.GetText("when_cond"))
+	s.Retries = int(stmt.GetInt64("retries"))
+	if b := fieldToBytes("retry_policy", stmt); b != nil {
+		s.RetryPolicy = &workflow.RetryPolicy{}
+		if err := json.Unmarshal(b, s.RetryPolicy); err != nil {
+			return nil, fmt.Errorf("couldn't decode sequence retry policy: %w", err)
+		}
+	}
+	if b := fieldToBytes("approval", stmt); b != nil {
+		s.Approval, err = decodeApproval(b)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode sequence approval: %w", err)
+		}
+	}
+	if b := fieldToBytes("locks", stmt); b != nil {
+		s.Locks, err = decodeLocks(b)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode sequence locks: %w", err)
+		}
+	}
+	if b := fieldToBytes("attempts", stmt); b != nil {
+		attempts, err := decodeSeqAttempts(b)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode sequence attempts: %w", err)
+		}
+		s.Attempts.Set(attempts)
+	}
 	state, err := fieldToState(stmt)
 	if err != nil {
 		return nil, fmt.Errorf("sequenceRowToSequence: %w", err)
@@ -94,6 +122,10 @@ func (p reader) sequenceRowToSequence(ctx context.Context, conn *sqlite.Conn, st
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read sequence actions: %w", err)
 	}
+	s.DeferredActions, err = p.fieldToDeferredActions(ctx, conn, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read sequence deferredactions: %w", err)
+	}
 
 	return s, nil
 }
@@ -26,7 +26,13 @@ SELECT
 	state_start,
 	state_end,
 	submit_time,
-	reason
+	reason,
+	concurrency,
+	timeout,
+	priority,
+	schedule,
+	windows,
+	depends_on
 FROM plans
 WHERE id = $id`
 
@@ -40,14 +46,24 @@ SELECT
 	pos,
 	entrancedelay,
 	exitdelay,
+	when_cond,
 	bypasschecks,
 	prechecks,
 	postchecks,
 	contchecks,
+	wavechecks,
 	deferredchecks,
+	deferredactions,
 	sequences,
+	depends_on,
 	concurrency,
+	ramp,
 	toleratedfailures,
+	toleratedfailurepercent,
+	timeout,
+	approval,
+	windows,
+	locks,
 	state_status,
 	state_start,
 	state_end
@@ -61,6 +77,10 @@ SELECT
 	plan_id,
 	actions,
 	delay,
+	wait_until,
+	soak_duration,
+	failure_threshold,
+	attempts,
 	state_status,
 	state_start,
 	state_end
@@ -74,7 +94,14 @@ SELECT
 	plan_id,
 	name,
 	descr,
+	when_cond,
 	actions,
+	deferredactions,
+	retries,
+	retry_policy,
+	approval,
+	locks,
+	attempts,
 	state_status,
 	state_start,
 	state_end
@@ -120,6 +147,8 @@ SELECT
 	plugin,
 	timeout,
 	retries,
+	when_cond,
+	undo,
 	req,
 	attempts,
 	state_status,
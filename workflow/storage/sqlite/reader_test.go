@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -369,6 +370,119 @@ func TestSearchMultipleStatuses(t *testing.T) {
 	}
 }
 
+// TestSearchIDsAndGroupIDs tests that searching by IDs and GroupIDs returns the matching plans and that
+// a status filter only narrows the results of the other filters.
+func TestSearchIDsAndGroupIDs(t *testing.T) {
+	tmpDir := os.TempDir()
+	id, err := uuid.NewV7()
+	if err != nil {
+		t.Fatalf("TestSearchIDsAndGroupIDs: couldn't generate UUID: %s", err)
+	}
+	dbPath := filepath.Join(tmpDir, id.String())
+	defer os.RemoveAll(dbPath)
+
+	pool, err := sqlitex.NewPool(
+		dbPath,
+		sqlitex.PoolOptions{
+			Flags:    sqlite.OpenReadWrite | sqlite.OpenCreate,
+			PoolSize: 1,
+		},
+	)
+	if err != nil {
+		t.Fatalf("TestSearchIDsAndGroupIDs: couldn't create pool: %s", err)
+	}
+	defer pool.Close()
+
+	ctx := t.Context()
+	conn, err := pool.Take(ctx)
+	if err != nil {
+		t.Fatalf("TestSearchIDsAndGroupIDs: couldn't get connection: %s", err)
+	}
+	if err := createTables(ctx, conn); err != nil {
+		pool.Put(conn)
+		t.Fatalf("TestSearchIDsAndGroupIDs: couldn't create tables: %s", err)
+	}
+	pool.Put(conn)
+
+	reg := registry.New()
+	reg.Register(&plugins.CheckPlugin{})
+	reg.Register(&plugins.HelloPlugin{})
+	r := reader{pool: pool, reg: reg}
+
+	now := time.Now()
+	group := mustUUID()
+	plans := []*workflow.Plan{
+		createTestPlanWithStatus(t, now.Add(-3*time.Hour), workflow.Running),
+		createTestPlanWithStatus(t, now.Add(-2*time.Hour), workflow.Completed),
+		createTestPlanWithStatus(t, now.Add(-1*time.Hour), workflow.Failed),
+	}
+	plans[0].GroupID = group
+	plans[1].GroupID = group
+	for _, plan := range plans {
+		conn, err := pool.Take(ctx)
+		if err != nil {
+			t.Fatalf("TestSearchIDsAndGroupIDs: couldn't get connection: %s", err)
+		}
+		if err := commitPlan(ctx, conn, plan, nil); err != nil {
+			pool.Put(conn)
+			t.Fatalf("TestSearchIDsAndGroupIDs: couldn't commit plan: %s", err)
+		}
+		pool.Put(conn)
+	}
+
+	tests := []struct {
+		name    string
+		filters storage.Filters
+		// want are the indexes of the plans that are found, newest first.
+		want []int
+	}{
+		{
+			name:    "Success: IDs",
+			filters: storage.Filters{ByIDs: []uuid.UUID{plans[0].ID, plans[2].ID}},
+			want:    []int{2, 0},
+		},
+		{
+			name:    "Success: GroupIDs",
+			filters: storage.Filters{ByGroupIDs: []uuid.UUID{group}},
+			want:    []int{1, 0},
+		},
+		{
+			name:    "Success: IDs and GroupIDs",
+			filters: storage.Filters{ByIDs: []uuid.UUID{plans[0].ID, plans[2].ID}, ByGroupIDs: []uuid.UUID{group}},
+			want:    []int{0},
+		},
+		{
+			name:    "Success: GroupIDs and statuses",
+			filters: storage.Filters{ByGroupIDs: []uuid.UUID{group}, ByStatus: []workflow.Status{workflow.Completed, workflow.Failed}},
+			want:    []int{1},
+		},
+	}
+
+	for _, test := range tests {
+		ch, err := r.Search(ctx, test.filters)
+		if err != nil {
+			t.Errorf("TestSearchIDsAndGroupIDs(%s): Search returned error: %s", test.name, err)
+			continue
+		}
+
+		var got []uuid.UUID
+		for item := range ch {
+			if item.Err != nil {
+				t.Errorf("TestSearchIDsAndGroupIDs(%s): got error in stream: %s", test.name, item.Err)
+				continue
+			}
+			got = append(got, item.Result.ID)
+		}
+		var want []uuid.UUID
+		for _, i := range test.want {
+			want = append(want, plans[i].ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("TestSearchIDsAndGroupIDs(%s): got %v, want %v", test.name, got, want)
+		}
+	}
+}
+
 // createTestPlanWithStatus creates a test plan with the specified status.
 func createTestPlanWithStatus(t *testing.T, submitTime time.Time, status workflow.Status) *workflow.Plan {
 	build, err := builder.New("test", "test", builder.WithGroupID(mustUUID()))
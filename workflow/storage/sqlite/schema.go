@@ -28,7 +28,13 @@ CREATE Table If Not Exists plans (
 	state_start INTEGER NOT NULL,
 	state_end INTEGER NOT NULL,
 	submit_time INTEGER NOT NULL,
-	reason INTEGER
+	reason INTEGER,
+	concurrency INTEGER NOT NULL,
+	timeout INTEGER NOT NULL,
+	priority INTEGER NOT NULL,
+	schedule BLOB,
+	windows BLOB,
+	depends_on BLOB
 );`
 
 var blocksSchema = `
@@ -41,14 +47,24 @@ CREATE Table If Not Exists blocks (
     pos INTEGER NOT NULL,
     entrancedelay INTEGER NOT NULL,
     exitdelay INTEGER NOT NULL,
+    when_cond TEXT,
     bypasschecks TEXT,
     prechecks TEXT,
     postchecks TEXT,
     contchecks TEXT,
+    wavechecks TEXT,
     deferredchecks TEXT,
+    deferredactions TEXT,
     sequences BLOB NOT NULL,
+    depends_on BLOB,
     concurrency INTEGER NOT NULL,
+    ramp BLOB,
     toleratedfailures INTEGER NOT NULL,
+    toleratedfailurepercent INTEGER NOT NULL,
+    timeout INTEGER NOT NULL,
+    approval BLOB,
+    windows BLOB,
+    locks BLOB,
     state_status INTEGER NOT NULL,
     state_start INTEGER NOT NULL,
     state_end INTEGER NOT NULL
@@ -61,6 +77,10 @@ CREATE Table If Not Exists checks (
     plan_id TEXT NOT NULL,
     actions BLOB NOT NULL,
     delay INTEGER NOT NULL,
+    wait_until INTEGER NOT NULL,
+    soak_duration INTEGER NOT NULL,
+    failure_threshold BLOB,
+    attempts BLOB,
     state_status INTEGER NOT NULL,
     state_start INTEGER NOT NULL,
     state_end INTEGER NOT NULL
@@ -74,7 +94,14 @@ CREATE Table If Not Exists sequences (
     name TEXT NOT NULL,
     descr TEXT NOT NULL,
     pos INTEGER NOT NULL,
+    when_cond TEXT,
     actions BLOB NOT NULL,
+    deferredactions TEXT,
+    retries INTEGER NOT NULL,
+    retry_policy BLOB,
+    approval BLOB,
+    locks BLOB,
+    attempts BLOB,
     state_status INTEGER NOT NULL,
     state_start INTEGER NOT NULL,
     state_end INTEGER NOT NULL
@@ -91,6 +118,8 @@ CREATE Table If Not Exists actions (
     plugin TEXT NOT NULL,
     timeout INTEGER NOT NULL,
     retries INTEGER NOT NULL,
+    when_cond TEXT,
+    undo TEXT,
     req BLOB,
     attempts BLOB,
     state_status INTEGER NOT NULL,
@@ -124,6 +153,50 @@ CREATE Table If Not Exists deferbatches (
     state_end INTEGER NOT NULL
 );`
 
+// migrations are the changes to the schema of a database that was created by an earlier release. A
+// database records in PRAGMA user_version how many of them it has. The tables above are the latest
+// schema, so a new database has all of them. A column that is NOT NULL must have a DEFAULT here, so
+// that the rows written before it was added can still be read. Add new changes to the end, never
+// change one that has been released.
+var migrations = [][]string{
+	// 1: The columns added since the first release.
+	{
+		`ALTER TABLE plans ADD COLUMN concurrency INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE plans ADD COLUMN timeout INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE plans ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE plans ADD COLUMN schedule BLOB;`,
+		`ALTER TABLE plans ADD COLUMN windows BLOB;`,
+		`ALTER TABLE plans ADD COLUMN depends_on BLOB;`,
+
+		`ALTER TABLE blocks ADD COLUMN when_cond TEXT;`,
+		`ALTER TABLE blocks ADD COLUMN wavechecks TEXT;`,
+		`ALTER TABLE blocks ADD COLUMN deferredactions TEXT;`,
+		`ALTER TABLE blocks ADD COLUMN depends_on BLOB;`,
+		`ALTER TABLE blocks ADD COLUMN ramp BLOB;`,
+		`ALTER TABLE blocks ADD COLUMN toleratedfailurepercent INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE blocks ADD COLUMN timeout INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE blocks ADD COLUMN approval BLOB;`,
+		`ALTER TABLE blocks ADD COLUMN windows BLOB;`,
+		`ALTER TABLE blocks ADD COLUMN locks BLOB;`,
+
+		`ALTER TABLE checks ADD COLUMN wait_until INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE checks ADD COLUMN soak_duration INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE checks ADD COLUMN failure_threshold BLOB;`,
+		`ALTER TABLE checks ADD COLUMN attempts BLOB;`,
+
+		`ALTER TABLE sequences ADD COLUMN when_cond TEXT;`,
+		`ALTER TABLE sequences ADD COLUMN deferredactions TEXT;`,
+		`ALTER TABLE sequences ADD COLUMN retries INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE sequences ADD COLUMN retry_policy BLOB;`,
+		`ALTER TABLE sequences ADD COLUMN approval BLOB;`,
+		`ALTER TABLE sequences ADD COLUMN locks BLOB;`,
+		`ALTER TABLE sequences ADD COLUMN attempts BLOB;`,
+
+		`ALTER TABLE actions ADD COLUMN when_cond TEXT;`,
+		`ALTER TABLE actions ADD COLUMN undo TEXT;`,
+	},
+}
+
 var indexes = []string{
 	`CREATE INDEX If Not Exists idx_plans ON plans(id, group_id, state_status, state_start, state_end, reason);`,
 	`CREATE INDEX If Not Exists idx_blocks ON blocks(id, key, plan_id, state_status, state_start, state_end);`,
@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/element-of-surprise/coercion/plugins/registry"
+	"github.com/element-of-surprise/coercion/workflow"
+	"github.com/element-of-surprise/coercion/workflow/storage/sqlite/testing/plugins"
+
+	"github.com/google/uuid"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+// firstTables is the schema of the first release, before any of the migrations.
+var firstTables = []string{
+	`CREATE Table If Not Exists plans (
+	id TEXT PRIMARY KEY,
+	group_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	descr TEXT NOT NULL,
+	meta BLOB,
+	bypasschecks TEXT,
+	prechecks TEXT,
+	postchecks TEXT,
+	contchecks TEXT,
+	deferredchecks TEXT,
+	deferredactions TEXT,
+	blocks BLOB NOT NULL,
+	state_status INTEGER NOT NULL,
+	state_start INTEGER NOT NULL,
+	state_end INTEGER NOT NULL,
+	submit_time INTEGER NOT NULL,
+	reason INTEGER
+);`,
+	`CREATE Table If Not Exists blocks (
+    id TEXT PRIMARY KEY,
+    key TEXT,
+    plan_id BLOB NOT NULL,
+    name TEXT NOT NULL,
+    descr TEXT NOT NULL,
+    pos INTEGER NOT NULL,
+    entrancedelay INTEGER NOT NULL,
+    exitdelay INTEGER NOT NULL,
+    bypasschecks TEXT,
+    prechecks TEXT,
+    postchecks TEXT,
+    contchecks TEXT,
+    deferredchecks TEXT,
+    sequences BLOB NOT NULL,
+    concurrency INTEGER NOT NULL,
+    toleratedfailures INTEGER NOT NULL,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+	`CREATE Table If Not Exists checks (
+    id TEXT PRIMARY KEY,
+    key TEXT,
+    plan_id TEXT NOT NULL,
+    actions BLOB NOT NULL,
+    delay INTEGER NOT NULL,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+	`CREATE Table If Not Exists sequences (
+    id TEXT PRIMARY KEY,
+    key TEXT,
+    plan_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    descr TEXT NOT NULL,
+    pos INTEGER NOT NULL,
+    actions BLOB NOT NULL,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+	`CREATE Table If Not Exists actions (
+    id TEXT PRIMARY KEY,
+    key TEXT,
+    plan_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    descr TEXT NOT NULL,
+    pos INTEGER NOT NULL,
+    plugin TEXT NOT NULL,
+    timeout INTEGER NOT NULL,
+    retries INTEGER NOT NULL,
+    req BLOB,
+    attempts BLOB,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+	`CREATE Table If Not Exists deferredactions (
+    id TEXT PRIMARY KEY,
+    plan_id TEXT NOT NULL,
+    batches BLOB,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+	`CREATE Table If Not Exists deferbatches (
+    id TEXT PRIMARY KEY,
+    plan_id TEXT NOT NULL,
+    deferredactions_id TEXT NOT NULL,
+    pos INTEGER NOT NULL,
+    when_run INTEGER NOT NULL,
+    fail_element INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    descr TEXT NOT NULL,
+    actions BLOB,
+    state_status INTEGER NOT NULL,
+    state_start INTEGER NOT NULL,
+    state_end INTEGER NOT NULL
+);`,
+}
+
+// TestMigrate tests that a database created by the first release is migrated to the latest schema and that
+// the rows written before the migration are read.
+func TestMigrate(t *testing.T) {
+	ctx := t.Context()
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		t.Fatalf("TestMigrate: couldn't generate UUID: %s", err)
+	}
+	root := filepath.Join(os.TempDir(), id.String())
+	defer os.RemoveAll(root)
+	if err := os.MkdirAll(root, 0700); err != nil {
+		t.Fatalf("TestMigrate: couldn't create root: %s", err)
+	}
+
+	planID, blockID, seqID, actionID := mustUUID(), mustUUID(), mustUUID(), mustUUID()
+	rows := []struct {
+		query string
+		args  []any
+	}{
+		{
+			`INSERT INTO plans (id, group_id, name, descr, blocks, state_status, state_start, state_end, submit_time, reason)
+			VALUES (?, ?, 'plan', 'plan', ?, ?, 0, 0, 0, 0);`,
+			[]any{planID.String(), uuid.Nil.String(), `["` + blockID.String() + `"]`, int64(workflow.Completed)},
+		},
+		{
+			`INSERT INTO blocks (id, key, plan_id, name, descr, pos, entrancedelay, exitdelay, sequences, concurrency,
+			toleratedfailures, state_status, state_start, state_end)
+			VALUES (?, ?, ?, 'block', 'block', 0, 0, 0, ?, 1, 0, ?, 0, 0);`,
+			[]any{blockID.String(), uuid.Nil.String(), planID.String(), `["` + seqID.String() + `"]`, int64(workflow.Completed)},
+		},
+		{
+			`INSERT INTO sequences (id, key, plan_id, name, descr, pos, actions, state_status, state_start, state_end)
+			VALUES (?, ?, ?, 'seq', 'seq', 0, ?, ?, 0, 0);`,
+			[]any{seqID.String(), uuid.Nil.String(), planID.String(), `["` + actionID.String() + `"]`, int64(workflow.Completed)},
+		},
+		{
+			`INSERT INTO actions (id, key, plan_id, name, descr, pos, plugin, timeout, retries, req, state_status, state_start, state_end)
+			VALUES (?, ?, ?, 'action', 'action', 0, ?, 60000000000, 0, ?, ?, 0, 0);`,
+			[]any{actionID.String(), uuid.Nil.String(), planID.String(), plugins.HelloPluginName, []byte(`{"Say":"hello"}`), int64(workflow.Completed)},
+		},
+	}
+
+	conn, err := sqlite.OpenConn(filepath.Join(root, "workstream.db"), sqlite.OpenReadWrite, sqlite.OpenCreate)
+	if err != nil {
+		t.Fatalf("TestMigrate: couldn't open database: %s", err)
+	}
+	for _, table := range firstTables {
+		if err := sqlitex.ExecuteTransient(conn, table, nil); err != nil {
+			t.Fatalf("TestMigrate: couldn't create first release table: %s", err)
+		}
+	}
+	for _, row := range rows {
+		if err := sqlitex.ExecuteTransient(conn, row.query, &sqlitex.ExecOptions{Args: row.args}); err != nil {
+			t.Fatalf("TestMigrate: couldn't write first release row: %s", err)
+		}
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("TestMigrate: couldn't close database: %s", err)
+	}
+
+	reg := registry.New()
+	reg.Register(&plugins.HelloPlugin{})
+
+	// The second open finds the database at the latest version and has nothing to migrate.
+	for i := 0; i < 2; i++ {
+		vault, err := New(ctx, root, reg)
+		if err != nil {
+			t.Fatalf("TestMigrate: open %d: got err == %s, want err == nil", i, err)
+		}
+
+		plan, err := vault.Read(ctx, planID)
+		if err != nil {
+			t.Fatalf("TestMigrate: open %d: Read got err == %s, want err == nil", i, err)
+		}
+		if plan.State.Get().Status != workflow.Completed || len(plan.Blocks) != 1 {
+			t.Fatalf("TestMigrate: open %d: got plan with status %v and %d blocks, want %v and 1", i, plan.State.Get().Status, len(plan.Blocks), workflow.Completed)
+		}
+		seqs := plan.Blocks[0].Sequences
+		if len(seqs) != 1 || len(seqs[0].Actions) != 1 || seqs[0].Actions[0].Req.(plugins.HelloReq).Say != "hello" {
+			t.Errorf("TestMigrate: open %d: did not read the sequence and action written before the migration", i)
+		}
+		if plan.Priority != 0 || plan.Blocks[0].Timeout != 0 || seqs[0].Retries != 0 {
+			t.Errorf("TestMigrate: open %d: got non-zero values for the columns added by the migration", i)
+		}
+
+		conn, err := vault.Pool().Take(ctx)
+		if err != nil {
+			t.Fatalf("TestMigrate: couldn't get connection: %s", err)
+		}
+		version, err := userVersion(conn)
+		vault.Pool().Put(conn)
+		if err != nil || version != len(migrations) {
+			t.Errorf("TestMigrate: open %d: got schema version (%d, %v), want (%d, nil)", i, version, err, len(migrations))
+		}
+		if err := vault.Close(ctx); err != nil {
+			t.Fatalf("TestMigrate: couldn't close vault: %s", err)
+		}
+	}
+}
@@ -156,7 +156,34 @@ func (v *Vault) Pool() *sqlitex.Pool {
 	return v.pool
 }
 
-func createTables(ctx context.Context, conn *sqlite.Conn) error {
+// createTables creates the tables of a new database, or brings the tables of a database that was created
+// by an earlier release up to date with migrations.
+func createTables(ctx context.Context, conn *sqlite.Conn) (err error) {
+	defer sqlitex.Save(conn)(&err)
+
+	version, err := userVersion(conn)
+	if err != nil {
+		return err
+	}
+	exists := false
+	err = sqlitex.ExecuteTransient(
+		conn,
+		`SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'plans';`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				exists = true
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't look for the tables: %w", err)
+	}
+	// A new database is created with the latest schema, so it needs none of the migrations.
+	if !exists {
+		version = len(migrations)
+	}
+
 	for _, table := range tables {
 		if err := sqlitex.ExecuteTransient(
 			conn,
@@ -166,6 +193,21 @@ func createTables(ctx context.Context, conn *sqlite.Conn) error {
 			return fmt.Errorf("couldn't create table: %w", err)
 		}
 	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version(%d) is newer than this release supports(%d)", version, len(migrations))
+	}
+	for i, migration := range migrations[version:] {
+		for _, stmt := range migration {
+			if err := sqlitex.ExecuteTransient(conn, stmt, &sqlitex.ExecOptions{}); err != nil {
+				return fmt.Errorf("couldn't migrate schema to version(%d): %w", version+i+1, err)
+			}
+		}
+	}
+	// PRAGMA statements don't take parameters.
+	if err := sqlitex.ExecuteTransient(conn, fmt.Sprintf("PRAGMA user_version = %d;", len(migrations)), &sqlitex.ExecOptions{}); err != nil {
+		return fmt.Errorf("couldn't set schema version: %w", err)
+	}
+
 	for _, index := range indexes {
 		if err := sqlitex.ExecuteTransient(conn, index, &sqlitex.ExecOptions{}); err != nil {
 			return fmt.Errorf("couldn't create index: %w", err)
@@ -173,3 +215,22 @@ func createTables(ctx context.Context, conn *sqlite.Conn) error {
 	}
 	return nil
 }
+
+// userVersion returns the schema version that the database records in PRAGMA user_version.
+func userVersion(conn *sqlite.Conn) (int, error) {
+	version := 0
+	err := sqlitex.ExecuteTransient(
+		conn,
+		`PRAGMA user_version;`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				version = stmt.ColumnInt(0)
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't read schema version: %w", err)
+	}
+	return version, nil
+}
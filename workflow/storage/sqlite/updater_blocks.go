@@ -36,9 +36,20 @@ func (b blockUpdater) UpdateBlock(ctx context.Context, action *workflow.Block) e
 	}
 	defer b.pool.Put(conn)
 
+	approval, err := encodeApproval(action.Approval)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("BlockWriter.Write: %w", err))
+	}
+	locks, err := encodeLocks(action.Locks)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("BlockWriter.Write: %w", err))
+	}
+
 	stmt := Stmt{}
 	stmt.Query(updateBlock)
 	stmt.SetText("$id", action.ID.String())
+	stmt.SetBytes("$approval", approval)
+	stmt.SetBytes("$locks", locks)
 	stmt.SetInt64("$state_status", int64(action.State.Get().Status))
 	stmt.SetInt64("$state_start", action.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", action.State.Get().End.UnixNano())
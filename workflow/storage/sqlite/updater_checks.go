@@ -36,9 +36,15 @@ func (c checksUpdater) UpdateChecks(ctx context.Context, check *workflow.Checks)
 	}
 	defer c.pool.Put(conn)
 
+	attempts, err := encodeSeqAttempts(check.Attempts.Get())
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("ChecksWriter.Checks: %w", err))
+	}
+
 	stmt := Stmt{}
 	stmt.Query(updateChecks)
 	stmt.SetText("$id", check.ID.String())
+	stmt.SetBytes("$attempts", attempts)
 	stmt.SetInt64("$state_status", int64(check.State.Get().Status))
 	stmt.SetInt64("$state_start", check.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", check.State.Get().End.UnixNano())
@@ -44,6 +44,16 @@ func (u planUpdater) UpdatePlan(ctx context.Context, plan *workflow.Plan) error
 	stmt.SetInt64("$state_status", int64(plan.State.Get().Status))
 	stmt.SetInt64("$state_start", plan.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", plan.State.Get().End.UnixNano())
+	schedule, err := encodeSchedule(plan.Schedule)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("PlanUpdater.UpdatePlan: %w", err))
+	}
+	stmt.SetBytes("$schedule", schedule)
+	dependsOn, err := encodePlanDeps(plan.DependsOn)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeBug, fmt.Errorf("PlanUpdater.UpdatePlan: %w", err))
+	}
+	stmt.SetBytes("$depends_on", dependsOn)
 
 	sStmt, err := stmt.Prepare(conn)
 	if err != nil {
@@ -36,9 +36,26 @@ func (s sequenceUpdater) UpdateSequence(ctx context.Context, seq *workflow.Seque
 	}
 	defer s.pool.Put(conn)
 
+	attempts, err := encodeSeqAttempts(seq.Attempts.Get())
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("SequenceWriter.Write: %w", err))
+	}
+
+	approval, err := encodeApproval(seq.Approval)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("SequenceWriter.Write: %w", err))
+	}
+	locks, err := encodeLocks(seq.Locks)
+	if err != nil {
+		return errors.E(ctx, errors.CatInternal, errors.TypeStorageUpdate, fmt.Errorf("SequenceWriter.Write: %w", err))
+	}
+
 	stmt := Stmt{}
 	stmt.Query(updateSequence)
 	stmt.SetText("$id", seq.ID.String())
+	stmt.SetBytes("$approval", approval)
+	stmt.SetBytes("$locks", locks)
+	stmt.SetBytes("$attempts", attempts)
 	stmt.SetInt64("$state_status", int64(seq.State.Get().Status))
 	stmt.SetInt64("$state_start", seq.State.Get().Start.UnixNano())
 	stmt.SetInt64("$state_end", seq.State.Get().End.UnixNano())
@@ -6,12 +6,15 @@ SET
 	reason = $reason,
 	state_status = $state_status,
 	state_start = $state_start,
-	state_end = $state_end
+	state_end = $state_end,
+	schedule = $schedule,
+	depends_on = $depends_on
 WHERE id = $id`
 
 const updateChecks = `
 UPDATE checks
 SET
+	attempts = $attempts,
 	state_status = $state_status,
 	state_start = $state_start,
 	state_end = $state_end
@@ -20,6 +23,8 @@ WHERE id = $id`
 const updateBlock = `
 UPDATE blocks
 SET
+	approval = $approval,
+	locks = $locks,
 	state_status = $state_status,
 	state_start = $state_start,
 	state_end = $state_end
@@ -28,6 +33,9 @@ WHERE id = $id`
 const updateSequence = `
 UPDATE sequences
 SET
+	approval = $approval,
+	locks = $locks,
+	attempts = $attempts,
 	state_status = $state_status,
 	state_start = $state_start,
 	state_end = $state_end
@@ -0,0 +1,23 @@
+package workflow
+
+import "fmt"
+
+// validateUndos validates that only an Action in a Sequence has an Undo. As the Undo of an Action
+// is not in a Sequence, this also stops an Undo from having its own Undo.
+func validateUndos(p *Plan) error {
+	inSeqs := map[*Action]bool{}
+	for _, b := range p.Blocks {
+		for _, s := range b.Sequences {
+			for _, a := range s.Actions {
+				inSeqs[a] = true
+			}
+		}
+	}
+
+	for _, a := range planActions(p) {
+		if !inSeqs[a] && a.Undo != nil {
+			return fmt.Errorf("Action(%s): only an Action in a Sequence can have an Undo", a.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"testing"
+)
+
+func TestValidateUndos(t *testing.T) {
+	t.Parallel()
+
+	seqPlan := func(a *Action) *Plan {
+		return &Plan{
+			Blocks: []*Block{
+				{Sequences: []*Sequence{{Actions: []*Action{a}}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		plan *Plan
+		err  bool
+	}{
+		{
+			name: "Success: no Undo",
+			plan: seqPlan(&Action{}),
+		},
+		{
+			name: "Success: Action in a Sequence has an Undo",
+			plan: seqPlan(&Action{Undo: &Action{}}),
+		},
+		{
+			name: "Error: Undo has an Undo",
+			plan: seqPlan(&Action{Undo: &Action{Undo: &Action{}}}),
+			err:  true,
+		},
+		{
+			name: "Error: Checks Action has an Undo",
+			plan: &Plan{
+				PreChecks: &Checks{Actions: []*Action{{Undo: &Action{}}}},
+				Blocks:    []*Block{{Sequences: []*Sequence{{Actions: []*Action{{}}}}}},
+			},
+			err: true,
+		},
+		{
+			name: "Error: DeferBatch Action has an Undo",
+			plan: &Plan{
+				DeferredActions: &DeferredActions{
+					DeferredBatches: []*DeferBatch{{Sequence: Sequence{Actions: []*Action{{Undo: &Action{}}}}}},
+				},
+				Blocks: []*Block{{Sequences: []*Sequence{{Actions: []*Action{{}}}}}},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateUndos(test.plan)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidateUndos(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidateUndos(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package clone
 
 import (
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/gostdlib/base/context"
@@ -36,8 +37,8 @@ func WithKeepSecrets() Option {
 	}
 }
 
-// WithRemoveCompletedSequences removes Sequences that are Completed from Blocks.
-// If a Block contains only Completed Sequences, the Block is removed as long as
+// WithRemoveCompletedSequences removes Sequences that are Completed from Blocks. Actions that were
+// Skipped are removed like Completed Actions. If a Block contains only Completed Sequences, the Block is removed as long as
 // all PreChecks, PostChecks have completed and ContChecks are not in a failed state.
 // If no Blocks exist and all the Plan checks are in a state similar to above, a returned Plan will be nil.
 func WithRemoveCompletedSequences() Option {
@@ -83,10 +84,15 @@ func Plan(ctx context.Context, p *workflow.Plan, options ...Option) *workflow.Pl
 	copy(meta, p.Meta)
 
 	np := &workflow.Plan{
-		Name:    p.Name,
-		Descr:   p.Descr,
-		GroupID: p.GroupID,
-		Meta:    meta,
+		Name:        p.Name,
+		Descr:       p.Descr,
+		GroupID:     p.GroupID,
+		Meta:        meta,
+		Concurrency: p.Concurrency,
+		Timeout:     p.Timeout,
+		Priority:    p.Priority,
+		Windows:     cloneWindows(p.Windows),
+		DependsOn:   clonePlanDeps(p.DependsOn, opts.keepState),
 	}
 
 	if opts.keepState {
@@ -94,6 +100,10 @@ func Plan(ctx context.Context, p *workflow.Plan, options ...Option) *workflow.Pl
 		np.Reason = p.Reason
 		cloneStateAtomic(&np.State, &p.State)
 		np.SubmitTime = p.SubmitTime
+		if p.Schedule != nil {
+			sched := *p.Schedule
+			np.Schedule = &sched
+		}
 	}
 
 	if p.BypassChecks != nil {
@@ -172,13 +182,22 @@ func Checks(ctx context.Context, c *workflow.Checks, options ...Option) *workflo
 	opts.callNum++
 
 	clone := &workflow.Checks{
-		Delay:   c.Delay,
-		Actions: make([]*workflow.Action, len(c.Actions)),
+		Delay:        c.Delay,
+		WaitUntil:    c.WaitUntil,
+		SoakDuration: c.SoakDuration,
+		Actions:      make([]*workflow.Action, len(c.Actions)),
+	}
+	if c.FailureThreshold != nil {
+		f := *c.FailureThreshold
+		clone.FailureThreshold = &f
 	}
 
 	if opts.keepState {
 		clone.ID = c.ID
 		cloneStateAtomic(&clone.State, &c.State)
+		if attempts := cloneSeqAttempts(c.Attempts.Get()); len(attempts) > 0 {
+			clone.Attempts.Set(attempts)
+		}
 	}
 
 	for i := 0; i < len(c.Actions); i++ {
@@ -205,13 +224,25 @@ func Block(ctx context.Context, b *workflow.Block, options ...Option) *workflow.
 	opts.callNum++
 
 	n := &workflow.Block{
-		Name:              b.Name,
-		Descr:             b.Descr,
-		EntranceDelay:     b.EntranceDelay,
-		ExitDelay:         b.ExitDelay,
-		Concurrency:       b.Concurrency,
-		ToleratedFailures: b.ToleratedFailures,
-	}
+		Name:                    b.Name,
+		Descr:                   b.Descr,
+		EntranceDelay:           b.EntranceDelay,
+		ExitDelay:               b.ExitDelay,
+		When:                    b.When,
+		DependsOn:               slices.Clone(b.DependsOn),
+		Concurrency:             b.Concurrency,
+		ToleratedFailures:       b.ToleratedFailures,
+		ToleratedFailurePercent: b.ToleratedFailurePercent,
+		Timeout:                 b.Timeout,
+	}
+	if b.Ramp != nil {
+		r := *b.Ramp
+		r.Steps = slices.Clone(b.Ramp.Steps)
+		n.Ramp = &r
+	}
+	n.Approval = cloneApproval(b.Approval, opts.keepState)
+	n.Windows = cloneWindows(b.Windows)
+	n.Locks = cloneLocks(b.Locks, opts.keepState)
 
 	if opts.keepState {
 		n.ID = b.ID
@@ -255,6 +286,9 @@ func Block(ctx context.Context, b *workflow.Block, options ...Option) *workflow.
 		}
 		n.PostChecks = Checks(ctx, b.PostChecks, withOptions(opts))
 	}
+	if b.WaveChecks != nil {
+		n.WaveChecks = Checks(ctx, b.WaveChecks, withOptions(opts))
+	}
 	if b.DeferredChecks != nil {
 		state := b.DeferredChecks.State.Get()
 		if state.Status == workflow.Completed {
@@ -262,6 +296,9 @@ func Block(ctx context.Context, b *workflow.Block, options ...Option) *workflow.
 		}
 		n.DeferredChecks = Checks(ctx, b.DeferredChecks, withOptions(opts))
 	}
+	if b.DeferredActions != nil {
+		n.DeferredActions = DeferredActions(ctx, b.DeferredActions, withOptions(opts))
+	}
 
 	n.Sequences = make([]*workflow.Sequence, 0, len(b.Sequences))
 	for _, seq := range b.Sequences {
@@ -300,12 +337,23 @@ func Sequence(ctx context.Context, s *workflow.Sequence, options ...Option) *wor
 	ns := &workflow.Sequence{
 		Name:    s.Name,
 		Descr:   s.Descr,
+		When:    s.When,
 		Actions: make([]*workflow.Action, len(s.Actions)),
+		Retries: s.Retries,
+	}
+	if s.RetryPolicy != nil {
+		p := *s.RetryPolicy
+		ns.RetryPolicy = &p
 	}
+	ns.Approval = cloneApproval(s.Approval, opts.keepState)
+	ns.Locks = cloneLocks(s.Locks, opts.keepState)
 
 	if opts.keepState {
 		ns.ID = s.ID
 		cloneStateAtomic(&ns.State, &s.State)
+		if attempts := cloneSeqAttempts(s.Attempts.Get()); len(attempts) > 0 {
+			ns.Attempts.Set(attempts)
+		}
 	}
 
 	for i, a := range s.Actions {
@@ -315,6 +363,9 @@ func Sequence(ctx context.Context, s *workflow.Sequence, options ...Option) *wor
 		}
 		ns.Actions[i] = na
 	}
+	if s.DeferredActions != nil {
+		ns.DeferredActions = DeferredActions(ctx, s.DeferredActions, withOptions(opts))
+	}
 
 	if len(ns.Actions) == 0 {
 		return nil
@@ -419,8 +470,11 @@ func Action(ctx context.Context, a *workflow.Action, options ...Option) *workflo
 	}
 	opts.callNum++
 
-	if opts.removeCompleted && a.State.Get().Status == workflow.Completed {
-		return nil
+	if opts.removeCompleted {
+		switch a.State.Get().Status {
+		case workflow.Completed, workflow.Skipped:
+			return nil
+		}
 	}
 
 	na := &workflow.Action{
@@ -429,7 +483,9 @@ func Action(ctx context.Context, a *workflow.Action, options ...Option) *workflo
 		Plugin:  a.Plugin,
 		Timeout: a.Timeout,
 		Retries: a.Retries,
+		When:    a.When,
 		Req:     deep.MustCopy(a.Req),
+		Undo:    Action(ctx, a.Undo, withOptions(opts)),
 	}
 
 	if opts.keepState {
@@ -462,6 +518,67 @@ func cloneStateAtomic(dst, src *workflow.AtomicValue[workflow.State]) {
 	})
 }
 
+// cloneApproval clones a *workflow.Approval. The Decision is only kept if keepState is set.
+func cloneApproval(a *workflow.Approval, keepState bool) *workflow.Approval {
+	if a == nil {
+		return nil
+	}
+	na := &workflow.Approval{Key: a.Key, Expiry: a.Expiry}
+	if d := a.Decision.Get(); keepState && d != (workflow.Decision{}) {
+		na.Decision.Set(d)
+	}
+	return na
+}
+
+// cloneLocks clones a *workflow.Locks. Held is only kept if keepState is set.
+func cloneLocks(l *workflow.Locks, keepState bool) *workflow.Locks {
+	if l == nil {
+		return nil
+	}
+	nl := &workflow.Locks{Shared: slices.Clone(l.Shared), Exclusive: slices.Clone(l.Exclusive)}
+	if keepState && l.Held.Get() {
+		nl.Held.Set(true)
+	}
+	return nl
+}
+
+// clonePlanDeps clones the DependsOn of a Plan. The Plans of each PlanDep are only kept if keepState is set.
+func clonePlanDeps(deps []*workflow.PlanDep, keepState bool) []*workflow.PlanDep {
+	if deps == nil {
+		return nil
+	}
+	n := make([]*workflow.PlanDep, 0, len(deps))
+	for _, d := range deps {
+		if d == nil {
+			n = append(n, nil)
+			continue
+		}
+		nd := &workflow.PlanDep{ID: d.ID, GroupID: d.GroupID, On: d.On}
+		if keepState {
+			nd.Plans = slices.Clone(d.Plans)
+		}
+		n = append(n, nd)
+	}
+	return n
+}
+
+// cloneWindows clones a *workflow.Windows.
+func cloneWindows(w *workflow.Windows) *workflow.Windows {
+	if w == nil {
+		return nil
+	}
+	nw := &workflow.Windows{
+		Location:    w.Location,
+		Blackouts:   slices.Clone(w.Blackouts),
+		StopOnClose: w.StopOnClose,
+	}
+	for _, a := range w.Allow {
+		a.Days = slices.Clone(a.Days)
+		nw.Allow = append(nw.Allow, a)
+	}
+	return nw
+}
+
 // cloneAttempts clones a []workflow.Attempt.
 func cloneAttempts(attempts []workflow.Attempt) []workflow.Attempt {
 	if len(attempts) == 0 {
@@ -481,6 +598,28 @@ func cloneAttempts(attempts []workflow.Attempt) []workflow.Attempt {
 	return sl
 }
 
+// cloneSeqAttempts clones a []workflow.SeqAttempt.
+func cloneSeqAttempts(attempts []workflow.SeqAttempt) []workflow.SeqAttempt {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	sl := make([]workflow.SeqAttempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		na := workflow.SeqAttempt{
+			Actions: make([]workflow.ActionResult, 0, len(attempt.Actions)),
+			Start:   attempt.Start,
+			End:     attempt.End,
+		}
+		for _, r := range attempt.Actions {
+			r.Err = cloneErr(r.Err)
+			na.Actions = append(na.Actions, r)
+		}
+		sl = append(sl, na)
+	}
+	return sl
+}
+
 // cloneErr clones a *plugins.Err.
 func cloneErr(e *plugins.Error) *plugins.Error {
 	if e == nil {
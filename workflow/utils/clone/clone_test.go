@@ -471,6 +471,7 @@ func TestSequence(t *testing.T) {
 		panic(err)
 	}
 
+	policy := workflow.DefaultRetryPolicy()
 	sequence := &workflow.Sequence{
 		ID:    id,
 		Name:  "name",
@@ -481,7 +482,16 @@ func TestSequence(t *testing.T) {
 				Req:  Req{Data: "Hello"},
 			},
 		},
+		Retries:     1,
+		RetryPolicy: &policy,
 	}
+	sequence.Attempts.Set([]workflow.SeqAttempt{
+		{
+			Actions: []workflow.ActionResult{
+				{Status: workflow.Failed, Attempts: 1, Err: &plugins.Error{Message: "error"}},
+			},
+		},
+	})
 	sequence.State.Set(workflow.State{
 		Status: workflow.Completed,
 	})
@@ -510,6 +520,8 @@ func TestSequence(t *testing.T) {
 						Req:  Req{Data: SecureStr},
 					},
 				},
+				Retries:     1,
+				RetryPolicy: &policy,
 			},
 		},
 		{
@@ -538,6 +550,8 @@ func TestSequence(t *testing.T) {
 						Req:  Req{"Hello"},
 					},
 				},
+				Retries:     1,
+				RetryPolicy: &policy,
 			},
 		},
 	}
@@ -556,6 +570,11 @@ func TestSequence(t *testing.T) {
 		if diff := pretty.Compare(test.want, got); diff != "" {
 			t.Errorf("TestSequnce(%s): -want/+got:\n%s", test.name, diff)
 		}
+		if test.want != nil {
+			if diff := pretty.Compare(test.want.Attempts.Get(), got.Attempts.Get()); diff != "" {
+				t.Errorf("TestSequnce(%s): Attempts -want/+got:\n%s", test.name, diff)
+			}
+		}
 
 		if test.want != nil {
 			test.want.Actions[0].Req = oldReq
@@ -1106,3 +1125,146 @@ func TestCloneErr(t *testing.T) {
 		}
 	}
 }
+
+func TestCloneApproval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	approval := &workflow.Approval{Key: "deploy", Expiry: time.Hour}
+	approval.Decision.Set(workflow.Decision{Status: workflow.APApproved, Approver: "operator", Requested: now, Decided: now})
+
+	tests := []struct {
+		name      string
+		a         *workflow.Approval
+		keepState bool
+		want      workflow.Decision
+	}{
+		{
+			name: "Decision removed",
+			a:    approval,
+		},
+		{
+			name:      "Decision kept",
+			a:         approval,
+			keepState: true,
+			want:      approval.Decision.Get(),
+		},
+	}
+
+	if got := cloneApproval(nil, true); got != nil {
+		t.Errorf("TestCloneApproval(nil): got %+v, want nil", got)
+	}
+	for _, test := range tests {
+		got := cloneApproval(test.a, test.keepState)
+		if got == test.a {
+			t.Errorf("TestCloneApproval(%s): got the same pointer, want a copy", test.name)
+		}
+		if got.Key != test.a.Key || got.Expiry != test.a.Expiry {
+			t.Errorf("TestCloneApproval(%s): got (%s, %v), want (%s, %v)", test.name, got.Key, got.Expiry, test.a.Key, test.a.Expiry)
+		}
+		if diff := pretty.Compare(test.want, got.Decision.Get()); diff != "" {
+			t.Errorf("TestCloneApproval(%s): Decision -want/+got:\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestCloneWindows(t *testing.T) {
+	t.Parallel()
+
+	windows := &workflow.Windows{
+		Location:    "America/New_York",
+		Allow:       []workflow.Window{{Days: []time.Weekday{time.Saturday}, Start: 22 * time.Hour, End: 2 * time.Hour}},
+		Blackouts:   []string{"2025-12-25"},
+		StopOnClose: true,
+	}
+
+	if got := cloneWindows(nil); got != nil {
+		t.Errorf("TestCloneWindows(nil): got %+v, want nil", got)
+	}
+
+	got := cloneWindows(windows)
+	if diff := pretty.Compare(windows, got); diff != "" {
+		t.Errorf("TestCloneWindows: -want/+got:\n%s", diff)
+	}
+	got.Allow[0].Days[0] = time.Sunday
+	got.Blackouts[0] = "2026-12-25"
+	if windows.Allow[0].Days[0] != time.Saturday || windows.Blackouts[0] != "2025-12-25" {
+		t.Errorf("TestCloneWindows: changing the clone changed the original")
+	}
+}
+
+func TestCloneLocks(t *testing.T) {
+	t.Parallel()
+
+	locks := &workflow.Locks{Shared: []string{"region"}, Exclusive: []string{"cluster"}}
+	locks.Held.Set(true)
+
+	tests := []struct {
+		name      string
+		keepState bool
+		wantHeld  bool
+	}{
+		{name: "Held removed"},
+		{name: "Held kept", keepState: true, wantHeld: true},
+	}
+
+	if got := cloneLocks(nil, true); got != nil {
+		t.Errorf("TestCloneLocks(nil): got %+v, want nil", got)
+	}
+	for _, test := range tests {
+		got := cloneLocks(locks, test.keepState)
+		if diff := pretty.Compare(locks.Names(), got.Names()); diff != "" {
+			t.Errorf("TestCloneLocks(%s): Names -want/+got:\n%s", test.name, diff)
+		}
+		if got.Held.Get() != test.wantHeld {
+			t.Errorf("TestCloneLocks(%s): Held got %v, want %v", test.name, got.Held.Get(), test.wantHeld)
+		}
+		got.Exclusive[0] = "other"
+		if locks.Exclusive[0] != "cluster" {
+			t.Errorf("TestCloneLocks(%s): changing the clone changed the original", test.name)
+		}
+	}
+}
+
+func TestClonePlanDeps(t *testing.T) {
+	t.Parallel()
+
+	dep := workflow.NewV7()
+	deps := []*workflow.PlanDep{
+		{ID: dep, Plans: []uuid.UUID{dep}},
+		{GroupID: workflow.NewV7(), On: workflow.OCFail, Plans: []uuid.UUID{workflow.NewV7(), workflow.NewV7()}},
+	}
+
+	tests := []struct {
+		name      string
+		keepState bool
+	}{
+		{name: "Plans removed"},
+		{name: "Plans kept", keepState: true},
+	}
+
+	if got := clonePlanDeps(nil, true); got != nil {
+		t.Errorf("TestClonePlanDeps(nil): got %+v, want nil", got)
+	}
+	for _, test := range tests {
+		got := clonePlanDeps(deps, test.keepState)
+		if len(got) != len(deps) {
+			t.Fatalf("TestClonePlanDeps(%s): got %d PlanDeps, want %d", test.name, len(got), len(deps))
+		}
+		for i, d := range deps {
+			want := &workflow.PlanDep{ID: d.ID, GroupID: d.GroupID, On: d.On}
+			if test.keepState {
+				want.Plans = d.Plans
+			}
+			if diff := pretty.Compare(want, got[i]); diff != "" {
+				t.Errorf("TestClonePlanDeps(%s): PlanDep(%d) -want/+got:\n%s", test.name, i, diff)
+			}
+		}
+		if test.keepState {
+			got[0].Plans[0] = workflow.NewV7()
+			if deps[0].Plans[0] != dep {
+				t.Errorf("TestClonePlanDeps(%s): changing the clone changed the original", test.name)
+			}
+		}
+	}
+}
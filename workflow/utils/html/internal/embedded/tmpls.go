@@ -71,16 +71,18 @@ type completed struct {
 	Total int
 	// Completed is the number of items that are in a completed state.
 	Completed int
+	// Skipped is the number of items that were skipped.
+	Skipped int
 	// Failed is the number of items that are in a failed state.
 	Failed int
 	// Running is the number of items that are in a running state.
 	Running int
-	// Percent is the percentage of items that are in a completed state.
+	// Percent is the percentage of items that are in a completed or skipped state.
 	Percent int
 }
 
 func (c completed) Done() int {
-	return c.Completed + c.Failed
+	return c.Completed + c.Skipped + c.Failed
 }
 
 func (c completed) Color() template.HTMLAttr {
@@ -105,6 +107,8 @@ func calcCompleted[T supportedCalcs](v T) completed {
 			switch b.State.Get().Status {
 			case workflow.Completed:
 				c.Completed++
+			case workflow.Skipped:
+				c.Skipped++
 			case workflow.Running:
 				c.Running++
 			case workflow.Failed:
@@ -121,6 +125,8 @@ func calcCompleted[T supportedCalcs](v T) completed {
 				c.Failed++
 			case workflow.Completed:
 				c.Completed++
+			case workflow.Skipped:
+				c.Skipped++
 			}
 		}
 		c.Total = len(x.Sequences)
@@ -133,6 +139,8 @@ func calcCompleted[T supportedCalcs](v T) completed {
 				c.Failed++
 			case workflow.Completed:
 				c.Completed++
+			case workflow.Skipped:
+				c.Skipped++
 			}
 		}
 		c.Total = len(x.Actions)
@@ -145,6 +153,8 @@ func calcCompleted[T supportedCalcs](v T) completed {
 				c.Failed++
 			case workflow.Completed:
 				c.Completed++
+			case workflow.Skipped:
+				c.Skipped++
 			}
 		}
 		c.Total = len(x)
@@ -157,6 +167,8 @@ func calcCompleted[T supportedCalcs](v T) completed {
 				c.Failed++
 			case workflow.Completed:
 				c.Completed++
+			case workflow.Skipped:
+				c.Skipped++
 			}
 		}
 		c.Total = len(x.Actions)
@@ -164,7 +176,7 @@ func calcCompleted[T supportedCalcs](v T) completed {
 		panic("unsupported type")
 	}
 	if c.Total > 0 {
-		c.Percent = (c.Completed * 100) / c.Total
+		c.Percent = ((c.Completed + c.Skipped) * 100) / c.Total
 	}
 	return c
 }
@@ -201,6 +213,8 @@ func statusColor(s workflow.Status) template.HTMLAttr {
 		return template.HTMLAttr("red")
 	case workflow.Completed:
 		return template.HTMLAttr("green")
+	case workflow.Skipped:
+		return template.HTMLAttr("darkgoldenrod")
 	default:
 		return template.HTMLAttr("blue")
 	}
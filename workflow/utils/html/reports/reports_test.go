@@ -383,6 +383,192 @@ func TestRenderActionTemplates(t *testing.T) {
 	}
 }
 
+func TestRenderUndo(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	plan := makePlan(workflow.Failed)
+	action := plan.Blocks[0].Sequences[0].Actions[0]
+	action.Undo = makeAction("undo action1", workflow.Completed, 1, false)
+
+	fs, err := Render(ctx, plan)
+	if err != nil {
+		t.Fatalf("[TestRenderUndo]: Render failed: %s", err)
+	}
+
+	seqHTML, err := fs.ReadFile("sequences/" + plan.Blocks[0].Sequences[0].ID.String() + ".html")
+	if err != nil {
+		t.Fatalf("[TestRenderUndo]: failed to read sequence html: %s", err)
+	}
+	if !strings.Contains(string(seqHTML), "../actions/"+action.Undo.ID.String()+".html") {
+		t.Errorf("[TestRenderUndo]: sequence html does not link to the Undo")
+	}
+
+	actionHTML, err := fs.ReadFile("actions/" + action.ID.String() + ".html")
+	if err != nil {
+		t.Fatalf("[TestRenderUndo]: failed to read action html: %s", err)
+	}
+	if !strings.Contains(string(actionHTML), action.Undo.Name) {
+		t.Errorf("[TestRenderUndo]: action html does not contain Undo name %q", action.Undo.Name)
+	}
+
+	undoHTML, err := fs.ReadFile("actions/" + action.Undo.ID.String() + ".html")
+	if err != nil {
+		t.Fatalf("[TestRenderUndo]: failed to read Undo html: %s", err)
+	}
+	if !strings.Contains(string(undoHTML), action.Undo.Name) {
+		t.Errorf("[TestRenderUndo]: Undo html does not contain Undo name %q", action.Undo.Name)
+	}
+}
+
+func TestRenderSequenceRetries(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	plan := makePlan(workflow.Completed)
+	seq := plan.Blocks[0].Sequences[0]
+	action := seq.Actions[0]
+	seq.Retries = 2
+	seq.Attempts.Set(
+		[]workflow.SeqAttempt{
+			{
+				Actions: []workflow.ActionResult{
+					{
+						ID:       action.ID,
+						Status:   workflow.Failed,
+						Attempts: 1,
+						Err:      &plugins.Error{Message: "prior attempt failed"},
+						Start:    time.Now().Add(-2 * time.Minute),
+						End:      time.Now().Add(-time.Minute),
+					},
+				},
+				Start: time.Now().Add(-2 * time.Minute),
+				End:   time.Now().Add(-time.Minute),
+			},
+		},
+	)
+
+	fs, err := Render(ctx, plan)
+	if err != nil {
+		t.Fatalf("[TestRenderSequenceRetries]: Render failed: %s", err)
+	}
+
+	seqHTML, err := fs.ReadFile("sequences/" + seq.ID.String() + ".html")
+	if err != nil {
+		t.Fatalf("[TestRenderSequenceRetries]: failed to read sequence html: %s", err)
+	}
+	for _, want := range []string{"1 of 2", "Prior Attempts", "prior attempt failed"} {
+		if !strings.Contains(string(seqHTML), want) {
+			t.Errorf("[TestRenderSequenceRetries]: sequence html does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderChecksWaitUntil(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	plan := makePlan(workflow.Completed)
+	checks := plan.PreChecks
+	checks.Delay = 10 * time.Second
+	checks.WaitUntil = 5 * time.Minute
+	checks.Attempts.Set(
+		[]workflow.SeqAttempt{
+			{
+				Actions: []workflow.ActionResult{{ID: checks.Actions[0].ID, Status: workflow.Failed}},
+			},
+		},
+	)
+
+	fs, err := Render(ctx, plan)
+	if err != nil {
+		t.Fatalf("[TestRenderChecksWaitUntil]: Render failed: %s", err)
+	}
+
+	planHTML, err := fs.ReadFile("plan.html")
+	if err != nil {
+		t.Fatalf("[TestRenderChecksWaitUntil]: failed to read plan html: %s", err)
+	}
+	for _, want := range []string{"WaitUntil: 5m0s", "Delay: 10s", "Failed Rounds: 1"} {
+		if !strings.Contains(string(planHTML), want) {
+			t.Errorf("[TestRenderChecksWaitUntil]: plan html does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderContChecksRounds(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	plan := makePlan(workflow.Completed)
+	checks := plan.ContChecks
+	checks.FailureThreshold = &workflow.FailureThreshold{Consecutive: 3}
+	checks.Attempts.Set(
+		[]workflow.SeqAttempt{
+			{
+				Actions: []workflow.ActionResult{
+					{
+						ID:     checks.Actions[0].ID,
+						Status: workflow.Failed,
+						Err:    &plugins.Error{Message: "flaky health signal"},
+					},
+				},
+				Start: time.Now().Add(-time.Minute),
+				End:   time.Now().Add(-time.Minute),
+			},
+		},
+	)
+
+	fs, err := Render(ctx, plan)
+	if err != nil {
+		t.Fatalf("[TestRenderContChecksRounds]: Render failed: %s", err)
+	}
+
+	planHTML, err := fs.ReadFile("plan.html")
+	if err != nil {
+		t.Fatalf("[TestRenderContChecksRounds]: failed to read plan html: %s", err)
+	}
+	for _, want := range []string{"Fails After: 3 In A Row", "Failed Round", "flaky health signal"} {
+		if !strings.Contains(string(planHTML), want) {
+			t.Errorf("[TestRenderContChecksRounds]: plan html does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderApprovals(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	plan := makePlan(workflow.Completed)
+	block := plan.Blocks[0]
+	block.Approval = &workflow.Approval{Key: "block", Expiry: time.Hour}
+	block.Approval.Decision.Set(workflow.Decision{Status: workflow.APApproved, Approver: "operator"})
+	seq := block.Sequences[0]
+	seq.Approval = &workflow.Approval{Key: "sequence"}
+	seq.Approval.Decision.Set(workflow.Decision{Status: workflow.APRejected, Approver: "reviewer"})
+
+	fs, err := Render(ctx, plan)
+	if err != nil {
+		t.Fatalf("[TestRenderApprovals]: Render failed: %s", err)
+	}
+
+	planHTML, err := fs.ReadFile("plan.html")
+	if err != nil {
+		t.Fatalf("[TestRenderApprovals]: failed to read plan html: %s", err)
+	}
+	if want := "block: Approved by operator (Expiry: 1h0m0s)"; !strings.Contains(string(planHTML), want) {
+		t.Errorf("[TestRenderApprovals]: plan html does not contain %q", want)
+	}
+
+	seqHTML, err := fs.ReadFile("sequences/" + seq.ID.String() + ".html")
+	if err != nil {
+		t.Fatalf("[TestRenderApprovals]: failed to read sequence html: %s", err)
+	}
+	if want := "sequence: Rejected by reviewer"; !strings.Contains(string(seqHTML), want) {
+		t.Errorf("[TestRenderApprovals]: sequence html does not contain %q", want)
+	}
+}
+
 func TestRenderAllStatuses(t *testing.T) {
 	t.Parallel()
 
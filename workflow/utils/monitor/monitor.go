@@ -54,8 +54,8 @@ func runningSummary(p *workflow.Plan) string {
 	blockTitle.Fprintln(&buff, "\nBlock Summaries")
 	writeOtherBlocks(&buff, p.Blocks)
 
-	blockIndex, block := findRunningBlock(p.Blocks)
-	if block != nil {
+	for _, blockIndex := range findRunningBlocks(p.Blocks) {
+		block := p.Blocks[blockIndex]
 		blockTitle.Fprintln(&buff, fmt.Sprintf("\nRunning Block(%d): %s", blockIndex, block.Name))
 		writeRunningBlock(&buff, block)
 
@@ -63,6 +63,9 @@ func runningSummary(p *workflow.Plan) string {
 			blockTitle.Fprintln(&buff, fmt.Sprintf("\nRunning Sequence Actions: %s", seq.Name))
 			writeRunningActions(&buff, seq)
 		}
+		if block.DeferredActions != nil && block.DeferredActions.State.Get().Status == workflow.Running {
+			writeDeferredActions(&buff, blockTitle, block.DeferredActions)
+		}
 	}
 
 	if p.DeferredActions != nil {
@@ -88,13 +91,16 @@ func writeOtherBlocks(buff *strings.Builder, blocks []*workflow.Block) {
 	tbl.Print()
 }
 
-func findRunningBlock(blocks []*workflow.Block) (int, *workflow.Block) {
+// findRunningBlocks returns the indexes of the Blocks that are Running. More than one Block runs at a time
+// if the Plan has a Concurrency above 1.
+func findRunningBlocks(blocks []*workflow.Block) []int {
+	var found []int
 	for i, b := range blocks {
 		if b.State.Get().Status == workflow.Running {
-			return i, b
+			found = append(found, i)
 		}
 	}
-	return -1, nil
+	return found
 }
 
 func findRunningSeq(seq []*workflow.Sequence) []*workflow.Sequence {
@@ -111,15 +117,50 @@ func writeRunningBlock(buff *strings.Builder, block *workflow.Block) {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
-	tbl := table.New("Seq Number", "Desc", "Status").WithWriter(buff)
+	failed := 0
+	for _, seq := range block.Sequences {
+		if seq.State.Get().Status == workflow.Failed {
+			failed++
+		}
+	}
+	buff.WriteString(fmt.Sprintf("Failures: %d of %s tolerated\n", failed, toleratedFailures(block)))
+	if block.Approval != nil {
+		buff.WriteString(fmt.Sprintf("Approval: %s\n", approval(block.Approval)))
+	}
+
+	tbl := table.New("Seq Number", "Desc", "Status", "Approval").WithWriter(buff)
 	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
 
 	for i, seq := range block.Sequences {
-		tbl.AddRow(i, seq.Name, seq.State.Get().Status)
+		tbl.AddRow(i, seq.Name, seq.State.Get().Status, approval(seq.Approval))
 	}
 	tbl.Print()
 }
 
+// approval returns the decision on an Approval, as shown to the user.
+func approval(a *workflow.Approval) string {
+	if a == nil {
+		return "-"
+	}
+	d := a.Decision.Get()
+	if d.Approver != "" {
+		return fmt.Sprintf("%s: %s by %s", a.Key, d.Status, d.Approver)
+	}
+	return fmt.Sprintf("%s: %s", a.Key, d.Status)
+}
+
+// toleratedFailures returns the number of sequences in the block that can fail, as shown to the user.
+func toleratedFailures(block *workflow.Block) string {
+	n := block.MaxFailures()
+	switch {
+	case n < 0:
+		return "all"
+	case block.ToleratedFailurePercent > 0:
+		return fmt.Sprintf("%d (%d%%)", n, block.ToleratedFailurePercent)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
 func writeDeferredActions(buff *strings.Builder, title *color.Color, da *workflow.DeferredActions) {
 	title.Fprintln(buff, fmt.Sprintf("\nDeferredActions: %s", da.State.Get().Status))
 
@@ -199,6 +199,16 @@ func walkBlock(yield func(Item) bool, chain []workflow.Object, block *workflow.B
 			return false
 		}
 	}
+	if block.WaveChecks != nil {
+		if !walkChecks(yield, chain, block.WaveChecks) {
+			return false
+		}
+	}
+	if block.DeferredActions != nil {
+		if !walkDeferredActions(yield, chain, block.DeferredActions) {
+			return false
+		}
+	}
 	if block.DeferredChecks != nil {
 		if !walkChecks(yield, chain, block.DeferredChecks) {
 			return false
@@ -247,6 +257,17 @@ func walkSequence(yield func(Item) bool, chain []workflow.Object, sequence *work
 			if !yield(Item{Chain: chain, Value: action}) {
 				return false
 			}
+			// The Undo of an Action has the Action at the end of its chain.
+			if action.Undo != nil {
+				if !yield(Item{Chain: append(chain, action), Value: action.Undo}) {
+					return false
+				}
+			}
+		}
+	}
+	if sequence.DeferredActions != nil {
+		if !walkDeferredActions(yield, chain, sequence.DeferredActions) {
+			return false
 		}
 	}
 	return true
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/element-of-surprise/coercion/internal/when"
+)
+
+// WhenCond is a condition that decides if a Block, Sequence or Action runs. An empty WhenCond always runs.
+// The condition is a CEL (https://cel.dev) expression that must evaluate to a bool.
+//
+// These variables can be used:
+//   - meta is the Plan's Meta decoded as JSON. This is null if Meta is empty or is not JSON.
+//   - status has the Status, such as "Completed" or "Failed", of each Block, Sequence, Checks and Action
+//     in a Sequence that has a Key. It is keyed by the Key's string form.
+//   - resp has the response of the final Attempt of each Completed Action in a Sequence that has a Key,
+//     encoded to JSON and decoded. It is keyed by the Key's string form. JSON numbers are doubles.
+//
+// For example:
+//
+//	meta.env == "prod" && status["0191cd4b-6e3c-7c3d-9a6e-2fe1b1e4a6c1"] == "Completed"
+//
+// The condition is checked by Validate. If it cannot be evaluated when the object would start, such as
+// selecting a key that is not in a map, the object fails. Use has(a.b) or "b" in a to check for a key first.
+type WhenCond string
+
+// validate validates that the condition can be parsed and only uses known variables.
+func (w WhenCond) validate() error {
+	if w == "" {
+		return nil
+	}
+	if _, err := when.Parse(string(w)); err != nil {
+		return fmt.Errorf("When(%s): %w", w, err)
+	}
+	return nil
+}
+
+// validateWhens validates the When conditions of the Blocks, Sequences and Actions in p.
+// Only an Action in a Sequence can have a When condition.
+func validateWhens(p *Plan) error {
+	inSeqs := map[*Action]bool{}
+	for _, b := range p.Blocks {
+		if err := b.When.validate(); err != nil {
+			return fmt.Errorf("Block(%s): %w", b.Name, err)
+		}
+		for _, s := range b.Sequences {
+			if err := s.When.validate(); err != nil {
+				return fmt.Errorf("Sequence(%s): %w", s.Name, err)
+			}
+			for _, a := range s.Actions {
+				inSeqs[a] = true
+				if err := a.When.validate(); err != nil {
+					return fmt.Errorf("Action(%s): %w", a.Name, err)
+				}
+			}
+		}
+	}
+
+	for _, a := range planActions(p) {
+		if !inSeqs[a] && a.When != "" {
+			return fmt.Errorf("Action(%s): only an Action in a Sequence can have a When condition", a.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"testing"
+)
+
+func TestValidateWhens(t *testing.T) {
+	t.Parallel()
+
+	seqPlan := func(b WhenCond, s WhenCond, a WhenCond) *Plan {
+		return &Plan{
+			Blocks: []*Block{
+				{When: b, Sequences: []*Sequence{{When: s, Actions: []*Action{{When: a}}}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		plan *Plan
+		err  bool
+	}{
+		{
+			name: "Success: no conditions",
+			plan: seqPlan("", "", ""),
+		},
+		{
+			name: "Success: conditions on a Block, Sequence and Action",
+			plan: seqPlan(`meta.env == "prod"`, `status["a"] == "Completed"`, `resp["a"].count > 1`),
+		},
+		{
+			name: "Error: Block condition does not parse",
+			plan: seqPlan(`meta.env ==`, "", ""),
+			err:  true,
+		},
+		{
+			name: "Error: Sequence condition has an unknown variable",
+			plan: seqPlan("", `params.env == "prod"`, ""),
+			err:  true,
+		},
+		{
+			name: "Error: Action condition does not parse",
+			plan: seqPlan("", "", `(true`),
+			err:  true,
+		},
+		{
+			name: "Error: Checks Action has a condition",
+			plan: &Plan{
+				PreChecks: &Checks{Actions: []*Action{{When: "true"}}},
+				Blocks:    []*Block{{Sequences: []*Sequence{{Actions: []*Action{{}}}}}},
+			},
+			err: true,
+		},
+		{
+			name: "Error: Sequence DeferredActions Action has a condition",
+			plan: &Plan{
+				Blocks: []*Block{{Sequences: []*Sequence{{
+					Actions: []*Action{{}},
+					DeferredActions: &DeferredActions{
+						DeferredBatches: []*DeferBatch{{Sequence: Sequence{Actions: []*Action{{When: "true"}}}}},
+					},
+				}}}},
+			},
+			err: true,
+		},
+		{
+			name: "Error: Undo has a condition",
+			plan: &Plan{
+				Blocks: []*Block{{Sequences: []*Sequence{{Actions: []*Action{{Undo: &Action{When: "true"}}}}}}},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateWhens(test.plan)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestValidateWhens(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestValidateWhens(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Windows are the maintenance windows of a Plan or Block. A Block only starts while the Windows of its Plan
+// and its own Windows are open, otherwise it waits until they open. A Block that is running is not stopped
+// when a window closes, unless StopOnClose is set.
+type Windows struct {
+	// Location is the name of the time zone, as used by time.LoadLocation, that Allow and Blackouts are in.
+	// This defaults to UTC.
+	Location string `json:",omitempty"`
+	// Allow are the windows when work can start. If empty, work can start at any time that is not in
+	// Blackouts.
+	Allow []Window `json:",omitempty"`
+	// Blackouts are dates, in the form "2006-01-02", when no work can start, even if a window in Allow
+	// is open.
+	Blackouts []string `json:",omitempty"`
+	// StopOnClose fails the ContChecks of the Plan or Block when a window closes, which stops its work.
+	// Requires ContChecks.
+	StopOnClose bool `json:",omitempty"`
+}
+
+// Window is a time of day on some days of the week when work can start.
+type Window struct {
+	// Days are the days of the week that the window opens on. If empty, it opens every day.
+	Days []time.Weekday `json:",omitempty"`
+	// Start is the time of day that the window opens, measured from midnight.
+	Start time.Duration `json:",format:iso8601"`
+	// End is the time of day that the window closes, measured from midnight. If End is not after Start,
+	// the window closes on the day after it opens.
+	End time.Duration `json:",format:iso8601"`
+}
+
+// span is a time range that a Window is open, from start up to end.
+type span struct {
+	start, end time.Time
+}
+
+func (w *Windows) validate() error {
+	if len(w.Allow) == 0 && len(w.Blackouts) == 0 {
+		return fmt.Errorf("must have Allow or Blackouts")
+	}
+	if _, err := time.LoadLocation(w.Location); err != nil {
+		return fmt.Errorf("Location(%s): %w", w.Location, err)
+	}
+	for i, a := range w.Allow {
+		if a.Start < 0 || a.Start >= 24*time.Hour {
+			return fmt.Errorf("Allow[%d]: Start must be within a day", i)
+		}
+		if a.End < 0 || a.End > 24*time.Hour {
+			return fmt.Errorf("Allow[%d]: End must be within a day", i)
+		}
+		for _, d := range a.Days {
+			if d < time.Sunday || d > time.Saturday {
+				return fmt.Errorf("Allow[%d]: Day(%d) is not a day of the week", i, d)
+			}
+		}
+	}
+	for _, b := range w.Blackouts {
+		if _, err := time.Parse(time.DateOnly, b); err != nil {
+			return fmt.Errorf("Blackouts: %q is not a date in the form 2006-01-02", b)
+		}
+	}
+	return nil
+}
+
+// Open reports if the Windows are open at t.
+func (w *Windows) Open(t time.Time) bool {
+	return w.NextOpen(t).Equal(t)
+}
+
+// NextOpen returns the first time at or after t that the Windows are open. If they are open at t, this
+// returns t. A nil Windows is always open.
+func (w *Windows) NextOpen(t time.Time) time.Time {
+	if w == nil {
+		return t
+	}
+	loc := w.location()
+	day := midnight(t.In(loc))
+	for range w.horizon(day) {
+		for _, s := range w.spans(day) {
+			if s.end.After(t) {
+				if s.start.After(t) {
+					return s.start.In(t.Location())
+				}
+				return t
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// Closes returns the time that the Windows, which are open at t, close. If they are not open at t, this
+// returns t. If they never close, this returns the zero time.
+func (w *Windows) Closes(t time.Time) time.Time {
+	if w == nil || !w.Open(t) {
+		return t
+	}
+	loc := w.location()
+	end := t
+	day := midnight(t.In(loc))
+	for range w.horizon(day) {
+		// A day without a window that continues from the day before.
+		if end.Before(day) {
+			return end.In(t.Location())
+		}
+		for _, s := range w.spans(day) {
+			if s.start.After(end) {
+				return end.In(t.Location())
+			}
+			if s.end.After(end) {
+				end = s.end
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// spans returns the spans that the Windows are open within the day that starts at the midnight day,
+// sorted by when they start.
+func (w *Windows) spans(day time.Time) []span {
+	if slices.Contains(w.Blackouts, day.Format(time.DateOnly)) {
+		return nil
+	}
+	next := day.AddDate(0, 0, 1)
+	if len(w.Allow) == 0 {
+		return []span{{start: day, end: next}}
+	}
+
+	yesterday := (day.Weekday() + 6) % 7
+	var spans []span
+	for _, a := range w.Allow {
+		overnight := a.End <= a.Start
+		if a.on(day.Weekday()) {
+			s := span{start: day.Add(a.Start), end: day.Add(a.End)}
+			if overnight {
+				s.end = next
+			}
+			spans = append(spans, s)
+		}
+		// The part of an overnight window that opened yesterday.
+		if overnight && a.End > 0 && a.on(yesterday) {
+			spans = append(spans, span{start: day, end: day.Add(a.End)})
+		}
+	}
+	slices.SortFunc(spans, func(a, b span) int { return a.start.Compare(b.start) })
+	return spans
+}
+
+// horizon returns the number of days from the midnight day that must be looked at to find the next change
+// in the Windows. A window in Allow repeats every week, so this is a week past the last Blackout.
+func (w *Windows) horizon(day time.Time) int {
+	n := 8
+	for _, b := range w.Blackouts {
+		d, err := time.ParseInLocation(time.DateOnly, b, day.Location())
+		if err != nil {
+			continue
+		}
+		if days := int(d.Sub(day).Hours()/24) + 8; days > n {
+			n = days
+		}
+	}
+	return n
+}
+
+// location returns the time.Location of the Windows. This is UTC if the Location cannot be loaded, which
+// validation prevents.
+func (w *Windows) location() *time.Location {
+	loc, err := time.LoadLocation(w.Location)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// on reports if the Window opens on the day of the week d.
+func (w Window) on(d time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, d)
+}
+
+// midnight returns the start of the day of t in the Location of t.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		windows *Windows
+		err     bool
+	}{
+		{name: "Success: Allow", windows: &Windows{Allow: []Window{{Days: []time.Weekday{time.Monday}, Start: 9 * time.Hour, End: 17 * time.Hour}}}},
+		{name: "Success: overnight", windows: &Windows{Allow: []Window{{Start: 22 * time.Hour, End: 2 * time.Hour}}}},
+		{name: "Success: Blackouts", windows: &Windows{Blackouts: []string{"2025-12-25"}}},
+		{name: "Success: Location", windows: &Windows{Location: "America/New_York", Blackouts: []string{"2025-12-25"}}},
+		{name: "Error: empty", windows: &Windows{}, err: true},
+		{name: "Error: unknown Location", windows: &Windows{Location: "Nowhere/Town", Blackouts: []string{"2025-12-25"}}, err: true},
+		{name: "Error: Start is a day", windows: &Windows{Allow: []Window{{Start: 24 * time.Hour}}}, err: true},
+		{name: "Error: negative Start", windows: &Windows{Allow: []Window{{Start: -time.Hour}}}, err: true},
+		{name: "Error: End is past a day", windows: &Windows{Allow: []Window{{End: 25 * time.Hour}}}, err: true},
+		{name: "Error: not a day of the week", windows: &Windows{Allow: []Window{{Days: []time.Weekday{7}, End: time.Hour}}}, err: true},
+		{name: "Error: Blackout is not a date", windows: &Windows{Blackouts: []string{"12/25/2025"}}, err: true},
+	}
+
+	for _, test := range tests {
+		err := test.windows.validate()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestWindowsValidate(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestWindowsValidate(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+// date returns the time on a day in January 2025 in UTC. January 15th, 2025 is a Wednesday.
+func date(day, hour, min int) time.Time {
+	return time.Date(2025, time.January, day, hour, min, 0, 0, time.UTC)
+}
+
+var (
+	workdays = &Windows{
+		Allow: []Window{
+			{
+				Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				Start: 9 * time.Hour,
+				End:   17 * time.Hour,
+			},
+		},
+	}
+	saturdayNight = &Windows{Allow: []Window{{Days: []time.Weekday{time.Saturday}, Start: 22 * time.Hour, End: 2 * time.Hour}}}
+)
+
+func TestWindowsNextOpen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		windows *Windows
+		t       time.Time
+		want    time.Time
+	}{
+		{name: "nil is always open", t: date(15, 10, 0), want: date(15, 10, 0)},
+		{name: "open", windows: workdays, t: date(15, 10, 0), want: date(15, 10, 0)},
+		{name: "opens when the window starts", windows: workdays, t: date(15, 9, 0), want: date(15, 9, 0)},
+		{name: "closed when the window ends", windows: workdays, t: date(15, 17, 0), want: date(16, 9, 0)},
+		{name: "opens tomorrow", windows: workdays, t: date(15, 18, 0), want: date(16, 9, 0)},
+		{name: "opens after the weekend", windows: workdays, t: date(17, 18, 0), want: date(20, 9, 0)},
+		{name: "open overnight", windows: saturdayNight, t: date(19, 1, 0), want: date(19, 1, 0)},
+		{name: "opens next week", windows: saturdayNight, t: date(19, 3, 0), want: date(25, 22, 0)},
+		{
+			name:    "Blackouts only",
+			windows: &Windows{Blackouts: []string{"2025-01-15"}},
+			t:       date(15, 10, 0),
+			want:    date(16, 0, 0),
+		},
+		{
+			name: "Blackouts in Allow",
+			windows: &Windows{
+				Allow:     []Window{{Start: 9 * time.Hour, End: 17 * time.Hour}},
+				Blackouts: []string{"2025-01-15", "2025-01-16"},
+			},
+			t:    date(15, 10, 0),
+			want: date(17, 9, 0),
+		},
+		{
+			name:    "Location",
+			windows: &Windows{Location: "America/New_York", Allow: []Window{{Start: 9 * time.Hour, End: 10 * time.Hour}}},
+			t:       date(15, 13, 0),
+			want:    date(15, 14, 0),
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.windows.NextOpen(test.t); !got.Equal(test.want) {
+			t.Errorf("TestWindowsNextOpen(%s): got %v, want %v", test.name, got, test.want)
+		}
+		if got, want := test.windows.Open(test.t), test.t.Equal(test.want); got != want {
+			t.Errorf("TestWindowsNextOpen(%s): Open() got %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestWindowsCloses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		windows *Windows
+		t       time.Time
+		want    time.Time
+	}{
+		{name: "closes when the window ends", windows: workdays, t: date(15, 10, 0), want: date(15, 17, 0)},
+		{name: "not open", windows: workdays, t: date(15, 18, 0), want: date(15, 18, 0)},
+		{name: "overnight", windows: saturdayNight, t: date(18, 23, 0), want: date(19, 2, 0)},
+		{
+			name:    "at a Blackout",
+			windows: &Windows{Blackouts: []string{"2025-01-17"}},
+			t:       date(15, 10, 0),
+			want:    date(17, 0, 0),
+		},
+		{
+			name:    "windows that follow each other",
+			windows: &Windows{Allow: []Window{{Start: 12 * time.Hour, End: 15 * time.Hour}, {Start: 9 * time.Hour, End: 12 * time.Hour}}},
+			t:       date(15, 10, 0),
+			want:    date(15, 15, 0),
+		},
+		{
+			name:    "never closes",
+			windows: &Windows{Allow: []Window{{}}},
+			t:       date(15, 10, 0),
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.windows.Closes(test.t); !got.Equal(test.want) {
+			t.Errorf("TestWindowsCloses(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
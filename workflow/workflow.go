@@ -27,11 +27,28 @@ type Status int
 const (
 	// NotStarted represents an object that has not started execution.
 	NotStarted Status = 0 // NotStarted
+	// Scheduled represents an object that waits on its Schedule to be started. Only a Plan will have this
+	// status. See coercion.Workstream.StartAt and coercion.Workstream.Schedule.
+	Scheduled Status = 25 // Scheduled
+	// Waiting represents an object that has been started, but is waiting for the Plans in its DependsOn
+	// to finish before it runs. Only a Plan will have this status. See PlanDep.
+	Waiting Status = 40 // Waiting
+	// Queued represents an object that has been started, but is waiting for other Plans to finish
+	// before it runs. Only a Plan will have this status. See coercion.WithMaxRunningPlans.
+	Queued Status = 50 // Queued
 	// Running represents an object that is currently running.
 	Running Status = 100 // Running
+	// Paused represents an object that has been paused by a user action. Only a Plan will have this status.
+	// Work that was running when the Plan was paused is allowed to finish, but nothing new is started.
+	Paused Status = 120 // Paused
+	// Stopping represents an object that has been asked to stop by a user action, but has not
+	// finished stopping. Only a Plan will have this status.
+	Stopping Status = 150 // Stopping
 	// Completed represents an object that has completed successfully. For a Plan,
 	// this indicates a successful execution, but does not mean that the workflow did not have errors.
 	Completed Status = 200 // Completed
+	// Skipped represents an object that was not executed because its When condition was false.
+	Skipped Status = 250 // Skipped
 	// Failed represents an object that has failed.
 	Failed Status = 300 // Failed
 	// Stopped represents an object that has been stopped by a user action.
@@ -63,11 +80,27 @@ const (
 	FRDeferredAction FailureReason = 475 // DeferredAction
 	// FRStopped represents a failure reason that occurred because the workflow was stopped.
 	FRStopped FailureReason = 500 // Stopped
+	// FRTimeout represents a failure reason that occurred because the workflow or one of its
+	// blocks ran past its Timeout.
+	FRTimeout FailureReason = 550 // Timeout
+	// FRMissed represents a failure reason that occurred because a workflow scheduled with
+	// Workstream.StartAt missed its start while the Workstream was down and its CatchUp was CUSkip.
+	FRMissed FailureReason = 575 // Missed
 	// FRExceedRecovery represents a failure reason that occurred because the last update for
 	// a workflow was too long ago to do a recovery.
 	FRExceedRecovery FailureReason = 600 // ExceedRecovery
 )
 
+// Active reports if the Status is one where the object has started executing, but has not
+// reached a final Status.
+func (s Status) Active() bool {
+	switch s {
+	case Waiting, Queued, Running, Paused, Stopping:
+		return true
+	}
+	return false
+}
+
 // State represents the internal state of a workflow object.
 type State struct {
 	// Status is the status of the object.
@@ -167,12 +200,33 @@ type Plan struct {
 	// Plan is bypassed via BypassChecks, this will not run.
 	// Useful for logging and similar operations. Optional.
 	DeferredChecks *Checks
-	// Blocks is a list of blocks that are executed in sequence.
-	// If a block fails, the workflow will fail.
-	// Only one block can be executed at a time. Required.
+	// Blocks is a list of blocks that are executed. A block starts once the blocks in its DependsOn
+	// have finished and fewer than Concurrency blocks are running. Blocks are started in the order
+	// they are listed. If a block fails, no more blocks are started and the workflow will fail. Required.
 	Blocks []*Block
+	// Concurrency is the number of blocks that can be executed at the same time. This defaults to 1,
+	// which executes the blocks one at a time.
+	Concurrency int
+	// Timeout is the amount of time the workflow has to finish, measured from when it starts.
+	// Once it has passed, no more blocks or actions are started, running sequences are stopped
+	// after their current action and the workflow fails with FRTimeout. DeferredActions and
+	// DeferredChecks still run. This defaults to 0, which is no timeout.
+	Timeout time.Duration `json:",omitempty,format:iso8601"`
+	// Priority is the order that Queued Plans are run in when the number of running Plans is limited.
+	// Plans with a higher Priority run first and Plans with the same Priority run in the order they were
+	// started. This defaults to 0.
+	Priority int `json:",omitempty"`
+	// Windows are the maintenance windows of the workflow. Blocks only start while they are open. Optional.
+	Windows *Windows `json:",omitempty"`
+	// DependsOn are the other Plans that must finish before the workflow runs. Once it is started, the
+	// workflow is Waiting until they have finished. See PlanDep. Optional.
+	DependsOn []*PlanDep `json:",omitempty"`
+	// Schedule is when the workflow is started if it was scheduled with Workstream.StartAt or
+	// Workstream.Schedule. Should not be set by the user.
+	Schedule *Schedule `json:",omitempty"`
 
 	// State is the internal state of the object. Should not be set by the user.
+	// While the Plan is Queued, State.Start is the time that it was queued.
 	State AtomicValue[State]
 	// SubmitTime is the time that the object was submitted. This is only
 	// set for the Plan object
@@ -221,6 +275,9 @@ func (p *Plan) Defaults() {
 		return
 	}
 	p.ID = NewV7()
+	if p.Concurrency < 1 {
+		p.Concurrency = 1
+	}
 	p.State.Set(
 		State{
 			Status: NotStarted,
@@ -254,6 +311,32 @@ func (p *Plan) validate(ctx context.Context) ([]validator, error) {
 	if !p.SubmitTime.IsZero() {
 		return nil, errors.New("submit time should not be set by the user")
 	}
+	if p.Timeout < 0 {
+		return nil, errors.New("timeout cannot be negative")
+	}
+	if p.Schedule != nil {
+		return nil, errors.New("schedule should not be set by the user")
+	}
+	if err := validatePlanDeps(p.DependsOn); err != nil {
+		return nil, fmt.Errorf("DependsOn: %w", err)
+	}
+	if p.Windows != nil {
+		if err := p.Windows.validate(); err != nil {
+			return nil, fmt.Errorf("Windows: %w", err)
+		}
+		if p.Windows.StopOnClose && p.ContChecks == nil {
+			return nil, errors.New("Windows: StopOnClose requires ContChecks")
+		}
+	}
+	if err := noWaitUntil(p.ContChecks, p.BypassChecks); err != nil {
+		return nil, err
+	}
+	if err := noSoakDuration(p.BypassChecks, p.PreChecks, p.ContChecks, p.DeferredChecks); err != nil {
+		return nil, err
+	}
+	if err := noFailureThreshold(p.BypassChecks, p.PreChecks, p.PostChecks, p.DeferredChecks); err != nil {
+		return nil, err
+	}
 
 	vals := []validator{p.BypassChecks, p.PreChecks, p.ContChecks, p.PostChecks, p.DeferredChecks, p.DeferredActions}
 	for _, b := range p.Blocks {
@@ -271,14 +354,33 @@ type Checks struct {
 	// the checks. Optional.
 	Key uuid.UUID
 	// Delay is the amount of time to wait before executing the checks. This
-	// is only used by continuous checks. Optional. Defaults to 30 seconds.
+	// is only used by continuous checks and as the time between rounds of WaitUntil and
+	// SoakDuration checks. Optional. Defaults to 30 seconds.
 	Delay time.Duration `json:",format:iso8601"`
+	// WaitUntil makes the checks wait for a condition, such as a node becoming Ready. Instead of
+	// failing on the first failed round of Actions, the Actions are run again every Delay until
+	// they all succeed in the same round or WaitUntil has passed since the checks started. This
+	// requires a Delay and cannot be used on ContChecks or BypassChecks. Optional.
+	WaitUntil time.Duration `json:",omitempty,format:iso8601"`
+	// SoakDuration makes PostChecks watch that the work stays healthy for a while, such as 15 minutes
+	// after a deployment. The Actions are run every Delay until SoakDuration has passed since the first
+	// round started, and any failed round fails the checks. This requires a Delay, cannot be used with
+	// WaitUntil and can only be used on PostChecks. Optional.
+	SoakDuration time.Duration `json:",omitempty,format:iso8601"`
+	// FailureThreshold lets ContChecks tolerate failed rounds, such as from a flaky health signal,
+	// instead of failing on the first one. The rounds are counted from Attempts. This can only
+	// be used on ContChecks. Optional.
+	FailureThreshold *FailureThreshold `json:",omitempty"`
 	// Actions is a list of actions that are executed in parallel. Any error will
 	// cause the workflow to fail. Required.
 	Actions []*Action
 
 	// State represents the internal state of the object. Should not be set by the user.
 	State AtomicValue[State]
+	// Attempts are the rounds of a WaitUntil check that failed and were run again. For ContChecks,
+	// these are all the rounds that have run. Past 100 rounds, the oldest rounds that passed are
+	// dropped first. Should not be set by the user.
+	Attempts AtomicSlice[SeqAttempt] `json:",omitempty"`
 
 	planID uuid.UUID
 }
@@ -352,9 +454,29 @@ func (c *Checks) validate(ctx context.Context) ([]validator, error) {
 	if len(c.Actions) == 0 {
 		return nil, fmt.Errorf("at least one action is required")
 	}
-	if c.State.Get() != (State{}) {
+	if c.State.Get() != (State{}) || len(c.Attempts.Get()) != 0 {
 		return nil, fmt.Errorf("internal settings should not be set by the user")
 	}
+	if c.WaitUntil < 0 {
+		return nil, fmt.Errorf("WaitUntil cannot be negative")
+	}
+	if c.WaitUntil > 0 && c.Delay <= 0 {
+		return nil, fmt.Errorf("WaitUntil requires a Delay")
+	}
+	if c.SoakDuration < 0 {
+		return nil, fmt.Errorf("SoakDuration cannot be negative")
+	}
+	if c.SoakDuration > 0 && c.Delay <= 0 {
+		return nil, fmt.Errorf("SoakDuration requires a Delay")
+	}
+	if c.SoakDuration > 0 && c.WaitUntil > 0 {
+		return nil, fmt.Errorf("cannot set both WaitUntil and SoakDuration")
+	}
+	if c.FailureThreshold != nil {
+		if err := c.FailureThreshold.validate(); err != nil {
+			return nil, fmt.Errorf("FailureThreshold: %w", err)
+		}
+	}
 
 	vals := make([]validator, len(c.Actions))
 	for i := 0; i < len(c.Actions); i++ {
@@ -364,6 +486,73 @@ func (c *Checks) validate(ctx context.Context) ([]validator, error) {
 	return vals, nil
 }
 
+// noWaitUntil returns an error if the ContChecks or BypassChecks have a WaitUntil. ContChecks
+// already run until the object finishes and BypassChecks are expected to fail.
+func noWaitUntil(cont, bypass *Checks) error {
+	if cont != nil && cont.WaitUntil != 0 {
+		return errors.New("ContChecks cannot have a WaitUntil")
+	}
+	if bypass != nil && bypass.WaitUntil != 0 {
+		return errors.New("BypassChecks cannot have a WaitUntil")
+	}
+	return nil
+}
+
+// noSoakDuration returns an error if any of checks has a SoakDuration. Only PostChecks can soak.
+func noSoakDuration(checks ...*Checks) error {
+	for _, c := range checks {
+		if c != nil && c.SoakDuration != 0 {
+			return errors.New("only PostChecks can have a SoakDuration")
+		}
+	}
+	return nil
+}
+
+// noFailureThreshold returns an error if any of checks has a FailureThreshold. Only ContChecks
+// can tolerate failed rounds.
+func noFailureThreshold(checks ...*Checks) error {
+	for _, c := range checks {
+		if c != nil && c.FailureThreshold != nil {
+			return errors.New("only ContChecks can have a FailureThreshold")
+		}
+	}
+	return nil
+}
+
+// FailureThreshold sets how many failed rounds ContChecks tolerate before they fail. The checks
+// fail once any limit that is set is reached.
+//
+// As an example, FailureThreshold{Consecutive: 3, Failures: 5, Window: 10 * time.Minute} fails the
+// checks on the third failed round in a row or the fifth failed round within 10 minutes.
+type FailureThreshold struct {
+	// Consecutive fails the checks once this many rounds in a row have failed.
+	Consecutive int `json:",omitempty"`
+	// Failures fails the checks once this many rounds have failed within Window.
+	// This must be set with Window.
+	Failures int `json:",omitempty"`
+	// Window is the span of time in which Failures are counted. This must be set with Failures.
+	Window time.Duration `json:",omitempty,format:iso8601"`
+}
+
+func (f *FailureThreshold) validate() error {
+	if f.Consecutive < 0 {
+		return fmt.Errorf("Consecutive cannot be negative")
+	}
+	if f.Failures < 0 {
+		return fmt.Errorf("Failures cannot be negative")
+	}
+	if f.Window < 0 {
+		return fmt.Errorf("Window cannot be negative")
+	}
+	if (f.Failures > 0) != (f.Window > 0) {
+		return fmt.Errorf("Failures and Window must be set together")
+	}
+	if f.Consecutive == 0 && f.Failures == 0 {
+		return fmt.Errorf("must have Consecutive or Failures and Window")
+	}
+	return nil
+}
+
 // Block represents a set of replated work. It contains a list of sequences that are executed with
 // a configurable amount of concurrency. If a block fails, the workflow will fail. Only one block
 // can be executed at a time.
@@ -382,6 +571,13 @@ type Block struct {
 	EntranceDelay time.Duration `json:",format:iso8601"`
 	// ExitDelay is the amount of time to wait after the block has completed. This defaults to 0.
 	ExitDelay time.Duration `json:",format:iso8601"`
+	// When is a condition that is evaluated when the block would start. If it is false, the block
+	// and everything in it is Skipped. See WhenCond for the condition syntax. Optional.
+	When WhenCond `json:",omitempty"`
+	// DependsOn has the Keys of other blocks in the Plan that must finish before this block starts.
+	// A block has finished when it is Completed or Skipped. The blocks cannot depend on each other
+	// in a cycle. Optional.
+	DependsOn []uuid.UUID `json:",omitempty"`
 
 	// BypassChecks are actions that if they succeed will cause the block to be skipped.
 	// If any gate fails, the workflow will be executed. Optional.
@@ -394,20 +590,50 @@ type Block struct {
 	// PostChecks are actions that are executed after the block has completed.
 	// Any error will cause the block to fail. Optional.
 	PostChecks *Checks
+	// WaveChecks are actions that are executed between the waves of the Ramp, after the Soak.
+	// The next wave only starts if they succeed, any error will cause the block to fail.
+	// Requires Ramp. Optional.
+	WaveChecks *Checks
 	// DeferredChecks are actions that are executed after the workflow has completed.
 	// This is executed regardless of Block success or failure. However, if the
 	// Block is bypassed via BypassChecks, this will not run.
 	// Useful for logging and similar operations. Optional.
 	DeferredChecks *Checks
+	// DeferredActions are actions that are executed when the block ends, after DeferredChecks.
+	// If the Block is bypassed via BypassChecks or skipped, these will not run.
+	// This is useful for per block cleanup. Optional.
+	DeferredActions *DeferredActions
 
 	// Sequences is a list of sequences that are executed. Required..
 	Sequences []*Sequence
 
 	// Concurrency is the number of sequences that are executed in parallel. This defaults to 1.
 	Concurrency int
+	// Ramp rolls the sequences out in waves of growing size. Each wave still runs at most
+	// Concurrency sequences at a time. If not set, all sequences are in a single wave. Optional.
+	Ramp *Ramp `json:",omitempty"`
 	// ToleratedFailures is the number of sequences that are allowed to fail before the block fails. This defaults to 0.
 	// If set to -1, all sequences are allowed to fail.
 	ToleratedFailures int
+	// ToleratedFailurePercent is the percentage, from 0 to 100, of the sequences that are allowed to fail before
+	// the block fails. The number of sequences is rounded down. This is for blocks whose number of sequences
+	// changes, such as between environments. It cannot be set with ToleratedFailures. This defaults to 0,
+	// which uses ToleratedFailures.
+	ToleratedFailurePercent int `json:",omitempty"`
+	// Timeout is the amount of time the block has to finish, measured from when it starts. This does
+	// not include the EntranceDelay. Once it has passed, no more sequences are started, running
+	// sequences are stopped after their current action and the block fails. The block's
+	// DeferredChecks and DeferredActions still run. This defaults to 0, which is no timeout.
+	Timeout time.Duration `json:",omitempty,format:iso8601"`
+	// Approval is a manual gate that the block waits on after its BypassChecks and before its PreChecks.
+	// If it is rejected or expires, the block fails. Optional.
+	Approval *Approval `json:",omitempty"`
+	// Windows are the maintenance windows of the block. The block only starts while they and the Windows
+	// of the Plan are open. Optional.
+	Windows *Windows `json:",omitempty"`
+	// Locks are the resources that the block locks before it starts and holds until it finishes. Its
+	// Sequences cannot lock the same resources. Optional.
+	Locks *Locks `json:",omitempty"`
 
 	// State represents settings that should not be set by the user, but users can query.
 	State AtomicValue[State]
@@ -500,18 +726,156 @@ func (b *Block) validate(ctx context.Context) ([]validator, error) {
 		return nil, fmt.Errorf("at least one sequence is required")
 	}
 
-	vals := []validator{b.BypassChecks, b.PreChecks, b.ContChecks, b.PostChecks, b.DeferredChecks}
+	if b.Timeout < 0 {
+		return nil, fmt.Errorf("timeout cannot be negative")
+	}
+
+	if b.ToleratedFailurePercent < 0 || b.ToleratedFailurePercent > 100 {
+		return nil, fmt.Errorf("ToleratedFailurePercent must be between 0 and 100")
+	}
+	if b.ToleratedFailurePercent != 0 && b.ToleratedFailures != 0 {
+		return nil, fmt.Errorf("cannot set both ToleratedFailures and ToleratedFailurePercent")
+	}
+
+	if b.Ramp != nil {
+		if err := b.Ramp.validate(); err != nil {
+			return nil, fmt.Errorf("Ramp: %w", err)
+		}
+	}
+	if b.Approval != nil {
+		if err := b.Approval.validate(); err != nil {
+			return nil, fmt.Errorf("Approval: %w", err)
+		}
+	}
+	if b.Windows != nil {
+		if err := b.Windows.validate(); err != nil {
+			return nil, fmt.Errorf("Windows: %w", err)
+		}
+		if b.Windows.StopOnClose && b.ContChecks == nil {
+			return nil, fmt.Errorf("Windows: StopOnClose requires ContChecks")
+		}
+	}
+	if b.Locks != nil {
+		if err := b.Locks.validate(); err != nil {
+			return nil, fmt.Errorf("Locks: %w", err)
+		}
+	}
+	if err := validateSeqLocks(b); err != nil {
+		return nil, fmt.Errorf("Locks: %w", err)
+	}
+	if b.WaveChecks != nil && b.Ramp == nil {
+		return nil, fmt.Errorf("WaveChecks requires a Ramp")
+	}
+	if err := noWaitUntil(b.ContChecks, b.BypassChecks); err != nil {
+		return nil, err
+	}
+	if err := noSoakDuration(b.BypassChecks, b.PreChecks, b.ContChecks, b.WaveChecks, b.DeferredChecks); err != nil {
+		return nil, err
+	}
+	if err := noFailureThreshold(b.BypassChecks, b.PreChecks, b.PostChecks, b.WaveChecks, b.DeferredChecks); err != nil {
+		return nil, err
+	}
+
+	vals := []validator{b.BypassChecks, b.PreChecks, b.ContChecks, b.PostChecks, b.WaveChecks, b.DeferredChecks}
+	if b.DeferredActions != nil {
+		vals = append(vals, b.DeferredActions)
+	}
 	for _, seq := range b.Sequences {
 		vals = append(vals, seq)
 	}
 	return vals, nil
 }
 
+// MaxFailures returns the number of sequences that are allowed to fail before the block fails, using
+// ToleratedFailurePercent if it is set and ToleratedFailures if not. If all sequences are allowed to fail,
+// this returns -1.
+func (b *Block) MaxFailures() int {
+	if b.ToleratedFailurePercent > 0 {
+		if b.ToleratedFailurePercent == 100 {
+			return -1
+		}
+		return len(b.Sequences) * b.ToleratedFailurePercent / 100
+	}
+	if b.ToleratedFailures < 0 {
+		return -1
+	}
+	return b.ToleratedFailures
+}
+
 // self simply returns itself. This is here to allows use in a generic interface for equality operations.
 func (b *Block) self() *Block {
 	return b
 }
 
+// Ramp is a progressive rollout of the Sequences in a Block. The first wave is Canary sequences,
+// then each of the Steps is a wave that brings the total number of sequences that were started up to
+// that percentage of the Block. If the Steps do not reach 100, a last wave runs the rest. A wave only
+// starts after every sequence in the previous one has finished, the Soak has passed and the Block's
+// WaveChecks have succeeded.
+//
+// As an example, a Block with 100 sequences and Ramp{Canary: 1, Steps: []int{5, 25, 100}} runs waves of
+// 1, 4, 20 and 75 sequences.
+type Ramp struct {
+	// Canary is the number of sequences in the first wave. This defaults to 0, which is no canary.
+	Canary int `json:",omitempty"`
+	// Steps are the percentages, from 1 to 100, of the Block's sequences that have been started once
+	// each wave has started. They must increase. A step that would not start any new sequences is
+	// skipped. Optional.
+	Steps []int `json:",omitempty"`
+	// Soak is the amount of time to wait after a wave has finished before the next wave starts. This defaults to 0.
+	Soak time.Duration `json:",omitempty,format:iso8601"`
+}
+
+// Waves returns the index in the sequences one past the last sequence of each wave for a Block with
+// n sequences. The last entry is always n. A nil Ramp is a single wave.
+func (r *Ramp) Waves(n int) []int {
+	if r == nil || n == 0 {
+		return []int{n}
+	}
+
+	waves := []int{}
+	add := func(end int) {
+		end = min(end, n)
+		if len(waves) > 0 && end <= waves[len(waves)-1] {
+			return
+		}
+		if end > 0 {
+			waves = append(waves, end)
+		}
+	}
+
+	add(r.Canary)
+	for _, step := range r.Steps {
+		// This is the ceiling of n * step / 100, so that any step gets at least one sequence.
+		add((n*step + 99) / 100)
+	}
+	add(n)
+	return waves
+}
+
+func (r *Ramp) validate() error {
+	if r.Canary < 0 {
+		return fmt.Errorf("Canary cannot be negative")
+	}
+	if r.Soak < 0 {
+		return fmt.Errorf("Soak cannot be negative")
+	}
+	if r.Canary == 0 && len(r.Steps) == 0 {
+		return fmt.Errorf("must have a Canary or Steps")
+	}
+	last := 0
+	for _, step := range r.Steps {
+		if step < 1 || step > 100 {
+			return fmt.Errorf("Steps must be between 1 and 100, got %d", step)
+		}
+		if step <= last {
+			return fmt.Errorf("Steps must increase, got %d after %d", step, last)
+		}
+		last = step
+	}
+	return nil
+}
+
 // Sequence represents a set of Actions that are executed in sequence. Any error will cause the workflow to fail.
 type Sequence struct {
 	// ID is a unique identifier for the object. Should not be set by the user.
@@ -523,9 +887,32 @@ type Sequence struct {
 	Name string
 	// Descr is a description of the sequence. Required.
 	Descr string
+	// When is a condition that is evaluated when the sequence would start. If it is false, the sequence
+	// and its actions are Skipped. See WhenCond for the condition syntax. Optional.
+	When WhenCond `json:",omitempty"`
 	// Actions is a list of actions that are executed in sequence. Any error will cause the workflow to fail. Required.
 	Actions []*Action
-
+	// DeferredActions are actions that are executed after the sequence has completed. If the
+	// sequence is skipped, these will not run. Optional.
+	DeferredActions *DeferredActions
+	// Retries is the number of times to run the sequence again from its first Action if it fails.
+	// Before a retry, the Undos of the Actions that Completed are run, the failed run is recorded in
+	// Attempts and the Actions are reset. A sequence that is stopped is not retried. DeferredActions
+	// only run after the final run. This defaults to 0.
+	Retries int
+	// RetryPolicy is the backoff policy used to wait between retries. If not set,
+	// DefaultRetryPolicy() is used. Optional.
+	RetryPolicy *RetryPolicy `json:",omitempty"`
+	// Approval is a manual gate that the sequence waits on before its first Action. If it is rejected
+	// or expires, the sequence fails. It is not asked again when the sequence is retried. Optional.
+	Approval *Approval `json:",omitempty"`
+	// Locks are the resources that the sequence locks before it starts and holds until it finishes,
+	// including its retries. Optional.
+	Locks *Locks `json:",omitempty"`
+
+	// Attempts are the prior runs of the sequence that failed and were retried. The current run is
+	// in the State of the sequence and its Actions. This should not be set by the user.
+	Attempts AtomicSlice[SeqAttempt] `json:",omitempty"`
 	// State represents settings that should not be set by the user, but users can query.
 	State AtomicValue[State]
 
@@ -608,15 +995,40 @@ func (s *Sequence) validate(ctx context.Context) ([]validator, error) {
 	if s.State.Get() != (State{}) {
 		return nil, fmt.Errorf("internal settings should not be set by the user")
 	}
+	if len(s.Attempts.Get()) != 0 {
+		return nil, fmt.Errorf("attempts should not be set by the user")
+	}
+
+	if s.Retries < 0 {
+		s.Retries = 0
+	}
+	if s.RetryPolicy != nil {
+		if err := s.RetryPolicy.validate(); err != nil {
+			return nil, fmt.Errorf("RetryPolicy: %w", err)
+		}
+	}
+	if s.Approval != nil {
+		if err := s.Approval.validate(); err != nil {
+			return nil, fmt.Errorf("Approval: %w", err)
+		}
+	}
+	if s.Locks != nil {
+		if err := s.Locks.validate(); err != nil {
+			return nil, fmt.Errorf("Locks: %w", err)
+		}
+	}
 
 	if len(s.Actions) == 0 {
 		return nil, fmt.Errorf("at least one Action is required")
 	}
 
-	vals := make([]validator, 0, len(s.Actions))
+	vals := make([]validator, 0, len(s.Actions)+1)
 	for _, a := range s.Actions {
 		vals = append(vals, a)
 	}
+	if s.DeferredActions != nil {
+		vals = append(vals, s.DeferredActions)
+	}
 	return vals, nil
 }
 
@@ -625,6 +1037,91 @@ func (s *Sequence) self() *Sequence {
 	return s
 }
 
+// RetryPolicy is an exponential backoff policy for waiting between retries of a Sequence.
+type RetryPolicy struct {
+	// InitialInterval is how long to wait before the first retry. Must be greater than 0.
+	InitialInterval time.Duration `json:",format:iso8601"`
+	// Multiplier is multiplied against the wait after each retry to get the next wait. Must be at least 1.
+	Multiplier float64
+	// RandomizationFactor randomizes each wait by up to this fraction of the wait in either direction.
+	// This keeps Sequences that fail together from retrying together. Must be between 0 and 1.
+	RandomizationFactor float64
+	// MaxInterval is the longest wait between retries. Must be at least InitialInterval.
+	MaxInterval time.Duration `json:",format:iso8601"`
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by a Sequence that does not set one.
+//
+// progression will be:
+// 1s, 2s, 4s, 8s, 16s, 32s, 60s
+// Not counting a randomization factor which will be +/- up to 50% of the interval.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialInterval:     1 * time.Second,
+		Multiplier:          2,
+		RandomizationFactor: 0.5,
+		MaxInterval:         60 * time.Second,
+	}
+}
+
+func (r RetryPolicy) validate() error {
+	if r.InitialInterval <= 0 {
+		return fmt.Errorf("InitialInterval must be greater than 0")
+	}
+	if r.Multiplier < 1 {
+		return fmt.Errorf("Multiplier must be at least 1")
+	}
+	if r.RandomizationFactor < 0 || r.RandomizationFactor > 1 {
+		return fmt.Errorf("RandomizationFactor must be between 0 and 1")
+	}
+	if r.MaxInterval < r.InitialInterval {
+		return fmt.Errorf("MaxInterval must be at least InitialInterval")
+	}
+	return nil
+}
+
+// SeqAttempt is the record of a run of a Sequence that failed and was retried. It also records
+// a round of WaitUntil Checks that failed and was run again.
+// Nothing in SeqAttempt should be set by the user.
+type SeqAttempt struct {
+	// Actions are the results of the Actions in the run. These are in the same order as the
+	// Sequence's or Checks' Actions.
+	Actions []ActionResult
+
+	// Start is the time the run started.
+	Start time.Time
+	// End is the time the run ended.
+	End time.Time
+}
+
+// self simply returns itself. This is here to allows use in a generic interface for equality operations.
+func (s SeqAttempt) self() SeqAttempt {
+	return s
+}
+
+// ActionResult is the result of an Action in a SeqAttempt.
+// Nothing in ActionResult should be set by the user.
+type ActionResult struct {
+	// ID is the ID of the Action.
+	ID uuid.UUID
+	// Status is the status the Action ended the run with.
+	Status Status
+	// Attempts is the number of times the Action's plugin was called.
+	Attempts int
+	// Err is the error of the Action's final attempt. If this is nil, the final attempt did not fail.
+	Err *plugins.Error
+
+	// Start is the time the Action started.
+	Start time.Time
+	// End is the time the Action ended.
+	End time.Time
+}
+
+// self simply returns itself. This is here to allows use in a generic interface for equality operations.
+func (a ActionResult) self() ActionResult {
+	return a
+}
+
 // DeferredActions represents a set of Actions that are executed after a workflow element has completed.
 type DeferredActions struct {
 	// DeferredBatches is a list of batches that are executed after the workflow element has completed.
@@ -781,6 +1278,18 @@ func (d *DeferBatch) validate(ctx context.Context) ([]validator, error) {
 	if len(d.Actions) == 0 {
 		return nil, fmt.Errorf("at least one Action is required")
 	}
+	if d.DeferredActions != nil {
+		return nil, fmt.Errorf("DeferBatch object(%s): cannot have DeferredActions", d.Name)
+	}
+	if d.Retries != 0 || d.RetryPolicy != nil {
+		return nil, fmt.Errorf("DeferBatch object(%s): cannot have Retries or a RetryPolicy", d.Name)
+	}
+	if d.Approval != nil {
+		return nil, fmt.Errorf("DeferBatch object(%s): cannot have an Approval", d.Name)
+	}
+	if d.Locks != nil {
+		return nil, fmt.Errorf("DeferBatch object(%s): cannot have Locks", d.Name)
+	}
 
 	vals := make([]validator, 0, len(d.Actions))
 	for _, a := range d.Actions {
@@ -833,6 +1342,14 @@ type Action struct {
 	Retries int
 	// Req is the request object that is passed to the plugin.
 	Req any
+	// When is a condition that is evaluated when the action would start. If it is false, the action
+	// is Skipped. This can only be set on an action in a Sequence. See WhenCond for the condition syntax. Optional.
+	When WhenCond `json:",omitempty"`
+	// Undo is an Action that reverses what this Action did. If the Sequence or Block this Action is in fails,
+	// the Undo of each Action that Completed is run in the reverse order that the Actions finished. If an Undo
+	// fails, the Undos after it are not run. This can only be set on an Action in a Sequence and an Undo cannot
+	// have its own Undo or a When condition. Optional.
+	Undo *Action `json:",omitempty"`
 	// Attempts is the attempts of the action. This should not be set by the user.
 	Attempts AtomicSlice[Attempt] `json:",omitempty"`
 	// State represents settings that should not be set by the user, but users can query.
@@ -958,6 +1475,9 @@ func (a *Action) validate(ctx context.Context) ([]validator, error) {
 		return nil, fmt.Errorf("plugin %q: %w", a.Plugin, err)
 	}
 
+	if a.Undo != nil {
+		return []validator{a.Undo}, nil
+	}
 	return nil, nil
 }
 
@@ -1059,6 +1579,21 @@ func Validate(p *Plan) error {
 			q.push(vals...)
 		}
 	}
+	if err := validateDependsOn(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Plan validation: %w", err))
+	}
+	if err := validateInputs(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Plan validation: %w", err))
+	}
+	if err := validateWhens(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Plan validation: %w", err))
+	}
+	if err := validateUndos(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Plan validation: %w", err))
+	}
+	if err := validateApprovals(p); err != nil {
+		return errors.E(context.Background(), errors.CatUser, errors.TypeParameter, fmt.Errorf("Plan validation: %w", err))
+	}
 	return nil
 }
 
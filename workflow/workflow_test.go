@@ -3,6 +3,7 @@ package workflow
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/gostdlib/base/context"
 
@@ -100,6 +101,88 @@ func TestPlanValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "Error: Timeout is negative",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.Timeout = -time.Second
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: Schedule is set",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.Schedule = &Schedule{Cron: "@daily"}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: Windows is invalid",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.Windows = &Windows{Blackouts: []string{"12/25/2025"}}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: Windows StopOnClose without ContChecks",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.ContChecks = nil
+				p.Windows = &Windows{Allow: []Window{{Start: time.Hour, End: 2 * time.Hour}}, StopOnClose: true}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: PreChecks has a SoakDuration",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.PreChecks = &Checks{Delay: time.Second, SoakDuration: time.Minute}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: PostChecks has a FailureThreshold",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.PostChecks = &Checks{FailureThreshold: &FailureThreshold{Consecutive: 3}}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: ContChecks has a WaitUntil",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.ContChecks = &Checks{Delay: time.Second, WaitUntil: time.Minute}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: DependsOn is invalid",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.DependsOn = []*PlanDep{{}}
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Success: DependsOn",
+			plan: func() *Plan {
+				p := goodPlan()
+				p.DependsOn = []*PlanDep{{ID: NewV7()}, {GroupID: NewV7(), On: OCFail}}
+				return p
+			},
+			validators: expectVals,
+		},
 		{
 			name:       "Success",
 			plan:       goodPlan,
@@ -178,6 +261,72 @@ func TestPreCheckValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "Error: Attempts are set",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.Attempts.Set([]SeqAttempt{{}})
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: WaitUntil is negative",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.WaitUntil = -time.Second
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: WaitUntil without a Delay",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.WaitUntil = time.Minute
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Success: WaitUntil with a Delay",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.WaitUntil = time.Minute
+				p.Delay = time.Second
+				return p
+			},
+			vals: []validator{goodPreChecks().Actions[0]},
+		},
+		{
+			name: "Error: SoakDuration is negative",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.SoakDuration = -time.Second
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: SoakDuration without a Delay",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.SoakDuration = time.Minute
+				return p
+			},
+			err: true,
+		},
+		{
+			name: "Error: SoakDuration with a WaitUntil",
+			preCheck: func() *Checks {
+				p := goodPreChecks()
+				p.Delay = time.Second
+				p.SoakDuration = time.Minute
+				p.WaitUntil = time.Minute
+				return p
+			},
+			err: true,
+		},
 		{
 			name:     "Error: Duplicate Key",
 			preCheck: goodPreChecks,
@@ -478,6 +627,36 @@ func TestContCheckValidate(t *testing.T) {
 	}
 }
 
+func TestFailureThresholdValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold FailureThreshold
+		err       bool
+	}{
+		{name: "Success: Consecutive", threshold: FailureThreshold{Consecutive: 3}},
+		{name: "Success: Failures within Window", threshold: FailureThreshold{Failures: 5, Window: time.Minute}},
+		{name: "Success: both", threshold: FailureThreshold{Consecutive: 3, Failures: 5, Window: time.Minute}},
+		{name: "Error: empty", threshold: FailureThreshold{}, err: true},
+		{name: "Error: negative Consecutive", threshold: FailureThreshold{Consecutive: -1}, err: true},
+		{name: "Error: negative Failures", threshold: FailureThreshold{Failures: -1, Window: time.Minute}, err: true},
+		{name: "Error: negative Window", threshold: FailureThreshold{Failures: 5, Window: -time.Minute}, err: true},
+		{name: "Error: Failures without a Window", threshold: FailureThreshold{Failures: 5}, err: true},
+		{name: "Error: Window without Failures", threshold: FailureThreshold{Consecutive: 3, Window: time.Minute}, err: true},
+	}
+
+	for _, test := range tests {
+		err := test.threshold.validate()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestFailureThresholdValidate(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestFailureThresholdValidate(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
 func TestBlockValidate(t *testing.T) {
 	t.Parallel()
 
@@ -491,8 +670,10 @@ func TestBlockValidate(t *testing.T) {
 			PreChecks:      &Checks{},
 			PostChecks:     &Checks{},
 			ContChecks:     &Checks{},
+			WaveChecks:     &Checks{},
 			DeferredChecks: &Checks{},
 			Sequences:      []*Sequence{{}},
+			Ramp:           &Ramp{Canary: 1},
 		}
 		x := func(block Block) Block {
 			return block
@@ -557,6 +738,126 @@ func TestBlockValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "Error: Timeout is negative",
+			block: func() *Block {
+				b := goodBlock()
+				b.Timeout = -time.Second
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Approval is invalid",
+			block: func() *Block {
+				b := goodBlock()
+				b.Approval = &Approval{}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Windows is invalid",
+			block: func() *Block {
+				b := goodBlock()
+				b.Windows = &Windows{Allow: []Window{{Start: 25 * time.Hour}}}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Windows StopOnClose without ContChecks",
+			block: func() *Block {
+				b := goodBlock()
+				b.ContChecks = nil
+				b.Windows = &Windows{Allow: []Window{{Start: time.Hour, End: 2 * time.Hour}}, StopOnClose: true}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Locks is invalid",
+			block: func() *Block {
+				b := goodBlock()
+				b.Locks = &Locks{}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Sequence locks a resource of its Block",
+			block: func() *Block {
+				b := goodBlock()
+				b.Locks = &Locks{Exclusive: []string{"cluster"}}
+				b.Sequences[0].Locks = &Locks{Shared: []string{"cluster"}}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: WaveChecks has a SoakDuration",
+			block: func() *Block {
+				b := goodBlock()
+				b.WaveChecks = &Checks{Delay: time.Second, SoakDuration: time.Minute}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: PreChecks has a FailureThreshold",
+			block: func() *Block {
+				b := goodBlock()
+				b.PreChecks = &Checks{FailureThreshold: &FailureThreshold{Consecutive: 3}}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: BypassChecks has a WaitUntil",
+			block: func() *Block {
+				b := goodBlock()
+				b.BypassChecks = &Checks{Delay: time.Second, WaitUntil: time.Minute}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: ToleratedFailurePercent is over 100",
+			block: func() *Block {
+				b := goodBlock()
+				b.ToleratedFailurePercent = 101
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: ToleratedFailures and ToleratedFailurePercent are both set",
+			block: func() *Block {
+				b := goodBlock()
+				b.ToleratedFailures = 1
+				b.ToleratedFailurePercent = 10
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: Ramp is invalid",
+			block: func() *Block {
+				b := goodBlock()
+				b.Ramp = &Ramp{Steps: []int{50, 25}}
+				return b
+			},
+			err: true,
+		},
+		{
+			name: "Error: WaveChecks without a Ramp",
+			block: func() *Block {
+				b := goodBlock()
+				b.Ramp = nil
+				return b
+			},
+			err: true,
+		},
 		{
 			name:    "Error: Duplicate Key",
 			block:   goodBlock,
@@ -566,6 +867,7 @@ func TestBlockValidate(t *testing.T) {
 				goodBlock().PreChecks,
 				goodBlock().PostChecks,
 				goodBlock().ContChecks,
+				goodBlock().WaveChecks,
 				goodBlock().DeferredChecks,
 				goodBlock().Sequences[0],
 			},
@@ -579,6 +881,7 @@ func TestBlockValidate(t *testing.T) {
 				goodBlock().PreChecks,
 				goodBlock().PostChecks,
 				goodBlock().ContChecks,
+				goodBlock().WaveChecks,
 				goodBlock().DeferredChecks,
 				goodBlock().Sequences[0],
 			},
@@ -609,6 +912,84 @@ func TestBlockValidate(t *testing.T) {
 	}
 }
 
+func TestBlockMaxFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		block *Block
+		want  int
+	}{
+		{name: "ToleratedFailures", block: &Block{ToleratedFailures: 2}, want: 2},
+		{name: "ToleratedFailures is -1", block: &Block{ToleratedFailures: -1}, want: -1},
+		{name: "ToleratedFailurePercent rounds down", block: &Block{ToleratedFailurePercent: 25, Sequences: make([]*Sequence, 7)}, want: 1},
+		{name: "ToleratedFailurePercent of a small block", block: &Block{ToleratedFailurePercent: 10, Sequences: make([]*Sequence, 5)}, want: 0},
+		{name: "ToleratedFailurePercent is 100", block: &Block{ToleratedFailurePercent: 100, Sequences: make([]*Sequence, 5)}, want: -1},
+	}
+
+	for _, test := range tests {
+		if got := test.block.MaxFailures(); got != test.want {
+			t.Errorf("TestBlockMaxFailures(%s): got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRampValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ramp Ramp
+		err  bool
+	}{
+		{name: "Error: Canary is negative", ramp: Ramp{Canary: -1}, err: true},
+		{name: "Error: Soak is negative", ramp: Ramp{Canary: 1, Soak: -time.Second}, err: true},
+		{name: "Error: no Canary or Steps", ramp: Ramp{Soak: time.Second}, err: true},
+		{name: "Error: Step is 0", ramp: Ramp{Steps: []int{0, 50}}, err: true},
+		{name: "Error: Step is over 100", ramp: Ramp{Steps: []int{50, 101}}, err: true},
+		{name: "Error: Steps do not increase", ramp: Ramp{Steps: []int{50, 50}}, err: true},
+		{name: "Success: Canary only", ramp: Ramp{Canary: 1}},
+		{name: "Success", ramp: Ramp{Canary: 1, Steps: []int{5, 25, 100}, Soak: time.Minute}},
+	}
+
+	for _, test := range tests {
+		err := test.ramp.validate()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("TestRampValidate(%s): got err == nil, want err != nil", test.name)
+		case !test.err && err != nil:
+			t.Errorf("TestRampValidate(%s): got err == %s, want err == nil", test.name, err)
+		}
+	}
+}
+
+func TestRampWaves(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ramp *Ramp
+		n    int
+		want []int
+	}{
+		{name: "nil Ramp", n: 10, want: []int{10}},
+		{name: "no sequences", ramp: &Ramp{Canary: 1}, n: 0, want: []int{0}},
+		{name: "canary then the rest", ramp: &Ramp{Canary: 1}, n: 10, want: []int{1, 10}},
+		{name: "canary and steps", ramp: &Ramp{Canary: 1, Steps: []int{5, 25, 100}}, n: 100, want: []int{1, 5, 25, 100}},
+		{name: "steps do not reach 100", ramp: &Ramp{Steps: []int{10, 50}}, n: 20, want: []int{2, 10, 20}},
+		{name: "steps round up", ramp: &Ramp{Steps: []int{1, 2}}, n: 10, want: []int{1, 10}},
+		{name: "canary larger than a step", ramp: &Ramp{Canary: 3, Steps: []int{10, 50}}, n: 10, want: []int{3, 5, 10}},
+		{name: "canary larger than the block", ramp: &Ramp{Canary: 5}, n: 2, want: []int{2}},
+	}
+
+	for _, test := range tests {
+		got := test.ramp.Waves(test.n)
+		if diff := pretty.Compare(test.want, got); diff != "" {
+			t.Errorf("TestRampWaves(%s): -want/+got:\n%s", test.name, diff)
+		}
+	}
+}
+
 func TestSequenceValidate(t *testing.T) {
 	t.Parallel()
 
@@ -679,6 +1060,43 @@ func TestSequenceValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "Error: Attempts is set",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.Attempts.Set([]SeqAttempt{{}})
+				return s
+			},
+			err: true,
+		},
+		{
+			name: "Error: RetryPolicy is invalid",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.Retries = 1
+				s.RetryPolicy = &RetryPolicy{}
+				return s
+			},
+			err: true,
+		},
+		{
+			name: "Error: Approval is invalid",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.Approval = &Approval{Key: "deploy", Expiry: -time.Second}
+				return s
+			},
+			err: true,
+		},
+		{
+			name: "Error: Locks is invalid",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.Locks = &Locks{Shared: []string{"cluster"}, Exclusive: []string{"cluster"}}
+				return s
+			},
+			err: true,
+		},
 		{
 			name:     "Error: Duplicate Key",
 			sequence: goodSequence,
@@ -691,6 +1109,26 @@ func TestSequenceValidate(t *testing.T) {
 			sequence: goodSequence,
 			vals:     []validator{goodSequence().Actions[0]},
 		},
+		{
+			name: "Success: with Retries and RetryPolicy",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.Retries = 2
+				p := DefaultRetryPolicy()
+				s.RetryPolicy = &p
+				return s
+			},
+			vals: []validator{goodSequence().Actions[0]},
+		},
+		{
+			name: "Success: with DeferredActions",
+			sequence: func() *Sequence {
+				s := goodSequence()
+				s.DeferredActions = &DeferredActions{}
+				return s
+			},
+			vals: []validator{goodSequence().Actions[0], &DeferredActions{}},
+		},
 	}
 
 	for _, test := range tests {